@@ -19,10 +19,16 @@ import (
 	"github.com/adverant/nexus/videoagent-worker/internal/queue"
 	"github.com/adverant/nexus/videoagent-worker/internal/similarity"
 	"github.com/adverant/nexus/videoagent-worker/internal/storage"
+	"github.com/adverant/nexus/videoagent-worker/internal/tracking"
 	"github.com/adverant/nexus/videoagent-worker/internal/utils"
 )
 
 func main() {
+	// Offline tools: "worker <subcommand> [flags]"
+	if len(os.Args) > 1 && os.Args[1] == "mot-eval" {
+		os.Exit(runMOTEval(os.Args[2:]))
+	}
+
 	// Check mode: "subprocess" or "standalone"
 	mode := getEnv("WORKER_MODE", "standalone")
 
@@ -114,6 +120,10 @@ func runSubprocessMode() {
 	if err != nil {
 		log.Printf("WARNING: Failed to initialize similarity module: %v", err)
 	} else {
+		if config.ReIDEmbeddingURL != "" {
+			similarityModule.QdrantManager.SetPersonDimension(config.ReIDEmbeddingDim)
+		}
+
 		// Initialize collections (best effort)
 		if err := similarityModule.InitializeCollections(ctx); err != nil {
 			log.Printf("WARNING: Failed to initialize Qdrant collections: %v", err)
@@ -209,6 +219,7 @@ func runSubprocessMode() {
 
 	// Step 3: Extract and analyze frames (if requested)
 	var frameResults []map[string]interface{}
+	var analyzedFrames []models.FrameAnalysis
 	shouldAnalyzeFrames := jobPayload.Options.AnalyzeFrames != nil && *jobPayload.Options.AnalyzeFrames
 	if shouldAnalyzeFrames {
 		log.Printf("Extracting and analyzing frames...")
@@ -219,6 +230,7 @@ func runSubprocessMode() {
 			os.Exit(1)
 		}
 		log.Printf("✓ Analyzed %d frames", len(frames))
+		analyzedFrames = frames
 
 		// Convert frames to serializable format
 		for _, frame := range frames {
@@ -237,16 +249,50 @@ func runSubprocessMode() {
 
 	// Step 4: Detect scenes (if requested)
 	var sceneResults []map[string]interface{}
-	if jobPayload.Options.ShouldDetectScenes() && len(frameResults) > 0 {
+	var scenes []models.SceneDetection
+	if jobPayload.Options.ShouldDetectScenes() && len(analyzedFrames) > 0 {
 		log.Printf("Detecting scenes...")
-		// Scene detection requires frames with embeddings
-		// For now, we'll skip scene detection in subprocess mode
-		// as it requires more complex coordination
-		log.Printf("⚠️ Scene detection not yet implemented in subprocess mode")
+		modelResp, err := mageAgent.SelectModel(ctx, models.MageAgentModelRequest{
+			TaskType:   "vision",
+			Complexity: 0.5,
+			Context: map[string]interface{}{
+				"task": "scene_detection",
+			},
+		})
+		if err != nil {
+			log.Printf("⚠️ Scene detection model selection failed: %v", err)
+			// Non-fatal - continue without scenes
+		} else {
+			frameExtractor := extractor.NewFrameExtractor(ffmpeg, mageAgent, config.WorkerConcurrency)
+			scenes, err = frameExtractor.DetectScenes(ctx, analyzedFrames, modelResp.ModelID)
+			if err != nil {
+				log.Printf("⚠️ Scene detection failed: %v", err)
+				// Non-fatal - continue without scenes
+				scenes = nil
+			} else {
+				log.Printf("✓ Detected %d scenes", len(scenes))
+			}
+		}
+
+		// Convert scenes to serializable format
+		for _, scene := range scenes {
+			sceneResults = append(sceneResults, map[string]interface{}{
+				"sceneId":     scene.SceneID,
+				"startTime":   scene.StartTime,
+				"endTime":     scene.EndTime,
+				"startFrame":  scene.StartFrame,
+				"endFrame":    scene.EndFrame,
+				"description": scene.Description,
+				"keyFrameId":  scene.KeyFrameID,
+				"sceneType":   scene.SceneType,
+				"confidence":  scene.Confidence,
+			})
+		}
 	}
 
 	// Step 5: Extract and transcribe audio (if requested)
 	var audioResult map[string]interface{}
+	var transcript *models.AudioAnalysis
 	shouldTranscribeAudio := jobPayload.Options.ShouldTranscribeAudio()
 	if shouldTranscribeAudio {
 		log.Printf("Extracting and transcribing audio...")
@@ -257,6 +303,7 @@ func runSubprocessMode() {
 			// Non-fatal - continue without audio
 		} else {
 			log.Printf("✓ Audio transcribed: %d characters, language: %s", len(audioAnalysis.Transcription), audioAnalysis.Language)
+			transcript = audioAnalysis
 			audioResult = map[string]interface{}{
 				"transcription": audioAnalysis.Transcription,
 				"language":      audioAnalysis.Language,
@@ -270,6 +317,133 @@ func runSubprocessMode() {
 		}
 	}
 
+	// Step 5b: Track objects (if requested)
+	var trackingResult *models.TrackingAnalysis
+	if jobPayload.Options.ShouldTrackObjects() {
+		log.Printf("Tracking objects...")
+		trackingStage := processor.NewTrackingStage(ffmpeg, mageAgent)
+		if detector := newDetector(config); detector != nil {
+			trackingStage.SetDetector(detector)
+		}
+		if embedder := newAppearanceEmbedder(config); embedder != nil {
+			trackingStage.SetAppearanceEmbedder(embedder)
+		}
+		trackingResult, err = trackingStage.Run(ctx, videoPath, jobPayload.JobID, jobPayload.TenantID(), jobPayload.Options, duration)
+		if err != nil {
+			log.Printf("⚠️ Object tracking failed: %v", err)
+			// Non-fatal - continue without tracking
+			trackingResult = nil
+		} else {
+			log.Printf("✓ Tracked %d objects (%d identities, %d interactions)",
+				len(trackingResult.Tracks), len(trackingResult.Identities), len(trackingResult.Interactions))
+		}
+	}
+
+	// Step 5c: Render annotated video (if requested)
+	var annotatedVideo *models.AnnotatedVideo
+	if jobPayload.Options.ShouldRenderAnnotatedVideo() {
+		log.Printf("Rendering annotated video...")
+		renderStage := processor.NewRenderStage(ffmpeg)
+		metadata := &models.VideoMetadata{Duration: duration, Width: width, Height: height}
+		annotatedVideo, err = renderStage.Run(ctx, videoPath, jobPayload.JobID, renderStage.OutputDir(&jobPayload), jobPayload.Options,
+			metadata, analyzedFrames, transcript, trackingResult, scenes)
+		if err != nil {
+			log.Printf("⚠️ Annotated video rendering failed: %v", err)
+			// Non-fatal - continue without annotated video
+			annotatedVideo = nil
+		} else {
+			log.Printf("✓ Annotated video rendered to %s (%s renderer)", annotatedVideo.Path, annotatedVideo.Renderer)
+		}
+	}
+
+	// Step 5d: Render poster frames, thumbnails and scrubbing sprites (if requested)
+	var thumbnails *models.ThumbnailSet
+	if jobPayload.Options.ShouldGenerateThumbnails() {
+		log.Printf("Generating thumbnails...")
+		thumbnailStage := processor.NewThumbnailStage(ffmpeg)
+		frameQuality := thumbnailStage.ScoreFrames(ctx, videoPath, jobPayload.JobID, analyzedFrames)
+		metadata := &models.VideoMetadata{Duration: duration, Width: width, Height: height}
+		thumbnails, err = thumbnailStage.Run(ctx, videoPath, jobPayload.JobID, processor.NewRenderStage(ffmpeg).OutputDir(&jobPayload), jobPayload.Options,
+			metadata, frameQuality, scenes)
+		if err != nil {
+			log.Printf("⚠️ Thumbnail generation failed: %v", err)
+			// Non-fatal - continue without thumbnails
+			thumbnails = nil
+		} else {
+			log.Printf("✓ Thumbnails generated (%d sprite sheets)", len(thumbnails.SpriteSheets))
+		}
+	}
+
+	// Step 5e: Technical QC (if requested)
+	var qcReport *models.QCReport
+	if jobPayload.Options.ShouldRunQC() {
+		log.Printf("Running technical QC...")
+		qcReport, err = processor.NewQCStage(ffmpeg).Run(ctx, videoPath, jobPayload.Options)
+		if err != nil {
+			log.Printf("⚠️ Technical QC failed: %v", err)
+			// Non-fatal - continue without QC
+			qcReport = nil
+		} else {
+			log.Printf("✓ QC complete: passed=%t (%d errors, %d warnings)", qcReport.Passed, qcReport.Errors, qcReport.Warnings)
+		}
+	}
+
+	// Step 5f: Export NLE timelines (if requested; cut at detected scenes, else the whole video is one clip)
+	var editExports []models.EditExport
+	if len(jobPayload.Options.GetExportFormats()) > 0 {
+		log.Printf("Exporting timelines...")
+		metadata := &models.VideoMetadata{Duration: duration, Width: width, Height: height}
+		if fps, ok := metadataMap["fps"].(float64); ok {
+			metadata.FrameRate = fps
+		}
+		metadata.AudioCodec, _ = metadataMap["audio_codec"].(string)
+		editExports, err = processor.NewExportStage().Run(&jobPayload, videoPath, processor.NewRenderStage(ffmpeg).OutputDir(&jobPayload),
+			metadata, scenes, nil, transcript)
+		if err != nil {
+			log.Printf("⚠️ Timeline export failed: %v", err)
+			// Non-fatal - continue without exports
+			editExports = nil
+		} else {
+			log.Printf("✓ Exported %d timelines", len(editExports))
+		}
+	}
+
+	// Step 5g: Generate highlight reel and clips (if requested; without scene detection shots are scored)
+	var highlightReel *models.HighlightReel
+	if jobPayload.Options.ShouldGenerateHighlights() {
+		log.Printf("Generating highlights...")
+		metadata := &models.VideoMetadata{Duration: duration, Width: width, Height: height}
+		metadata.AudioCodec, _ = metadataMap["audio_codec"].(string)
+		highlightReel, err = processor.NewHighlightStage(ffmpeg).Run(ctx, &jobPayload, videoPath, processor.NewRenderStage(ffmpeg).OutputDir(&jobPayload),
+			metadata, scenes, transcript, trackingResult)
+		if err != nil {
+			log.Printf("⚠️ Highlight generation failed: %v", err)
+			// Non-fatal - continue without highlights
+			highlightReel = nil
+		} else {
+			log.Printf("✓ Generated %.0fs highlight reel from %d highlights", highlightReel.Duration, len(highlightReel.Highlights))
+		}
+	}
+
+	// Step 5h: Reframe to vertical/square (if requested)
+	var reframed []models.ReframedVideo
+	if len(jobPayload.Options.GetReframeFormats()) > 0 {
+		log.Printf("Reframing video...")
+		metadata := &models.VideoMetadata{Duration: duration, Width: width, Height: height}
+		if fps, ok := metadataMap["fps"].(float64); ok {
+			metadata.FrameRate = fps
+		}
+		reframed, err = processor.NewReframeStage(ffmpeg).Run(ctx, &jobPayload, videoPath, processor.NewRenderStage(ffmpeg).OutputDir(&jobPayload),
+			metadata, analyzedFrames, trackingResult)
+		if err != nil {
+			log.Printf("⚠️ Reframing failed: %v", err)
+			// Non-fatal - continue without reframed renditions
+			reframed = nil
+		} else {
+			log.Printf("✓ Reframed to %d aspect ratios", len(reframed))
+		}
+	}
+
 	// Step 6: Build success response
 	log.Printf("✅ Video processing complete for job: %s", jobPayload.JobID)
 	successResponse := map[string]interface{}{
@@ -286,9 +460,16 @@ func runSubprocessMode() {
 				"format":   format,
 				"bitrate":  bitrate,
 			},
-			"frames": frameResults,
-			"scenes": sceneResults,
-			"audio":  audioResult,
+			"frames":         frameResults,
+			"scenes":         sceneResults,
+			"audio":          audioResult,
+			"tracking":       trackingResult,
+			"annotatedVideo": annotatedVideo,
+			"thumbnails":     thumbnails,
+			"qc":             qcReport,
+			"editExports":    editExports,
+			"highlights":     highlightReel,
+			"reframed":       reframed,
 		},
 	}
 
@@ -390,6 +571,19 @@ func runStandaloneMode() {
 		log.Fatalf("Failed to initialize similarity module: %v", err)
 	}
 
+	// Enable transcript/OCR/object-label hybrid components, moment retrieval and interaction search
+	similarityModule.SearchAPI.SetKeywordSearcher(storageManager)
+	similarityModule.SearchAPI.SetTimelineSource(storageManager)
+	similarityModule.SearchAPI.SetInteractionSource(storageManager)
+
+	// Enable video-clip queries (frame sampling via FFmpeg)
+	similarityModule.SearchAPI.SetFFmpegHelper(ffmpeg)
+
+	// Person re-ID vectors follow the embedding server (128-D colour histograms otherwise)
+	if config.ReIDEmbeddingURL != "" {
+		similarityModule.QdrantManager.SetPersonDimension(config.ReIDEmbeddingDim)
+	}
+
 	// Initialize Qdrant collections (1024-D vectors)
 	if err := similarityModule.InitializeCollections(ctx); err != nil {
 		log.Printf("WARNING: Failed to initialize Qdrant collections: %v", err)
@@ -411,6 +605,30 @@ func runStandaloneMode() {
 		log.Println("✓ Video processor configured with YouTube OAuth authentication")
 	}
 
+	// Store frame and transcript embeddings so moment retrieval only embeds the query
+	videoProcessor.SetTextEmbedder(graphragClient)
+
+	// Index video and scene embeddings (tenant-scoped) for similarity search
+	videoProcessor.SetVideoIndexer(similarityModule)
+
+	// Configure dedicated object detector for tracking (MageAgent vision otherwise)
+	if detector := newDetector(config); detector != nil {
+		videoProcessor.SetDetector(detector)
+		log.Printf("✓ Tracking detector configured: %s", detector.Name())
+	}
+
+	// Configure person re-ID embedding server (colour histograms otherwise)
+	if embedder := newAppearanceEmbedder(config); embedder != nil {
+		videoProcessor.SetAppearanceEmbedder(embedder)
+		log.Printf("✓ Re-ID embedder configured: %s", config.ReIDEmbeddingURL)
+	}
+
+	// Persistent cross-video re-ID gallery (PostgreSQL, indexed in Qdrant)
+	personGallery := tracking.NewPersonGallery(storageManager)
+	personGallery.SetIndex(similarityModule.QdrantManager)
+	personGallery.SetRetention(config.GalleryMaxIdentities, time.Duration(config.GalleryRetentionDays)*24*time.Hour)
+	videoProcessor.SetPersonGallery(personGallery)
+
 	log.Println("✓ Video processor initialized")
 
 	// 7. Queue consumer
@@ -478,11 +696,37 @@ func loadConfig() models.Config {
 		TempDir:               getEnv("TEMP_DIR", "/tmp/videoagent"),
 		MaxVideoSize:          getEnvInt64("MAX_VIDEO_SIZE", 2*1024*1024*1024), // 2GB default
 		EnableGoogleDrive:     getEnvBool("ENABLE_GOOGLE_DRIVE", true),
+		DetectorURL:           getEnv("DETECTOR_URL", ""),
+		DetectorAPIKey:        getEnv("DETECTOR_API_KEY", ""),
+		ReIDEmbeddingURL:      getEnv("REID_EMBEDDING_URL", ""),
+		ReIDEmbeddingAPIKey:   getEnv("REID_EMBEDDING_API_KEY", ""),
+		ReIDEmbeddingDim:      getEnvInt("REID_EMBEDDING_DIM", 512),
+		GalleryMaxIdentities:  getEnvInt("REID_GALLERY_MAX_IDENTITIES", 10000),
+		GalleryRetentionDays:  getEnvInt("REID_GALLERY_RETENTION_DAYS", 365),
 	}
 
 	return config
 }
 
+// newDetector builds the tracking detector from configuration
+// Returns nil when no detection server is configured (MageAgent vision is used)
+// Recorded detections are only replayed by the mot-eval command, never for jobs.
+func newDetector(config models.Config) tracking.Detector {
+	if config.DetectorURL == "" {
+		return nil
+	}
+	return tracking.NewHTTPDetector(config.DetectorURL, config.DetectorAPIKey, 30*time.Second)
+}
+
+// newAppearanceEmbedder builds the re-ID embedder from configuration
+// Returns nil when no embedding server is configured (colour histograms are used)
+func newAppearanceEmbedder(config models.Config) tracking.AppearanceEmbedder {
+	if config.ReIDEmbeddingURL == "" {
+		return nil
+	}
+	return tracking.NewHTTPAppearanceEmbedder(config.ReIDEmbeddingURL, config.ReIDEmbeddingAPIKey, 30*time.Second)
+}
+
 // getEnv gets environment variable with default
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/tracking"
+)
+
+// runMOTEval replays recorded detections through the tracker and scores it against MOT ground truth
+//
+//	worker mot-eval -detections det.txt -gt gt.txt -width 1920 -height 1080 [-tracker bytetrack] [-output res.txt]
+//
+// Detections are a MOTChallenge det file (pixels) or a replay JSON Lines file (normalized).
+// Metrics are printed as JSON; -min-mota/-min-idf1 make the command fail on regressions.
+func runMOTEval(args []string) int {
+	fs := flag.NewFlagSet("mot-eval", flag.ContinueOnError)
+	detectionsPath := fs.String("detections", "", "recorded detections: MOT det .txt or replay .jsonl (required)")
+	groundTruthPath := fs.String("gt", "", "MOTChallenge ground-truth file")
+	outputPath := fs.String("output", "", "write tracker output in MOTChallenge format")
+	trackerType := fs.String("tracker", string(tracking.TrackerByteTrack), "tracker type: bytetrack, deepsort or simple_iou")
+	width := fs.Float64("width", 0, "frame width in pixels (required for MOT files)")
+	height := fs.Float64("height", 0, "frame height in pixels (required for MOT files)")
+	fps := fs.Float64("fps", 30, "frame rate of the sequence (trajectory timestamps and lost-track lifetime)")
+	frames := fs.Int("frames", 0, "number of frames to track (default: last frame in detections or ground truth)")
+	iouThreshold := fs.Float64("iou", 0.5, "IoU threshold for a ground-truth match")
+	classes := fs.String("gt-classes", "", "comma-separated ground-truth classes to keep (e.g. 1 for MOT17 pedestrians)")
+	minMOTA := fs.Float64("min-mota", -1e9, "fail if MOTA is below this value")
+	minIDF1 := fs.Float64("min-idf1", 0, "fail if IDF1 is below this value")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *detectionsPath == "" {
+		fmt.Fprintln(os.Stderr, "mot-eval: -detections is required")
+		fs.Usage()
+		return 2
+	}
+
+	switch tracking.TrackerType(*trackerType) {
+	case tracking.TrackerByteTrack, tracking.TrackerDeepSORT, tracking.TrackerSimpleIOU:
+	default:
+		fmt.Fprintf(os.Stderr, "mot-eval: unknown tracker type: %s\n", *trackerType)
+		return 2
+	}
+
+	if !(*fps > 0) { // Also rejects NaN
+		fmt.Fprintf(os.Stderr, "mot-eval: -fps must be positive: %g\n", *fps)
+		return 2
+	}
+
+	// Step 1: Load detections
+	var detector *tracking.ReplayDetector
+	if strings.EqualFold(filepath.Ext(*detectionsPath), ".jsonl") {
+		replay, err := tracking.NewReplayDetector(*detectionsPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "mot-eval: %v\n", err)
+			return 1
+		}
+		detector = replay
+	} else {
+		records, err := readMOTFile(*detectionsPath, *width, *height)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "mot-eval: %v\n", err)
+			return 1
+		}
+		detector = tracking.NewReplayDetectorFromFrames(tracking.DetectionsFromMOT(records, tracking.ClassPerson))
+	}
+
+	// Step 2: Load ground truth
+	var groundTruth []tracking.MOTRecord
+	if *groundTruthPath != "" {
+		records, err := readMOTFile(*groundTruthPath, *width, *height)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "mot-eval: %v\n", err)
+			return 1
+		}
+
+		keep := make([]int, 0)
+		for _, field := range strings.Split(*classes, ",") {
+			var class int
+			if _, err := fmt.Sscanf(strings.TrimSpace(field), "%d", &class); err == nil {
+				keep = append(keep, class)
+			}
+		}
+		groundTruth = tracking.FilterGroundTruth(records, keep...)
+	}
+
+	frameCount := *frames
+	if frameCount <= 0 {
+		frameCount = detector.FrameCount()
+		for _, record := range groundTruth {
+			if record.Frame > frameCount {
+				frameCount = record.Frame
+			}
+		}
+	}
+
+	// Step 3: Track every frame
+	ctx := context.Background()
+	tracker := tracking.NewMultiObjectTrackerWithDetector(tracking.TrackerType(*trackerType), detector)
+	tracker.SetFrameRate(*fps)
+	videoEpoch := time.Unix(0, 0).UTC()
+	results := make([]*tracking.TrackingResult, 0, frameCount)
+	for i := 0; i < frameCount; i++ {
+		frameTime := videoEpoch.Add(time.Duration(float64(i) / *fps * float64(time.Second)))
+		result, err := tracker.TrackAt(ctx, "", frameTime)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "mot-eval: tracking failed at frame %d: %v\n", i+1, err)
+			return 1
+		}
+		results = append(results, result)
+	}
+	hypotheses := tracking.MOTRecordsFromResults(results)
+
+	// Step 4: Export tracker output
+	if *outputPath != "" {
+		if err := writeMOTFile(*outputPath, hypotheses, *width, *height); err != nil {
+			fmt.Fprintf(os.Stderr, "mot-eval: %v\n", err)
+			return 1
+		}
+	}
+
+	// Step 5: Evaluate
+	if *groundTruthPath == "" {
+		fmt.Fprintf(os.Stderr, "mot-eval: tracked %d frames, %d boxes (no ground truth, skipping evaluation)\n", frameCount, len(hypotheses))
+		return 0
+	}
+
+	metrics := tracking.EvaluateMOT(groundTruth, hypotheses, *iouThreshold)
+	output, _ := json.MarshalIndent(metrics, "", "  ")
+	fmt.Println(string(output))
+
+	if metrics.MOTA < *minMOTA {
+		fmt.Fprintf(os.Stderr, "mot-eval: MOTA %.4f below minimum %.4f\n", metrics.MOTA, *minMOTA)
+		return 1
+	}
+	if metrics.IDF1 < *minIDF1 {
+		fmt.Fprintf(os.Stderr, "mot-eval: IDF1 %.4f below minimum %.4f\n", metrics.IDF1, *minIDF1)
+		return 1
+	}
+
+	return 0
+}
+
+// readMOTFile reads a MOTChallenge file from disk
+func readMOTFile(path string, width, height float64) ([]tracking.MOTRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	records, err := tracking.ReadMOT(file, width, height)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return records, nil
+}
+
+// writeMOTFile writes a MOTChallenge file to disk
+func writeMOTFile(path string, records []tracking.MOTRecord, width, height float64) error {
+	if width <= 0 || height <= 0 {
+		// Replay input is normalized; write normalized boxes
+		width, height = 1, 1
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if err := tracking.WriteMOT(file, records, width, height); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+	"github.com/adverant/nexus/videoagent-worker/internal/tracking"
+)
+
+// writeSequence writes a two-person MOT sequence (1000x1000 frames) as detections and ground truth
+func writeSequence(t *testing.T, frames int) (string, string) {
+	t.Helper()
+	var det, gt strings.Builder
+	for f := 1; f <= frames; f++ {
+		left := 100 + 10*f
+		right := 700 - 10*f
+		fmt.Fprintf(&det, "%d,-1,%d,300,100,300,0.9,-1,-1,-1\n", f, left)
+		fmt.Fprintf(&det, "%d,-1,%d,300,100,300,0.8,-1,-1,-1\n", f, right)
+		fmt.Fprintf(&gt, "%d,1,%d,300,100,300,1,1,1\n", f, left)
+		fmt.Fprintf(&gt, "%d,2,%d,300,100,300,1,1,1\n", f, right)
+	}
+
+	dir := t.TempDir()
+	detPath := filepath.Join(dir, "det.txt")
+	gtPath := filepath.Join(dir, "gt.txt")
+	if err := os.WriteFile(detPath, []byte(det.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(gtPath, []byte(gt.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return detPath, gtPath
+}
+
+func TestMOTEvalScoresPerfectDetections(t *testing.T) {
+	detPath, gtPath := writeSequence(t, 10)
+	outPath := filepath.Join(t.TempDir(), "res.txt")
+
+	// Tentative frames are exported too, so perfect detections give perfect scores
+	code := runMOTEval([]string{
+		"-detections", detPath, "-gt", gtPath, "-output", outPath,
+		"-width", "1000", "-height", "1000", "-fps", "5",
+		"-min-mota", "1", "-min-idf1", "1",
+	})
+	if code != 0 {
+		t.Fatalf("runMOTEval exit code = %d, want 0", code)
+	}
+
+	file, err := os.Open(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	records, err := tracking.ReadMOT(file, 1000, 1000)
+	if err != nil {
+		t.Fatalf("ReadMOT output: %v", err)
+	}
+	if len(records) != 20 {
+		t.Errorf("output has %d boxes, want 20", len(records))
+	}
+}
+
+func TestMOTEvalIsDeterministic(t *testing.T) {
+	detPath, _ := writeSequence(t, 15)
+	dir := t.TempDir()
+
+	outputs := make([]string, 2)
+	for i := range outputs {
+		path := filepath.Join(dir, fmt.Sprintf("run%d.txt", i))
+		if code := runMOTEval([]string{"-detections", detPath, "-output", path, "-width", "1000", "-height", "1000"}); code != 0 {
+			t.Fatalf("run %d: exit code %d", i, code)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[i] = string(data)
+	}
+	if outputs[0] != outputs[1] {
+		t.Errorf("tracker output differs between runs:\n%s\nvs\n%s", outputs[0], outputs[1])
+	}
+}
+
+func TestMOTEvalFailsBelowMinimum(t *testing.T) {
+	detPath, gtPath := writeSequence(t, 10)
+
+	// Ground truth for a sequence twice as long leaves half of it unmatched
+	_, longGT := writeSequence(t, 20)
+	if code := runMOTEval([]string{"-detections", detPath, "-gt", gtPath, "-width", "1000", "-height", "1000", "-min-mota", "1"}); code != 0 {
+		t.Fatalf("exit code = %d for a perfect run, want 0", code)
+	}
+	if code := runMOTEval([]string{"-detections", detPath, "-gt", longGT, "-width", "1000", "-height", "1000", "-min-mota", "0.9"}); code != 1 {
+		t.Errorf("exit code = %d below the MOTA minimum, want 1", code)
+	}
+}
+
+func TestMOTEvalRejectsBadArguments(t *testing.T) {
+	detPath, _ := writeSequence(t, 2)
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "missing detections", args: []string{}, want: 2},
+		{name: "unknown tracker", args: []string{"-detections", detPath, "-tracker", "kcf"}, want: 2},
+		{name: "zero fps", args: []string{"-detections", detPath, "-width", "1000", "-height", "1000", "-fps", "0"}, want: 2},
+		{name: "negative fps", args: []string{"-detections", detPath, "-width", "1000", "-height", "1000", "-fps", "-25"}, want: 2},
+		{name: "NaN fps", args: []string{"-detections", detPath, "-width", "1000", "-height", "1000", "-fps", "NaN"}, want: 2},
+		{name: "MOT file without frame size", args: []string{"-detections", detPath}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runMOTEval(tt.args); got != tt.want {
+				t.Errorf("exit code = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDetectorNeverReplays(t *testing.T) {
+	if detector := newDetector(models.Config{}); detector != nil {
+		t.Errorf("newDetector() = %s without a detection server, want nil (MageAgent vision)", detector.Name())
+	}
+
+	detector := newDetector(models.Config{DetectorURL: "http://detector:8080"})
+	if _, ok := detector.(*tracking.HTTPDetector); !ok {
+		t.Errorf("newDetector() = %T with a detection server, want *tracking.HTTPDetector", detector)
+	}
+}
@@ -0,0 +1,292 @@
+package highlights
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// Weights balance the components of a scene's highlight score
+type Weights struct {
+	Interaction float64 // Strongest tracked interaction in the scene
+	Loudness    float64 // Loudest moment relative to the programme
+	Keywords    float64 // Keyword mentions per minute of transcript
+	Priority    float64 // Scene-type priority
+}
+
+// DefaultWeights favours on-screen interactions, then audio peaks and keywords
+var DefaultWeights = Weights{Interaction: 0.35, Loudness: 0.25, Keywords: 0.25, Priority: 0.15}
+
+// defaultPriority is the priority of scene types not in the table
+const defaultPriority = 0.4
+
+// sceneTypePriority ranks scene types by how well they work out of context
+var sceneTypePriority = map[string]float64{
+	"action":       1.0,
+	"montage":      0.7,
+	"dialogue":     0.6,
+	"interview":    0.5,
+	"establishing": 0.3,
+	"title":        0.1,
+	"credits":      0.0,
+	"transition":   0.0,
+}
+
+// loudnessFloor drops samples below this (LUFS) as silence
+const loudnessFloor = -70.0
+
+// Candidate is a scored stretch of the source a highlight can be cut from
+type Candidate struct {
+	SceneID     string
+	SceneType   string
+	Start       float64
+	End         float64
+	Peak        float64 // Most interesting moment (seconds); segments are centred on it
+	Interaction float64
+	Loudness    float64
+	Keywords    float64
+	Priority    float64
+	Score       float64
+	Reasons     []string
+}
+
+// Input is what the scorer ranks scenes with
+type Input struct {
+	Scenes       []models.SceneDetection // Empty scores shots (or the whole source) instead
+	Duration     float64
+	Interactions []models.InteractionRecord
+	Transcript   []models.SpeakerSegment
+	Keywords     []string
+	Signals      *Signals
+}
+
+// Scorer ranks scenes as highlight candidates
+type Scorer struct {
+	weights Weights
+}
+
+// NewScorer creates a scorer with the default weights
+func NewScorer() *Scorer {
+	return &Scorer{weights: DefaultWeights}
+}
+
+// SetWeights replaces the component weights
+func (s *Scorer) SetWeights(weights Weights) {
+	s.weights = weights
+}
+
+// Score scores every candidate stretch; candidates are returned in source order
+func (s *Scorer) Score(in Input) []Candidate {
+	signals := in.Signals
+	if signals == nil {
+		signals = &Signals{}
+	}
+
+	candidates := spans(in.Scenes, signals.ShotBoundaries, in.Duration)
+	if len(candidates) == 0 {
+		return candidates
+	}
+
+	reference, ceiling := loudnessRange(signals.Loudness)
+	matchers := keywordMatchers(in.Keywords)
+
+	densities := make([]float64, len(candidates))
+	hits := make([][]string, len(candidates))
+	firstHit := make([]float64, len(candidates))
+	maxDensity := 0.0
+	for i, c := range candidates {
+		count := 0
+		firstHit[i] = -1
+		seen := make(map[string]bool)
+		for _, segment := range in.Transcript {
+			mid := (segment.StartTime + segment.EndTime) / 2
+			if mid < c.Start || mid >= c.End {
+				continue
+			}
+			for _, m := range matchers {
+				n := len(m.pattern.FindAllStringIndex(segment.Text, -1))
+				if n == 0 {
+					continue
+				}
+				count += n
+				if firstHit[i] < 0 {
+					firstHit[i] = segment.StartTime
+				}
+				if !seen[m.keyword] {
+					seen[m.keyword] = true
+					hits[i] = append(hits[i], m.keyword)
+				}
+			}
+		}
+		if length := c.End - c.Start; length > 0 && count > 0 {
+			densities[i] = float64(count) / (math.Max(length, 1) / 60)
+			maxDensity = math.Max(maxDensity, densities[i])
+		}
+	}
+
+	for i := range candidates {
+		c := &candidates[i]
+		c.Peak = (c.Start + c.End) / 2
+		peakFound := false
+
+		// Strongest interaction overlapping the stretch
+		var strongest *models.InteractionRecord
+		for j := range in.Interactions {
+			interaction := &in.Interactions[j]
+			if interaction.EndTime < c.Start || interaction.StartTime >= c.End {
+				continue
+			}
+			if strongest == nil || interaction.Significance > strongest.Significance {
+				strongest = interaction
+			}
+		}
+		if strongest != nil {
+			c.Interaction = clamp01(strongest.Significance)
+			c.Peak = clampTime(strongest.StartTime, c.Start, c.End)
+			peakFound = true
+			c.Reasons = append(c.Reasons, fmt.Sprintf("%s interaction (%.2f)", strongest.Type, strongest.Significance))
+		}
+
+		// Loudest moment, relative to the programme's typical level
+		if ceiling > reference {
+			loudest, at := math.Inf(-1), 0.0
+			for _, sample := range signals.Loudness {
+				if sample.Time >= c.Start && sample.Time < c.End && sample.Momentary > loudest {
+					loudest, at = sample.Momentary, sample.Time
+				}
+			}
+			if !math.IsInf(loudest, -1) {
+				c.Loudness = clamp01((loudest - reference) / (ceiling - reference))
+				if !peakFound && c.Loudness > 0 {
+					c.Peak = at
+					peakFound = true
+				}
+				if c.Loudness >= 0.5 {
+					c.Reasons = append(c.Reasons, fmt.Sprintf("loudness peak %.1f LUFS", loudest))
+				}
+			}
+		}
+
+		if maxDensity > 0 {
+			c.Keywords = densities[i] / maxDensity
+		}
+		if len(hits[i]) > 0 {
+			if !peakFound {
+				c.Peak = firstHit[i]
+			}
+			c.Reasons = append(c.Reasons, "keywords: "+strings.Join(hits[i], ", "))
+		}
+
+		c.Priority = defaultPriority
+		if priority, ok := sceneTypePriority[strings.ToLower(c.SceneType)]; ok {
+			c.Priority = priority
+		}
+		if c.SceneType != "" {
+			c.Reasons = append(c.Reasons, c.SceneType+" scene")
+		}
+
+		total := s.weights.Interaction + s.weights.Loudness + s.weights.Keywords + s.weights.Priority
+		if total > 0 {
+			c.Score = (s.weights.Interaction*c.Interaction +
+				s.weights.Loudness*c.Loudness +
+				s.weights.Keywords*c.Keywords +
+				s.weights.Priority*c.Priority) / total
+		}
+	}
+
+	return candidates
+}
+
+// spans builds candidate stretches: scenes running on to the next scene's start,
+// otherwise shots, otherwise the whole source
+func spans(scenes []models.SceneDetection, boundaries []float64, duration float64) []Candidate {
+	candidates := make([]Candidate, 0)
+	switch {
+	case len(scenes) > 0:
+		sorted := make([]models.SceneDetection, len(scenes))
+		copy(sorted, scenes)
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].StartTime < sorted[j].StartTime })
+		for i, scene := range sorted {
+			end := math.Max(scene.EndTime, duration)
+			if i+1 < len(sorted) {
+				end = sorted[i+1].StartTime
+			}
+			candidates = append(candidates, Candidate{
+				SceneID:   scene.SceneID,
+				SceneType: scene.SceneType,
+				Start:     scene.StartTime,
+				End:       end,
+			})
+		}
+	case len(boundaries) > 0:
+		starts := append([]float64{0}, boundaries...)
+		for i, start := range starts {
+			end := duration
+			if i+1 < len(starts) {
+				end = starts[i+1]
+			}
+			candidates = append(candidates, Candidate{Start: start, End: end})
+		}
+	case duration > 0:
+		candidates = append(candidates, Candidate{Start: 0, End: duration})
+	}
+
+	kept := candidates[:0]
+	for _, c := range candidates {
+		if c.End > c.Start {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// loudnessRange returns the programme's median and maximum momentary loudness
+func loudnessRange(samples []LoudnessSample) (float64, float64) {
+	levels := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		if sample.Momentary > loudnessFloor {
+			levels = append(levels, sample.Momentary)
+		}
+	}
+	if len(levels) == 0 {
+		return 0, 0
+	}
+	sort.Float64s(levels)
+	return levels[len(levels)/2], levels[len(levels)-1]
+}
+
+type keywordMatcher struct {
+	keyword string
+	pattern *regexp.Regexp
+}
+
+// keywordMatchers builds case-insensitive whole-word matchers for the keywords
+func keywordMatchers(keywords []string) []keywordMatcher {
+	matchers := make([]keywordMatcher, 0, len(keywords))
+	seen := make(map[string]bool)
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		key := strings.ToLower(keyword)
+		if keyword == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		matchers = append(matchers, keywordMatcher{
+			keyword: keyword,
+			pattern: regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(keyword) + `\b`),
+		})
+	}
+	return matchers
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+func clampTime(t, start, end float64) float64 {
+	return math.Max(start, math.Min(end, t))
+}
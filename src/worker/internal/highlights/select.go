@@ -0,0 +1,136 @@
+package highlights
+
+import (
+	"math"
+	"sort"
+)
+
+// Segment limits (seconds)
+const (
+	defaultMinLength     = 2.0  // Shorter cuts read as flashes
+	defaultMaxLength     = 12.0 // One scene should not dominate the reel
+	defaultSnapTolerance = 1.5  // How far a cut may move to land on a shot boundary
+	leadIn               = 0.35 // Fraction of a segment placed before its peak
+)
+
+// Segment is a selected highlight: part of a candidate, cut at shot boundaries
+type Segment struct {
+	Candidate
+	Start float64
+	End   float64
+}
+
+// Duration is the segment length in seconds
+func (s Segment) Duration() float64 {
+	return s.End - s.Start
+}
+
+// Selector picks the best candidates up to a target reel length
+type Selector struct {
+	minLength     float64
+	maxLength     float64
+	snapTolerance float64
+}
+
+// NewSelector creates a selector with the default segment limits
+func NewSelector() *Selector {
+	return &Selector{
+		minLength:     defaultMinLength,
+		maxLength:     defaultMaxLength,
+		snapTolerance: defaultSnapTolerance,
+	}
+}
+
+// SetSegmentLength sets the shortest and longest segment (seconds)
+func (s *Selector) SetSegmentLength(min, max float64) {
+	if min > 0 && max >= min {
+		s.minLength, s.maxLength = min, max
+	}
+}
+
+// Select takes candidates in score order, cutting a window around each one's peak
+// and snapping its ends to the nearest shot boundary, until the target is filled
+// Overlapping windows are skipped; segments are returned in source order.
+func (s *Selector) Select(candidates []Candidate, boundaries []float64, target float64) []Segment {
+	ranked := make([]Candidate, len(candidates))
+	copy(ranked, candidates)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Score > ranked[j].Score })
+
+	selected := make([]Segment, 0)
+	total := 0.0
+	for _, c := range ranked {
+		remaining := target - total
+		if remaining < s.minLength {
+			break
+		}
+		if c.Score <= 0 || c.End-c.Start < s.minLength {
+			continue
+		}
+
+		segment, ok := s.cut(c, boundaries, math.Min(s.maxLength, remaining))
+		if !ok || overlaps(selected, segment) {
+			continue
+		}
+		selected = append(selected, segment)
+		total += segment.Duration()
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool { return selected[i].Start < selected[j].Start })
+	return selected
+}
+
+// cut places a window of at most maxLength around the candidate's peak, snapped to shots
+func (s *Selector) cut(c Candidate, boundaries []float64, maxLength float64) (Segment, bool) {
+	length := math.Min(c.End-c.Start, maxLength)
+	if length < s.minLength {
+		return Segment{}, false
+	}
+
+	start := c.Peak - length*leadIn
+	start = math.Max(c.Start, math.Min(c.End-length, start))
+
+	// Cut points: shot changes inside the candidate plus its own ends
+	cuts := []float64{c.Start, c.End}
+	for _, b := range boundaries {
+		if b > c.Start && b < c.End {
+			cuts = append(cuts, b)
+		}
+	}
+
+	if snapped, ok := nearest(cuts, start, start-s.snapTolerance, math.Min(start+s.snapTolerance, c.End-s.minLength)); ok {
+		start = snapped
+	}
+	end := start + length
+	if snapped, ok := nearest(cuts, end, math.Max(end-s.snapTolerance, start+s.minLength), math.Min(start+maxLength, c.End)); ok {
+		end = snapped
+	}
+	end = math.Min(end, math.Min(start+maxLength, c.End))
+	if end-start < s.minLength {
+		return Segment{}, false
+	}
+
+	return Segment{Candidate: c, Start: start, End: end}, true
+}
+
+// nearest returns the cut closest to t within [lo, hi]
+func nearest(cuts []float64, t, lo, hi float64) (float64, bool) {
+	best, found := 0.0, false
+	for _, cut := range cuts {
+		if cut < lo || cut > hi {
+			continue
+		}
+		if !found || math.Abs(cut-t) < math.Abs(best-t) {
+			best, found = cut, true
+		}
+	}
+	return best, found
+}
+
+func overlaps(selected []Segment, segment Segment) bool {
+	for _, other := range selected {
+		if segment.Start < other.End && other.Start < segment.End {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package highlights
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// shotChangeThreshold is the ffmpeg scene-change score above which a frame starts a new shot
+const shotChangeThreshold = 0.3
+
+// LoudnessSample is the momentary (400 ms) loudness at a time
+type LoudnessSample struct {
+	Time      float64 // Seconds
+	Momentary float64 // LUFS
+}
+
+// Signals are the audio and cut measurements highlights are chosen from
+type Signals struct {
+	Loudness       []LoudnessSample
+	ShotBoundaries []float64 // Shot start times (seconds), ascending
+}
+
+// FilterGraph builds the ffmpeg -filter_complex graph measuring shot changes and
+// (when there is audio) momentary loudness in one decode
+// It returns the graph and the output labels to map.
+func FilterGraph(hasAudio bool) (string, []string) {
+	video := fmt.Sprintf("[0:v:0]select=gt(scene\\,%g),showinfo[vout]", shotChangeThreshold)
+	if !hasAudio {
+		return video, []string{"[vout]"}
+	}
+	return video + ";[0:a:0]ebur128=framelog=info[aout]", []string{"[vout]", "[aout]"}
+}
+
+var (
+	momentaryPattern = regexp.MustCompile(`\bt:\s*([\d.]+)\s+.*\bM:\s*(-?[\d.]+|-inf)`)
+	showinfoPattern  = regexp.MustCompile(`Parsed_showinfo.*\bpts_time:\s*([\d.]+)`)
+)
+
+// ParseLog extracts momentary loudness and shot changes from ffmpeg's log output
+func ParseLog(log string) *Signals {
+	signals := &Signals{}
+	for _, line := range strings.Split(log, "\n") {
+		if match := showinfoPattern.FindStringSubmatch(line); match != nil {
+			if t, err := strconv.ParseFloat(match[1], 64); err == nil {
+				signals.ShotBoundaries = append(signals.ShotBoundaries, t)
+			}
+			continue
+		}
+		if match := momentaryPattern.FindStringSubmatch(line); match != nil {
+			t, err := strconv.ParseFloat(match[1], 64)
+			if err != nil || match[2] == "-inf" {
+				continue
+			}
+			m, err := strconv.ParseFloat(match[2], 64)
+			if err != nil {
+				continue
+			}
+			signals.Loudness = append(signals.Loudness, LoudnessSample{Time: t, Momentary: m})
+		}
+	}
+
+	sort.Float64s(signals.ShotBoundaries)
+	return signals
+}
@@ -0,0 +1,228 @@
+package imagestats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// kmeansIterations bounds the refinement of dominant colour clusters
+const kmeansIterations = 10
+
+// dominantColors clusters pixels into up to k colours with k-means in RGB
+// Centres are seeded deterministically (farthest-point), so equal images give equal results.
+func dominantColors(pixels []rgb, k int) []DominantColor {
+	if len(pixels) == 0 {
+		return nil
+	}
+
+	points := make([][3]float64, len(pixels))
+	for i, p := range pixels {
+		points[i] = [3]float64{float64(p.r), float64(p.g), float64(p.b)}
+	}
+
+	// Step 1: Seed with the mean colour, then repeatedly the pixel farthest from all centres
+	centres := make([][3]float64, 0, k)
+	var mean [3]float64
+	for _, p := range points {
+		for c := 0; c < 3; c++ {
+			mean[c] += p[c] / float64(len(points))
+		}
+	}
+	centres = append(centres, mean)
+	distances := make([]float64, len(points))
+	for i, p := range points {
+		distances[i] = sqDist(p, mean)
+	}
+	for len(centres) < k {
+		far := 0
+		for i := range points {
+			if distances[i] > distances[far] {
+				far = i
+			}
+		}
+		if distances[far] < 1 {
+			break // Fewer distinct colours than k
+		}
+		centres = append(centres, points[far])
+		for i, p := range points {
+			distances[i] = math.Min(distances[i], sqDist(p, points[far]))
+		}
+	}
+
+	// Step 2: Lloyd iterations
+	assignment := make([]int, len(points))
+	counts := make([]int, len(centres))
+	for iter := 0; iter < kmeansIterations; iter++ {
+		changed := false
+		for i, p := range points {
+			best, bestDist := 0, math.MaxFloat64
+			for c, centre := range centres {
+				if d := sqDist(p, centre); d < bestDist {
+					best, bestDist = c, d
+				}
+			}
+			if assignment[i] != best {
+				assignment[i] = best
+				changed = true
+			}
+		}
+
+		sums := make([][3]float64, len(centres))
+		counts = make([]int, len(centres))
+		for i, p := range points {
+			c := assignment[i]
+			for j := 0; j < 3; j++ {
+				sums[c][j] += p[j]
+			}
+			counts[c]++
+		}
+		for c := range centres {
+			if counts[c] > 0 {
+				for j := 0; j < 3; j++ {
+					centres[c][j] = sums[c][j] / float64(counts[c])
+				}
+			}
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	colors := make([]DominantColor, 0, len(centres))
+	for c, centre := range centres {
+		if counts[c] == 0 {
+			continue
+		}
+		p := rgb{uint8(math.Round(centre[0])), uint8(math.Round(centre[1])), uint8(math.Round(centre[2]))}
+		colors = append(colors, DominantColor{
+			Hex:   fmt.Sprintf("#%02x%02x%02x", p.r, p.g, p.b),
+			Name:  colorName(p),
+			Share: float64(counts[c]) / float64(len(points)),
+			R:     p.r,
+			G:     p.g,
+			B:     p.b,
+		})
+	}
+	sort.SliceStable(colors, func(i, j int) bool { return colors[i].Share > colors[j].Share })
+	return colors
+}
+
+// sqDist is the squared Euclidean distance between two RGB points
+func sqDist(a, b [3]float64) float64 {
+	dr, dg, db := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dr*dr + dg*dg + db*db
+}
+
+// colorName maps a colour to a basic colour name
+func colorName(p rgb) string {
+	hue, sat, val := toHSV(p)
+	switch {
+	case val < 0.2:
+		return "black"
+	case sat < 0.15 && val > 0.85:
+		return "white"
+	case sat < 0.15:
+		return "gray"
+	}
+
+	// Dark, muted oranges and reds read as brown
+	if (hue < 45 || hue >= 345) && val < 0.6 && sat < 0.8 && hue >= 10 {
+		return "brown"
+	}
+
+	switch {
+	case hue < 15 || hue >= 345:
+		return "red"
+	case hue < 45:
+		return "orange"
+	case hue < 70:
+		return "yellow"
+	case hue < 160:
+		return "green"
+	case hue < 200:
+		return "cyan"
+	case hue < 260:
+		return "blue"
+	case hue < 300:
+		return "purple"
+	default:
+		return "pink"
+	}
+}
+
+// colorTemperature estimates the correlated colour temperature (Kelvin) of the average
+// illuminant: mid-tone pixels are averaged in linear sRGB, converted to CIE xy and
+// passed through McCamy's approximation
+func colorTemperature(pixels []rgb) float64 {
+	var sumR, sumG, sumB float64
+	n := 0
+	for _, p := range pixels {
+		_, _, val := toHSV(p)
+		// Clipped highlights and deep shadows say little about the light
+		if val < 0.1 || val > 0.98 {
+			continue
+		}
+		sumR += linearize(p.r)
+		sumG += linearize(p.g)
+		sumB += linearize(p.b)
+		n++
+	}
+	if n == 0 {
+		return 6500
+	}
+	r, g, b := sumR/float64(n), sumG/float64(n), sumB/float64(n)
+
+	x := 0.4124*r + 0.3576*g + 0.1805*b
+	y := 0.2126*r + 0.7152*g + 0.0722*b
+	z := 0.0193*r + 0.1192*g + 0.9505*b
+	if x+y+z == 0 {
+		return 6500
+	}
+	cx, cy := x/(x+y+z), y/(x+y+z)
+
+	nn := (cx - 0.3320) / (0.1858 - cy)
+	cct := 449*nn*nn*nn + 3525*nn*nn + 6823.3*nn + 5520.33
+	return math.Max(1000, math.Min(20000, cct))
+}
+
+// linearize converts an 8-bit sRGB value to linear light
+func linearize(v uint8) float64 {
+	c := float64(v) / 255
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+// temperatureClass grades a colour temperature
+func temperatureClass(kelvin float64) string {
+	switch {
+	case kelvin < 3000:
+		return "very_warm"
+	case kelvin < 4800:
+		return "warm"
+	case kelvin < 7000:
+		return "neutral"
+	case kelvin < 10000:
+		return "cool"
+	default:
+		return "very_cool"
+	}
+}
+
+// colorProfile summarizes the image as warm, cool, vibrant or neutral
+func colorProfile(stats *Stats) string {
+	switch {
+	case stats.Saturation >= 0.45:
+		return "vibrant"
+	case stats.Saturation < 0.1:
+		return "neutral"
+	case stats.ColorTemperature < 4800:
+		return "warm"
+	case stats.ColorTemperature >= 7000:
+		return "cool"
+	}
+	return "neutral"
+}
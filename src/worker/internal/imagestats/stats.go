@@ -0,0 +1,257 @@
+package imagestats
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/jpeg" // Decode extracted frames
+	_ "image/png"
+	"math"
+	"os"
+)
+
+// Histogram sizes
+const (
+	RGBBins        = 64 // Per channel; RGBHistogram holds R, G then B
+	HueBins        = 36 // 10 degrees each
+	SaturationBins = 16
+	ValueBins      = 16
+)
+
+// maxSampleSide bounds the analyzed resolution; larger images are sampled on a grid
+const maxSampleSide = 320
+
+// Stats holds colour and exposure statistics measured from an image
+// Levels are in 0-1 unless noted.
+type Stats struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+
+	RGBHistogram        []float64 `json:"rgbHistogram"`        // 3 x RGBBins, each channel sums to 1
+	HueHistogram        []float64 `json:"hueHistogram"`        // Hue of coloured pixels, sums to 1 (all 0 for grey images)
+	SaturationHistogram []float64 `json:"saturationHistogram"` // Sums to 1
+	ValueHistogram      []float64 `json:"valueHistogram"`      // Sums to 1
+
+	Luminance         float64 `json:"luminance"`         // Mean Rec. 709 luma
+	LuminanceVariance float64 `json:"luminanceVariance"` // Variance of luma
+	RMSContrast       float64 `json:"rmsContrast"`       // Standard deviation of luma (0-0.5)
+	Contrast          float64 `json:"contrast"`          // RMSContrast scaled to 0-1
+	Saturation        float64 `json:"saturation"`        // Mean HSV saturation
+	SharpnessVariance float64 `json:"sharpnessVariance"` // Variance of the luma Laplacian (8-bit scale)
+	Sharpness         float64 `json:"sharpness"`         // SharpnessVariance mapped to 0-1
+
+	DominantColors   []DominantColor `json:"dominantColors"`   // By share, largest first
+	ColorTemperature float64         `json:"colorTemperature"` // Correlated colour temperature (Kelvin)
+	TemperatureClass string          `json:"temperatureClass"` // very_warm, warm, neutral, cool, very_cool
+	ColorProfile     string          `json:"colorProfile"`     // warm, cool, vibrant, neutral
+}
+
+// DominantColor is a k-means colour cluster
+type DominantColor struct {
+	Hex   string  `json:"hex"`
+	Name  string  `json:"name"`  // Basic colour name (red, orange, ..., gray)
+	Share float64 `json:"share"` // Fraction of pixels
+	R     uint8   `json:"r"`
+	G     uint8   `json:"g"`
+	B     uint8   `json:"b"`
+}
+
+// ColorNames returns the basic colour names of the dominant colours without repeats
+func (s *Stats) ColorNames() []string {
+	names := make([]string, 0, len(s.DominantColors))
+	seen := make(map[string]bool)
+	for _, c := range s.DominantColors {
+		if !seen[c.Name] {
+			seen[c.Name] = true
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// AnalyzeFile decodes a JPEG or PNG file and measures it
+func AnalyzeFile(path string) (*Stats, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %w", err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return Analyze(img), nil
+}
+
+// AnalyzeBase64 decodes a base64 JPEG or PNG (as sent to vision models) and measures it
+func AnalyzeBase64(data string) (*Stats, error) {
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64 image: %w", err)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return Analyze(img), nil
+}
+
+// Analyze measures an image
+func Analyze(img image.Image) *Stats {
+	bounds := img.Bounds()
+	stats := &Stats{
+		Width:               bounds.Dx(),
+		Height:              bounds.Dy(),
+		RGBHistogram:        make([]float64, 3*RGBBins),
+		HueHistogram:        make([]float64, HueBins),
+		SaturationHistogram: make([]float64, SaturationBins),
+		ValueHistogram:      make([]float64, ValueBins),
+		TemperatureClass:    "neutral",
+		ColorProfile:        "neutral",
+	}
+	if stats.Width == 0 || stats.Height == 0 {
+		return stats
+	}
+
+	pixels, w, h := samplePixels(img)
+	n := float64(len(pixels))
+
+	// Step 1: Histograms, luma and saturation
+	luma := make([]float64, len(pixels))
+	sumLuma, sumLumaSq, sumSat := 0.0, 0.0, 0.0
+	coloured := 0.0
+	for i, p := range pixels {
+		stats.RGBHistogram[int(p.r)*RGBBins/256] += 1 / n
+		stats.RGBHistogram[RGBBins+int(p.g)*RGBBins/256] += 1 / n
+		stats.RGBHistogram[2*RGBBins+int(p.b)*RGBBins/256] += 1 / n
+
+		hue, sat, val := toHSV(p)
+		stats.SaturationHistogram[binOf(sat, SaturationBins)] += 1 / n
+		stats.ValueHistogram[binOf(val, ValueBins)] += 1 / n
+		// Hue is meaningless for near-grey or near-black pixels
+		if sat >= 0.15 && val >= 0.15 {
+			stats.HueHistogram[binOf(hue/360, HueBins)]++
+			coloured++
+		}
+
+		y := (0.2126*float64(p.r) + 0.7152*float64(p.g) + 0.0722*float64(p.b)) / 255
+		luma[i] = y
+		sumLuma += y
+		sumLumaSq += y * y
+		sumSat += sat
+	}
+	if coloured > 0 {
+		for i := range stats.HueHistogram {
+			stats.HueHistogram[i] /= coloured
+		}
+	}
+
+	stats.Luminance = sumLuma / n
+	stats.LuminanceVariance = math.Max(0, sumLumaSq/n-stats.Luminance*stats.Luminance)
+	stats.RMSContrast = math.Sqrt(stats.LuminanceVariance)
+	stats.Contrast = math.Min(1, 2*stats.RMSContrast)
+	stats.Saturation = sumSat / n
+
+	// Step 2: Sharpness from the Laplacian of the sampled luma
+	stats.SharpnessVariance = laplacianVariance(luma, w, h)
+	stats.Sharpness = stats.SharpnessVariance / (stats.SharpnessVariance + sharpnessHalf)
+
+	// Step 3: Dominant colours and colour temperature
+	stats.DominantColors = dominantColors(pixels, 5)
+	stats.ColorTemperature = colorTemperature(pixels)
+	stats.TemperatureClass = temperatureClass(stats.ColorTemperature)
+	stats.ColorProfile = colorProfile(stats)
+
+	return stats
+}
+
+// sharpnessHalf is the Laplacian variance that maps to a sharpness of 0.5
+const sharpnessHalf = 300.0
+
+// rgb is an 8-bit pixel
+type rgb struct {
+	r, g, b uint8
+}
+
+// samplePixels reads the image on a grid of at most maxSampleSide per side
+func samplePixels(img image.Image) ([]rgb, int, int) {
+	bounds := img.Bounds()
+	step := 1
+	for bounds.Dx()/step > maxSampleSide || bounds.Dy()/step > maxSampleSide {
+		step++
+	}
+
+	w := (bounds.Dx() + step - 1) / step
+	h := (bounds.Dy() + step - 1) / step
+	pixels := make([]rgb, 0, w*h)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			r, g, b, _ := img.At(x, y).RGBA()
+			pixels = append(pixels, rgb{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8)})
+		}
+	}
+	return pixels, w, h
+}
+
+// toHSV converts a pixel to hue (degrees), saturation and value (0-1)
+func toHSV(p rgb) (float64, float64, float64) {
+	r, g, b := float64(p.r)/255, float64(p.g)/255, float64(p.b)/255
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	delta := max - min
+
+	hue := 0.0
+	switch {
+	case delta == 0:
+	case max == r:
+		hue = 60 * math.Mod((g-b)/delta, 6)
+	case max == g:
+		hue = 60 * ((b-r)/delta + 2)
+	default:
+		hue = 60 * ((r-g)/delta + 4)
+	}
+	if hue < 0 {
+		hue += 360
+	}
+
+	sat := 0.0
+	if max > 0 {
+		sat = delta / max
+	}
+	return hue, sat, max
+}
+
+// binOf returns the histogram bin of a 0-1 value
+func binOf(v float64, bins int) int {
+	bin := int(v * float64(bins))
+	if bin >= bins {
+		return bins - 1
+	}
+	if bin < 0 {
+		return 0
+	}
+	return bin
+}
+
+// laplacianVariance returns the variance of the 4-neighbour Laplacian of luma (8-bit scale)
+func laplacianVariance(luma []float64, w, h int) float64 {
+	if w < 3 || h < 3 {
+		return 0
+	}
+
+	sum, sumSq, n := 0.0, 0.0, 0.0
+	for y := 1; y < h-1; y++ {
+		for x := 1; x < w-1; x++ {
+			i := y*w + x
+			lap := 255 * (luma[i-1] + luma[i+1] + luma[i-w] + luma[i+w] - 4*luma[i])
+			sum += lap
+			sumSq += lap * lap
+			n++
+		}
+	}
+	mean := sum / n
+	return math.Max(0, sumSq/n-mean*mean)
+}
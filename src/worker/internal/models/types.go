@@ -18,6 +18,7 @@ type JobPayload struct {
 	VideoBuffer   []byte                 `json:"videoBuffer,omitempty"`   // Base64-encoded video data (for Google Drive streams)
 	SourceType    string                 `json:"sourceType,omitempty"`    // "url", "gdrive", "upload"
 	UserID        string                 `json:"userId"`
+	OrgID         string                 `json:"orgId,omitempty"`         // Organisation owning the video (tenant scope when set)
 	SessionID     *string                `json:"sessionId,omitempty"`
 	Filename      string                 `json:"filename,omitempty"`      // BullMQ compatibility
 	OutputDir     string                 `json:"outputDir,omitempty"`     // BullMQ worker sets this
@@ -26,12 +27,30 @@ type JobPayload struct {
 	EnqueuedAt    *time.Time             `json:"enqueuedAt,omitempty"`
 }
 
+// TenantID returns the isolation scope for this job's stored data
+func (p *JobPayload) TenantID() string {
+	return TenantIDFor(p.UserID, p.OrgID)
+}
+
+// TenantIDFor derives a tenant scope: the organisation when set, otherwise the user
+// Returns empty string when neither is known (callers must reject unscoped writes/searches)
+func TenantIDFor(userID, orgID string) string {
+	if orgID != "" {
+		return "org:" + orgID
+	}
+	if userID != "" {
+		return "user:" + userID
+	}
+	return ""
+}
+
 // ProcessingOptions defines what processing to perform
 // All boolean fields default to false, all optional fields use pointers
 type ProcessingOptions struct {
 	// BullMQ-compatible fields (sent by Node.js worker)
 	ExtractMetadata *bool `json:"extractMetadata,omitempty"`
 	DetectScenes    *bool `json:"detectScenes,omitempty"`
+	CinematicAnalysis *bool `json:"cinematicAnalysis,omitempty"` // Scene type, composition, camera and look per scene (implies detectScenes)
 	AnalyzeFrames   *bool `json:"analyzeFrames,omitempty"`
 	TranscribeAudio *bool `json:"transcribeAudio,omitempty"`
 	MaxFrames       *int  `json:"maxFrames,omitempty"`
@@ -45,7 +64,30 @@ type ProcessingOptions struct {
 	DetectObjects       *bool              `json:"detectObjects,omitempty"`
 	ExtractText         *bool              `json:"extractText,omitempty"`         // OCR
 	ClassifyContent     *bool              `json:"classifyContent,omitempty"`
+	TrackObjects        *bool              `json:"trackObjects,omitempty"`        // Multi-object tracking, re-ID and interactions
+	TrackingSampleRate  *int               `json:"trackingSampleRate,omitempty"`  // Frames per second sampled for tracking
+	TrackerType         *string            `json:"trackerType,omitempty"`         // "bytetrack", "deepsort", "simple_iou"
+	MaxTrackingFrames   *int               `json:"maxTrackingFrames,omitempty"`
+	SpatialRules        *SpatialRules      `json:"spatialRules,omitempty"`        // Zones and lines evaluated on tracks
+	InteractionThresholds map[string]ProximityThresholds `json:"interactionThresholds,omitempty"` // Per class ("default" for all others)
+	TrackingVisuals     *bool              `json:"trackingVisuals,omitempty"`     // Heatmaps, trajectory overlay and track timeline
+	RenderAnnotatedVideo *bool             `json:"renderAnnotatedVideo,omitempty"` // MP4 with track/OCR boxes, subtitles and scene markers
+	SubtitleMode        *string            `json:"subtitleMode,omitempty"`        // "burn", "soft", "none" (annotated video)
+	GenerateThumbnails  *bool              `json:"generateThumbnails,omitempty"`  // Quality-scored poster/scene keyframes, thumbnails and scrubbing sprites
+	ThumbnailSizes      []int              `json:"thumbnailSizes,omitempty"`      // Thumbnail widths in pixels (default 1280, 640, 320)
+	SpriteInterval      *float64           `json:"spriteInterval,omitempty"`      // Seconds between sprite sheet tiles (default 5)
+	RunQC               *bool              `json:"runQc,omitempty"`               // Technical QC: black/freeze/silence, loudness, interlacing, letterboxing
+	QCProfile           *QCProfile         `json:"qcProfile,omitempty"`           // Pass/fail thresholds (default EBU R128 broadcast)
+	ExportFormats       []string           `json:"exportFormats,omitempty"`       // NLE timelines: "edl", "fcpxml", "otio"
+	ExportKeywords      []string           `json:"exportKeywords,omitempty"`      // Transcript keywords marked in exports (default: transcription keywords)
+	GenerateHighlights  *bool              `json:"generateHighlights,omitempty"`  // Highlight reel and per-highlight clips from scored scenes
+	HighlightDuration   *float64           `json:"highlightDuration,omitempty"`   // Target reel length in seconds (default 60)
+	HighlightFormats    []string           `json:"highlightFormats,omitempty"`    // Clip aspect ratios: "16:9", "9:16", "1:1" (default 16:9)
+	HighlightKeywords   []string           `json:"highlightKeywords,omitempty"`   // Transcript keywords that raise a scene's score (default: transcription keywords)
+	ReframeFormats      []string           `json:"reframeFormats,omitempty"`      // Subject-following renditions: "9:16", "1:1"
+	ReframePanSpeed     *float64           `json:"reframePanSpeed,omitempty"`     // Max crop pan speed in frame widths per second (default 0.2)
 	GenerateSummary     *bool              `json:"generateSummary,omitempty"`
+	SummaryChapterLength *float64          `json:"summaryChapterLength,omitempty"` // Target chapter length in seconds for the structured summary (default 300)
 	CustomAnalysis      *string            `json:"customAnalysis,omitempty"`      // Custom prompt for MageAgent
 	TargetLanguages     []string           `json:"targetLanguages,omitempty"`     // For transcription (empty = auto-detect)
 	QualityPreference   *string            `json:"qualityPreference,omitempty"`   // "speed", "balanced", "accuracy"
@@ -61,6 +103,10 @@ func (o *ProcessingOptions) ShouldDetectScenes() bool {
 	return o.DetectScenes != nil && *o.DetectScenes
 }
 
+func (o *ProcessingOptions) ShouldRunCinematicAnalysis() bool {
+	return o.CinematicAnalysis != nil && *o.CinematicAnalysis
+}
+
 func (o *ProcessingOptions) ShouldAnalyzeFrames() bool {
 	return o.AnalyzeFrames != nil && *o.AnalyzeFrames
 }
@@ -69,6 +115,153 @@ func (o *ProcessingOptions) ShouldTranscribeAudio() bool {
 	return o.TranscribeAudio != nil && *o.TranscribeAudio
 }
 
+func (o *ProcessingOptions) ShouldTrackObjects() bool {
+	return o.TrackObjects != nil && *o.TrackObjects
+}
+
+func (o *ProcessingOptions) ShouldGenerateTrackingVisuals() bool {
+	return o.TrackingVisuals != nil && *o.TrackingVisuals
+}
+
+func (o *ProcessingOptions) ShouldRenderAnnotatedVideo() bool {
+	return o.RenderAnnotatedVideo != nil && *o.RenderAnnotatedVideo
+}
+
+func (o *ProcessingOptions) ShouldGenerateThumbnails() bool {
+	return o.GenerateThumbnails != nil && *o.GenerateThumbnails
+}
+
+func (o *ProcessingOptions) GetThumbnailSizes() []int {
+	sizes := make([]int, 0, len(o.ThumbnailSizes))
+	for _, size := range o.ThumbnailSizes {
+		if size > 0 {
+			sizes = append(sizes, size)
+		}
+	}
+	if len(sizes) == 0 {
+		return []int{1280, 640, 320} // default
+	}
+	return sizes
+}
+
+func (o *ProcessingOptions) GetSpriteInterval() float64 {
+	if o.SpriteInterval != nil && *o.SpriteInterval > 0 {
+		return *o.SpriteInterval
+	}
+	return 5.0 // default
+}
+
+func (o *ProcessingOptions) ShouldRunQC() bool {
+	return o.RunQC != nil && *o.RunQC
+}
+
+func (o *ProcessingOptions) GetExportFormats() []string {
+	formats := make([]string, 0, len(o.ExportFormats))
+	seen := make(map[string]bool)
+	for _, format := range o.ExportFormats {
+		format = strings.ToLower(strings.TrimSpace(format))
+		switch format {
+		case ExportFormatEDL, ExportFormatFCPXML, ExportFormatOTIO:
+			if !seen[format] {
+				seen[format] = true
+				formats = append(formats, format)
+			}
+		}
+	}
+	return formats
+}
+
+func (o *ProcessingOptions) ShouldGenerateHighlights() bool {
+	return o.GenerateHighlights != nil && *o.GenerateHighlights
+}
+
+func (o *ProcessingOptions) GetHighlightDuration() float64 {
+	if o.HighlightDuration != nil && *o.HighlightDuration > 0 {
+		return *o.HighlightDuration
+	}
+	return 60.0 // default
+}
+
+func (o *ProcessingOptions) GetHighlightFormats() []string {
+	formats := make([]string, 0, len(o.HighlightFormats))
+	seen := make(map[string]bool)
+	for _, format := range o.HighlightFormats {
+		format = strings.TrimSpace(format)
+		switch format {
+		case AspectLandscape, AspectVertical, AspectSquare:
+			if !seen[format] {
+				seen[format] = true
+				formats = append(formats, format)
+			}
+		}
+	}
+	if len(formats) == 0 {
+		return []string{AspectLandscape} // default
+	}
+	return formats
+}
+
+func (o *ProcessingOptions) GetReframeFormats() []string {
+	formats := make([]string, 0, len(o.ReframeFormats))
+	seen := make(map[string]bool)
+	for _, format := range o.ReframeFormats {
+		format = strings.TrimSpace(format)
+		switch format {
+		case AspectVertical, AspectSquare:
+			if !seen[format] {
+				seen[format] = true
+				formats = append(formats, format)
+			}
+		}
+	}
+	return formats
+}
+
+func (o *ProcessingOptions) GetReframePanSpeed() float64 {
+	if o.ReframePanSpeed != nil && *o.ReframePanSpeed > 0 {
+		return *o.ReframePanSpeed
+	}
+	return 0.2 // default
+}
+
+func (o *ProcessingOptions) GetSummaryChapterLength() float64 {
+	if o.SummaryChapterLength != nil && *o.SummaryChapterLength > 0 {
+		return *o.SummaryChapterLength
+	}
+	return 300.0 // default
+}
+
+func (o *ProcessingOptions) GetSubtitleMode() string {
+	if o.SubtitleMode != nil {
+		switch *o.SubtitleMode {
+		case SubtitleModeBurn, SubtitleModeSoft, SubtitleModeNone:
+			return *o.SubtitleMode
+		}
+	}
+	return SubtitleModeBurn // default
+}
+
+func (o *ProcessingOptions) GetTrackingSampleRate() int {
+	if o.TrackingSampleRate != nil && *o.TrackingSampleRate > 0 {
+		return *o.TrackingSampleRate
+	}
+	return 5 // default
+}
+
+func (o *ProcessingOptions) GetTrackerType() string {
+	if o.TrackerType != nil && *o.TrackerType != "" {
+		return *o.TrackerType
+	}
+	return "bytetrack" // default
+}
+
+func (o *ProcessingOptions) GetMaxTrackingFrames() int {
+	if o.MaxTrackingFrames != nil && *o.MaxTrackingFrames > 0 {
+		return *o.MaxTrackingFrames
+	}
+	return 600 // default (2 minutes at 5fps; the default rate is lowered to fit longer videos)
+}
+
 func (o *ProcessingOptions) GetMaxFrames() int {
 	if o.MaxFrames != nil {
 		return *o.MaxFrames
@@ -94,7 +287,15 @@ type ProcessingResult struct {
 	Objects         []ObjectDetection      `json:"objects"`
 	TextExtraction  []TextExtraction       `json:"textExtraction"`
 	Classification  *ContentClassification `json:"classification,omitempty"`
+	Tracking        *TrackingAnalysis      `json:"tracking,omitempty"`
+	AnnotatedVideo  *AnnotatedVideo        `json:"annotatedVideo,omitempty"`
+	Thumbnails      *ThumbnailSet          `json:"thumbnails,omitempty"`
+	QC              *QCReport              `json:"qc,omitempty"`
+	EditExports     []EditExport           `json:"editExports,omitempty"`
+	Highlights      *HighlightReel         `json:"highlights,omitempty"`
+	Reframed        []ReframedVideo        `json:"reframed,omitempty"`
 	Summary         string                 `json:"summary"`
+	StructuredSummary *VideoSummary        `json:"structuredSummary,omitempty"` // Per-scene/chapter summaries, key moments and storyboard
 	Error           string                 `json:"error,omitempty"`
 	ProcessingTime  float64                `json:"processingTime"`  // Seconds
 	ModelUsage      []ModelUsageRecord     `json:"modelUsage"`      // Track which models were used
@@ -102,6 +303,245 @@ type ProcessingResult struct {
 	CompletedAt     time.Time              `json:"completedAt"`
 }
 
+// Subtitle modes for annotated videos
+const (
+	SubtitleModeBurn = "burn" // Drawn into the picture
+	SubtitleModeSoft = "soft" // Separate mov_text track
+	SubtitleModeNone = "none"
+)
+
+// AnnotatedVideo describes a rendered MP4 with analysis overlays
+type AnnotatedVideo struct {
+	Path          string  `json:"path"`
+	SubtitlesPath string  `json:"subtitlesPath,omitempty"` // SRT written next to the video
+	SubtitleMode  string  `json:"subtitleMode"`            // Mode actually used (burn falls back to soft without libass)
+	Renderer      string  `json:"renderer"`                // "ass" or "drawbox"
+	TrackBoxes    int     `json:"trackBoxes"`
+	TextBoxes     int     `json:"textBoxes"`
+	SubtitleCues  int     `json:"subtitleCues"`
+	SceneMarkers  int     `json:"sceneMarkers"`
+	RenderTime    float64 `json:"renderTime"` // Seconds
+}
+
+// FrameQuality scores how well a frame works as a poster or keyframe
+// Components and Score are 0-1; blank (black or flat) frames score 0.
+type FrameQuality struct {
+	FrameID     string  `json:"frameId"`
+	Timestamp   float64 `json:"timestamp"`   // Seconds from start
+	Score       float64 `json:"score"`       // Weighted combination of the components
+	Sharpness   float64 `json:"sharpness"`
+	Exposure    float64 `json:"exposure"`    // Mid-tone brightness without clipping
+	Subject     float64 `json:"subject"`     // Size, confidence and centring of the best person/face/object
+	Composition float64 `json:"composition"` // Subject placement on the rule-of-thirds grid
+	Blank       bool    `json:"blank"`
+}
+
+// Thumbnail is one rendered size of a poster frame
+type Thumbnail struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Path   string `json:"path"`
+}
+
+// PosterFrame is the best-scoring frame of a video or scene with its thumbnails
+type PosterFrame struct {
+	SceneID    string      `json:"sceneId,omitempty"` // Empty for the video poster
+	FrameID    string      `json:"frameId"`
+	Timestamp  float64     `json:"timestamp"`
+	Score      float64     `json:"score"`
+	Thumbnails []Thumbnail `json:"thumbnails"`
+}
+
+// ThumbnailSet holds a job's poster frames, thumbnails and scrubbing sprites
+type ThumbnailSet struct {
+	Poster         *PosterFrame   `json:"poster,omitempty"`
+	Scenes         []PosterFrame  `json:"scenes"`
+	SpriteSheets   []string       `json:"spriteSheets"`            // JPEG grids of TileColumns x TileRows tiles
+	SpriteVTTPath  string         `json:"spriteVttPath,omitempty"` // WebVTT thumbnail track referencing the sheets
+	SpriteInterval float64        `json:"spriteInterval"`          // Seconds per tile
+	TileWidth      int            `json:"tileWidth"`
+	TileHeight     int            `json:"tileHeight"`
+	TileColumns    int            `json:"tileColumns"`
+	TileRows       int            `json:"tileRows"`
+	FrameQuality   []FrameQuality `json:"frameQuality"`
+}
+
+// QCProfile sets the thresholds a video must meet to pass technical QC
+// Name selects a preset ("ebu_r128", "atsc_a85", "streaming"); zero or nil fields take the preset's value.
+type QCProfile struct {
+	Name               string  `json:"name,omitempty"`
+	MaxBlackDuration   float64 `json:"maxBlackDuration,omitempty"`   // Seconds of mid-programme black before failing
+	MaxFreezeDuration  float64 `json:"maxFreezeDuration,omitempty"`  // Seconds of frozen picture before failing
+	MaxSilenceDuration float64 `json:"maxSilenceDuration,omitempty"` // Seconds of mid-programme silence before failing
+	SilenceThreshold   float64 `json:"silenceThreshold,omitempty"`   // dBFS below which audio counts as silent
+	TargetLoudness     float64 `json:"targetLoudness,omitempty"`     // Integrated loudness (LUFS)
+	LoudnessTolerance  float64 `json:"loudnessTolerance,omitempty"`  // Allowed deviation from the target (LU)
+	MaxTruePeak        float64 `json:"maxTruePeak,omitempty"`        // dBTP
+	AllowInterlaced    *bool   `json:"allowInterlaced,omitempty"`
+	AllowLetterbox     *bool   `json:"allowLetterbox,omitempty"`     // Letterbox or pillarbox bars
+}
+
+// QC issue severities (only errors fail the report)
+const (
+	QCSeverityError   = "error"
+	QCSeverityWarning = "warning"
+	QCSeverityInfo    = "info"
+)
+
+// QCIssue is one technical problem found by QC
+// Whole-file issues (loudness, interlacing, letterboxing) span the full duration.
+type QCIssue struct {
+	Type      string  `json:"type"`     // "black", "freeze", "silence", "loudness", "true_peak", "interlaced", "letterbox", "pillarbox", "no_audio"
+	Severity  string  `json:"severity"` // "error", "warning", "info"
+	StartTime float64 `json:"startTime"`
+	EndTime   float64 `json:"endTime"`
+	Duration  float64 `json:"duration"`
+	Value     float64 `json:"value,omitempty"` // Measured value for level issues (LUFS, dBTP, interlaced share)
+	Message   string  `json:"message"`
+}
+
+// CropRect is the picture area inside letterbox/pillarbox bars
+type CropRect struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	X      int `json:"x"`
+	Y      int `json:"y"`
+}
+
+// QCReport is the result of technical QC against a profile
+type QCReport struct {
+	Profile            QCProfile `json:"profile"` // Resolved thresholds
+	Passed             bool      `json:"passed"`
+	Issues             []QCIssue `json:"issues"`
+	Errors             int       `json:"errors"`
+	Warnings           int       `json:"warnings"`
+	Duration           float64   `json:"duration"`
+	HasAudio           bool      `json:"hasAudio"`
+	IntegratedLoudness float64   `json:"integratedLoudness"` // LUFS
+	LoudnessRange      float64   `json:"loudnessRange"`      // LU
+	TruePeak           float64   `json:"truePeak"`           // dBTP
+	FieldOrder         string    `json:"fieldOrder"`         // "progressive", "tff", "bff"
+	InterlacedFrames   int       `json:"interlacedFrames"`
+	ProgressiveFrames  int       `json:"progressiveFrames"`
+	Crop               *CropRect `json:"crop,omitempty"`     // Set when bars were detected
+	BlackDuration      float64   `json:"blackDuration"`      // Totals over all segments (seconds)
+	FreezeDuration     float64   `json:"freezeDuration"`
+	SilenceDuration    float64   `json:"silenceDuration"`
+	AnalysisTime       float64   `json:"analysisTime"`       // Seconds
+}
+
+// NLE export formats
+const (
+	ExportFormatEDL    = "edl"    // CMX3600 edit decision list
+	ExportFormatFCPXML = "fcpxml" // Final Cut Pro XML (also read by DaVinci Resolve)
+	ExportFormatOTIO   = "otio"   // OpenTimelineIO JSON
+)
+
+// EditExport is a timeline exported for editing applications
+type EditExport struct {
+	Format  string `json:"format"` // "edl", "fcpxml", "otio"
+	Path    string `json:"path"`
+	Events  int    `json:"events"`  // Clips on the timeline
+	Markers int    `json:"markers"` // Scene-type and keyword markers
+}
+
+// Social aspect ratios (highlight clips and reframed renditions)
+const (
+	AspectLandscape = "16:9" // Source framing
+	AspectVertical  = "9:16" // Stories, Reels, Shorts, TikTok
+	AspectSquare    = "1:1"  // Feed posts
+)
+
+// HighlightReel is a highlight reel cut from the highest-scoring scenes
+type HighlightReel struct {
+	Path           string      `json:"path"`
+	Duration       float64     `json:"duration"`       // Seconds
+	TargetDuration float64     `json:"targetDuration"` // Seconds
+	Highlights     []Highlight `json:"highlights"`     // In reel (source) order
+}
+
+// Highlight is one segment of the reel, cut at shot boundaries
+type Highlight struct {
+	SceneID     string          `json:"sceneId,omitempty"`
+	SceneType   string          `json:"sceneType,omitempty"`
+	StartTime   float64         `json:"startTime"` // Source seconds
+	EndTime     float64         `json:"endTime"`
+	Score       float64         `json:"score"`       // 0-1 weighted total
+	Interaction float64         `json:"interaction"` // 0-1 component scores
+	Loudness    float64         `json:"loudness"`
+	Keywords    float64         `json:"keywords"`
+	Priority    float64         `json:"priority"` // Scene-type priority
+	Reasons     []string        `json:"reasons,omitempty"`
+	Clips       []HighlightClip `json:"clips,omitempty"`
+}
+
+// HighlightClip is a highlight rendered on its own in a social format
+type HighlightClip struct {
+	Format string `json:"format"` // "16:9", "9:16", "1:1"
+	Path   string `json:"path"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// ReframedVideo is a rendition cropped to another aspect ratio, following the main subject
+type ReframedVideo struct {
+	Format   string `json:"format"`   // "9:16", "1:1"
+	Path     string `json:"path"`
+	CropPath string `json:"cropPath"` // JSON crop window keyframes per shot
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Shots    int    `json:"shots"`
+	Tracked  int    `json:"tracked"` // Shots where a subject was followed (the rest are centred)
+}
+
+// VideoSummary is a hierarchical, time-coded summary of a video
+type VideoSummary struct {
+	Synopsis       string            `json:"synopsis"` // Whole-video summary
+	Chapters       []ChapterSummary  `json:"chapters"`
+	Scenes         []SceneSummary    `json:"scenes"`
+	KeyMoments     []KeyMoment       `json:"keyMoments"`
+	Storyboard     []StoryboardFrame `json:"storyboard"`
+	SynthesisCalls int               `json:"synthesisCalls"` // Requests made (map-reduce over long transcripts takes several)
+}
+
+// ChapterSummary summarizes a run of consecutive scenes
+type ChapterSummary struct {
+	Index     int      `json:"index"` // 1-based
+	Title     string   `json:"title"`
+	StartTime float64  `json:"startTime"`
+	EndTime   float64  `json:"endTime"`
+	Summary   string   `json:"summary"`
+	SceneIDs  []string `json:"sceneIds,omitempty"`
+}
+
+// SceneSummary summarizes one scene (or a fixed window when scenes were not detected)
+type SceneSummary struct {
+	SceneID   string  `json:"sceneId,omitempty"`
+	SceneType string  `json:"sceneType,omitempty"`
+	StartTime float64 `json:"startTime"`
+	EndTime   float64 `json:"endTime"`
+	Summary   string  `json:"summary"`
+}
+
+// KeyMoment is a time-coded bullet point
+type KeyMoment struct {
+	Timestamp float64 `json:"timestamp"` // Seconds
+	Timecode  string  `json:"timecode"`  // HH:MM:SS
+	Text      string  `json:"text"`
+	SceneID   string  `json:"sceneId,omitempty"`
+}
+
+// StoryboardFrame is a representative frame of a scene with its caption
+type StoryboardFrame struct {
+	SceneID       string  `json:"sceneId,omitempty"`
+	FrameID       string  `json:"frameId"`
+	Timestamp     float64 `json:"timestamp"`
+	Timecode      string  `json:"timecode"`
+	FilePath      string  `json:"filePath,omitempty"`
+	ThumbnailPath string  `json:"thumbnailPath,omitempty"` // Scene poster thumbnail (when thumbnails were generated)
+	Caption       string  `json:"caption"`
+}
+
 // VideoMetadata contains technical video information
 type VideoMetadata struct {
 	Duration    float64 `json:"duration"`    // Seconds
@@ -167,6 +607,70 @@ type SceneDetection struct {
 	KeyFrameID    string  `json:"keyFrameId"`    // Reference to FrameAnalysis
 	SceneType     string  `json:"sceneType"`     // "action", "dialogue", "establishing", etc.
 	Confidence    float64 `json:"confidence"`
+	Cinematic     *SceneCinematics `json:"cinematic,omitempty"` // Cinematic analysis of the scene's keyframes
+}
+
+// SceneCinematics aggregates cinematic analysis over a scene's keyframes
+// (categorical fields take the most common value, numeric fields the mean)
+type SceneCinematics struct {
+	KeyframeTimes  []float64               `json:"keyframeTimes"` // Seconds from start
+	Classification CinematicClassification `json:"classification"`
+	Composition    CinematicComposition    `json:"composition"`
+	Camera         CinematicCamera         `json:"camera"`
+	Look           CinematicLook           `json:"look"`
+}
+
+// CinematicClassification is the scene type and setting
+type CinematicClassification struct {
+	SceneType    string  `json:"sceneType"` // interior, exterior, action, dialogue, establishing, ...
+	Setting      string  `json:"setting"`
+	TimeOfDay    string  `json:"timeOfDay"`
+	Weather      string  `json:"weather,omitempty"`
+	LocationType string  `json:"locationType"`
+	Confidence   float64 `json:"confidence"`
+}
+
+// CinematicComposition is the framing of the scene's shots
+type CinematicComposition struct {
+	ShotSize     string  `json:"shotSize"`  // close_up, medium_shot, wide_shot, ...
+	ShotAngle    string  `json:"shotAngle"` // eye_level, high_angle, low_angle, ...
+	RuleOfThirds bool    `json:"ruleOfThirds"`
+	LeadingLines bool    `json:"leadingLines"`
+	Symmetry     bool    `json:"symmetry"`
+	Depth        string  `json:"depth"` // shallow, moderate, deep
+	Balance      string  `json:"balance"`
+	Confidence   float64 `json:"confidence"`
+}
+
+// CinematicCamera is the camera movement over the scene
+type CinematicCamera struct {
+	Movement   string  `json:"movement"` // static, pan, tilt, zoom, dolly, tracking, crane, handheld
+	Speed      string  `json:"speed"`
+	Smoothness string  `json:"smoothness"`
+	Stabilized bool    `json:"stabilized"`
+	Shake      string  `json:"shake"` // Shake intensity (none, minimal, moderate, ...)
+	Confidence float64 `json:"confidence"`
+
+	// Set when measured from consecutive frames rather than judged from keyframes
+	Measured bool    `json:"measured"`
+	PanRate  float64 `json:"panRate,omitempty"`  // Frame widths per second (+ = right)
+	TiltRate float64 `json:"tiltRate,omitempty"` // Frame heights per second (+ = up)
+	ZoomRate float64 `json:"zoomRate,omitempty"` // Relative scale change per second (+ = in)
+	Jitter   float64 `json:"jitter,omitempty"`   // RMS frame-to-frame change of the shift
+}
+
+// CinematicLook is the colour, lighting and mood of the scene
+type CinematicLook struct {
+	ColorGrading     string   `json:"colorGrading"`
+	ColorTemperature string   `json:"colorTemperature"`
+	DominantColors   []string `json:"dominantColors"`
+	Saturation       float64  `json:"saturation"` // 0-1
+	Contrast         float64  `json:"contrast"`   // 0-1
+	LightingSetup    string   `json:"lightingSetup"`
+	LightingQuality  string   `json:"lightingQuality"`
+	Mood             string   `json:"mood"`
+	MoodIntensity    float64  `json:"moodIntensity"` // 0-1
+	Confidence       float64  `json:"confidence"`
 }
 
 // ObjectDetection represents a detected object in a frame
@@ -196,6 +700,294 @@ type TextExtraction struct {
 	Timestamp   float64     `json:"timestamp,omitempty"`
 }
 
+// KeywordMatch represents a keyword hit against stored transcripts, OCR text or object labels
+type KeywordMatch struct {
+	JobID        string  `json:"jobId"`
+	Source       string  `json:"source"`       // "transcript", "ocr", "objects"
+	Score        float64 `json:"score"`        // Source-specific relevance (ts_rank sum or hit count)
+	MatchedTerms int     `json:"matchedTerms"` // Distinct query terms/labels matched
+	Snippet      string  `json:"snippet,omitempty"`
+	Timestamp    float64 `json:"timestamp,omitempty"` // First occurrence (seconds)
+}
+
+// Timeline embedding kinds
+const (
+	EmbeddingKindFrame   = "frame"   // Frame description
+	EmbeddingKindSegment = "segment" // Transcript segment
+)
+
+// TimelineEmbedding is the stored text embedding of one frame description or transcript segment
+// Stored at ingest so moment retrieval only embeds the query
+type TimelineEmbedding struct {
+	Kind      string    `json:"kind"`
+	Key       string    `json:"key"` // FrameID, or SegmentKey for transcript segments
+	Embedding []float64 `json:"embedding"`
+}
+
+// SegmentKey identifies a transcript segment by its time span
+func SegmentKey(segment SpeakerSegment) string {
+	return fmt.Sprintf("%.3f-%.3f", segment.StartTime, segment.EndTime)
+}
+
+// TrackingAnalysis contains multi-object tracking results for a video
+type TrackingAnalysis struct {
+	Tracks          []ObjectTrack          `json:"tracks"`
+	Identities      []PersonIdentityRecord `json:"identities"`
+	Interactions    []InteractionRecord    `json:"interactions"`
+	InteractionEvents []InteractionEventRecord `json:"interactionEvents,omitempty"` // Start/type-change/end events of recorded interactions
+	FramesProcessed int                    `json:"framesProcessed"`
+	SampleRate      float64                `json:"sampleRate"`      // Frames per second sampled
+	TrackedDuration float64                `json:"trackedDuration"` // Seconds of video covered by tracking
+	Truncated       bool                   `json:"truncated"`       // The frame budget ran out before the end of the video
+	TrackerType     string                 `json:"trackerType"`
+	ProcessingTime  float64                `json:"processingTime"`  // Seconds
+	SpatialEvents   []SpatialEvent         `json:"spatialEvents,omitempty"` // Zone/line events (when rules are set)
+	Occupancy       []OccupancySample      `json:"occupancy,omitempty"`     // Zone occupancy changes over time
+	ZoneStats       []ZoneStats            `json:"zoneStats,omitempty"`
+	Visuals         *TrackingVisuals       `json:"visuals,omitempty"` // Dashboard summaries (when requested)
+}
+
+// TrackingVisuals are visual summaries of tracking output for dashboards
+type TrackingVisuals struct {
+	Heatmaps []OccupancyHeatmap `json:"heatmaps"` // Per class, plus "all"
+	Timeline TrackTimeline      `json:"timeline"`
+	Images   []TrackingImage    `json:"images"` // Rendered heatmaps and trajectory overlay
+}
+
+// OccupancyHeatmap is the time tracks of a class spent in each cell of a grid over the frame
+type OccupancyHeatmap struct {
+	Class    string    `json:"class"`
+	Columns  int       `json:"columns"`
+	Rows     int       `json:"rows"`
+	Cells    []float64 `json:"cells"`    // Seconds, row-major from the top-left cell
+	MaxValue float64   `json:"maxValue"` // Largest cell value
+	Total    float64   `json:"total"`    // Track-seconds accumulated
+}
+
+// TrackTimeline is a Gantt-style view of when each track was visible
+type TrackTimeline struct {
+	Duration float64         `json:"duration"` // Seconds covered by tracking
+	Tracks   []TimelineTrack `json:"tracks"`
+}
+
+// TimelineTrack is one row of the track timeline
+type TimelineTrack struct {
+	TrackID       string            `json:"trackId"`
+	Class         string            `json:"class"`
+	IdentityID    string            `json:"identityId,omitempty"`
+	Pattern       string            `json:"pattern,omitempty"`
+	AverageSpeed  float64           `json:"averageSpeed"`
+	TotalDistance float64           `json:"totalDistance"`
+	Segments      []TimelineSegment `json:"segments"` // Visible intervals (split at detection gaps)
+}
+
+// TimelineSegment is a continuous interval in which a track was observed
+type TimelineSegment struct {
+	Start      float64 `json:"start"` // Seconds
+	End        float64 `json:"end"`
+	StartFrame int     `json:"startFrame"`
+	EndFrame   int     `json:"endFrame"`
+}
+
+// TrackingImage is a rendered PNG summary
+type TrackingImage struct {
+	Kind        string `json:"kind"`  // "heatmap", "trajectories"
+	Class       string `json:"class"` // Heatmap class ("all" for every class)
+	ContentType string `json:"contentType"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Data        []byte `json:"data"` // Base64 in JSON
+}
+
+// ObjectTrack is one object followed across sampled frames
+type ObjectTrack struct {
+	TrackID        string       `json:"trackId"`
+	Class          string       `json:"class"`
+	IdentityID     string       `json:"identityId,omitempty"` // Person re-ID identity
+	StartTime      float64      `json:"startTime"`            // Seconds from start
+	EndTime        float64      `json:"endTime"`
+	StartFrame     int          `json:"startFrame"`           // Sampled frame index
+	EndFrame       int          `json:"endFrame"`
+	FrameCount     int          `json:"frameCount"`
+	MeanConfidence float64      `json:"meanConfidence"`
+	Pattern        string       `json:"pattern,omitempty"`    // Trajectory pattern (linear, curved, ...)
+	TotalDistance  float64      `json:"totalDistance"`        // Normalized frame units
+	AverageSpeed   float64      `json:"averageSpeed"`         // Normalized units per second
+	Trajectory     []TrackPoint `json:"trajectory"`
+}
+
+// TrackPoint is a track observation in one sampled frame
+type TrackPoint struct {
+	Timestamp   float64     `json:"timestamp"`
+	FrameNumber int         `json:"frameNumber"`
+	BoundingBox BoundingBox `json:"boundingBox"`
+	Confidence  float64     `json:"confidence"`
+}
+
+// PersonIdentityRecord is a re-identified person linking one or more tracks
+type PersonIdentityRecord struct {
+	IdentityID  string                 `json:"identityId"`
+	TrackIDs    []string               `json:"trackIds"`
+	Appearances int                    `json:"appearances"`
+	StartTime   float64                `json:"startTime"`
+	EndTime     float64                `json:"endTime"`
+	Confidence  float64                `json:"confidence"`
+	Attributes  map[string]interface{} `json:"attributes"`
+	GalleryID   string                 `json:"galleryId,omitempty"` // Tenant-wide identity across videos
+}
+
+// GalleryIdentity is a tenant-wide person identity linked across videos (persistent re-ID gallery)
+// Appearances and VideoCount are derived from the identity's sightings
+type GalleryIdentity struct {
+	TenantID    string                 `json:"tenantId"`
+	IdentityID  string                 `json:"identityId"`
+	Features    []float64              `json:"features"` // Mean appearance embedding over sightings
+	Attributes  map[string]interface{} `json:"attributes"`
+	Confidence  float64                `json:"confidence"`
+	Aliases     []string               `json:"aliases"` // Identity IDs merged into this one
+	Appearances int                    `json:"appearances"`
+	VideoCount  int                    `json:"videoCount"`
+	FirstSeen   time.Time              `json:"firstSeen"`
+	LastSeen    time.Time              `json:"lastSeen"`
+}
+
+// GallerySighting links a gallery identity to a track in a processed video
+type GallerySighting struct {
+	TenantID   string    `json:"tenantId"`
+	IdentityID string    `json:"identityId"`
+	JobID      string    `json:"jobId"`
+	TrackID    string    `json:"trackId"`
+	StartTime  float64   `json:"startTime"` // Video seconds
+	EndTime    float64   `json:"endTime"`
+	Confidence float64   `json:"confidence"`
+	Features   []float64 `json:"features"`
+	SeenAt     time.Time `json:"seenAt"`
+}
+
+// SightingKey identifies a sighting (a track within a job)
+type SightingKey struct {
+	JobID   string `json:"jobId"`
+	TrackID string `json:"trackId"`
+}
+
+// GalleryMatch is a gallery search result
+type GalleryMatch struct {
+	Identity   GalleryIdentity   `json:"identity"`
+	Similarity float64           `json:"similarity"`
+	Sightings  []GallerySighting `json:"sightings,omitempty"`
+}
+
+// InteractionRecord is an interaction between tracks over a time range
+type InteractionRecord struct {
+	InteractionID string   `json:"interactionId"`
+	Type          string   `json:"type"`
+	Participants  []string `json:"participants"` // Track IDs
+	Classes       []string `json:"classes"`      // Participant classes (same order)
+	StartTime     float64  `json:"startTime"`
+	EndTime       float64  `json:"endTime"`
+	Duration      float64  `json:"duration"`     // Seconds of video time
+	StartFrame    int      `json:"startFrame"`
+	EndFrame      int      `json:"endFrame"`
+	Confidence    float64  `json:"confidence"`
+	Significance  float64  `json:"significance"`
+	Description   string   `json:"description"`
+}
+
+// InteractionEventRecord is a change in an interaction's state (started, changed, ended)
+type InteractionEventRecord struct {
+	EventID         string   `json:"eventId"`
+	InteractionID   string   `json:"interactionId"`
+	EventType       string   `json:"eventType"`
+	InteractionType string   `json:"interactionType"` // Type at the time of the event
+	Participants    []string `json:"participants"`
+	Timestamp       float64  `json:"timestamp"` // Seconds from start
+	FrameNumber     int      `json:"frameNumber"`
+	Significance    float64  `json:"significance"`
+	Proximity       float64  `json:"proximity"` // Normalized centre distance
+}
+
+// ProximityThresholds are normalized centre distances for interaction classification
+type ProximityThresholds struct {
+	Near   float64 `json:"near"`   // Meeting/collision below this
+	Medium float64 `json:"medium"` // Grouping below this
+	Far    float64 `json:"far"`    // No interaction above this
+}
+
+// InteractionQuery filters stored interactions
+type InteractionQuery struct {
+	JobID       string  `json:"jobId"`
+	Type        string  `json:"type,omitempty"`        // e.g. "handoff"
+	Class       string  `json:"class,omitempty"`       // Every participant has this class
+	TrackID     string  `json:"trackId,omitempty"`     // Involves this track
+	MinDuration float64 `json:"minDuration,omitempty"` // Seconds
+	Limit       int     `json:"limit,omitempty"`
+}
+
+// SpatialPoint is a point in normalized frame coordinates (0-1, origin top-left)
+type SpatialPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// SpatialRules are user-defined zones and lines evaluated against object tracks
+type SpatialRules struct {
+	Zones []Zone     `json:"zones,omitempty"`
+	Lines []TripLine `json:"lines,omitempty"`
+}
+
+// Zone is a polygon region; tracks are inside when their trajectory point (box centre) is
+type Zone struct {
+	ZoneID         string         `json:"zoneId"`
+	Name           string         `json:"name,omitempty"`
+	Polygon        []SpatialPoint `json:"polygon"`                  // At least 3 vertices
+	Classes        []string       `json:"classes,omitempty"`        // Object classes to count (empty = all)
+	DwellThreshold float64        `json:"dwellThreshold,omitempty"` // Seconds inside before a dwell event (0 = none)
+}
+
+// TripLine is a line segment; crossings are reported with their direction
+// Directions are relative to the line drawn from Start to End: with Start on the left
+// and End on the right of the frame, moving downwards is "left_to_right".
+type TripLine struct {
+	LineID  string       `json:"lineId"`
+	Name    string       `json:"name,omitempty"`
+	Start   SpatialPoint `json:"start"`
+	End     SpatialPoint `json:"end"`
+	Classes []string     `json:"classes,omitempty"` // Object classes to count (empty = all)
+}
+
+// SpatialEvent is a zone enter/exit, dwell or line crossing by a track
+type SpatialEvent struct {
+	Type        string       `json:"type"`   // "zone_enter", "zone_exit", "dwell", "line_cross"
+	RuleID      string       `json:"ruleId"` // Zone or line ID
+	TrackID     string       `json:"trackId"`
+	Class       string       `json:"class"`
+	Timestamp   float64      `json:"timestamp"` // Seconds (video time for jobs, Unix time for streams)
+	FrameNumber int          `json:"frameNumber"`
+	Position    SpatialPoint `json:"position"`
+	Direction   string       `json:"direction,omitempty"` // Line crossings: "left_to_right", "right_to_left"
+	DwellTime   float64      `json:"dwellTime,omitempty"` // Seconds inside (dwell and zone_exit)
+}
+
+// OccupancySample is the number of tracks inside a zone from Timestamp until the next sample
+type OccupancySample struct {
+	ZoneID      string         `json:"zoneId"`
+	Timestamp   float64        `json:"timestamp"`
+	FrameNumber int            `json:"frameNumber"`
+	Count       int            `json:"count"`
+	ByClass     map[string]int `json:"byClass,omitempty"`
+}
+
+// ZoneStats summarizes activity in a zone
+type ZoneStats struct {
+	ZoneID       string  `json:"zoneId"`
+	Entries      int     `json:"entries"`
+	Exits        int     `json:"exits"`
+	DwellEvents  int     `json:"dwellEvents"`
+	MaxOccupancy int     `json:"maxOccupancy"`
+	MeanDwell    float64 `json:"meanDwell"` // Seconds, over completed visits
+	MaxDwell     float64 `json:"maxDwell"`
+}
+
 // ContentClassification contains AI classification results
 type ContentClassification struct {
 	PrimaryCategory string            `json:"primaryCategory"`
@@ -686,6 +1478,13 @@ type Config struct {
 	TempDir               string
 	MaxVideoSize          int64 // Bytes
 	EnableGoogleDrive     bool
+	DetectorURL           string // Object detection server for tracking (empty = MageAgent vision)
+	DetectorAPIKey        string // Bearer token for the detection server
+	ReIDEmbeddingURL      string // Person re-ID embedding server (empty = colour histograms)
+	ReIDEmbeddingAPIKey   string // Bearer token for the re-ID embedding server
+	ReIDEmbeddingDim      int    // Embedding size returned by the re-ID server
+	GalleryMaxIdentities  int    // Re-ID gallery size limit per tenant (least recently seen dropped first)
+	GalleryRetentionDays  int    // Drop gallery identities not seen for this many days (0 = keep)
 }
 
 // UnmarshalJSON implements custom JSON unmarshaling for JobPayload
@@ -0,0 +1,67 @@
+package nle
+
+import (
+	"fmt"
+	"strings"
+)
+
+// recordStartHour is where the edit starts on the record side (01:00:00:00, the usual programme start)
+const recordStartHour = 1
+
+// edlReel is the reel name of the single source clip (CMX3600 reels are up to 8 characters)
+const edlReel = "AX"
+
+// BuildEDL builds a CMX3600 edit decision list
+// Every event cuts the source clip onto the record timeline end to end; markers are
+// written as "* LOC:" comments at their record timecode.
+func BuildEDL(tl *Timeline) string {
+	var b strings.Builder
+
+	fcm := "NON-DROP FRAME"
+	if tl.Rate.DropFrame {
+		fcm = "DROP FRAME"
+	}
+	fmt.Fprintf(&b, "TITLE: %s\n", edlText(tl.Title, 70))
+	fmt.Fprintf(&b, "FCM: %s\n\n", fcm)
+
+	channels := "V    "
+	if tl.HasAudio {
+		channels = "B    " // Video and audio 1
+	}
+
+	markers := tl.Markers()
+	record := tl.Rate.HourFrames(recordStartHour)
+	for _, event := range tl.Events() {
+		sourceIn := tl.Rate.Frames(event.Start)
+		sourceOut := tl.Rate.Frames(event.End)
+		recordOut := record + sourceOut - sourceIn
+
+		fmt.Fprintf(&b, "%03d  %-8s %s C        %s %s %s %s\n",
+			event.Index, edlReel, channels,
+			tl.Rate.Timecode(sourceIn), tl.Rate.Timecode(sourceOut),
+			tl.Rate.Timecode(record), tl.Rate.Timecode(recordOut))
+		fmt.Fprintf(&b, "* FROM CLIP NAME: %s\n", edlText(tl.MediaName, 120))
+		if event.Name != "" {
+			fmt.Fprintf(&b, "* COMMENT: %s\n", edlText(event.Name, 120))
+		}
+
+		for _, m := range MarkersIn(markers, event) {
+			at := record + tl.Rate.Frames(m.Time) - sourceIn
+			fmt.Fprintf(&b, "* LOC: %s %-7s %s\n", tl.Rate.Timecode(at), m.Color, edlText(m.Name, 60))
+		}
+		b.WriteString("\n")
+
+		record = recordOut
+	}
+
+	return b.String()
+}
+
+// edlText flattens text to one line of at most max characters
+func edlText(text string, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > max {
+		text = string(runes[:max])
+	}
+	return text
+}
@@ -0,0 +1,60 @@
+package nle
+
+import (
+	"testing"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+func TestBuildEDL(t *testing.T) {
+	tl := &Timeline{
+		Title:     "Product launch",
+		MediaName: "launch.mp4",
+		Duration:  75,
+		Rate:      ParseRate(29.97),
+		HasAudio:  true,
+		Scenes: []models.SceneDetection{
+			{SceneID: "s1", StartTime: 0, EndTime: 40, SceneType: "establishing"},
+			{SceneID: "s2", StartTime: 40, EndTime: 75, SceneType: "dialogue"},
+		},
+		Shots: []Shot{
+			{Start: 0.2, End: 12, Name: "Shot 1 (wide)"},
+			{Start: 12, End: 40, Name: "Shot 2 (medium)"},
+			{Start: 40, End: 75, Name: "Shot 3 (close-up)"},
+		},
+		Transcript: []models.SpeakerSegment{
+			{StartTime: 15.5, Text: "Welcome to the launch"},
+			{StartTime: 30, Text: "No keywords here"},
+			{StartTime: 62, Text: "The LAUNCH date is set"},
+		},
+		Keywords: []string{"launch"},
+	}
+
+	// Record starts at 01:00:00;00 and each event's record in is the previous
+	// record out; the third event crosses the minute-1 drop. Markers sit at
+	// their record position within the event that contains them
+	want := `TITLE: Product launch
+FCM: DROP FRAME
+
+001  AX       B     C        00:00:00;00 00:00:12;00 01:00:00;00 01:00:12;00
+* FROM CLIP NAME: launch.mp4
+* COMMENT: Shot 1 (wide)
+* LOC: 01:00:00;00 CYAN    establishing
+
+002  AX       B     C        00:00:12;00 00:00:39;29 01:00:12;00 01:00:39;29
+* FROM CLIP NAME: launch.mp4
+* COMMENT: Shot 2 (medium)
+* LOC: 01:00:15;15 YELLOW  launch
+
+003  AX       B     C        00:00:39;29 00:01:15;00 01:00:39;29 01:01:15;00
+* FROM CLIP NAME: launch.mp4
+* COMMENT: Shot 3 (close-up)
+* LOC: 01:00:39;29 GREEN   dialogue
+* LOC: 01:01:02;00 YELLOW  launch
+
+`
+
+	if got := BuildEDL(tl); got != want {
+		t.Errorf("BuildEDL() =\n%s\nwant\n%s", got, want)
+	}
+}
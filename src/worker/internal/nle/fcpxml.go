@@ -0,0 +1,187 @@
+package nle
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path/filepath"
+)
+
+// fcpxmlVersion is the FCPXML version written (Final Cut Pro 10.4.1+, DaVinci Resolve 16+)
+const fcpxmlVersion = "1.8"
+
+type fcpxmlDocument struct {
+	XMLName   xml.Name        `xml:"fcpxml"`
+	Version   string          `xml:"version,attr"`
+	Resources fcpxmlResources `xml:"resources"`
+	Library   fcpxmlLibrary   `xml:"library"`
+}
+
+type fcpxmlResources struct {
+	Format fcpxmlFormat `xml:"format"`
+	Asset  fcpxmlAsset  `xml:"asset"`
+}
+
+type fcpxmlFormat struct {
+	ID            string `xml:"id,attr"`
+	FrameDuration string `xml:"frameDuration,attr"`
+	Width         int    `xml:"width,attr,omitempty"`
+	Height        int    `xml:"height,attr,omitempty"`
+}
+
+type fcpxmlAsset struct {
+	ID       string `xml:"id,attr"`
+	Name     string `xml:"name,attr"`
+	Src      string `xml:"src,attr"`
+	Start    string `xml:"start,attr"`
+	Duration string `xml:"duration,attr"`
+	HasVideo string `xml:"hasVideo,attr"`
+	HasAudio string `xml:"hasAudio,attr,omitempty"`
+	Format   string `xml:"format,attr"`
+}
+
+type fcpxmlLibrary struct {
+	Event fcpxmlEvent `xml:"event"`
+}
+
+type fcpxmlEvent struct {
+	Name    string        `xml:"name,attr"`
+	Project fcpxmlProject `xml:"project"`
+}
+
+type fcpxmlProject struct {
+	Name     string         `xml:"name,attr"`
+	Sequence fcpxmlSequence `xml:"sequence"`
+}
+
+type fcpxmlSequence struct {
+	Format   string           `xml:"format,attr"`
+	Duration string           `xml:"duration,attr"`
+	TCStart  string           `xml:"tcStart,attr"`
+	TCFormat string           `xml:"tcFormat,attr"`
+	Spine    []fcpxmlAssetRef `xml:"spine>asset-clip"`
+}
+
+type fcpxmlAssetRef struct {
+	Ref      string          `xml:"ref,attr"`
+	Name     string          `xml:"name,attr"`
+	Offset   string          `xml:"offset,attr"`
+	Start    string          `xml:"start,attr"`
+	Duration string          `xml:"duration,attr"`
+	TCFormat string          `xml:"tcFormat,attr"`
+	Keywords []fcpxmlKeyword `xml:"keyword"`
+	Markers  []fcpxmlMarker  `xml:"marker"`
+}
+
+type fcpxmlMarker struct {
+	Start    string `xml:"start,attr"`
+	Duration string `xml:"duration,attr"`
+	Value    string `xml:"value,attr"`
+	Note     string `xml:"note,attr,omitempty"`
+}
+
+type fcpxmlKeyword struct {
+	Start    string `xml:"start,attr"`
+	Duration string `xml:"duration,attr"`
+	Value    string `xml:"value,attr"`
+}
+
+// BuildFCPXML builds an FCPXML project with one asset-clip per event on the primary storyline
+// Scene types become keyword ranges on their clips (searchable in the browser) and every
+// marker a one-frame marker; clip start/marker times are in source time.
+func BuildFCPXML(tl *Timeline) ([]byte, error) {
+	const formatID, assetID = "r1", "r2"
+
+	tcFormat := "NDF"
+	if tl.Rate.DropFrame {
+		tcFormat = "DF"
+	}
+	total := tl.Rate.Frames(tl.Duration)
+
+	doc := fcpxmlDocument{
+		Version: fcpxmlVersion,
+		Resources: fcpxmlResources{
+			Format: fcpxmlFormat{
+				ID:            formatID,
+				FrameDuration: tl.Rate.Rational(1),
+				Width:         tl.Width,
+				Height:        tl.Height,
+			},
+			Asset: fcpxmlAsset{
+				ID:       assetID,
+				Name:     tl.MediaName,
+				Src:      tl.MediaURL,
+				Start:    "0s",
+				Duration: tl.Rate.Rational(total),
+				HasVideo: "1",
+				Format:   formatID,
+			},
+		},
+		Library: fcpxmlLibrary{
+			Event: fcpxmlEvent{
+				Name: tl.Title,
+				Project: fcpxmlProject{
+					Name: tl.Title,
+					Sequence: fcpxmlSequence{
+						Format:   formatID,
+						TCStart:  "0s",
+						TCFormat: tcFormat,
+					},
+				},
+			},
+		},
+	}
+	if tl.HasAudio {
+		doc.Resources.Asset.HasAudio = "1"
+	}
+
+	markers := tl.Markers()
+	offset := int64(0)
+	for _, event := range tl.Events() {
+		start := tl.Rate.Frames(event.Start)
+		length := tl.Rate.Frames(event.End) - start
+
+		clip := fcpxmlAssetRef{
+			Ref:      assetID,
+			Name:     event.Name,
+			Offset:   tl.Rate.Rational(offset),
+			Start:    tl.Rate.Rational(start),
+			Duration: tl.Rate.Rational(length),
+			TCFormat: tcFormat,
+		}
+		if event.SceneType != "" {
+			clip.Keywords = append(clip.Keywords, fcpxmlKeyword{
+				Start:    clip.Start,
+				Duration: clip.Duration,
+				Value:    event.SceneType,
+			})
+		}
+		for _, m := range MarkersIn(markers, event) {
+			clip.Markers = append(clip.Markers, fcpxmlMarker{
+				Start:    tl.Rate.Rational(tl.Rate.Frames(m.Time)),
+				Duration: tl.Rate.Rational(1),
+				Value:    m.Name,
+				Note:     m.Comment,
+			})
+		}
+
+		doc.Library.Event.Project.Sequence.Spine = append(doc.Library.Event.Project.Sequence.Spine, clip)
+		offset += length
+	}
+	doc.Library.Event.Project.Sequence.Duration = tl.Rate.Rational(offset)
+
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal FCPXML: %w", err)
+	}
+
+	return append([]byte(xml.Header+"<!DOCTYPE fcpxml>\n\n"), append(body, '\n')...), nil
+}
+
+// FileURL converts a local path to a file:// URL
+func FileURL(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
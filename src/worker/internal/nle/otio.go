@@ -0,0 +1,172 @@
+package nle
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// OTIO schema objects (only the fields this export writes)
+
+type otioRationalTime struct {
+	Schema string  `json:"OTIO_SCHEMA"`
+	Rate   float64 `json:"rate"`
+	Value  float64 `json:"value"`
+}
+
+type otioTimeRange struct {
+	Schema    string           `json:"OTIO_SCHEMA"`
+	StartTime otioRationalTime `json:"start_time"`
+	Duration  otioRationalTime `json:"duration"`
+}
+
+type otioTimeline struct {
+	Schema          string                 `json:"OTIO_SCHEMA"`
+	Name            string                 `json:"name"`
+	GlobalStartTime otioRationalTime       `json:"global_start_time"`
+	Tracks          otioStack              `json:"tracks"`
+	Metadata        map[string]interface{} `json:"metadata"`
+}
+
+type otioStack struct {
+	Schema   string                 `json:"OTIO_SCHEMA"`
+	Name     string                 `json:"name"`
+	Children []otioTrack            `json:"children"`
+	Markers  []otioMarker           `json:"markers"`
+	Effects  []interface{}          `json:"effects"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+type otioTrack struct {
+	Schema   string                 `json:"OTIO_SCHEMA"`
+	Name     string                 `json:"name"`
+	Kind     string                 `json:"kind"`
+	Children []otioClip             `json:"children"`
+	Markers  []otioMarker           `json:"markers"`
+	Effects  []interface{}          `json:"effects"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+type otioClip struct {
+	Schema         string                 `json:"OTIO_SCHEMA"`
+	Name           string                 `json:"name"`
+	SourceRange    otioTimeRange          `json:"source_range"`
+	MediaReference otioExternalReference  `json:"media_reference"`
+	Markers        []otioMarker           `json:"markers"`
+	Effects        []interface{}          `json:"effects"`
+	Metadata       map[string]interface{} `json:"metadata"`
+}
+
+type otioExternalReference struct {
+	Schema         string                 `json:"OTIO_SCHEMA"`
+	Name           string                 `json:"name"`
+	TargetURL      string                 `json:"target_url"`
+	AvailableRange otioTimeRange          `json:"available_range"`
+	Metadata       map[string]interface{} `json:"metadata"`
+}
+
+type otioMarker struct {
+	Schema      string                 `json:"OTIO_SCHEMA"`
+	Name        string                 `json:"name"`
+	Color       string                 `json:"color"`
+	MarkedRange otioTimeRange          `json:"marked_range"`
+	Comment     string                 `json:"comment"`
+	Metadata    map[string]interface{} `json:"metadata"`
+}
+
+// BuildOTIO builds an OpenTimelineIO timeline: a video track (and a matching audio
+// track when the source has audio) with one clip per event
+// Markers sit on the video clips in source time; scene IDs and types go in clip metadata.
+func BuildOTIO(tl *Timeline) ([]byte, error) {
+	rate := tl.Rate.FPS()
+	media := otioExternalReference{
+		Schema:         "ExternalReference.1",
+		Name:           tl.MediaName,
+		TargetURL:      tl.MediaURL,
+		AvailableRange: otioRange(rate, 0, tl.Rate.Frames(tl.Duration)),
+		Metadata:       map[string]interface{}{},
+	}
+
+	markers := tl.Markers()
+	video := otioNewTrack("V1", "Video")
+	audio := otioNewTrack("A1", "Audio")
+	for _, event := range tl.Events() {
+		start := tl.Rate.Frames(event.Start)
+		clip := otioClip{
+			Schema:         "Clip.1",
+			Name:           event.Name,
+			SourceRange:    otioRange(rate, start, tl.Rate.Frames(event.End)-start),
+			MediaReference: media,
+			Markers:        []otioMarker{},
+			Effects:        []interface{}{},
+			Metadata: map[string]interface{}{
+				"videoagent": map[string]interface{}{
+					"sceneId":   event.SceneID,
+					"sceneType": event.SceneType,
+				},
+			},
+		}
+		audio.Children = append(audio.Children, clip)
+
+		for _, m := range MarkersIn(markers, event) {
+			clip.Markers = append(clip.Markers, otioMarker{
+				Schema:      "Marker.2",
+				Name:        m.Name,
+				Color:       m.Color,
+				MarkedRange: otioRange(rate, tl.Rate.Frames(m.Time), 0),
+				Comment:     m.Comment,
+				Metadata:    map[string]interface{}{"videoagent": map[string]interface{}{"kind": m.Kind}},
+			})
+		}
+		video.Children = append(video.Children, clip)
+	}
+
+	tracks := []otioTrack{video}
+	if tl.HasAudio {
+		tracks = append(tracks, audio)
+	}
+
+	timeline := otioTimeline{
+		Schema:          "Timeline.1",
+		Name:            tl.Title,
+		GlobalStartTime: otioTime(rate, 0),
+		Tracks: otioStack{
+			Schema:   "Stack.1",
+			Name:     "tracks",
+			Children: tracks,
+			Markers:  []otioMarker{},
+			Effects:  []interface{}{},
+			Metadata: map[string]interface{}{},
+		},
+		Metadata: map[string]interface{}{},
+	}
+
+	body, err := json.MarshalIndent(timeline, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal OTIO timeline: %w", err)
+	}
+	return append(body, '\n'), nil
+}
+
+func otioNewTrack(name, kind string) otioTrack {
+	return otioTrack{
+		Schema:   "Track.1",
+		Name:     name,
+		Kind:     kind,
+		Children: []otioClip{},
+		Markers:  []otioMarker{},
+		Effects:  []interface{}{},
+		Metadata: map[string]interface{}{},
+	}
+}
+
+func otioTime(rate float64, frames int64) otioRationalTime {
+	return otioRationalTime{Schema: "RationalTime.1", Rate: rate, Value: float64(frames)}
+}
+
+func otioRange(rate float64, start, duration int64) otioTimeRange {
+	return otioTimeRange{
+		Schema:    "TimeRange.1",
+		StartTime: otioTime(rate, start),
+		Duration:  otioTime(rate, duration),
+	}
+}
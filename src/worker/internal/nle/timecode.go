@@ -0,0 +1,100 @@
+package nle
+
+import (
+	"fmt"
+	"math"
+)
+
+// Rate is a video frame rate as a rational frame duration (Num/Den seconds per frame)
+// NTSC rates (23.976, 29.97, 59.94) are 1001/(n*1000); 29.97 and 59.94 use drop-frame timecode.
+type Rate struct {
+	Num       int64 // Frame duration numerator
+	Den       int64 // Frame duration denominator
+	Nominal   int64 // Timecode frames per second (24, 25, 30, ...)
+	DropFrame bool
+}
+
+// defaultFrameRate is used when the frame rate is unknown
+const defaultFrameRate = 30.0
+
+// ntscRates are the nominal rates that have a 1000/1001 variant
+var ntscRates = map[int64]bool{24: true, 30: true, 48: true, 60: true}
+
+// ParseRate converts frames per second into a rate, recognising NTSC rates
+func ParseRate(fps float64) Rate {
+	if fps <= 0 {
+		fps = defaultFrameRate
+	}
+
+	if n := int64(math.Round(fps * 1.001)); ntscRates[n] && math.Abs(fps-float64(n)/1.001) < 0.005 {
+		return Rate{Num: 1001, Den: n * 1000, Nominal: n, DropFrame: n == 30 || n == 60}
+	}
+
+	n := int64(math.Round(fps))
+	if n < 1 {
+		n = 1
+	}
+	return Rate{Num: 1, Den: n, Nominal: n}
+}
+
+// FPS returns the exact frames per second
+func (r Rate) FPS() float64 {
+	return float64(r.Den) / float64(r.Num)
+}
+
+// Frames converts seconds to the nearest frame count
+func (r Rate) Frames(seconds float64) int64 {
+	return int64(math.Round(seconds * r.FPS()))
+}
+
+// dropPerMinute is how many frame numbers drop-frame timecode skips each minute
+// (2 at 29.97, 4 at 59.94; none in every tenth minute)
+func (r Rate) dropPerMinute() int64 {
+	if !r.DropFrame {
+		return 0
+	}
+	return r.Nominal / 15
+}
+
+// Timecode formats a frame count as HH:MM:SS:FF (HH:MM:SS;FF for drop-frame)
+func (r Rate) Timecode(frames int64) string {
+	if frames < 0 {
+		frames = 0
+	}
+
+	separator := ":"
+	if drop := r.dropPerMinute(); drop > 0 {
+		separator = ";"
+		perMinute := r.Nominal*60 - drop
+		perTenMinutes := r.Nominal*600 - drop*9
+
+		tens := frames / perTenMinutes
+		rest := frames % perTenMinutes
+		frames += drop * 9 * tens
+		if rest > drop {
+			frames += drop * ((rest - drop) / perMinute)
+		}
+	}
+
+	ff := frames % r.Nominal
+	ss := frames / r.Nominal % 60
+	mm := frames / (r.Nominal * 60) % 60
+	hh := frames / (r.Nominal * 3600)
+	return fmt.Sprintf("%02d:%02d:%02d%s%02d", hh, mm, ss, separator, ff)
+}
+
+// HourFrames returns the frame count at timecode hours:00:00:00
+func (r Rate) HourFrames(hours int64) int64 {
+	if drop := r.dropPerMinute(); drop > 0 {
+		return hours * 6 * (r.Nominal*600 - drop*9)
+	}
+	return hours * 3600 * r.Nominal
+}
+
+// Rational formats a frame count as FCPXML rational seconds (e.g. "1001/30000s")
+func (r Rate) Rational(frames int64) string {
+	if frames == 0 {
+		return "0s"
+	}
+	return fmt.Sprintf("%d/%ds", frames*r.Num, r.Den)
+}
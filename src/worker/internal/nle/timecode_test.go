@@ -0,0 +1,94 @@
+package nle
+
+import "testing"
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		fps  float64
+		want Rate
+	}{
+		{29.97, Rate{Num: 1001, Den: 30000, Nominal: 30, DropFrame: true}},
+		{59.94, Rate{Num: 1001, Den: 60000, Nominal: 60, DropFrame: true}},
+		{23.976, Rate{Num: 1001, Den: 24000, Nominal: 24}},
+		{24, Rate{Num: 1, Den: 24, Nominal: 24}},
+		{25, Rate{Num: 1, Den: 25, Nominal: 25}},
+		{0, Rate{Num: 1, Den: 30, Nominal: 30}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseRate(tt.fps); got != tt.want {
+			t.Errorf("ParseRate(%v) = %+v, want %+v", tt.fps, got, tt.want)
+		}
+	}
+}
+
+func TestTimecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		fps    float64
+		frames int64
+		want   string
+	}{
+		{"29.97 last frame of minute 0", 29.97, 1799, "00:00:59;29"},
+		{"29.97 skips ;00 and ;01 at minute 1", 29.97, 1800, "00:01:00;02"},
+		{"29.97 last frame of minute 1", 29.97, 3597, "00:01:59;29"},
+		{"29.97 skips at minute 2", 29.97, 3598, "00:02:00;02"},
+		{"29.97 keeps ;00 at minute 10", 29.97, 17982, "00:10:00;00"},
+		{"29.97 one hour", 29.97, 107892, "01:00:00;00"},
+		{"59.94 last frame of minute 0", 59.94, 3599, "00:00:59;59"},
+		{"59.94 skips four frames at minute 1", 59.94, 3600, "00:01:00;04"},
+		{"59.94 keeps ;00 at minute 10", 59.94, 35964, "00:10:00;00"},
+		{"23.976 counts non-drop", 23.976, 24, "00:00:01:00"},
+		{"23.976 last frame of the hour", 23.976, 86399, "00:59:59:23"},
+		{"24", 24, 86399, "00:59:59:23"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseRate(tt.fps).Timecode(tt.frames); got != tt.want {
+				t.Errorf("Timecode(%d) = %s, want %s", tt.frames, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHourFrames(t *testing.T) {
+	tests := []struct {
+		fps  float64
+		want int64
+	}{
+		{29.97, 107892},
+		{59.94, 215784},
+		{23.976, 86400},
+		{24, 86400},
+		{25, 90000},
+	}
+
+	for _, tt := range tests {
+		r := ParseRate(tt.fps)
+		if got := r.HourFrames(1); got != tt.want {
+			t.Errorf("HourFrames(1) at %v = %d, want %d", tt.fps, got, tt.want)
+		}
+		if tc := r.Timecode(r.HourFrames(1)); tc[:8] != "01:00:00" {
+			t.Errorf("Timecode(HourFrames(1)) at %v = %s, want 01:00:00", tt.fps, tc)
+		}
+	}
+}
+
+func TestRational(t *testing.T) {
+	tests := []struct {
+		fps    float64
+		frames int64
+		want   string
+	}{
+		{23.976, 1, "1001/24000s"},
+		{24, 48, "48/24s"},
+		{29.97, 0, "0s"},
+	}
+
+	for _, tt := range tests {
+		if got := ParseRate(tt.fps).Rational(tt.frames); got != tt.want {
+			t.Errorf("Rational(%d) at %v = %s, want %s", tt.frames, tt.fps, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package nle
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+	"github.com/adverant/nexus/videoagent-worker/internal/similarity"
+)
+
+// Marker kinds
+const (
+	MarkerScene   = "scene"
+	MarkerKeyword = "keyword"
+)
+
+// Timeline is the material for an NLE export: one source clip cut at shot
+// (or scene) boundaries, with scene-type and keyword markers
+type Timeline struct {
+	Title      string
+	MediaName  string // Source clip name (EDL clip name, FCPXML/OTIO media name)
+	MediaURL   string // Source clip location referenced by FCPXML and OTIO (editors relink by name when it moves)
+	Duration   float64
+	Rate       Rate
+	Width      int
+	Height     int
+	HasAudio   bool
+	Scenes     []models.SceneDetection
+	Shots      []Shot // Cut points inside scenes; empty cuts at scene boundaries
+	Transcript []models.SpeakerSegment
+	Keywords   []string // Transcript segments mentioning these get keyword markers
+}
+
+// Shot is a cut within the source clip (seconds)
+type Shot struct {
+	Start float64
+	End   float64
+	Name  string
+}
+
+// Event is one clip of the edit, covering [Start, End) of the source
+type Event struct {
+	Index     int // 1-based
+	Name      string
+	Start     float64
+	End       float64
+	SceneID   string
+	SceneType string
+}
+
+// Marker is a point of interest on the source (seconds)
+type Marker struct {
+	Time    float64
+	Kind    string // MarkerScene or MarkerKeyword
+	Name    string
+	Comment string
+	Color   string // Upper-case colour name understood by EDL readers and OTIO
+}
+
+// ShotsFromInfo converts scene-embedding shots to seconds
+// Shot frame numbers index frames sampled at fps; infos are the video's shots in order
+// (ShotNum restarts in every scene, so shots are renumbered across the video).
+func ShotsFromInfo(infos []similarity.ShotInfo, fps float64) []Shot {
+	if fps <= 0 {
+		fps = defaultFrameRate
+	}
+
+	shots := make([]Shot, 0, len(infos))
+	for i, info := range infos {
+		shots = append(shots, Shot{
+			Start: float64(info.StartFrame) / fps,
+			End:   float64(info.EndFrame+1) / fps,
+			Name:  fmt.Sprintf("Shot %d (%s)", i+1, info.ShotSize),
+		})
+	}
+	return shots
+}
+
+// Events cuts the source into contiguous clips: at shot starts when there are
+// shots, otherwise at scene starts, otherwise one clip for the whole source
+// Each clip runs to the next cut so the edit has no gaps.
+func (tl *Timeline) Events() []Event {
+	type cut struct {
+		start float64
+		name  string
+	}
+
+	cuts := make([]cut, 0)
+	switch {
+	case len(tl.Shots) > 0:
+		for _, shot := range tl.Shots {
+			cuts = append(cuts, cut{shot.Start, shot.Name})
+		}
+	case len(tl.Scenes) > 0:
+		for i, s := range tl.Scenes {
+			cuts = append(cuts, cut{s.StartTime, sceneName(i, s)})
+		}
+	default:
+		cuts = append(cuts, cut{0, tl.Title})
+	}
+	sort.SliceStable(cuts, func(i, j int) bool { return cuts[i].start < cuts[j].start })
+
+	// The first clip starts at the head of the source (but keeps its scene)
+	head := cuts[0].start
+	cuts[0].start = 0
+
+	events := make([]Event, 0, len(cuts))
+	for i, c := range cuts {
+		end := tl.Duration
+		if i+1 < len(cuts) {
+			end = cuts[i+1].start
+		}
+		if tl.Rate.Frames(end) <= tl.Rate.Frames(c.start) {
+			continue // Shorter than a frame
+		}
+
+		event := Event{Index: len(events) + 1, Name: c.name, Start: c.start, End: end}
+		if s := tl.sceneAt(math.Max(c.start, head)); s != nil {
+			event.SceneID, event.SceneType = s.SceneID, s.SceneType
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// Markers returns a marker at each scene start (named by scene type) and one per
+// keyword-bearing transcript segment, ordered by time
+func (tl *Timeline) Markers() []Marker {
+	markers := make([]Marker, 0)
+
+	for i, s := range tl.Scenes {
+		name := s.SceneType
+		if name == "" {
+			name = "scene"
+		}
+		markers = append(markers, Marker{
+			Time:    s.StartTime,
+			Kind:    MarkerScene,
+			Name:    name,
+			Comment: strings.TrimSpace(sceneName(i, s) + " " + s.Description),
+			Color:   sceneColor(s.SceneType),
+		})
+	}
+
+	patterns := keywordPatterns(tl.Keywords)
+	for _, segment := range tl.Transcript {
+		hits := make([]string, 0)
+		for _, p := range patterns {
+			if p.pattern.MatchString(segment.Text) {
+				hits = append(hits, p.keyword)
+			}
+		}
+		if len(hits) == 0 {
+			continue
+		}
+		markers = append(markers, Marker{
+			Time:    segment.StartTime,
+			Kind:    MarkerKeyword,
+			Name:    strings.Join(hits, ", "),
+			Comment: strings.TrimSpace(segment.Text),
+			Color:   "YELLOW",
+		})
+	}
+
+	sort.SliceStable(markers, func(i, j int) bool { return markers[i].Time < markers[j].Time })
+	return markers
+}
+
+// MarkersIn returns the markers within [event.Start, event.End)
+func MarkersIn(markers []Marker, event Event) []Marker {
+	within := make([]Marker, 0)
+	for _, m := range markers {
+		if m.Time >= event.Start && m.Time < event.End {
+			within = append(within, m)
+		}
+	}
+	return within
+}
+
+// sceneAt returns the scene containing t (the last scene starting at or before it)
+func (tl *Timeline) sceneAt(t float64) *models.SceneDetection {
+	var found *models.SceneDetection
+	for i := range tl.Scenes {
+		if tl.Scenes[i].StartTime <= t+1e-6 {
+			found = &tl.Scenes[i]
+		}
+	}
+	return found
+}
+
+// sceneName labels a scene for clip names
+func sceneName(index int, s models.SceneDetection) string {
+	if s.SceneType != "" {
+		return fmt.Sprintf("Scene %d (%s)", index+1, s.SceneType)
+	}
+	return fmt.Sprintf("Scene %d", index+1)
+}
+
+// sceneColor picks a marker colour per scene type
+func sceneColor(sceneType string) string {
+	switch sceneType {
+	case "action":
+		return "RED"
+	case "dialogue":
+		return "GREEN"
+	case "establishing":
+		return "CYAN"
+	case "transition", "montage":
+		return "PURPLE"
+	default:
+		return "BLUE"
+	}
+}
+
+type keywordPattern struct {
+	keyword string
+	pattern *regexp.Regexp
+}
+
+// keywordPatterns builds case-insensitive whole-word matchers for the keywords
+func keywordPatterns(keywords []string) []keywordPattern {
+	patterns := make([]keywordPattern, 0, len(keywords))
+	seen := make(map[string]bool)
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		key := strings.ToLower(keyword)
+		if keyword == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		patterns = append(patterns, keywordPattern{
+			keyword: keyword,
+			pattern: regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(keyword) + `\b`),
+		})
+	}
+	return patterns
+}
@@ -0,0 +1,180 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/clients"
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+	"github.com/adverant/nexus/videoagent-worker/internal/scene"
+	"github.com/adverant/nexus/videoagent-worker/internal/utils"
+)
+
+// keyframesPerScene is how many keyframes of each scene are analyzed (first, middle, last)
+const keyframesPerScene = 3
+
+// Camera motion is measured on a short grayscale burst from the middle of each scene
+const (
+	motionWindow = 2.0 // Seconds
+	motionFPS    = 10.0
+	motionWidth  = 160 // Pixels (height follows the aspect ratio)
+)
+
+// CinematicStage runs scene classification, shot composition, camera movement and
+// colour/lighting/mood analysis on the keyframes of each detected scene
+type CinematicStage struct {
+	ffmpeg   *utils.FFmpegHelper
+	analyzer *scene.CinematicAnalyzer
+	mage     *clients.MageAgentClient
+}
+
+// NewCinematicStage creates a new cinematic analysis stage
+func NewCinematicStage(ffmpeg *utils.FFmpegHelper, mageAgent *clients.MageAgentClient) *CinematicStage {
+	return &CinematicStage{
+		ffmpeg:   ffmpeg,
+		analyzer: scene.NewCinematicAnalyzer(mageAgent),
+		mage:     mageAgent,
+	}
+}
+
+// Run analyzes each scene and fills its Cinematic, SceneType and Confidence
+// Scene StartFrame/EndFrame index into frames (as produced by FrameExtractor.DetectScenes).
+// Scenes whose analysis fails are returned unchanged.
+func (cs *CinematicStage) Run(
+	ctx context.Context,
+	videoPath string,
+	jobID string,
+	frames []models.FrameAnalysis,
+	scenes []models.SceneDetection,
+) ([]models.SceneDetection, error) {
+	outputDir := filepath.Join(filepath.Dir(videoPath), fmt.Sprintf("%s_cinematic_frames", jobID))
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create keyframe directory: %w", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	analyzed := make([]models.SceneDetection, len(scenes))
+	copy(analyzed, scenes)
+
+	// Camera motion is measured at a fixed width; without the resolution it's left to the vision model
+	motionHeight := 0
+	if width, height, err := cs.ffmpeg.GetResolution(videoPath); err != nil {
+		log.Printf("Warning: failed to get resolution, camera movement will not be measured: %v", err)
+	} else if width > 0 && height > 0 {
+		motionHeight = (motionWidth*height/width + 1) / 2 * 2
+	}
+
+	for i := range analyzed {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		s := &analyzed[i]
+
+		// Step 1: Load the scene's keyframes
+		keyframes := make([]string, 0, keyframesPerScene)
+		keyframeTimes := make([]float64, 0, keyframesPerScene)
+		for _, idx := range keyframeIndices(s.StartFrame, s.EndFrame, len(frames)) {
+			frameData, err := cs.loadKeyframe(videoPath, outputDir, frames[idx], i)
+			if err != nil {
+				log.Printf("Warning: failed to load keyframe at %.2fs for scene %s: %v", frames[idx].Timestamp, s.SceneID, err)
+				continue
+			}
+			keyframes = append(keyframes, frameData)
+			keyframeTimes = append(keyframeTimes, frames[idx].Timestamp)
+		}
+
+		// Step 2: Measure camera movement
+		var measured *scene.CameraMovement
+		if motionHeight > 0 {
+			movement, err := cs.measureMovement(ctx, videoPath, *s, motionHeight)
+			if err != nil {
+				log.Printf("Warning: failed to measure camera movement for scene %s: %v", s.SceneID, err)
+			} else {
+				measured = movement
+			}
+		}
+
+		// Step 3: Analyze and attach
+		cinematics, err := cs.analyzer.AnalyzeScene(ctx, keyframes, keyframeTimes, measured)
+		if err != nil {
+			log.Printf("Warning: cinematic analysis failed for scene %s: %v", s.SceneID, err)
+			continue
+		}
+
+		s.Cinematic = cinematics
+		s.SceneType = cinematics.Classification.SceneType
+		if s.Confidence == 0 {
+			s.Confidence = cinematics.Classification.Confidence
+		}
+	}
+
+	return analyzed, nil
+}
+
+// measureMovement measures camera movement on a grayscale burst from the middle of the scene
+func (cs *CinematicStage) measureMovement(ctx context.Context, videoPath string, s models.SceneDetection, height int) (*scene.CameraMovement, error) {
+	window := math.Min(motionWindow, s.EndTime-s.StartTime)
+	if window < 2/motionFPS {
+		return nil, fmt.Errorf("scene too short (%.2fs)", s.EndTime-s.StartTime)
+	}
+	start := (s.StartTime+s.EndTime)/2 - window/2
+
+	frames, err := cs.ffmpeg.ExtractGrayFrames(ctx, videoPath, start, window, motionFPS, motionWidth, height)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamps := make([]float64, len(frames))
+	for i := range frames {
+		timestamps[i] = start + float64(i)/motionFPS
+	}
+
+	return scene.NewCameraMovementDetector(cs.mage).MeasureMovement(frames, timestamps)
+}
+
+// loadKeyframe returns a frame as base64 JPEG, re-extracting it when the file is gone
+func (cs *CinematicStage) loadKeyframe(videoPath, outputDir string, frame models.FrameAnalysis, sceneIndex int) (string, error) {
+	if frame.FilePath != "" {
+		if _, err := os.Stat(frame.FilePath); err == nil {
+			return cs.ffmpeg.EncodeFrameToBase64(frame.FilePath)
+		}
+	}
+
+	framePath := filepath.Join(outputDir, fmt.Sprintf("scene_%03d_%d.jpg", sceneIndex, frame.FrameNumber))
+	if err := cs.ffmpeg.ExtractFrame(videoPath, frame.Timestamp, framePath); err != nil {
+		return "", err
+	}
+	return cs.ffmpeg.EncodeFrameToBase64(framePath)
+}
+
+// keyframeIndices picks up to keyframesPerScene evenly spaced frame indices in [start, end]
+func keyframeIndices(start, end, frameCount int) []int {
+	if start < 0 {
+		start = 0
+	}
+	if end >= frameCount {
+		end = frameCount - 1
+	}
+	if end < start {
+		return nil
+	}
+
+	span := end - start
+	if span+1 <= keyframesPerScene {
+		indices := make([]int, 0, span+1)
+		for idx := start; idx <= end; idx++ {
+			indices = append(indices, idx)
+		}
+		return indices
+	}
+
+	indices := make([]int, 0, keyframesPerScene)
+	for k := 0; k < keyframesPerScene; k++ {
+		indices = append(indices, start+k*span/(keyframesPerScene-1))
+	}
+	return indices
+}
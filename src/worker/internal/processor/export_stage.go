@@ -0,0 +1,136 @@
+package processor
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+	"github.com/adverant/nexus/videoagent-worker/internal/nle"
+	"github.com/adverant/nexus/videoagent-worker/internal/similarity"
+)
+
+// ExportStage writes the detected scenes (or shots), scene-type markers and
+// transcript keyword markers as timelines for editing applications
+type ExportStage struct{}
+
+// NewExportStage creates a new NLE export stage
+func NewExportStage() *ExportStage {
+	return &ExportStage{}
+}
+
+// Run writes one timeline per requested format into outputDir
+// shots may be empty, in which case clips are cut at scene boundaries.
+func (es *ExportStage) Run(
+	job *models.JobPayload,
+	videoPath string,
+	outputDir string,
+	metadata *models.VideoMetadata,
+	scenes []models.SceneDetection,
+	shots []nle.Shot,
+	audio *models.AudioAnalysis,
+) ([]models.EditExport, error) {
+	formats := job.Options.GetExportFormats()
+	if len(formats) == 0 {
+		return nil, nil
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Step 1: Build the timeline
+	tl := &nle.Timeline{
+		Title:     exportTitle(job),
+		MediaName: exportTitle(job),
+		MediaURL:  mediaURL(job, videoPath),
+		Scenes:    scenes,
+		Shots:     shots,
+		Keywords:  job.Options.ExportKeywords,
+	}
+	if metadata != nil {
+		tl.Duration = metadata.Duration
+		tl.Rate = nle.ParseRate(metadata.FrameRate)
+		tl.Width, tl.Height = metadata.Width, metadata.Height
+		tl.HasAudio = metadata.AudioCodec != ""
+	} else {
+		tl.Rate = nle.ParseRate(0)
+	}
+	if audio != nil {
+		tl.Transcript = audio.Speakers
+		if len(tl.Keywords) == 0 {
+			tl.Keywords = audio.Keywords
+		}
+	}
+	if tl.Duration <= 0 && len(scenes) > 0 {
+		tl.Duration = scenes[len(scenes)-1].EndTime
+	}
+
+	events := len(tl.Events())
+	markers := len(tl.Markers())
+
+	// Step 2: Write each format
+	exports := make([]models.EditExport, 0, len(formats))
+	for _, format := range formats {
+		var data []byte
+		var ext string
+		var err error
+
+		switch format {
+		case models.ExportFormatEDL:
+			data, ext = []byte(nle.BuildEDL(tl)), "edl"
+		case models.ExportFormatFCPXML:
+			data, err = nle.BuildFCPXML(tl)
+			ext = "fcpxml"
+		case models.ExportFormatOTIO:
+			data, err = nle.BuildOTIO(tl)
+			ext = "otio"
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		path := filepath.Join(outputDir, fmt.Sprintf("%s_timeline.%s", job.JobID, ext))
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s export: %w", format, err)
+		}
+
+		exports = append(exports, models.EditExport{
+			Format:  format,
+			Path:    path,
+			Events:  events,
+			Markers: markers,
+		})
+	}
+
+	return exports, nil
+}
+
+// ExportShots converts the shots found while embedding scenes into cuts for the exports
+// Shot frames index the frameCount frames embedded over duration seconds; nil when there are none.
+func ExportShots(embeddings []similarity.SceneEmbedding, frameCount int, duration float64) []nle.Shot {
+	infos := make([]similarity.ShotInfo, 0)
+	for _, embedding := range embeddings {
+		infos = append(infos, embedding.Shots...)
+	}
+	if len(infos) == 0 || frameCount == 0 || duration <= 0 {
+		return nil
+	}
+	return nle.ShotsFromInfo(infos, float64(frameCount)/duration)
+}
+
+// exportTitle names the timeline and its clip after the uploaded file when known
+func exportTitle(job *models.JobPayload) string {
+	if job.Filename != "" {
+		return job.Filename
+	}
+	return job.JobID
+}
+
+// mediaURL points exports at the original source when it's a URL, else at the processed copy
+func mediaURL(job *models.JobPayload, videoPath string) string {
+	if u, err := url.Parse(job.VideoURL); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return job.VideoURL
+	}
+	return nle.FileURL(videoPath)
+}
@@ -0,0 +1,200 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/highlights"
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+	"github.com/adverant/nexus/videoagent-worker/internal/reframe"
+	"github.com/adverant/nexus/videoagent-worker/internal/utils"
+)
+
+// maxSocialWidth caps the width of cropped social clips (1080x1920 vertical, 1080x1080 square)
+const maxSocialWidth = 1080
+
+// HighlightStage scores scenes by interaction significance, loudness peaks,
+// transcript keywords and scene type, then cuts the best of them into a reel
+// and per-highlight clips
+type HighlightStage struct {
+	ffmpeg   *utils.FFmpegHelper
+	scorer   *highlights.Scorer
+	selector *highlights.Selector
+}
+
+// NewHighlightStage creates a new highlight stage
+func NewHighlightStage(ffmpeg *utils.FFmpegHelper) *HighlightStage {
+	return &HighlightStage{
+		ffmpeg:   ffmpeg,
+		scorer:   highlights.NewScorer(),
+		selector: highlights.NewSelector(),
+	}
+}
+
+// Run selects highlights up to the target duration and renders them into outputDir
+// scenes may be empty, in which case shots detected here are scored instead.
+func (hs *HighlightStage) Run(
+	ctx context.Context,
+	job *models.JobPayload,
+	videoPath string,
+	outputDir string,
+	metadata *models.VideoMetadata,
+	scenes []models.SceneDetection,
+	audio *models.AudioAnalysis,
+	tracking *models.TrackingAnalysis,
+) (*models.HighlightReel, error) {
+	if metadata == nil {
+		return nil, fmt.Errorf("video metadata is required for highlights")
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	options := job.Options
+	target := options.GetHighlightDuration()
+
+	// Step 1: Measure shot changes and momentary loudness in one pass
+	graph, outputs := highlights.FilterGraph(metadata.AudioCodec != "")
+	analysis, err := hs.ffmpeg.RunFilterAnalysis(ctx, videoPath, graph, outputs)
+	if err != nil {
+		return nil, err
+	}
+	signals := highlights.ParseLog(analysis)
+
+	// Step 2: Score scenes
+	input := highlights.Input{
+		Scenes:   scenes,
+		Duration: metadata.Duration,
+		Keywords: options.HighlightKeywords,
+		Signals:  signals,
+	}
+	if tracking != nil {
+		input.Interactions = tracking.Interactions
+	}
+	if audio != nil {
+		input.Transcript = audio.Speakers
+		if len(input.Keywords) == 0 {
+			input.Keywords = audio.Keywords
+		}
+	}
+	candidates := hs.scorer.Score(input)
+
+	// Step 3: Select segments up to the target, snapped to shot boundaries
+	segments := hs.selector.Select(candidates, signals.ShotBoundaries, target)
+	reel := &models.HighlightReel{
+		TargetDuration: target,
+		Highlights:     make([]models.Highlight, 0, len(segments)),
+	}
+	if len(segments) == 0 {
+		return reel, nil
+	}
+
+	// Step 4: Cut each segment in source framing and join them into the reel
+	formats := options.GetHighlightFormats()
+	keepLandscape := false
+	for _, format := range formats {
+		if format == models.AspectLandscape {
+			keepLandscape = true
+		}
+	}
+
+	cuts := make([]string, 0, len(segments))
+	for i, segment := range segments {
+		path := filepath.Join(outputDir, fmt.Sprintf("%s_highlight_%02d.mp4", job.JobID, i+1))
+		if err := hs.ffmpeg.CutClip(ctx, videoPath, segment.Start, segment.Duration(), "", path); err != nil {
+			return nil, fmt.Errorf("failed to cut highlight %d: %w", i+1, err)
+		}
+		cuts = append(cuts, path)
+
+		highlight := models.Highlight{
+			SceneID:     segment.SceneID,
+			SceneType:   segment.SceneType,
+			StartTime:   segment.Start,
+			EndTime:     segment.End,
+			Score:       segment.Score,
+			Interaction: segment.Interaction,
+			Loudness:    segment.Loudness,
+			Keywords:    segment.Keywords,
+			Priority:    segment.Priority,
+			Reasons:     segment.Reasons,
+		}
+		if keepLandscape {
+			highlight.Clips = append(highlight.Clips, models.HighlightClip{
+				Format: models.AspectLandscape,
+				Path:   path,
+				Width:  metadata.Width,
+				Height: metadata.Height,
+			})
+		}
+		reel.Highlights = append(reel.Highlights, highlight)
+		reel.Duration += segment.Duration()
+	}
+
+	reel.Path = filepath.Join(outputDir, fmt.Sprintf("%s_highlights.mp4", job.JobID))
+	if err := hs.ffmpeg.ConcatClips(ctx, cuts, reel.Path); err != nil {
+		return nil, err
+	}
+	if !keepLandscape {
+		hs.ffmpeg.Cleanup(cuts...)
+	}
+
+	// Step 5: Crop per-highlight clips for the other social formats
+	for _, format := range formats {
+		if format == models.AspectLandscape {
+			continue
+		}
+		filter, width, height := cropFilter(format, metadata.Width, metadata.Height)
+		for i, segment := range segments {
+			path := filepath.Join(outputDir, fmt.Sprintf("%s_highlight_%02d_%s.mp4", job.JobID, i+1, formatSlug(format)))
+			if err := hs.ffmpeg.CutClip(ctx, videoPath, segment.Start, segment.Duration(), filter, path); err != nil {
+				log.Printf("Warning: failed to render %s clip for highlight %d: %v", format, i+1, err)
+				// Non-fatal - continue without this clip
+				continue
+			}
+			reel.Highlights[i].Clips = append(reel.Highlights[i].Clips, models.HighlightClip{
+				Format: format,
+				Path:   path,
+				Width:  width,
+				Height: height,
+			})
+		}
+	}
+
+	return reel, nil
+}
+
+// cropFilter builds a centred crop (and downscale) from the source to the format's
+// aspect ratio, returning the filter and the output size
+func cropFilter(format string, width, height int) (string, int, int) {
+	num, den, _ := reframe.Aspect(format)
+	cropW, cropH := reframe.CropSize(width, height, num, den)
+
+	filter := fmt.Sprintf("crop=%d:%d:%d:%d", cropW, cropH, (width-cropW)/2, (height-cropH)/2)
+	outW, outH := socialSize(cropW, cropH)
+	if outW != cropW {
+		filter += fmt.Sprintf(",scale=%d:%d", outW, outH)
+	}
+	return filter, outW, outH
+}
+
+// socialSize caps a cropped frame at maxSocialWidth, keeping its aspect ratio
+func socialSize(cropW, cropH int) (int, int) {
+	if cropW <= maxSocialWidth {
+		return cropW, cropH
+	}
+	return maxSocialWidth, (maxSocialWidth * cropH / cropW) &^ 1
+}
+
+// formatSlug turns an aspect ratio into a file name part ("9:16" -> "9x16")
+func formatSlug(format string) string {
+	switch format {
+	case models.AspectVertical:
+		return "9x16"
+	case models.AspectSquare:
+		return "1x1"
+	default:
+		return "16x9"
+	}
+}
@@ -0,0 +1,54 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+	"github.com/adverant/nexus/videoagent-worker/internal/similarity"
+	"github.com/adverant/nexus/videoagent-worker/internal/utils"
+)
+
+// VideoIndexer embeds processed videos for similarity search (similarity.SimilarityModule satisfies it)
+type VideoIndexer interface {
+	IndexVideo(ctx context.Context, job *models.JobPayload, frames []string, metadata similarity.VideoMetadata, scenes []models.SceneDetection) (*similarity.VideoEmbedding, []similarity.SceneEmbedding, error)
+}
+
+// IndexStage stores video and scene embeddings in the vector index under the job's tenant
+type IndexStage struct {
+	ffmpeg  *utils.FFmpegHelper
+	indexer VideoIndexer
+}
+
+// NewIndexStage creates a new index stage
+func NewIndexStage(ffmpeg *utils.FFmpegHelper, indexer VideoIndexer) *IndexStage {
+	return &IndexStage{
+		ffmpeg:  ffmpeg,
+		indexer: indexer,
+	}
+}
+
+// Run embeds the analyzed frames and indexes the video and its scenes
+// scenes carry cinematic analysis into the scene embeddings; duration is the video length in seconds
+func (is *IndexStage) Run(ctx context.Context, job *models.JobPayload, frames []models.FrameAnalysis, scenes []models.SceneDetection, duration float64) (*similarity.VideoEmbedding, []similarity.SceneEmbedding, error) {
+	encoded := make([]string, 0, len(frames))
+	for _, frame := range frames {
+		data, err := is.ffmpeg.EncodeFrameToBase64(frame.FilePath)
+		if err != nil {
+			// Non-fatal - continue without this frame
+			fmt.Printf("Warning: failed to encode frame %s for indexing: %v\n", frame.FrameID, err)
+			continue
+		}
+		encoded = append(encoded, data)
+	}
+	if len(encoded) == 0 {
+		return nil, nil, fmt.Errorf("no frames could be encoded for indexing")
+	}
+
+	metadata := similarity.VideoMetadata{
+		Title:      job.Filename,
+		Duration:   duration,
+		Attributes: map[string]interface{}{"source": job.SourceType},
+	}
+	return is.indexer.IndexVideo(ctx, job, encoded, metadata, scenes)
+}
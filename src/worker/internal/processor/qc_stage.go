@@ -0,0 +1,61 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+	"github.com/adverant/nexus/videoagent-worker/internal/qc"
+	"github.com/adverant/nexus/videoagent-worker/internal/utils"
+)
+
+// QCStage runs technical quality control: black and frozen picture, silence,
+// loudness (EBU R128), interlacing and letterboxing, judged against a QC profile
+type QCStage struct {
+	ffmpeg *utils.FFmpegHelper
+}
+
+// NewQCStage creates a new QC stage
+func NewQCStage(ffmpeg *utils.FFmpegHelper) *QCStage {
+	return &QCStage{
+		ffmpeg: ffmpeg,
+	}
+}
+
+// Run analyzes the video in a single decode and returns the QC report
+func (qs *QCStage) Run(ctx context.Context, videoPath string, options models.ProcessingOptions) (*models.QCReport, error) {
+	startTime := time.Now()
+	profile := qc.ResolveProfile(options.QCProfile)
+
+	// Step 1: Probe the streams (the audio filters need an audio stream)
+	probe, err := qs.ffmpeg.GetVideoMetadata(videoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe video: %w", err)
+	}
+	_, hasAudio := probe["audio_codec"]
+	width, _ := probe["width"].(int)
+	height, _ := probe["height"].(int)
+	duration, _ := probe["duration"].(float64)
+
+	// Step 2: Run all QC filters in one pass
+	graph, outputs := qc.FilterGraph(profile, hasAudio)
+	log, err := qs.ffmpeg.RunFilterAnalysis(ctx, videoPath, graph, outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 3: Judge the measurements against the profile
+	report := qc.Evaluate(qc.ParseLog(log), profile, width, height, duration, hasAudio)
+	report.AnalysisTime = time.Since(startTime).Seconds()
+
+	return report, nil
+}
+
+// qcVerdict returns "passed" or "failed" for progress messages
+func qcVerdict(report *models.QCReport) string {
+	if report.Passed {
+		return "passed"
+	}
+	return "failed"
+}
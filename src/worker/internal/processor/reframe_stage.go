@@ -0,0 +1,131 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/highlights"
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+	"github.com/adverant/nexus/videoagent-worker/internal/reframe"
+	"github.com/adverant/nexus/videoagent-worker/internal/utils"
+)
+
+// ReframeStage renders vertical and square versions of landscape video whose crop
+// follows the main subject (tracked or detected boxes), cutting with each shot
+type ReframeStage struct {
+	ffmpeg *utils.FFmpegHelper
+}
+
+// NewReframeStage creates a new reframe stage
+func NewReframeStage(ffmpeg *utils.FFmpegHelper) *ReframeStage {
+	return &ReframeStage{
+		ffmpeg: ffmpeg,
+	}
+}
+
+// Run plans and renders one rendition per requested format into outputDir,
+// writing each crop path as JSON next to its video
+func (rs *ReframeStage) Run(
+	ctx context.Context,
+	job *models.JobPayload,
+	videoPath string,
+	outputDir string,
+	metadata *models.VideoMetadata,
+	frames []models.FrameAnalysis,
+	tracking *models.TrackingAnalysis,
+) ([]models.ReframedVideo, error) {
+	formats := job.Options.GetReframeFormats()
+	if len(formats) == 0 {
+		return nil, nil
+	}
+	if metadata == nil {
+		return nil, fmt.Errorf("video metadata is required for reframing")
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Step 1: Find shot boundaries (the crop cuts with the picture)
+	graph, outputs := highlights.FilterGraph(false)
+	analysis, err := rs.ffmpeg.RunFilterAnalysis(ctx, videoPath, graph, outputs)
+	if err != nil {
+		return nil, err
+	}
+	cuts := highlights.ParseLog(analysis).ShotBoundaries
+
+	// Step 2: Collect subject boxes (tracks are denser than analyzed frames)
+	var observations []reframe.Observation
+	if tracking != nil && len(tracking.Tracks) > 0 {
+		observations = reframe.FromTracks(tracking.Tracks)
+	} else {
+		observations = reframe.FromFrames(frames)
+	}
+
+	planner := reframe.NewPlanner()
+	planner.SetPanSpeed(job.Options.GetReframePanSpeed())
+
+	// Step 3: Plan, export and render each format
+	reframed := make([]models.ReframedVideo, 0, len(formats))
+	for _, format := range formats {
+		plan, err := planner.Plan(format, metadata.Width, metadata.Height, metadata.Duration, cuts, observations)
+		if err != nil {
+			return nil, err
+		}
+
+		base := filepath.Join(outputDir, fmt.Sprintf("%s_reframe_%s", job.JobID, formatSlug(format)))
+		video, err := rs.render(ctx, videoPath, base, plan, metadata.FrameRate)
+		if err != nil {
+			log.Printf("Warning: failed to render %s reframe: %v", format, err)
+			// Non-fatal - continue without this format
+			continue
+		}
+		reframed = append(reframed, *video)
+	}
+
+	return reframed, nil
+}
+
+// render writes the crop path JSON and sendcmd script, then encodes the cropped video
+func (rs *ReframeStage) render(ctx context.Context, videoPath, base string, plan *reframe.Plan, fps float64) (*models.ReframedVideo, error) {
+	pathJSON, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal crop path: %w", err)
+	}
+	video := &models.ReframedVideo{
+		Format:   plan.Format,
+		Path:     base + ".mp4",
+		CropPath: base + ".json",
+		Shots:    len(plan.Shots),
+	}
+	for _, shot := range plan.Shots {
+		if shot.Subject {
+			video.Tracked++
+		}
+	}
+	if err := os.WriteFile(video.CropPath, pathJSON, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write crop path: %w", err)
+	}
+
+	commands := base + ".cmd"
+	if err := os.WriteFile(commands, []byte(reframe.BuildCommands(plan, fps)), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write crop commands: %w", err)
+	}
+	defer os.Remove(commands)
+
+	video.Width, video.Height = socialSize(plan.CropWidth, plan.CropHeight)
+	filterScript := base + ".filter"
+	if err := os.WriteFile(filterScript, []byte(reframe.BuildFilter(plan, commands, video.Width, video.Height)), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write filter script: %w", err)
+	}
+	defer os.Remove(filterScript)
+
+	if err := rs.ffmpeg.RenderAnnotated(ctx, videoPath, filterScript, "", video.Path); err != nil {
+		return nil, err
+	}
+
+	return video, nil
+}
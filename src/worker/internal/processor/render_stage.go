@@ -0,0 +1,148 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+	"github.com/adverant/nexus/videoagent-worker/internal/render"
+	"github.com/adverant/nexus/videoagent-worker/internal/utils"
+)
+
+// ocrDisplayTime is the longest an OCR box stays up after the frame it was read from (seconds)
+const ocrDisplayTime = 2.0
+
+// RenderStage renders an annotated MP4 with track boxes, OCR boxes,
+// subtitles and scene-boundary markers drawn over the source video
+type RenderStage struct {
+	ffmpeg *utils.FFmpegHelper
+}
+
+// NewRenderStage creates a new render stage
+func NewRenderStage(ffmpeg *utils.FFmpegHelper) *RenderStage {
+	return &RenderStage{
+		ffmpeg: ffmpeg,
+	}
+}
+
+// OutputDir returns where a job's rendered outputs are written
+// (the job's output directory when set, otherwise a per-job directory under the temp dir)
+func (rs *RenderStage) OutputDir(job *models.JobPayload) string {
+	if job.OutputDir != "" {
+		return job.OutputDir
+	}
+	return filepath.Join(rs.ffmpeg.TempDir(), "outputs", job.JobID)
+}
+
+// Run renders the annotated video into outputDir
+// Any of frames, audio, trackingAnalysis and scenes may be empty; their overlays are skipped.
+func (rs *RenderStage) Run(
+	ctx context.Context,
+	videoPath string,
+	jobID string,
+	outputDir string,
+	options models.ProcessingOptions,
+	metadata *models.VideoMetadata,
+	frames []models.FrameAnalysis,
+	audio *models.AudioAnalysis,
+	trackingAnalysis *models.TrackingAnalysis,
+	scenes []models.SceneDetection,
+) (*models.AnnotatedVideo, error) {
+	startTime := time.Now()
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Step 1: Collect overlays
+	overlay := render.Overlay{
+		Text:   render.OCRFromFrames(frames, ocrDisplayTime),
+		Scenes: scenes,
+	}
+	if metadata != nil {
+		overlay.Width, overlay.Height, overlay.Duration = metadata.Width, metadata.Height, metadata.Duration
+	}
+	if overlay.Width <= 0 || overlay.Height <= 0 {
+		width, height, err := rs.ffmpeg.GetResolution(videoPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get video resolution: %w", err)
+		}
+		overlay.Width, overlay.Height = width, height
+	}
+	if trackingAnalysis != nil {
+		overlay.Tracks = trackingAnalysis.Tracks
+		if trackingAnalysis.SampleRate > 0 {
+			overlay.TrackInterval = 1 / trackingAnalysis.SampleRate
+		}
+	}
+
+	subtitleMode := options.GetSubtitleMode()
+	if audio != nil && subtitleMode != models.SubtitleModeNone {
+		overlay.Subtitles = audio.Speakers
+	}
+
+	result := &models.AnnotatedVideo{
+		Path:         filepath.Join(outputDir, fmt.Sprintf("%s_annotated.mp4", jobID)),
+		SubtitleMode: subtitleMode,
+		TextBoxes:    len(overlay.Text),
+		SceneMarkers: len(overlay.Scenes),
+	}
+	for _, track := range overlay.Tracks {
+		result.TrackBoxes += len(track.Trajectory)
+	}
+
+	// Step 2: Write the transcript as SRT (kept next to the video, muxed when soft)
+	srt := render.BuildSRT(overlay.Subtitles)
+	if srt != "" {
+		result.SubtitleCues = strings.Count(srt, " --> ")
+		result.SubtitlesPath = filepath.Join(outputDir, fmt.Sprintf("%s_annotated.srt", jobID))
+		if err := os.WriteFile(result.SubtitlesPath, []byte(srt), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write subtitles: %w", err)
+		}
+	}
+
+	// Step 3: Build the filter script (ASS via libass, drawbox chain without it)
+	filterScript := filepath.Join(outputDir, fmt.Sprintf("%s_overlay.filter", jobID))
+	defer os.Remove(filterScript)
+
+	var filter string
+	if rs.ffmpeg.HasFilter("ass") {
+		result.Renderer = "ass"
+		assPath := filepath.Join(outputDir, fmt.Sprintf("%s_overlay.ass", jobID))
+		defer os.Remove(assPath)
+
+		burn := subtitleMode == models.SubtitleModeBurn
+		if err := os.WriteFile(assPath, []byte(render.BuildASS(overlay, burn)), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write ASS script: %w", err)
+		}
+		filter = fmt.Sprintf("ass=filename='%s'", strings.ReplaceAll(assPath, "'", `'\''`))
+	} else {
+		result.Renderer = "drawbox"
+		filter = render.BuildDrawboxScript(overlay)
+		if subtitleMode == models.SubtitleModeBurn {
+			log.Printf("Warning: ffmpeg has no libass, muxing subtitles as a soft track instead of burning them in")
+			result.SubtitleMode = models.SubtitleModeSoft
+		}
+	}
+
+	if err := os.WriteFile(filterScript, []byte(filter), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write filter script: %w", err)
+	}
+
+	// Step 4: Render
+	softSubtitles := ""
+	if result.SubtitleMode == models.SubtitleModeSoft {
+		softSubtitles = result.SubtitlesPath
+	}
+	if err := rs.ffmpeg.RenderAnnotated(ctx, videoPath, filterScript, softSubtitles, result.Path); err != nil {
+		return nil, err
+	}
+
+	result.RenderTime = time.Since(startTime).Seconds()
+	return result, nil
+}
@@ -0,0 +1,83 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// ResultsStream is the Redis stream consumers read processing results from
+const ResultsStream = "videoagent:results"
+
+// ResultsPublisher publishes StreamResults to the Redis results stream
+// Shared by live stream processing and batch jobs so consumers read a single stream.
+type ResultsPublisher struct {
+	redisClient *redis.Client
+}
+
+// SpatialResult is the payload of "spatial" results (zone/line events and occupancy changes)
+type SpatialResult struct {
+	Events    []models.SpatialEvent    `json:"events"`
+	Occupancy []models.OccupancySample `json:"occupancy,omitempty"`
+}
+
+// NewResultsPublisher creates a publisher on the given Redis client
+func NewResultsPublisher(redisClient *redis.Client) *ResultsPublisher {
+	return &ResultsPublisher{
+		redisClient: redisClient,
+	}
+}
+
+// Publish adds a result to the results stream
+func (rp *ResultsPublisher) Publish(ctx context.Context, result StreamResult) error {
+	// Serialize result to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	_, err = rp.redisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: ResultsStream,
+		MaxLen: 10000, // Keep last 10k results
+		Approx: true,  // Approximate trimming for performance
+		Values: map[string]interface{}{
+			"result": string(resultJSON),
+		},
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to publish result: %w", err)
+	}
+
+	return nil
+}
+
+// PublishSpatial publishes spatial events and occupancy changes for a frame
+// streamID is the frame stream key for live streams and the job ID for batch jobs.
+func (rp *ResultsPublisher) PublishSpatial(
+	ctx context.Context,
+	streamID string,
+	clientID string,
+	frameNumber int,
+	events []models.SpatialEvent,
+	occupancy []models.OccupancySample,
+) error {
+	if len(events) == 0 && len(occupancy) == 0 {
+		return nil
+	}
+
+	return rp.Publish(ctx, StreamResult{
+		StreamID:    streamID,
+		ClientID:    clientID,
+		FrameNumber: frameNumber,
+		Timestamp:   time.Now().UnixMilli(),
+		Type:        "spatial",
+		Data: SpatialResult{
+			Events:    events,
+			Occupancy: occupancy,
+		},
+	})
+}
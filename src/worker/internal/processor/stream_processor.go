@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,6 +14,17 @@ import (
 	"github.com/adverant/nexus/videoagent-worker/internal/clients"
 	"github.com/adverant/nexus/videoagent-worker/internal/extractor"
 	"github.com/adverant/nexus/videoagent-worker/internal/models"
+	"github.com/adverant/nexus/videoagent-worker/internal/tracking"
+)
+
+// Spatial rules for a live stream are read from videoagent:rules:<id> for stream
+// videoagent:frames:<id> (JSON models.SpatialRules), refreshed periodically
+const (
+	frameStreamPrefix   = "videoagent:frames:"
+	spatialRulesPrefix  = "videoagent:rules:"
+	spatialRulesRefresh = 30 * time.Second
+	spatialSessionIdle  = 5 * time.Minute // Sessions without frames for this long are closed
+	spatialSessionSweep = time.Minute
 )
 
 // StreamFrame represents an incoming frame from Redis Streams
@@ -36,7 +48,7 @@ type StreamResult struct {
 	ClientID       string      `json:"clientId"`
 	FrameNumber    int         `json:"frameNumber"`
 	Timestamp      int64       `json:"timestamp"`
-	Type           string      `json:"type"` // "vision", "transcription", "classification"
+	Type           string      `json:"type"` // "vision", "transcription", "classification", "spatial"
 	Data           interface{} `json:"data"`
 	ProcessingTime int64       `json:"processingTime"` // Milliseconds
 	Error          string      `json:"error,omitempty"`
@@ -57,6 +69,26 @@ type StreamProcessor struct {
 	maxBatchSize     int
 	maxBatchWait     time.Duration
 	processingWorkers int
+	publisher        *ResultsPublisher
+	detector         tracking.Detector // Detector for spatial analytics (MageAgent vision when nil)
+	spatialSessions  map[string]*spatialSession
+	spatialChecked   map[string]time.Time // Streams without rules: last Redis lookup
+	fixedRules       map[string]string    // Rules set via SetSpatialRules, by stream
+	spatialMutex     sync.Mutex
+}
+
+// spatialSession tracks objects on one stream and evaluates its zones and lines
+type spatialSession struct {
+	mu            sync.Mutex
+	rulesJSON     string // Rules the analyzer was built from ("" = no rules)
+	fixed         bool   // Set via SetSpatialRules (not refreshed from Redis)
+	loadedAt      time.Time
+	tracker       *tracking.MultiObjectTracker
+	analyzer      *tracking.SpatialAnalyzer
+	lastFrameNum  int
+	lastFrameTime time.Time
+	lastSeen      time.Time // Wall clock of the last frame (idle eviction)
+	closed        bool      // Evicted - open visits were published and the tracker dropped
 }
 
 // StreamStats tracks processing statistics
@@ -128,12 +160,44 @@ func NewStreamProcessor(
 		maxBatchSize:      config.MaxBatchSize,
 		maxBatchWait:      config.MaxBatchWait,
 		processingWorkers: config.ProcessingWorkers,
+		publisher:         NewResultsPublisher(redisClient),
+		spatialSessions:   make(map[string]*spatialSession),
+		spatialChecked:    make(map[string]time.Time),
+		fixedRules:        make(map[string]string),
 		stats: StreamStats{
 			LastProcessedAt: time.Now(),
 		},
 	}, nil
 }
 
+// SetDetector sets the object detector used for spatial analytics on streams
+func (sp *StreamProcessor) SetDetector(detector tracking.Detector) {
+	sp.detector = detector
+}
+
+// SetSpatialRules sets zones and lines for a stream, overriding rules stored in Redis
+// Any open zone visits under previous rules are closed and published.
+func (sp *StreamProcessor) SetSpatialRules(ctx context.Context, streamKey string, rules models.SpatialRules) error {
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return fmt.Errorf("failed to marshal spatial rules: %w", err)
+	}
+
+	sp.spatialMutex.Lock()
+	sp.fixedRules[streamKey] = string(rulesJSON)
+	sp.spatialMutex.Unlock()
+
+	session := sp.getSpatialSession(streamKey)
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if err := sp.applySpatialRules(ctx, streamKey, session, string(rulesJSON)); err != nil {
+		return err
+	}
+	session.fixed = true
+	return nil
+}
+
 // Start begins consuming frames from Redis Streams
 func (sp *StreamProcessor) Start(ctx context.Context) error {
 	if sp.isRunning {
@@ -159,6 +223,10 @@ func (sp *StreamProcessor) Start(ctx context.Context) error {
 	sp.wg.Add(1)
 	go sp.statsLogger(ctx)
 
+	// Close spatial sessions of streams that stop sending frames
+	sp.wg.Add(1)
+	go sp.spatialJanitor(ctx)
+
 	log.Println("StreamProcessor: Started successfully")
 	return nil
 }
@@ -176,6 +244,11 @@ func (sp *StreamProcessor) Stop() error {
 	// Wait for all workers to finish
 	sp.wg.Wait()
 
+	// Publish open zone visits and dwell before the connection goes away
+	closeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	sp.closeSpatialSessions(closeCtx, func(*spatialSession) bool { return true })
+	cancel()
+
 	// Close Redis connection
 	if err := sp.redisClient.Close(); err != nil {
 		log.Printf("StreamProcessor: Error closing Redis connection: %v", err)
@@ -281,7 +354,7 @@ func (sp *StreamProcessor) consumeFrames(ctx context.Context, workerID int) erro
 func (sp *StreamProcessor) getFrameStreams(ctx context.Context) ([]string, error) {
 	// Use SCAN to find all keys matching pattern
 	var streams []string
-	iter := sp.redisClient.Scan(ctx, 0, "videoagent:frames:*", 100).Iterator()
+	iter := sp.redisClient.Scan(ctx, 0, frameStreamPrefix+"*", 100).Iterator()
 
 	for iter.Next(ctx) {
 		streams = append(streams, iter.Val())
@@ -340,6 +413,11 @@ func (sp *StreamProcessor) processMessage(ctx context.Context, streamKey string,
 		return fmt.Errorf("failed to publish result: %w", err)
 	}
 
+	// Zone and line analytics (only for streams with rules)
+	if err := sp.processSpatial(ctx, streamKey, frame); err != nil {
+		log.Printf("StreamProcessor: Spatial analytics failed for %s: %v", streamKey, err)
+	}
+
 	// Acknowledge message
 	if err := sp.redisClient.XAck(ctx, streamKey, sp.consumerGroup, message.ID).Err(); err != nil {
 		log.Printf("StreamProcessor: Failed to ACK message %s: %v", message.ID, err)
@@ -445,26 +523,264 @@ func (sp *StreamProcessor) processFrame(ctx context.Context, frame *StreamFrame)
 	return result, nil
 }
 
-// publishResult publishes processing result to Redis Streams
-func (sp *StreamProcessor) publishResult(ctx context.Context, result StreamResult) error {
-	// Serialize result to JSON
-	resultJSON, err := json.Marshal(result)
+// processSpatial tracks objects in a stream frame and publishes zone/line events
+func (sp *StreamProcessor) processSpatial(ctx context.Context, streamKey string, frame *StreamFrame) error {
+	session, err := sp.activeSpatialSession(ctx, streamKey)
+	if err != nil || session == nil {
+		return err
+	}
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.closed {
+		return nil
+	}
+
+	// Step 1: Pick up rule changes from Redis
+	if !session.fixed && time.Since(session.loadedAt) > spatialRulesRefresh {
+		rulesJSON, err := sp.redisClient.Get(ctx, spatialRulesKey(streamKey)).Result()
+		if err != nil && err != redis.Nil {
+			return fmt.Errorf("failed to load spatial rules: %w", err)
+		}
+		if err := sp.applySpatialRules(ctx, streamKey, session, rulesJSON); err != nil {
+			return err
+		}
+	}
+	if session.analyzer == nil {
+		// Rules were removed - drop the session until they come back
+		sp.removeSpatialSession(streamKey, session)
+		return nil
+	}
+
+	// Step 2: Track objects (frames handled out of order by other workers are skipped)
+	frameTime := time.Now()
+	if frame.Timestamp > 0 {
+		frameTime = time.UnixMilli(frame.Timestamp)
+	}
+	if !frameTime.After(session.lastFrameTime) {
+		return nil
+	}
+
+	result, err := session.tracker.TrackAt(ctx, frame.FrameData, frameTime)
 	if err != nil {
-		return fmt.Errorf("failed to marshal result: %w", err)
+		return fmt.Errorf("tracking failed: %w", err)
 	}
+	session.lastFrameNum = frame.FrameNumber
+	session.lastFrameTime = frameTime
 
-	// Publish to videoagent:results stream
-	_, err = sp.redisClient.XAdd(ctx, &redis.XAddArgs{
-		Stream: "videoagent:results",
-		MaxLen: 10000, // Keep last 10k results
-		Approx: true,  // Approximate trimming for performance
-		Values: map[string]interface{}{
-			"result": string(resultJSON),
-		},
-	}).Result()
+	// Step 3: Evaluate rules and publish
+	events, occupancy := session.analyzer.Update(result.TrackedObjects, frame.FrameNumber, frameTime)
+	for i := range events {
+		events[i].FrameNumber = frame.FrameNumber // Tracker frame numbers are internal counters
+	}
+	return sp.publishSpatial(ctx, streamKey, frame.ClientID, frame.FrameNumber, events, occupancy)
+}
+
+// applySpatialRules rebuilds a session's analyzer when its rules changed
+// Open zone visits under the previous rules are closed and published first.
+func (sp *StreamProcessor) applySpatialRules(ctx context.Context, streamKey string, session *spatialSession, rulesJSON string) error {
+	session.loadedAt = time.Now()
+	if rulesJSON == session.rulesJSON {
+		return nil
+	}
+
+	sp.finishSpatial(ctx, streamKey, session)
+	session.rulesJSON = rulesJSON
+	if rulesJSON == "" {
+		session.tracker = nil
+		return nil
+	}
 
+	var rules models.SpatialRules
+	if err := json.Unmarshal([]byte(rulesJSON), &rules); err != nil {
+		return fmt.Errorf("invalid spatial rules for %s: %w", streamKey, err)
+	}
+	analyzer, err := tracking.NewSpatialAnalyzer(rules, time.Unix(0, 0).UTC())
 	if err != nil {
-		return fmt.Errorf("failed to publish result: %w", err)
+		return fmt.Errorf("invalid spatial rules for %s: %w", streamKey, err)
+	}
+	session.analyzer = analyzer
+
+	if session.tracker == nil {
+		detector := sp.detector
+		if detector == nil {
+			detector = tracking.NewMageAgentDetector(sp.mageAgentClient)
+		}
+		session.tracker = tracking.NewMultiObjectTrackerWithDetector(tracking.TrackerByteTrack, detector)
+	}
+
+	log.Printf("StreamProcessor: Spatial rules active for %s (%d zones, %d lines)",
+		streamKey, len(rules.Zones), len(rules.Lines))
+	return nil
+}
+
+// finishSpatial closes open zone visits under a session's current rules and publishes them
+func (sp *StreamProcessor) finishSpatial(ctx context.Context, streamKey string, session *spatialSession) {
+	if session.analyzer == nil {
+		return
+	}
+	events, occupancy := session.analyzer.Finish(session.lastFrameNum, session.lastFrameTime)
+	if err := sp.publishSpatial(ctx, streamKey, "", session.lastFrameNum, events, occupancy); err != nil {
+		log.Printf("StreamProcessor: Failed to publish closing spatial events: %v", err)
+	}
+	session.analyzer = nil
+}
+
+// activeSpatialSession returns the spatial session of a stream, creating it once the stream has rules
+// Streams without a session are checked for rules in Redis at most every spatialRulesRefresh.
+func (sp *StreamProcessor) activeSpatialSession(ctx context.Context, streamKey string) (*spatialSession, error) {
+	sp.spatialMutex.Lock()
+	if session, exists := sp.spatialSessions[streamKey]; exists {
+		session.lastSeen = time.Now()
+		sp.spatialMutex.Unlock()
+		return session, nil
+	}
+	rulesJSON, fixed := sp.fixedRules[streamKey]
+	if !fixed && time.Since(sp.spatialChecked[streamKey]) <= spatialRulesRefresh {
+		sp.spatialMutex.Unlock()
+		return nil, nil
+	}
+	sp.spatialChecked[streamKey] = time.Now()
+	sp.spatialMutex.Unlock()
+
+	if !fixed {
+		var err error
+		rulesJSON, err = sp.redisClient.Get(ctx, spatialRulesKey(streamKey)).Result()
+		if err != nil && err != redis.Nil {
+			return nil, fmt.Errorf("failed to load spatial rules: %w", err)
+		}
+		if rulesJSON == "" {
+			return nil, nil
+		}
+	}
+
+	session := sp.getSpatialSession(streamKey)
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	// Another worker may have set the session up meanwhile
+	if session.analyzer == nil && !session.closed {
+		if err := sp.applySpatialRules(ctx, streamKey, session, rulesJSON); err != nil {
+			sp.removeSpatialSession(streamKey, session)
+			return nil, err
+		}
+		session.fixed = fixed
+	}
+	return session, nil
+}
+
+// getSpatialSession returns the spatial session of a stream, creating it on first use
+func (sp *StreamProcessor) getSpatialSession(streamKey string) *spatialSession {
+	sp.spatialMutex.Lock()
+	defer sp.spatialMutex.Unlock()
+
+	session, exists := sp.spatialSessions[streamKey]
+	if !exists {
+		session = &spatialSession{}
+		sp.spatialSessions[streamKey] = session
+		delete(sp.spatialChecked, streamKey)
+	}
+	session.lastSeen = time.Now()
+	return session
+}
+
+// removeSpatialSession drops a session that has no rules (the caller holds session.mu)
+func (sp *StreamProcessor) removeSpatialSession(streamKey string, session *spatialSession) {
+	session.closed = true
+	session.tracker = nil
+
+	sp.spatialMutex.Lock()
+	defer sp.spatialMutex.Unlock()
+	if sp.spatialSessions[streamKey] == session {
+		delete(sp.spatialSessions, streamKey)
+	}
+	sp.spatialChecked[streamKey] = time.Now()
+}
+
+// closeSpatialSessions removes the sessions selected by evict, publishing their open
+// zone visits and dwell before the tracker and analyzer are dropped
+func (sp *StreamProcessor) closeSpatialSessions(ctx context.Context, evict func(*spatialSession) bool) int {
+	sp.spatialMutex.Lock()
+	closing := make(map[string]*spatialSession)
+	for streamKey, session := range sp.spatialSessions {
+		if evict(session) {
+			closing[streamKey] = session
+			delete(sp.spatialSessions, streamKey)
+		}
+	}
+	sp.spatialMutex.Unlock()
+
+	for streamKey, session := range closing {
+		session.mu.Lock()
+		sp.finishSpatial(ctx, streamKey, session)
+		session.tracker = nil
+		session.closed = true
+		session.mu.Unlock()
+	}
+	return len(closing)
+}
+
+// evictIdleSpatialSessions closes sessions of streams without frames for spatialSessionIdle
+func (sp *StreamProcessor) evictIdleSpatialSessions(ctx context.Context, now time.Time) {
+	closed := sp.closeSpatialSessions(ctx, func(session *spatialSession) bool {
+		return now.Sub(session.lastSeen) > spatialSessionIdle
+	})
+
+	sp.spatialMutex.Lock()
+	for streamKey, checkedAt := range sp.spatialChecked {
+		if now.Sub(checkedAt) > spatialSessionIdle {
+			delete(sp.spatialChecked, streamKey)
+		}
+	}
+	sp.spatialMutex.Unlock()
+
+	if closed > 0 {
+		log.Printf("StreamProcessor: Closed %d idle spatial sessions", closed)
+	}
+}
+
+// spatialJanitor periodically closes spatial sessions of streams that stopped sending frames
+func (sp *StreamProcessor) spatialJanitor(ctx context.Context) {
+	defer sp.wg.Done()
+
+	ticker := time.NewTicker(spatialSessionSweep)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sp.stopChan:
+			return
+		case now := <-ticker.C:
+			sp.evictIdleSpatialSessions(ctx, now)
+		}
+	}
+}
+
+// spatialRulesKey returns the Redis key holding a stream's spatial rules
+func spatialRulesKey(streamKey string) string {
+	return spatialRulesPrefix + strings.TrimPrefix(streamKey, frameStreamPrefix)
+}
+
+// publishSpatial publishes spatial events and counts them as results
+func (sp *StreamProcessor) publishSpatial(ctx context.Context, streamKey, clientID string, frameNumber int, events []models.SpatialEvent, occupancy []models.OccupancySample) error {
+	if len(events) == 0 && len(occupancy) == 0 {
+		return nil
+	}
+	if err := sp.publisher.PublishSpatial(ctx, streamKey, clientID, frameNumber, events, occupancy); err != nil {
+		return err
+	}
+
+	sp.statsMutex.Lock()
+	sp.stats.ResultsPublished++
+	sp.statsMutex.Unlock()
+	return nil
+}
+
+// publishResult publishes processing result to Redis Streams
+func (sp *StreamProcessor) publishResult(ctx context.Context, result StreamResult) error {
+	if err := sp.publisher.Publish(ctx, result); err != nil {
+		return err
 	}
 
 	sp.statsMutex.Lock()
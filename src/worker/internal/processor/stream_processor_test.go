@@ -0,0 +1,102 @@
+package processor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// newOfflineStreamProcessor returns a stream processor whose Redis is unreachable,
+// so rule lookups and publishes fail fast
+func newOfflineStreamProcessor(t *testing.T) *StreamProcessor {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	t.Cleanup(func() { client.Close() })
+	return &StreamProcessor{
+		redisClient:     client,
+		publisher:       NewResultsPublisher(client),
+		spatialSessions: make(map[string]*spatialSession),
+		spatialChecked:  make(map[string]time.Time),
+		fixedRules:      make(map[string]string),
+	}
+}
+
+func doorwayRules() models.SpatialRules {
+	return models.SpatialRules{
+		Zones: []models.Zone{{
+			ZoneID:  "doorway",
+			Polygon: []models.SpatialPoint{{X: 0, Y: 0}, {X: 0.5, Y: 0}, {X: 0.5, Y: 1}, {X: 0, Y: 1}},
+		}},
+	}
+}
+
+func TestStreamWithoutRulesHasNoSpatialSession(t *testing.T) {
+	sp := newOfflineStreamProcessor(t)
+	ctx := context.Background()
+	streamKey := frameStreamPrefix + "cam1"
+
+	// The rule lookup fails, so no session is created
+	if session, err := sp.activeSpatialSession(ctx, streamKey); err == nil || session != nil {
+		t.Fatalf("activeSpatialSession = %v, %v; want the Redis error and no session", session, err)
+	}
+	if len(sp.spatialSessions) != 0 {
+		t.Fatalf("%d sessions created for a stream without rules", len(sp.spatialSessions))
+	}
+
+	// Redis isn't asked again until the refresh interval passes
+	if session, err := sp.activeSpatialSession(ctx, streamKey); err != nil || session != nil {
+		t.Errorf("second lookup = %v, %v; want no session and no Redis call", session, err)
+	}
+
+	// Stale lookups are forgotten with idle sessions
+	sp.evictIdleSpatialSessions(ctx, time.Now().Add(spatialSessionIdle+time.Second))
+	if len(sp.spatialChecked) != 0 {
+		t.Errorf("rule lookups kept for %d idle streams", len(sp.spatialChecked))
+	}
+}
+
+func TestIdleSpatialSessionsAreClosed(t *testing.T) {
+	sp := newOfflineStreamProcessor(t)
+	ctx := context.Background()
+	streamKey := frameStreamPrefix + "cam1"
+
+	if err := sp.SetSpatialRules(ctx, streamKey, doorwayRules()); err != nil {
+		t.Fatalf("SetSpatialRules: %v", err)
+	}
+	session := sp.spatialSessions[streamKey]
+	if session == nil || session.analyzer == nil || session.tracker == nil {
+		t.Fatal("SetSpatialRules did not start a session")
+	}
+
+	sp.evictIdleSpatialSessions(ctx, time.Now())
+	if sp.spatialSessions[streamKey] != session {
+		t.Fatal("active session evicted")
+	}
+
+	sp.evictIdleSpatialSessions(ctx, time.Now().Add(spatialSessionIdle+time.Second))
+	if _, exists := sp.spatialSessions[streamKey]; exists {
+		t.Fatal("idle session not evicted")
+	}
+	if !session.closed || session.analyzer != nil || session.tracker != nil {
+		t.Errorf("evicted session still holds its tracker or analyzer")
+	}
+
+	// Fixed rules outlive the session and start a new one when frames resume
+	resumed, err := sp.activeSpatialSession(ctx, streamKey)
+	if err != nil || resumed == nil || resumed == session {
+		t.Fatalf("activeSpatialSession = %v, %v; want a new session", resumed, err)
+	}
+	if !resumed.fixed || resumed.analyzer == nil {
+		t.Errorf("resumed session not built from the fixed rules")
+	}
+
+	// Stop closes every session
+	sp.closeSpatialSessions(ctx, func(*spatialSession) bool { return true })
+	if len(sp.spatialSessions) != 0 || !resumed.closed {
+		t.Errorf("%d sessions left open", len(sp.spatialSessions))
+	}
+}
@@ -0,0 +1,237 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/imagestats"
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+	"github.com/adverant/nexus/videoagent-worker/internal/thumbnail"
+	"github.com/adverant/nexus/videoagent-worker/internal/utils"
+)
+
+// Scrubbing sprites: small tiles laid out 10 x 10 per sheet
+const (
+	spriteTileWidth = 160
+	spriteColumns   = 10
+	spriteRows      = 10
+)
+
+// ThumbnailStage scores analyzed frames for quality, picks the best keyframe per
+// scene and per video, and renders poster thumbnails and scrubbing sprite sheets
+type ThumbnailStage struct {
+	ffmpeg *utils.FFmpegHelper
+	scorer *thumbnail.Scorer
+}
+
+// NewThumbnailStage creates a new thumbnail stage
+func NewThumbnailStage(ffmpeg *utils.FFmpegHelper) *ThumbnailStage {
+	return &ThumbnailStage{
+		ffmpeg: ffmpeg,
+		scorer: thumbnail.NewScorer(),
+	}
+}
+
+// ScoreFrames scores every frame; the result is index-aligned with frames
+// Frames whose image can't be read get a zero score.
+func (ts *ThumbnailStage) ScoreFrames(ctx context.Context, videoPath string, jobID string, frames []models.FrameAnalysis) []models.FrameQuality {
+	outputDir := filepath.Join(filepath.Dir(videoPath), fmt.Sprintf("%s_quality_frames", jobID))
+	defer os.RemoveAll(outputDir)
+
+	qualities := make([]models.FrameQuality, len(frames))
+	for i, frame := range frames {
+		if ctx.Err() != nil {
+			qualities[i] = models.FrameQuality{FrameID: frame.FrameID, Timestamp: frame.Timestamp}
+			continue
+		}
+
+		stats, err := ts.frameStats(videoPath, outputDir, frame)
+		if err != nil {
+			log.Printf("Warning: failed to measure frame at %.2fs: %v", frame.Timestamp, err)
+		}
+		qualities[i] = ts.scorer.Score(frame, stats)
+	}
+
+	return qualities
+}
+
+// SelectSceneKeyframes points each scene's KeyFrameID at its best-scoring frame
+// Scenes whose frames are all blank keep their keyframe.
+func SelectSceneKeyframes(scenes []models.SceneDetection, qualities []models.FrameQuality) []models.SceneDetection {
+	selected := make([]models.SceneDetection, len(scenes))
+	copy(selected, scenes)
+
+	for i := range selected {
+		if best := thumbnail.Best(qualities, selected[i].StartFrame, selected[i].EndFrame); best >= 0 {
+			selected[i].KeyFrameID = qualities[best].FrameID
+		}
+	}
+	return selected
+}
+
+// Run renders the video poster, one poster per scene and the scrubbing sprites into outputDir
+// qualities come from ScoreFrames and may be empty, in which case only sprites are produced.
+func (ts *ThumbnailStage) Run(
+	ctx context.Context,
+	videoPath string,
+	jobID string,
+	outputDir string,
+	options models.ProcessingOptions,
+	metadata *models.VideoMetadata,
+	qualities []models.FrameQuality,
+	scenes []models.SceneDetection,
+) (*models.ThumbnailSet, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	width, height, duration := 0, 0, 0.0
+	if metadata != nil {
+		width, height, duration = metadata.Width, metadata.Height, metadata.Duration
+	}
+	if width <= 0 || height <= 0 {
+		w, h, err := ts.ffmpeg.GetResolution(videoPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get video resolution: %w", err)
+		}
+		width, height = w, h
+	}
+	if duration <= 0 {
+		d, err := ts.ffmpeg.GetVideoDuration(videoPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get video duration: %w", err)
+		}
+		duration = d
+	}
+
+	result := &models.ThumbnailSet{
+		Scenes:       []models.PosterFrame{},
+		SpriteSheets: []string{},
+		FrameQuality: qualities,
+	}
+	sizes := options.GetThumbnailSizes()
+
+	// Step 1: Video poster
+	if best := thumbnail.Best(qualities, 0, len(qualities)-1); best >= 0 {
+		poster, err := ts.renderPoster(ctx, videoPath, qualities[best], sizes, width, height,
+			filepath.Join(outputDir, fmt.Sprintf("%s_poster", jobID)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to render poster: %w", err)
+		}
+		result.Poster = poster
+	}
+
+	// Step 2: Scene posters
+	for i, s := range scenes {
+		best := thumbnail.Best(qualities, s.StartFrame, s.EndFrame)
+		if best < 0 {
+			continue
+		}
+		poster, err := ts.renderPoster(ctx, videoPath, qualities[best], sizes, width, height,
+			filepath.Join(outputDir, fmt.Sprintf("%s_scene_%03d", jobID, i)))
+		if err != nil {
+			log.Printf("Warning: failed to render poster for scene %s: %v", s.SceneID, err)
+			continue
+		}
+		poster.SceneID = s.SceneID
+		result.Scenes = append(result.Scenes, *poster)
+	}
+
+	// Step 3: Scrubbing sprites and their WebVTT track
+	grid := thumbnail.SpriteGrid{
+		Interval:   options.GetSpriteInterval(),
+		TileWidth:  spriteTileWidth,
+		TileHeight: scaledHeight(spriteTileWidth, width, height),
+		Columns:    spriteColumns,
+		Rows:       spriteRows,
+	}
+	result.SpriteInterval = grid.Interval
+	result.TileWidth, result.TileHeight = grid.TileWidth, grid.TileHeight
+	result.TileColumns, result.TileRows = grid.Columns, grid.Rows
+
+	sheets, err := ts.ffmpeg.GenerateSpriteSheets(ctx, videoPath, grid.Interval, grid.TileWidth, grid.TileHeight,
+		grid.Columns, grid.Rows, filepath.Join(outputDir, fmt.Sprintf("%s_sprite_%%03d.jpg", jobID)))
+	if err != nil {
+		// Non-fatal - keep the posters
+		log.Printf("Warning: sprite sheet generation failed: %v", err)
+		return result, nil
+	}
+	result.SpriteSheets = sheets
+
+	// Sheets are referenced relative to the VTT file, which sits next to them
+	names := make([]string, len(sheets))
+	for i, sheet := range sheets {
+		names[i] = filepath.Base(sheet)
+	}
+	result.SpriteVTTPath = filepath.Join(outputDir, fmt.Sprintf("%s_sprite.vtt", jobID))
+	if err := os.WriteFile(result.SpriteVTTPath, []byte(thumbnail.BuildSpriteVTT(grid, names, duration)), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write sprite track: %w", err)
+	}
+
+	return result, nil
+}
+
+// renderPoster extracts a frame at every thumbnail size as <pathPrefix>_<width>.jpg
+func (ts *ThumbnailStage) renderPoster(ctx context.Context, videoPath string, quality models.FrameQuality, sizes []int, width, height int, pathPrefix string) (*models.PosterFrame, error) {
+	poster := &models.PosterFrame{
+		FrameID:    quality.FrameID,
+		Timestamp:  quality.Timestamp,
+		Score:      quality.Score,
+		Thumbnails: make([]models.Thumbnail, 0, len(sizes)),
+	}
+
+	rendered := make(map[int]bool)
+	for _, size := range sizes {
+		// Never upscale past the source
+		if size > width {
+			size = width
+		}
+		if rendered[size] {
+			continue
+		}
+		rendered[size] = true
+
+		path := fmt.Sprintf("%s_%d.jpg", pathPrefix, size)
+		if err := ts.ffmpeg.ExtractThumbnail(ctx, videoPath, quality.Timestamp, size, path); err != nil {
+			return nil, err
+		}
+		poster.Thumbnails = append(poster.Thumbnails, models.Thumbnail{
+			Width:  size,
+			Height: scaledHeight(size, width, height),
+			Path:   path,
+		})
+	}
+
+	return poster, nil
+}
+
+// frameStats measures a frame's image, re-extracting it when the file is gone
+func (ts *ThumbnailStage) frameStats(videoPath, outputDir string, frame models.FrameAnalysis) (*imagestats.Stats, error) {
+	if frame.FilePath != "" {
+		if _, err := os.Stat(frame.FilePath); err == nil {
+			return imagestats.AnalyzeFile(frame.FilePath)
+		}
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create frame directory: %w", err)
+	}
+	framePath := filepath.Join(outputDir, fmt.Sprintf("frame_%d.jpg", frame.FrameNumber))
+	if err := ts.ffmpeg.ExtractFrame(videoPath, frame.Timestamp, framePath); err != nil {
+		return nil, err
+	}
+	defer os.Remove(framePath)
+	return imagestats.AnalyzeFile(framePath)
+}
+
+// scaledHeight returns the even height of a width-wide scale of a width x height source
+// (matching ffmpeg's scale=W:-2)
+func scaledHeight(scaledWidth, width, height int) int {
+	if width <= 0 {
+		return 0
+	}
+	return (scaledWidth*height/width + 1) / 2 * 2
+}
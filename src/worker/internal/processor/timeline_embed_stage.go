@@ -0,0 +1,72 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// TextEmbedder embeds texts as search documents (clients.GraphRAGClient satisfies it)
+// Must be the model moment retrieval embeds queries with.
+type TextEmbedder interface {
+	GenerateEmbeddingBatch(ctx context.Context, texts []string, inputType string) ([][]float64, error)
+}
+
+// TimelineEmbedStage embeds frame descriptions and transcript segments at ingest
+// so moment retrieval only has to embed the query
+type TimelineEmbedStage struct {
+	embedder TextEmbedder
+}
+
+// NewTimelineEmbedStage creates a new timeline embedding stage
+func NewTimelineEmbedStage(embedder TextEmbedder) *TimelineEmbedStage {
+	return &TimelineEmbedStage{
+		embedder: embedder,
+	}
+}
+
+// Run embeds every described frame and non-empty transcript segment
+func (ts *TimelineEmbedStage) Run(ctx context.Context, frames []models.FrameAnalysis, audio *models.AudioAnalysis) ([]models.TimelineEmbedding, error) {
+	kinds := make([]string, 0)
+	keys := make([]string, 0)
+	texts := make([]string, 0)
+
+	for _, frame := range frames {
+		if strings.TrimSpace(frame.Description) == "" {
+			continue
+		}
+		kinds = append(kinds, models.EmbeddingKindFrame)
+		keys = append(keys, frame.FrameID)
+		texts = append(texts, frame.Description)
+	}
+	if audio != nil {
+		for _, segment := range audio.Speakers {
+			if strings.TrimSpace(segment.Text) == "" || segment.EndTime <= segment.StartTime {
+				continue
+			}
+			kinds = append(kinds, models.EmbeddingKindSegment)
+			keys = append(keys, models.SegmentKey(segment))
+			texts = append(texts, segment.Text)
+		}
+	}
+
+	if len(texts) == 0 {
+		return []models.TimelineEmbedding{}, nil
+	}
+
+	vectors, err := ts.embedder.GenerateEmbeddingBatch(ctx, texts, "document")
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed timeline: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("timeline embedding count mismatch: got %d, expected %d", len(vectors), len(texts))
+	}
+
+	embeddings := make([]models.TimelineEmbedding, len(texts))
+	for i := range texts {
+		embeddings[i] = models.TimelineEmbedding{Kind: kinds[i], Key: keys[i], Embedding: vectors[i]}
+	}
+	return embeddings, nil
+}
@@ -0,0 +1,662 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/jpeg" // Decode extracted frames
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/clients"
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+	"github.com/adverant/nexus/videoagent-worker/internal/tracking"
+	"github.com/adverant/nexus/videoagent-worker/internal/utils"
+)
+
+// TrackingStage runs multi-object tracking, person re-ID, trajectory and interaction analysis
+// over densely sampled frames of a video
+type TrackingStage struct {
+	ffmpeg    *utils.FFmpegHelper
+	mageAgent *clients.MageAgentClient
+	detector  tracking.Detector           // Optional; MageAgent vision detection when nil
+	gallery   *tracking.PersonGallery     // Optional; links identities across the tenant's videos
+	embedder  tracking.AppearanceEmbedder // Optional; colour histograms when nil
+	publisher *ResultsPublisher           // Optional; spatial events are streamed while tracking
+}
+
+// trackRecord accumulates every observation of a track
+// (the tracker itself only keeps a bounded trajectory and drops lost tracks)
+type trackRecord struct {
+	trackID       string
+	class         tracking.ObjectClass
+	points        []models.TrackPoint
+	trajectory    []tracking.TrajectoryPoint
+	confidenceSum float64
+	bestPoint     models.TrackPoint
+	bestFramePath string
+	identityID    string
+}
+
+// NewTrackingStage creates a new tracking stage
+func NewTrackingStage(ffmpeg *utils.FFmpegHelper, mageAgent *clients.MageAgentClient) *TrackingStage {
+	return &TrackingStage{
+		ffmpeg:    ffmpeg,
+		mageAgent: mageAgent,
+	}
+}
+
+// SetDetector replaces MageAgent vision prompts with a dedicated object detector
+func (ts *TrackingStage) SetDetector(detector tracking.Detector) {
+	ts.detector = detector
+}
+
+// SetPersonGallery enables cross-video re-identification against the tenant's gallery
+func (ts *TrackingStage) SetPersonGallery(gallery *tracking.PersonGallery) {
+	ts.gallery = gallery
+}
+
+// SetAppearanceEmbedder sets the re-ID embedding model used on person crops
+func (ts *TrackingStage) SetAppearanceEmbedder(embedder tracking.AppearanceEmbedder) {
+	ts.embedder = embedder
+}
+
+// SetResultsPublisher streams spatial events to the Redis results stream as they occur
+func (ts *TrackingStage) SetResultsPublisher(publisher *ResultsPublisher) {
+	ts.publisher = publisher
+}
+
+// Run tracks objects across the video and returns tracks, identities and interactions
+func (ts *TrackingStage) Run(
+	ctx context.Context,
+	videoPath string,
+	jobID string,
+	tenantID string,
+	options models.ProcessingOptions,
+	duration float64,
+) (*models.TrackingAnalysis, error) {
+	startTime := time.Now()
+
+	// Step 1: Sample frames densely (tracking needs small inter-frame motion)
+	maxFrames := options.GetMaxTrackingFrames()
+	sampleRate := trackingSampleRate(options, maxFrames, duration)
+	outputDir := filepath.Join(filepath.Dir(videoPath), fmt.Sprintf("%s_tracking_frames", jobID))
+	defer os.RemoveAll(outputDir)
+
+	framePaths, err := ts.ffmpeg.ExtractFrames(videoPath, "uniform", sampleRate, maxFrames, duration, outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("tracking frame extraction failed: %w", err)
+	}
+	sortFramePaths(framePaths)
+
+	// The frame budget may still end tracking before the video does
+	trackedDuration := float64(len(framePaths)) / float64(sampleRate)
+	truncated := duration > 0 && len(framePaths) >= maxFrames && trackedDuration < duration-1.0/float64(sampleRate)
+	if truncated {
+		log.Printf("Warning: tracking covers only the first %.1fs of %.1fs (%d frames at %dfps); raise maxTrackingFrames to track the rest",
+			trackedDuration, duration, maxFrames, sampleRate)
+	} else if duration > 0 {
+		trackedDuration = duration
+	}
+
+	trackerType := tracking.TrackerType(options.GetTrackerType())
+	switch trackerType {
+	case tracking.TrackerByteTrack, tracking.TrackerDeepSORT, tracking.TrackerSimpleIOU:
+	default:
+		return nil, fmt.Errorf("unknown tracker type: %s", trackerType)
+	}
+
+	detector := ts.detector
+	if detector == nil {
+		detector = tracking.NewMageAgentDetector(ts.mageAgent)
+	}
+	tracker := tracking.NewMultiObjectTrackerWithDetector(trackerType, detector)
+	tracker.SetFrameRate(float64(sampleRate))
+	interactionDetector := tracking.NewInteractionDetector(ts.mageAgent)
+	interactionDetector.SetHistoryLimit(0) // Every completed interaction is persisted
+	applyInteractionThresholds(interactionDetector, options.InteractionThresholds)
+
+	// Trajectory timestamps are video time, expressed as offsets from the Unix epoch
+	videoEpoch := time.Unix(0, 0).UTC()
+
+	// Zones and lines (optional)
+	var spatialAnalyzer *tracking.SpatialAnalyzer
+	spatialEvents := make([]models.SpatialEvent, 0)
+	if options.SpatialRules != nil {
+		spatialAnalyzer, err = tracking.NewSpatialAnalyzer(*options.SpatialRules, videoEpoch)
+		if err != nil {
+			log.Printf("Warning: invalid spatial rules, skipping zone analytics: %v", err)
+			spatialAnalyzer = nil
+		}
+	}
+	var lastFrameNum int
+	var lastFrameTime time.Time
+
+	// Frame with the most visible tracks (background for visual summaries)
+	busiestFramePath := ""
+	busiestFrameCount := -1
+
+	records := make(map[string]*trackRecord)
+	recordOrder := make([]string, 0)
+	trackedFramePaths := make(map[int]string) // Tracker frame number -> frame file
+	observe := func(trackID string, class tracking.ObjectClass, box tracking.BoundingBox, confidence float64, frameNum int, frameTime time.Time, framePath string) {
+		record, exists := records[trackID]
+		if !exists {
+			record = &trackRecord{trackID: trackID, class: class}
+			records[trackID] = record
+			recordOrder = append(recordOrder, trackID)
+		}
+
+		point := models.TrackPoint{
+			Timestamp:   frameTime.Sub(videoEpoch).Seconds(),
+			FrameNumber: frameNum,
+			BoundingBox: models.BoundingBox{
+				X:      box.X,
+				Y:      box.Y,
+				Width:  box.Width,
+				Height: box.Height,
+			},
+			Confidence: confidence,
+		}
+		record.points = append(record.points, point)
+		record.trajectory = append(record.trajectory, tracking.TrajectoryPoint{
+			X:         box.X + box.Width/2,
+			Y:         box.Y + box.Height/2,
+			Timestamp: frameTime,
+			FrameNum:  frameNum,
+		})
+		record.confidenceSum += confidence
+		if confidence > record.bestPoint.Confidence || record.bestFramePath == "" {
+			record.bestPoint = point
+			record.bestFramePath = framePath
+		}
+	}
+	interactionEvents := make([]models.InteractionEventRecord, 0)
+	interactionSignificance := make(map[string]float64)
+	framesProcessed := 0
+
+	// Step 2: Track frame by frame
+	for i, framePath := range framePaths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		timestamp := float64(i) / float64(sampleRate)
+
+		frameData, err := ts.ffmpeg.EncodeFrameToBase64(framePath)
+		if err != nil {
+			log.Printf("Warning: failed to encode tracking frame %s: %v", framePath, err)
+			continue
+		}
+
+		frameTime := videoEpoch.Add(time.Duration(timestamp * float64(time.Second)))
+		result, err := tracker.TrackAt(ctx, frameData, frameTime)
+		if err != nil {
+			log.Printf("Warning: tracking failed at %.2fs: %v", timestamp, err)
+			continue
+		}
+		framesProcessed++
+		lastFrameNum = result.FrameNum
+		lastFrameTime = frameTime
+		trackedFramePaths[result.FrameNum] = framePath
+
+		// Tracks confirmed in this frame bring the observations made while tentative
+		for _, obs := range result.ConfirmedObservations {
+			observe(obs.TrackID, obs.Class, obs.BoundingBox, obs.Confidence, obs.FrameNum, obs.Timestamp, trackedFramePaths[obs.FrameNum])
+		}
+
+		if spatialAnalyzer != nil {
+			events, occupancy := spatialAnalyzer.Update(result.TrackedObjects, result.FrameNum, frameTime)
+			spatialEvents = append(spatialEvents, events...)
+			ts.publishSpatial(ctx, jobID, result.FrameNum, events, occupancy)
+		}
+
+		// Only tracks matched in this frame have a current bounding box
+		// (simple_iou also reports unmatched tracks with a stale box)
+		observed := make([]tracking.TrackedObject, 0, len(result.TrackedObjects))
+		for _, obj := range result.TrackedObjects {
+			if obj.LostFrames == 0 {
+				observed = append(observed, obj)
+			}
+		}
+		sort.Slice(observed, func(a, b int) bool { return observed[a].TrackID < observed[b].TrackID })
+		if len(observed) > busiestFrameCount {
+			busiestFrameCount = len(observed)
+			busiestFramePath = framePath
+		}
+
+		for _, obj := range observed {
+			observe(obj.TrackID, obj.Class, obj.BoundingBox, obj.Confidence, result.FrameNum, frameTime, framePath)
+		}
+
+		// Interactions between tracks visible in this frame (timed by video time)
+		events, err := interactionDetector.DetectInteractionsAt(ctx, observed, frameData, result.FrameNum, frameTime)
+		if err != nil {
+			log.Printf("Warning: interaction detection failed at %.2fs: %v", timestamp, err)
+			continue
+		}
+		interactionEvents = collectInteractionEvents(interactionEvents, interactionSignificance, events, videoEpoch)
+	}
+
+	interactionEvents = collectInteractionEvents(interactionEvents, interactionSignificance,
+		interactionDetector.Finish(lastFrameNum, lastFrameTime), videoEpoch)
+
+	if spatialAnalyzer != nil {
+		events, occupancy := spatialAnalyzer.Finish(lastFrameNum, lastFrameTime)
+		spatialEvents = append(spatialEvents, events...)
+		ts.publishSpatial(ctx, jobID, lastFrameNum, events, occupancy)
+	}
+
+	// Step 3: Re-identify person tracks using their most confident observation
+	personReID := tracking.NewPersonReID(ts.mageAgent)
+	if ts.embedder != nil {
+		personReID.SetEmbedder(ts.embedder)
+	}
+	for _, trackID := range recordOrder {
+		record := records[trackID]
+		if record.class != tracking.ClassPerson {
+			continue
+		}
+
+		frameData, err := ts.ffmpeg.EncodeFrameToBase64(record.bestFramePath)
+		if err != nil {
+			log.Printf("Warning: failed to encode re-ID frame for %s: %v", trackID, err)
+			continue
+		}
+
+		track := &tracking.TrackedObject{
+			TrackID: trackID,
+			Class:   record.class,
+			BoundingBox: tracking.BoundingBox{
+				X:      record.bestPoint.BoundingBox.X,
+				Y:      record.bestPoint.BoundingBox.Y,
+				Width:  record.bestPoint.BoundingBox.Width,
+				Height: record.bestPoint.BoundingBox.Height,
+			},
+			Attributes: make(map[string]interface{}),
+		}
+
+		match, err := personReID.IdentifyPerson(ctx, track, frameData)
+		if err != nil {
+			log.Printf("Warning: re-ID failed for %s: %v", trackID, err)
+			continue
+		}
+		record.identityID = match.IdentityID
+	}
+
+	// Step 4: Analyze full trajectories and build results
+	trajectoryAnalyzer := tracking.NewTrajectoryAnalyzer()
+	trajectoryAnalyses := make(map[string]*tracking.TrajectoryAnalysis)
+	tracks := make([]models.ObjectTrack, 0, len(recordOrder))
+	for _, trackID := range recordOrder {
+		record := records[trackID]
+		first := record.points[0]
+		last := record.points[len(record.points)-1]
+
+		objectTrack := models.ObjectTrack{
+			TrackID:        trackID,
+			Class:          string(record.class),
+			IdentityID:     record.identityID,
+			StartTime:      first.Timestamp,
+			EndTime:        last.Timestamp,
+			StartFrame:     first.FrameNumber,
+			EndFrame:       last.FrameNumber,
+			FrameCount:     len(record.points),
+			MeanConfidence: record.confidenceSum / float64(len(record.points)),
+			Trajectory:     record.points,
+		}
+
+		analysis, err := trajectoryAnalyzer.AnalyzeTrajectory(&tracking.TrackedObject{
+			TrackID:    trackID,
+			Class:      record.class,
+			Trajectory: record.trajectory,
+		}, last.FrameNumber)
+		if err == nil {
+			trajectoryAnalyses[trackID] = analysis
+			objectTrack.Pattern = string(analysis.Pattern)
+			objectTrack.TotalDistance = analysis.TotalDistance
+			objectTrack.AverageSpeed = analysis.AverageSpeed
+		}
+
+		tracks = append(tracks, objectTrack)
+	}
+
+	interactionRecords, interactionEvents := buildInteractionRecords(
+		interactionDetector.GetHistory(0), interactionEvents, interactionSignificance, records, videoEpoch)
+
+	// Step 5: Link identities to the tenant's cross-video gallery
+	identities := ts.buildIdentities(personReID, records)
+	if ts.gallery != nil && tenantID != "" && len(identities) > 0 {
+		mapping, err := ts.gallery.Resolve(ctx, tenantID, jobID, ts.galleryCandidates(personReID, records))
+		if err != nil {
+			log.Printf("Warning: re-ID gallery resolution failed: %v", err)
+		} else {
+			for i := range identities {
+				identities[i].GalleryID = mapping[identities[i].IdentityID]
+			}
+		}
+	}
+
+	analysis := &models.TrackingAnalysis{
+		Tracks:            tracks,
+		Identities:        identities,
+		Interactions:      interactionRecords,
+		InteractionEvents: interactionEvents,
+		FramesProcessed:   framesProcessed,
+		SampleRate:        float64(sampleRate),
+		TrackedDuration:   trackedDuration,
+		Truncated:         truncated,
+		TrackerType:       string(trackerType),
+		ProcessingTime:    time.Since(startTime).Seconds(),
+	}
+	// Step 6: Visual summaries for dashboards (optional)
+	if options.ShouldGenerateTrackingVisuals() && len(recordOrder) > 0 {
+		visuals, err := ts.buildVisuals(records, recordOrder, trajectoryAnalyses, busiestFramePath, sampleRate, videoEpoch)
+		if err != nil {
+			log.Printf("Warning: failed to build tracking visuals: %v", err)
+		} else {
+			analysis.Visuals = visuals
+		}
+	}
+
+	if spatialAnalyzer != nil {
+		analysis.SpatialEvents = spatialEvents
+		analysis.Occupancy = spatialAnalyzer.Occupancy()
+		analysis.ZoneStats = spatialAnalyzer.Stats()
+	}
+
+	log.Printf("Tracking complete: %d tracks, %d identities, %d interactions over %d frames",
+		len(analysis.Tracks), len(analysis.Identities), len(analysis.Interactions), framesProcessed)
+
+	return analysis, nil
+}
+
+// applyInteractionThresholds configures per-class proximity thresholds ("default" sets the fallback)
+func applyInteractionThresholds(detector *tracking.InteractionDetector, thresholds map[string]models.ProximityThresholds) {
+	for class, threshold := range thresholds {
+		if threshold.Far <= 0 || threshold.Near > threshold.Medium || threshold.Medium > threshold.Far {
+			log.Printf("Warning: ignoring invalid interaction thresholds for %s", class)
+			continue
+		}
+
+		proximity := tracking.ProximityThreshold{Near: threshold.Near, Medium: threshold.Medium, Far: threshold.Far}
+		if class == "default" {
+			detector.SetProximityThreshold(proximity)
+		} else {
+			detector.SetClassProximityThreshold(tracking.ObjectClass(class), proximity)
+		}
+	}
+}
+
+// collectInteractionEvents appends state changes (not per-frame "ongoing" updates)
+// and tracks each interaction's peak significance
+func collectInteractionEvents(
+	collected []models.InteractionEventRecord,
+	significance map[string]float64,
+	events []tracking.InteractionEvent,
+	epoch time.Time,
+) []models.InteractionEventRecord {
+	for _, event := range events {
+		interaction := event.Interaction
+		if interaction == nil {
+			continue
+		}
+
+		if event.Significance > significance[interaction.InteractionID] {
+			significance[interaction.InteractionID] = event.Significance
+		}
+		if event.EventType == "ongoing" {
+			continue
+		}
+
+		collected = append(collected, models.InteractionEventRecord{
+			EventID:         event.EventID,
+			InteractionID:   interaction.InteractionID,
+			EventType:       event.EventType,
+			InteractionType: string(interaction.Type),
+			Participants:    append([]string{}, interaction.Participants...),
+			Timestamp:       event.Timestamp.Sub(epoch).Seconds(),
+			FrameNumber:     event.FrameNum,
+			Significance:    event.Significance,
+			Proximity:       interaction.Proximity,
+		})
+	}
+	return collected
+}
+
+// buildInteractionRecords converts completed interactions to records, in start order
+// Events of interactions too short to be recorded are dropped.
+func buildInteractionRecords(
+	completed []tracking.Interaction,
+	events []models.InteractionEventRecord,
+	significance map[string]float64,
+	records map[string]*trackRecord,
+	epoch time.Time,
+) ([]models.InteractionRecord, []models.InteractionEventRecord) {
+	sort.SliceStable(completed, func(i, j int) bool { return completed[i].StartFrame < completed[j].StartFrame })
+
+	interactionRecords := make([]models.InteractionRecord, 0, len(completed))
+	recorded := make(map[string]bool, len(completed))
+	for _, interaction := range completed {
+		classes := make([]string, len(interaction.Participants))
+		for i, trackID := range interaction.Participants {
+			if record, ok := records[trackID]; ok {
+				classes[i] = string(record.class)
+			}
+		}
+
+		endTime := interaction.LastSeen
+		if interaction.EndTime != nil {
+			endTime = *interaction.EndTime
+		}
+
+		interactionRecords = append(interactionRecords, models.InteractionRecord{
+			InteractionID: interaction.InteractionID,
+			Type:          string(interaction.Type),
+			Participants:  interaction.Participants,
+			Classes:       classes,
+			StartTime:     interaction.StartTime.Sub(epoch).Seconds(),
+			EndTime:       endTime.Sub(epoch).Seconds(),
+			Duration:      interaction.Duration,
+			StartFrame:    interaction.StartFrame,
+			EndFrame:      interaction.EndFrame,
+			Confidence:    interaction.Confidence,
+			Significance:  significance[interaction.InteractionID],
+			Description:   interaction.Description,
+		})
+		recorded[interaction.InteractionID] = true
+	}
+
+	kept := make([]models.InteractionEventRecord, 0, len(events))
+	for _, event := range events {
+		if recorded[event.InteractionID] {
+			kept = append(kept, event)
+		}
+	}
+
+	return interactionRecords, kept
+}
+
+// buildVisuals renders heatmaps, the trajectory overlay and the track timeline
+func (ts *TrackingStage) buildVisuals(
+	records map[string]*trackRecord,
+	recordOrder []string,
+	analyses map[string]*tracking.TrajectoryAnalysis,
+	backgroundPath string,
+	sampleRate int,
+	videoEpoch time.Time,
+) (*models.TrackingVisuals, error) {
+	objects := make([]tracking.TrackedObject, 0, len(recordOrder))
+	for _, trackID := range recordOrder {
+		record := records[trackID]
+		objects = append(objects, tracking.TrackedObject{
+			TrackID:    trackID,
+			Class:      record.class,
+			Trajectory: record.trajectory,
+		})
+	}
+
+	var background image.Image
+	if backgroundPath != "" {
+		if file, err := os.Open(backgroundPath); err == nil {
+			background, _, err = image.Decode(file)
+			file.Close()
+			if err != nil {
+				log.Printf("Warning: failed to decode tracking background frame: %v", err)
+				background = nil
+			}
+		}
+	}
+
+	visualizer := tracking.NewTrackVisualizer(videoEpoch)
+	visualizer.SetSampleInterval(time.Second / time.Duration(sampleRate))
+	visuals, err := visualizer.Build(objects, analyses, background)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range visuals.Timeline.Tracks {
+		if record, exists := records[visuals.Timeline.Tracks[i].TrackID]; exists {
+			visuals.Timeline.Tracks[i].IdentityID = record.identityID
+		}
+	}
+
+	return visuals, nil
+}
+
+// publishSpatial streams a frame's spatial events (best effort)
+func (ts *TrackingStage) publishSpatial(ctx context.Context, jobID string, frameNum int, events []models.SpatialEvent, occupancy []models.OccupancySample) {
+	if ts.publisher == nil {
+		return
+	}
+	if err := ts.publisher.PublishSpatial(ctx, jobID, "", frameNum, events, occupancy); err != nil {
+		log.Printf("Warning: failed to publish spatial events: %v", err)
+	}
+}
+
+// buildIdentities converts re-ID identities into result records with video time ranges
+func (ts *TrackingStage) buildIdentities(personReID *tracking.PersonReID, records map[string]*trackRecord) []models.PersonIdentityRecord {
+	identities := personReID.GetAllIdentities()
+	sort.Slice(identities, func(i, j int) bool { return identities[i].IdentityID < identities[j].IdentityID })
+
+	results := make([]models.PersonIdentityRecord, 0, len(identities))
+	for _, identity := range identities {
+		result := models.PersonIdentityRecord{
+			IdentityID:  identity.IdentityID,
+			TrackIDs:    identity.TrackIDs,
+			Appearances: identity.Appearances,
+			Confidence:  identity.Confidence,
+			Attributes:  make(map[string]interface{}),
+		}
+
+		// Time range spans all linked tracks
+		for i, trackID := range identity.TrackIDs {
+			record, exists := records[trackID]
+			if !exists {
+				continue
+			}
+			start := record.points[0].Timestamp
+			end := record.points[len(record.points)-1].Timestamp
+			if i == 0 || start < result.StartTime {
+				result.StartTime = start
+			}
+			if end > result.EndTime {
+				result.EndTime = end
+			}
+		}
+
+		if data, err := json.Marshal(identity.Attributes); err == nil {
+			json.Unmarshal(data, &result.Attributes)
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// galleryCandidates converts re-ID identities into gallery candidates with one sighting per track
+func (ts *TrackingStage) galleryCandidates(personReID *tracking.PersonReID, records map[string]*trackRecord) []tracking.GalleryCandidate {
+	identities := personReID.GetAllIdentities()
+	sort.Slice(identities, func(i, j int) bool { return identities[i].IdentityID < identities[j].IdentityID })
+
+	candidates := make([]tracking.GalleryCandidate, 0, len(identities))
+	for _, identity := range identities {
+		candidate := tracking.GalleryCandidate{
+			LocalID:    identity.IdentityID,
+			Features:   identity.Features,
+			Attributes: identity.Attributes,
+			Confidence: identity.Confidence,
+		}
+
+		for _, trackID := range identity.TrackIDs {
+			record, exists := records[trackID]
+			if !exists {
+				continue
+			}
+			features, _ := personReID.GetTrackFeatures(trackID)
+			candidate.Sightings = append(candidate.Sightings, models.GallerySighting{
+				TrackID:   trackID,
+				StartTime: record.points[0].Timestamp,
+				EndTime:   record.points[len(record.points)-1].Timestamp,
+				Features:  features,
+			})
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates
+}
+
+// minAdaptiveTrackingRate is the lowest rate the default sampling drops to for long videos
+// (below it objects move too far between frames to be associated reliably)
+const minAdaptiveTrackingRate = 2
+
+// trackingSampleRate returns the tracking sample rate (frames per second)
+// An explicit trackingSampleRate is kept; otherwise the default rate is lowered
+// (down to minAdaptiveTrackingRate) so the frame budget covers the whole video.
+func trackingSampleRate(options models.ProcessingOptions, maxFrames int, duration float64) int {
+	rate := options.GetTrackingSampleRate()
+	if options.TrackingSampleRate != nil || duration <= 0 || float64(rate)*duration <= float64(maxFrames) {
+		return rate
+	}
+
+	fitted := int(float64(maxFrames) / duration)
+	if fitted < minAdaptiveTrackingRate {
+		fitted = minAdaptiveTrackingRate
+	}
+	if fitted < rate {
+		return fitted
+	}
+	return rate
+}
+
+// sortFramePaths orders extracted frames by the sequence number ffmpeg writes into
+// frame_%04d.jpg (lexical order breaks past frame_9999.jpg)
+func sortFramePaths(framePaths []string) {
+	sort.SliceStable(framePaths, func(i, j int) bool {
+		a, okA := frameSequence(framePaths[i])
+		b, okB := frameSequence(framePaths[j])
+		if okA && okB && a != b {
+			return a < b
+		}
+		return framePaths[i] < framePaths[j]
+	})
+}
+
+// frameSequence parses the sequence number from an extracted frame path
+func frameSequence(path string) (int, bool) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	n, err := strconv.Atoi(strings.TrimPrefix(name, "frame_"))
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,30 @@
+package processor
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestSortFramePathsPastFourDigits(t *testing.T) {
+	dir := filepath.Join("tmp", "job_tracking_frames")
+	want := make([]string, 0, 10100)
+	for i := 1; i <= 10100; i++ {
+		want = append(want, filepath.Join(dir, fmt.Sprintf("frame_%04d.jpg", i)))
+	}
+
+	// Directory listings come back in lexical order
+	framePaths := append([]string(nil), want...)
+	sort.Strings(framePaths)
+	if framePaths[1000] == want[1000] {
+		t.Fatal("lexical order unexpectedly matches frame order")
+	}
+
+	sortFramePaths(framePaths)
+	for i := range want {
+		if framePaths[i] != want[i] {
+			t.Fatalf("position %d = %s, want %s", i, framePaths[i], want[i])
+		}
+	}
+}
@@ -10,7 +10,10 @@ import (
 	"github.com/adverant/nexus/videoagent-worker/internal/clients"
 	"github.com/adverant/nexus/videoagent-worker/internal/extractor"
 	"github.com/adverant/nexus/videoagent-worker/internal/models"
+	"github.com/adverant/nexus/videoagent-worker/internal/nle"
 	"github.com/adverant/nexus/videoagent-worker/internal/storage"
+	"github.com/adverant/nexus/videoagent-worker/internal/summary"
+	"github.com/adverant/nexus/videoagent-worker/internal/tracking"
 	"github.com/adverant/nexus/videoagent-worker/internal/utils"
 	"github.com/redis/go-redis/v9"
 )
@@ -23,6 +26,16 @@ type VideoProcessor struct {
 	frameExtractor    *extractor.FrameExtractor
 	audioExtractor    *extractor.AudioExtractor
 	metadataExtractor *extractor.MetadataExtractor
+	trackingStage     *TrackingStage
+	renderStage       *RenderStage
+	cinematicStage    *CinematicStage
+	thumbnailStage    *ThumbnailStage
+	qcStage           *QCStage
+	exportStage       *ExportStage
+	highlightStage    *HighlightStage
+	reframeStage      *ReframeStage
+	timelineStage     *TimelineEmbedStage
+	indexStage        *IndexStage
 	httpDownloader    *utils.HTTPDownloader
 	youtubeDownloader *utils.YouTubeDownloader
 	redisClient       *redis.Client
@@ -54,6 +67,12 @@ func NewVideoProcessor(
 		youtubeDownloader = nil
 	}
 
+	// Spatial events are streamed to the results stream while tracking
+	trackingStage := NewTrackingStage(ffmpeg, mageAgent)
+	if redisClient != nil {
+		trackingStage.SetResultsPublisher(NewResultsPublisher(redisClient))
+	}
+
 	return &VideoProcessor{
 		ffmpeg:            ffmpeg,
 		mageAgent:         mageAgent,
@@ -61,6 +80,14 @@ func NewVideoProcessor(
 		frameExtractor:    extractor.NewFrameExtractor(ffmpeg, mageAgent, concurrency),
 		audioExtractor:    extractor.NewAudioExtractor(ffmpeg, mageAgent),
 		metadataExtractor: extractor.NewMetadataExtractor(ffmpeg),
+		trackingStage:     trackingStage,
+		renderStage:       NewRenderStage(ffmpeg),
+		cinematicStage:    NewCinematicStage(ffmpeg, mageAgent),
+		thumbnailStage:    NewThumbnailStage(ffmpeg),
+		qcStage:           NewQCStage(ffmpeg),
+		exportStage:       NewExportStage(),
+		highlightStage:    NewHighlightStage(ffmpeg),
+		reframeStage:      NewReframeStage(ffmpeg),
 		httpDownloader:    httpDownloader,
 		youtubeDownloader: youtubeDownloader,
 		redisClient:       redisClient,
@@ -75,6 +102,31 @@ func (vp *VideoProcessor) SetYouTubeAuthClient(authClient utils.YouTubeAuthClien
 	}
 }
 
+// SetDetector configures the object detector used for tracking
+func (vp *VideoProcessor) SetDetector(detector tracking.Detector) {
+	vp.trackingStage.SetDetector(detector)
+}
+
+// SetAppearanceEmbedder configures the person re-ID embedding model for tracking
+func (vp *VideoProcessor) SetAppearanceEmbedder(embedder tracking.AppearanceEmbedder) {
+	vp.trackingStage.SetAppearanceEmbedder(embedder)
+}
+
+// SetTextEmbedder enables storing frame description and transcript embeddings for moment retrieval
+func (vp *VideoProcessor) SetTextEmbedder(embedder TextEmbedder) {
+	vp.timelineStage = NewTimelineEmbedStage(embedder)
+}
+
+// SetVideoIndexer enables storing video and scene embeddings for similarity search
+func (vp *VideoProcessor) SetVideoIndexer(indexer VideoIndexer) {
+	vp.indexStage = NewIndexStage(vp.ffmpeg, indexer)
+}
+
+// SetPersonGallery enables cross-video person re-identification for tracking
+func (vp *VideoProcessor) SetPersonGallery(gallery *tracking.PersonGallery) {
+	vp.trackingStage.SetPersonGallery(gallery)
+}
+
 // Process processes a video job (main entry point)
 func (vp *VideoProcessor) Process(ctx context.Context, job *models.JobPayload) error {
 	startTime := time.Now()
@@ -145,6 +197,18 @@ func (vp *VideoProcessor) Process(ctx context.Context, job *models.JobPayload) e
 					return fmt.Errorf("failed to store objects: %w", err)
 				}
 			}
+
+			// Store OCR text (indexed for hybrid keyword search)
+			if len(frame.Text) > 0 {
+				for i := range frame.Text {
+					if frame.Text[i].Timestamp == 0 {
+						frame.Text[i].Timestamp = frame.Timestamp
+					}
+				}
+				if err := vp.storage.StoreTextExtractions(ctx, job.JobID, frame.FrameID, frame.Text); err != nil {
+					return fmt.Errorf("failed to store text extractions: %w", err)
+				}
+			}
 		}
 
 		vp.sendProgress(ctx, job.JobID, 60, "processing", fmt.Sprintf("Analyzed %d frames", len(frames)))
@@ -166,9 +230,42 @@ func (vp *VideoProcessor) Process(ctx context.Context, job *models.JobPayload) e
 		}
 	}
 
-	// Step 6: Detect scenes (if requested)
+	// Step 5a: Embed frame descriptions and transcript segments for moment retrieval
+	if vp.timelineStage != nil && (len(frames) > 0 || audioAnalysis != nil) {
+		embeddings, err := vp.timelineStage.Run(ctx, frames, audioAnalysis)
+		if err != nil {
+			// Non-fatal - moment retrieval embeds the timeline at query time
+			fmt.Printf("Warning: timeline embedding failed: %v\n", err)
+		} else if err := vp.storage.StoreTimelineEmbeddings(ctx, job.JobID, embeddings); err != nil {
+			return fmt.Errorf("failed to store timeline embeddings: %w", err)
+		}
+	}
+
+	// Step 5b: Track objects, re-identify people and detect interactions (if requested)
+	var trackingAnalysis *models.TrackingAnalysis
+	if job.Options.ShouldTrackObjects() {
+		trackingAnalysis, err = vp.trackingStage.Run(ctx, videoPath, job.JobID, job.TenantID(), job.Options, metadata.Duration)
+		if err != nil {
+			// Non-fatal - continue without tracking
+			fmt.Printf("Warning: object tracking failed: %v\n", err)
+			trackingAnalysis = nil
+		} else {
+			if err := vp.storage.StoreTrackingAnalysis(ctx, job.JobID, trackingAnalysis); err != nil {
+				return fmt.Errorf("failed to store tracking analysis: %w", err)
+			}
+			vp.sendProgress(ctx, job.JobID, 80, "processing", fmt.Sprintf("Tracked %d objects", len(trackingAnalysis.Tracks)))
+		}
+	}
+
+	// Step 5c: Score frame quality for keyframe and poster selection (if thumbnails requested)
+	var frameQuality []models.FrameQuality
+	if job.Options.ShouldGenerateThumbnails() && len(frames) > 0 {
+		frameQuality = vp.thumbnailStage.ScoreFrames(ctx, videoPath, job.JobID, frames)
+	}
+
+	// Step 6: Detect scenes (if requested; cinematic analysis needs scenes)
 	var scenes []models.SceneDetection
-	if job.Options.ShouldDetectScenes() && len(frames) > 0 {
+	if (job.Options.ShouldDetectScenes() || job.Options.ShouldRunCinematicAnalysis()) && len(frames) > 0 {
 		// Select model for scene analysis
 		modelReq := models.MageAgentModelRequest{
 			TaskType:   "vision",
@@ -183,9 +280,131 @@ func (vp *VideoProcessor) Process(ctx context.Context, job *models.JobPayload) e
 			scenes, _ = vp.frameExtractor.DetectScenes(ctx, frames, modelResp.ModelID)
 		}
 
+		// Scene type, composition, camera movement and look per scene (if requested)
+		if job.Options.ShouldRunCinematicAnalysis() && len(scenes) > 0 {
+			analyzed, err := vp.cinematicStage.Run(ctx, videoPath, job.JobID, frames, scenes)
+			if err != nil {
+				// Non-fatal - keep scenes without cinematic analysis
+				fmt.Printf("Warning: cinematic analysis failed: %v\n", err)
+			} else {
+				scenes = analyzed
+			}
+		}
+
+		// Best-quality frame as each scene's keyframe (first frame otherwise)
+		if len(frameQuality) > 0 {
+			scenes = SelectSceneKeyframes(scenes, frameQuality)
+		}
+
+		if len(scenes) > 0 {
+			if err := vp.storage.StoreScenes(ctx, job.JobID, scenes); err != nil {
+				return fmt.Errorf("failed to store scenes: %w", err)
+			}
+		}
+
 		vp.sendProgress(ctx, job.JobID, 85, "processing", fmt.Sprintf("Detected %d scenes", len(scenes)))
 	}
 
+	// Step 6a: Index video and scene embeddings for similarity search
+	// (the shots found in the scene embeddings become cuts in the edit exports)
+	var shots []nle.Shot
+	if vp.indexStage != nil && len(frames) > 0 {
+		videoEmbedding, sceneEmbeddings, err := vp.indexStage.Run(ctx, job, frames, scenes, metadata.Duration)
+		if err != nil {
+			// Non-fatal - the video is processed but not searchable by similarity
+			fmt.Printf("Warning: similarity indexing failed: %v\n", err)
+		} else {
+			shots = ExportShots(sceneEmbeddings, videoEmbedding.FrameCount, metadata.Duration)
+			vp.sendProgress(ctx, job.JobID, 86, "processing", fmt.Sprintf("Indexed video with %d scenes (%d frames)", len(sceneEmbeddings), videoEmbedding.FrameCount))
+		}
+	}
+
+	// Step 6b: Render annotated video (if requested)
+	var annotatedVideo *models.AnnotatedVideo
+	if job.Options.ShouldRenderAnnotatedVideo() {
+		annotatedVideo, err = vp.renderStage.Run(ctx, videoPath, job.JobID, vp.renderStage.OutputDir(job), job.Options,
+			metadata, frames, audioAnalysis, trackingAnalysis, scenes)
+		if err != nil {
+			// Non-fatal - continue without annotated video
+			fmt.Printf("Warning: annotated video rendering failed: %v\n", err)
+			annotatedVideo = nil
+		} else {
+			vp.sendProgress(ctx, job.JobID, 88, "processing", "Annotated video rendered")
+		}
+	}
+
+	// Step 6c: Render poster frames, thumbnails and scrubbing sprites (if requested)
+	var thumbnails *models.ThumbnailSet
+	if job.Options.ShouldGenerateThumbnails() {
+		thumbnails, err = vp.thumbnailStage.Run(ctx, videoPath, job.JobID, vp.renderStage.OutputDir(job), job.Options,
+			metadata, frameQuality, scenes)
+		if err != nil {
+			// Non-fatal - continue without thumbnails
+			fmt.Printf("Warning: thumbnail generation failed: %v\n", err)
+			thumbnails = nil
+		} else {
+			vp.sendProgress(ctx, job.JobID, 89, "processing", "Thumbnails generated")
+		}
+	}
+
+	// Step 6d: Technical QC (if requested)
+	var qcReport *models.QCReport
+	if job.Options.ShouldRunQC() {
+		qcReport, err = vp.qcStage.Run(ctx, videoPath, job.Options)
+		if err != nil {
+			// Non-fatal - continue without QC
+			fmt.Printf("Warning: technical QC failed: %v\n", err)
+			qcReport = nil
+		} else {
+			if err := vp.storage.StoreQCReport(ctx, job.JobID, qcReport); err != nil {
+				return fmt.Errorf("failed to store QC report: %w", err)
+			}
+			vp.sendProgress(ctx, job.JobID, 89, "processing", fmt.Sprintf("QC %s with %d errors, %d warnings",
+				qcVerdict(qcReport), qcReport.Errors, qcReport.Warnings))
+		}
+	}
+
+	// Step 6e: Export NLE timelines (if requested)
+	var editExports []models.EditExport
+	if len(job.Options.GetExportFormats()) > 0 {
+		editExports, err = vp.exportStage.Run(job, videoPath, vp.renderStage.OutputDir(job), metadata, scenes, shots, audioAnalysis)
+		if err != nil {
+			// Non-fatal - continue without exports
+			fmt.Printf("Warning: timeline export failed: %v\n", err)
+			editExports = nil
+		} else {
+			vp.sendProgress(ctx, job.JobID, 89, "processing", fmt.Sprintf("Exported %d timelines", len(editExports)))
+		}
+	}
+
+	// Step 6f: Generate highlight reel and clips (if requested)
+	var highlightReel *models.HighlightReel
+	if job.Options.ShouldGenerateHighlights() {
+		highlightReel, err = vp.highlightStage.Run(ctx, job, videoPath, vp.renderStage.OutputDir(job), metadata,
+			scenes, audioAnalysis, trackingAnalysis)
+		if err != nil {
+			// Non-fatal - continue without highlights
+			fmt.Printf("Warning: highlight generation failed: %v\n", err)
+			highlightReel = nil
+		} else {
+			vp.sendProgress(ctx, job.JobID, 89, "processing", fmt.Sprintf("Generated %.0fs highlight reel from %d highlights",
+				highlightReel.Duration, len(highlightReel.Highlights)))
+		}
+	}
+
+	// Step 6g: Reframe to vertical/square (if requested)
+	var reframed []models.ReframedVideo
+	if len(job.Options.GetReframeFormats()) > 0 {
+		reframed, err = vp.reframeStage.Run(ctx, job, videoPath, vp.renderStage.OutputDir(job), metadata, frames, trackingAnalysis)
+		if err != nil {
+			// Non-fatal - continue without reframed renditions
+			fmt.Printf("Warning: reframing failed: %v\n", err)
+			reframed = nil
+		} else {
+			vp.sendProgress(ctx, job.JobID, 89, "processing", fmt.Sprintf("Reframed to %d aspect ratios", len(reframed)))
+		}
+	}
+
 	// Step 7: Classify content (if requested)
 	var classification *models.ContentClassification
 	shouldClassifyContent := job.Options.ClassifyContent != nil && *job.Options.ClassifyContent
@@ -200,12 +419,14 @@ func (vp *VideoProcessor) Process(ctx context.Context, job *models.JobPayload) e
 	}
 
 	// Step 8: Generate summary (if requested)
-	var summary string
+	var structuredSummary *models.VideoSummary
 	shouldGenerateSummary := job.Options.GenerateSummary != nil && *job.Options.GenerateSummary
 	if shouldGenerateSummary {
-		summary, err = vp.generateSummary(ctx, frames, audioAnalysis, metadata)
+		structuredSummary, err = vp.generateSummary(ctx, job, frames, scenes, audioAnalysis, thumbnails, metadata)
 		if err != nil {
-			summary = ""
+			// Non-fatal - continue without summary
+			fmt.Printf("Warning: summary generation failed: %v\n", err)
+			structuredSummary = nil
 		}
 
 		vp.sendProgress(ctx, job.JobID, 95, "processing", "Summary generated")
@@ -221,18 +442,26 @@ func (vp *VideoProcessor) Process(ctx context.Context, job *models.JobPayload) e
 	}
 
 	result := &models.ProcessingResult{
-		JobID:           job.JobID,
-		Status:          "completed",
-		VideoMetadata:   *metadata,
-		Frames:          frames,
-		AudioAnalysis:   audioAnalysis,
-		Scenes:          scenes,
-		Objects:         allObjects,
-		Classification:  classification,
-		Summary:         summary,
-		ProcessingTime:  processingTime,
-		StartedAt:       startTime,
-		CompletedAt:     time.Now(),
+		JobID:             job.JobID,
+		Status:            "completed",
+		VideoMetadata:     *metadata,
+		Frames:            frames,
+		AudioAnalysis:     audioAnalysis,
+		Scenes:            scenes,
+		Objects:           allObjects,
+		Classification:    classification,
+		Tracking:          trackingAnalysis,
+		AnnotatedVideo:    annotatedVideo,
+		Thumbnails:        thumbnails,
+		QC:                qcReport,
+		EditExports:       editExports,
+		Highlights:        highlightReel,
+		Reframed:          reframed,
+		Summary:           summaryText(structuredSummary),
+		StructuredSummary: structuredSummary,
+		ProcessingTime:    processingTime,
+		StartedAt:         startTime,
+		CompletedAt:       time.Now(),
 	}
 
 	// Store result
@@ -383,57 +612,51 @@ func (vp *VideoProcessor) classifyContent(
 	return classification, nil
 }
 
-// generateSummary generates video summary using AI
+// generateSummary builds a hierarchical, time-coded summary: per scene, per chapter
+// and for the whole video, with key moments and a storyboard of representative frames
 func (vp *VideoProcessor) generateSummary(
 	ctx context.Context,
+	job *models.JobPayload,
 	frames []models.FrameAnalysis,
+	scenes []models.SceneDetection,
 	audioAnalysis *models.AudioAnalysis,
+	thumbnails *models.ThumbnailSet,
 	metadata *models.VideoMetadata,
-) (string, error) {
-
-	// Collect sources for synthesis
-	sources := []string{}
-
-	// Add metadata summary
-	metadataSummary := fmt.Sprintf(
-		"Video: %dx%d, %.1f seconds, %.1f fps, %s codec, %s quality",
-		metadata.Width,
-		metadata.Height,
-		metadata.Duration,
-		metadata.FrameRate,
-		metadata.Codec,
-		metadata.Quality,
-	)
-	sources = append(sources, metadataSummary)
-
-	// Add frame descriptions (sample key frames)
-	sampleCount := 5
-	if len(frames) > 0 {
-		step := len(frames) / sampleCount
-		if step == 0 {
-			step = 1
-		}
-
-		for i := 0; i < len(frames); i += step {
-			if len(sources) >= sampleCount+1 {
-				break
-			}
-			sources = append(sources, frames[i].Description)
-		}
-	}
-
-	// Add transcription
-	if audioAnalysis != nil && audioAnalysis.Transcription != "" {
-		sources = append(sources, "Transcription: "+audioAnalysis.Transcription)
-	}
+) (*models.VideoSummary, error) {
+	input := summary.Input{
+		Metadata: fmt.Sprintf(
+			"Video: %dx%d, %.1f seconds, %.1f fps, %s codec, %s quality",
+			metadata.Width,
+			metadata.Height,
+			metadata.Duration,
+			metadata.FrameRate,
+			metadata.Codec,
+			metadata.Quality,
+		),
+		Duration: metadata.Duration,
+		Scenes:   scenes,
+		Frames:   frames,
+	}
+	if audioAnalysis != nil {
+		input.Transcript = audioAnalysis.Speakers
+		input.TranscriptText = audioAnalysis.Transcription
+	}
+	if thumbnails != nil {
+		input.Posters = thumbnails.Scenes
+	}
+
+	summarizer := summary.NewSummarizer(vp.mageAgent)
+	summarizer.SetChapterLength(job.Options.GetSummaryChapterLength())
+
+	return summarizer.Summarize(ctx, input)
+}
 
-	// Synthesize summary
-	summary, err := vp.mageAgent.Synthesize(ctx, sources, "summary", "video summary")
-	if err != nil {
-		return "", err
+// summaryText returns the synopsis kept in the result's plain summary field
+func summaryText(structured *models.VideoSummary) string {
+	if structured == nil {
+		return ""
 	}
-
-	return summary, nil
+	return structured.Synopsis
 }
 
 // sendProgress sends progress update via WebSocket (through Redis pub/sub)
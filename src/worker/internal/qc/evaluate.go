@@ -0,0 +1,154 @@
+package qc
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// Classification thresholds
+const (
+	edgeTolerance     = 0.1  // Seconds from the start/end within which an event counts as leading/trailing
+	interlacedShare   = 0.5  // Share of decided frames that must be interlaced
+	barMinimumPercent = 0.02 // Bars thinner than this share of the frame per side are ignored
+)
+
+// Evaluate turns measurements into a report against the profile
+// width and height are the coded picture size; duration closes events still open at the end.
+func Evaluate(m *Measurements, profile models.QCProfile, width, height int, duration float64, hasAudio bool) *models.QCReport {
+	report := &models.QCReport{
+		Profile:    profile,
+		Issues:     []models.QCIssue{},
+		Duration:   duration,
+		HasAudio:   hasAudio,
+		FieldOrder: "progressive",
+	}
+
+	// Step 1: Time-coded events
+	report.BlackDuration = addSegmentIssues(report, "black", "black picture", m.Black, profile.MaxBlackDuration, duration)
+	report.FreezeDuration = addSegmentIssues(report, "freeze", "frozen picture", m.Freeze, profile.MaxFreezeDuration, duration)
+	if hasAudio {
+		report.SilenceDuration = addSegmentIssues(report, "silence", "silence", m.Silence, profile.MaxSilenceDuration, duration)
+	}
+
+	// Step 2: Loudness
+	if !hasAudio {
+		addIssue(report, "no_audio", models.QCSeverityWarning, 0, duration, 0, "no audio stream")
+	} else if m.HasLoudness {
+		report.IntegratedLoudness = m.IntegratedLoudness
+		report.LoudnessRange = m.LoudnessRange
+		report.TruePeak = m.TruePeak
+
+		if deviation := m.IntegratedLoudness - profile.TargetLoudness; math.Abs(deviation) > profile.LoudnessTolerance {
+			addIssue(report, "loudness", models.QCSeverityError, 0, duration, m.IntegratedLoudness,
+				fmt.Sprintf("integrated loudness %.1f LUFS is %+.1f LU from the %.1f LUFS target (±%.1f)",
+					m.IntegratedLoudness, deviation, profile.TargetLoudness, profile.LoudnessTolerance))
+		}
+		if m.TruePeak > profile.MaxTruePeak {
+			addIssue(report, "true_peak", models.QCSeverityError, 0, duration, m.TruePeak,
+				fmt.Sprintf("true peak %.1f dBTP exceeds %.1f dBTP", m.TruePeak, profile.MaxTruePeak))
+		}
+	}
+
+	// Step 3: Interlacing
+	report.InterlacedFrames = m.TFF + m.BFF
+	report.ProgressiveFrames = m.Progressive
+	if decided := report.InterlacedFrames + report.ProgressiveFrames; decided > 0 {
+		share := float64(report.InterlacedFrames) / float64(decided)
+		if share > interlacedShare {
+			report.FieldOrder = "tff"
+			if m.BFF > m.TFF {
+				report.FieldOrder = "bff"
+			}
+			addIssue(report, "interlaced", severity(allowed(profile.AllowInterlaced)), 0, duration, share,
+				fmt.Sprintf("interlaced (%s) in %.0f%% of frames", report.FieldOrder, share*100))
+		}
+	}
+
+	// Step 4: Letterbox and pillarbox bars
+	if m.Crop != nil && width > 0 && height > 0 {
+		letterbox := float64(height-m.Crop.Height)/2 >= barMinimumPercent*float64(height)
+		pillarbox := float64(width-m.Crop.Width)/2 >= barMinimumPercent*float64(width)
+		if letterbox || pillarbox {
+			report.Crop = m.Crop
+			kind := "letterbox"
+			if pillarbox && !letterbox {
+				kind = "pillarbox"
+			}
+			addIssue(report, kind, severity(allowed(profile.AllowLetterbox)), 0, duration, 0,
+				fmt.Sprintf("%s bars: picture is %dx%d at %d,%d in a %dx%d frame",
+					kind, m.Crop.Width, m.Crop.Height, m.Crop.X, m.Crop.Y, width, height))
+		}
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool { return report.Issues[i].StartTime < report.Issues[j].StartTime })
+	report.Passed = report.Errors == 0
+	return report
+}
+
+// addSegmentIssues reports each event and returns their total duration
+// Events longer than max fail mid-programme and warn at the head or tail; shorter ones are informational.
+func addSegmentIssues(report *models.QCReport, kind, label string, segments []Segment, max, duration float64) float64 {
+	total := 0.0
+	for _, seg := range segments {
+		end := seg.End
+		if end < 0 || (duration > 0 && end > duration) {
+			end = duration
+		}
+		length := end - seg.Start
+		if length <= 0 {
+			continue
+		}
+		total += length
+
+		level := models.QCSeverityInfo
+		position := ""
+		if length > max {
+			level = models.QCSeverityError
+			switch {
+			case seg.Start <= edgeTolerance:
+				level, position = models.QCSeverityWarning, "leading "
+			case duration > 0 && end >= duration-edgeTolerance:
+				level, position = models.QCSeverityWarning, "trailing "
+			}
+		}
+
+		addIssue(report, kind, level, seg.Start, end, 0,
+			fmt.Sprintf("%.1fs of %s%s (limit %.1fs)", length, position, label, max))
+	}
+	return total
+}
+
+// addIssue appends an issue and counts it
+func addIssue(report *models.QCReport, kind, level string, start, end, value float64, message string) {
+	report.Issues = append(report.Issues, models.QCIssue{
+		Type:      kind,
+		Severity:  level,
+		StartTime: start,
+		EndTime:   end,
+		Duration:  end - start,
+		Value:     value,
+		Message:   message,
+	})
+
+	switch level {
+	case models.QCSeverityError:
+		report.Errors++
+	case models.QCSeverityWarning:
+		report.Warnings++
+	}
+}
+
+// severity is informational when the profile allows the condition and an error otherwise
+func severity(isAllowed bool) string {
+	if isAllowed {
+		return models.QCSeverityInfo
+	}
+	return models.QCSeverityError
+}
+
+func allowed(flag *bool) bool {
+	return flag != nil && *flag
+}
@@ -0,0 +1,135 @@
+package qc
+
+import (
+	"math"
+	"testing"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+func TestEvaluateSegmentSeverity(t *testing.T) {
+	profile := ResolveProfile(nil) // EBU R128: 2s black/freeze, 3s silence
+	const duration = 60.0
+
+	tests := []struct {
+		name      string
+		black     []Segment
+		freeze    []Segment
+		kind      string
+		severity  string
+		start     float64
+		end       float64
+		passed    bool
+		errors    int
+		warnings  int
+		blackTime float64
+	}{
+		{
+			name: "leading black", black: []Segment{{Start: 0, End: 2.44}},
+			kind: "black", severity: models.QCSeverityWarning, start: 0, end: 2.44, passed: true, warnings: 1, blackTime: 2.44,
+		},
+		{
+			name: "trailing black", black: []Segment{{Start: 57.5, End: 59.95}},
+			kind: "black", severity: models.QCSeverityWarning, start: 57.5, end: 59.95, passed: true, warnings: 1, blackTime: 2.45,
+		},
+		{
+			name: "mid-programme black", black: []Segment{{Start: 20, End: 22.5}},
+			kind: "black", severity: models.QCSeverityError, start: 20, end: 22.5, passed: false, errors: 1, blackTime: 2.5,
+		},
+		{
+			name: "short mid-programme black", black: []Segment{{Start: 20, End: 21}},
+			kind: "black", severity: models.QCSeverityInfo, start: 20, end: 21, passed: true, blackTime: 1,
+		},
+		{
+			name: "unterminated freeze runs to the end", freeze: []Segment{{Start: 57.5, End: -1}},
+			kind: "freeze", severity: models.QCSeverityWarning, start: 57.5, end: 60, passed: true, warnings: 1,
+		},
+		{
+			name: "freeze past the end of the container", freeze: []Segment{{Start: 30, End: 75}},
+			kind: "freeze", severity: models.QCSeverityWarning, start: 30, end: 60, passed: true, warnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Measurements{Black: tt.black, Freeze: tt.freeze}
+			report := Evaluate(m, profile, 1920, 1080, duration, false)
+
+			var issue *models.QCIssue
+			for i := range report.Issues {
+				if report.Issues[i].Type == tt.kind {
+					issue = &report.Issues[i]
+				}
+			}
+			if issue == nil {
+				t.Fatalf("no %s issue in %+v", tt.kind, report.Issues)
+			}
+			if issue.Severity != tt.severity {
+				t.Errorf("Severity = %s, want %s (%s)", issue.Severity, tt.severity, issue.Message)
+			}
+			if math.Abs(issue.StartTime-tt.start) > 1e-9 || math.Abs(issue.EndTime-tt.end) > 1e-9 {
+				t.Errorf("span = %v-%v, want %v-%v", issue.StartTime, issue.EndTime, tt.start, tt.end)
+			}
+			if math.Abs(report.BlackDuration-tt.blackTime) > 1e-9 {
+				t.Errorf("BlackDuration = %v, want %v", report.BlackDuration, tt.blackTime)
+			}
+
+			// Every report here also warns about the missing audio stream
+			if report.Passed != tt.passed || report.Errors != tt.errors || report.Warnings != tt.warnings+1 {
+				t.Errorf("passed/errors/warnings = %v/%d/%d, want %v/%d/%d",
+					report.Passed, report.Errors, report.Warnings, tt.passed, tt.errors, tt.warnings+1)
+			}
+		})
+	}
+}
+
+func TestEvaluateBars(t *testing.T) {
+	allow, deny := true, false
+
+	tests := []struct {
+		name     string
+		crop     models.CropRect
+		allow    *bool
+		kind     string // "" = no bars reported
+		severity string
+	}{
+		{"letterbox", models.CropRect{Width: 1920, Height: 800, X: 0, Y: 140}, &allow, "letterbox", models.QCSeverityInfo},
+		{"letterbox not allowed", models.CropRect{Width: 1920, Height: 800, X: 0, Y: 140}, &deny, "letterbox", models.QCSeverityError},
+		{"pillarbox", models.CropRect{Width: 1440, Height: 1080, X: 240, Y: 0}, &deny, "pillarbox", models.QCSeverityError},
+		{"windowbox counts as letterbox", models.CropRect{Width: 1440, Height: 800, X: 240, Y: 140}, nil, "letterbox", models.QCSeverityError},
+		{"edge noise below the bar minimum", models.CropRect{Width: 1904, Height: 1072, X: 8, Y: 4}, &deny, "", ""},
+		{"full frame", models.CropRect{Width: 1920, Height: 1080}, &deny, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := ResolveProfile(nil)
+			profile.AllowLetterbox = tt.allow
+			crop := tt.crop
+			report := Evaluate(&Measurements{Crop: &crop, CropSamples: 10}, profile, 1920, 1080, 60, false)
+
+			var bars []models.QCIssue
+			for _, issue := range report.Issues {
+				if issue.Type == "letterbox" || issue.Type == "pillarbox" {
+					bars = append(bars, issue)
+				}
+			}
+
+			if tt.kind == "" {
+				if len(bars) != 0 || report.Crop != nil {
+					t.Errorf("bars reported: %+v", bars)
+				}
+				return
+			}
+			if len(bars) != 1 || bars[0].Type != tt.kind || bars[0].Severity != tt.severity {
+				t.Fatalf("bar issues = %+v, want one %s %s", bars, tt.severity, tt.kind)
+			}
+			if report.Crop == nil || *report.Crop != tt.crop {
+				t.Errorf("Crop = %+v, want %+v", report.Crop, tt.crop)
+			}
+			if bars[0].StartTime != 0 || bars[0].EndTime != 60 {
+				t.Errorf("bars span %v-%v, want the whole file", bars[0].StartTime, bars[0].EndTime)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package qc
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// Detection minimums: shorter events are not reported by ffmpeg at all
+const (
+	minBlackDuration   = 0.5
+	minFreezeDuration  = 1.0
+	minSilenceDuration = 1.0
+	blackPixelLevel    = 0.10 // Luma below which a pixel is black
+	freezeNoise        = -60  // dB difference below which frames count as identical
+	cropSampleRate     = 1    // Frames per second sampled for bar detection
+)
+
+// Segment is a detected event; End is -1 while it lasts to the end of the file
+type Segment struct {
+	Start float64
+	End   float64
+}
+
+// Measurements are the raw results of the QC filters
+type Measurements struct {
+	Black   []Segment
+	Freeze  []Segment
+	Silence []Segment
+
+	HasLoudness        bool
+	IntegratedLoudness float64 // LUFS (-70 for silent audio)
+	LoudnessRange      float64 // LU
+	TruePeak           float64 // dBTP
+
+	TFF          int // Multi-frame idet counts
+	BFF          int
+	Progressive  int
+	Undetermined int
+
+	Crop        *models.CropRect // Most common cropdetect result
+	CropSamples int
+}
+
+// FilterGraph builds the ffmpeg -filter_complex graph running every QC filter in one decode
+// It returns the graph and the output labels to map (the cropdetect branch ends in a sink).
+func FilterGraph(profile models.QCProfile, hasAudio bool) (string, []string) {
+	video := fmt.Sprintf("[0:v:0]split=2[qc][bars];"+
+		"[qc]blackdetect=d=%g:pix_th=%g,freezedetect=n=%ddB:d=%g,idet[vout];"+
+		"[bars]fps=%d,cropdetect=limit=24:round=2:reset=0,nullsink",
+		minBlackDuration, blackPixelLevel, freezeNoise, minFreezeDuration, cropSampleRate)
+	if !hasAudio {
+		return video, []string{"[vout]"}
+	}
+
+	audio := fmt.Sprintf("[0:a:0]silencedetect=noise=%gdB:d=%g,ebur128=peak=true:framelog=quiet[aout]",
+		profile.SilenceThreshold, minSilenceDuration)
+	return video + ";" + audio, []string{"[vout]", "[aout]"}
+}
+
+var (
+	blackPattern        = regexp.MustCompile(`black_start:\s*(-?[\d.]+)\s+black_end:\s*(-?[\d.]+)`)
+	freezeStartPattern  = regexp.MustCompile(`freeze_start:\s*(-?[\d.]+)`)
+	freezeEndPattern    = regexp.MustCompile(`freeze_end:\s*(-?[\d.]+)`)
+	silenceStartPattern = regexp.MustCompile(`silence_start:\s*(-?[\d.]+)`)
+	silenceEndPattern   = regexp.MustCompile(`silence_end:\s*(-?[\d.]+)`)
+	loudnessPattern     = regexp.MustCompile(`^\s*I:\s*(-?[\d.]+|-inf)\s*LUFS`)
+	rangePattern        = regexp.MustCompile(`^\s*LRA:\s*(-?[\d.]+)\s*LU`)
+	peakPattern         = regexp.MustCompile(`^\s*Peak:\s*(-?[\d.]+|-inf)\s*dBFS`)
+	idetPattern         = regexp.MustCompile(`Multi frame detection:\s*TFF:\s*(\d+)\s*BFF:\s*(\d+)\s*Progressive:\s*(\d+)\s*Undetermined:\s*(\d+)`)
+	cropPattern         = regexp.MustCompile(`crop=(\d+):(\d+):(\d+):(\d+)`)
+)
+
+// ParseLog extracts the QC filter results from ffmpeg's log output
+func ParseLog(log string) *Measurements {
+	m := &Measurements{}
+	crops := make(map[models.CropRect]int)
+	inSummary := false
+
+	for _, line := range strings.Split(log, "\n") {
+		switch {
+		case blackPattern.MatchString(line):
+			match := blackPattern.FindStringSubmatch(line)
+			m.Black = append(m.Black, Segment{Start: parseFloat(match[1]), End: parseFloat(match[2])})
+
+		case freezeStartPattern.MatchString(line):
+			m.Freeze = append(m.Freeze, Segment{Start: parseFloat(freezeStartPattern.FindStringSubmatch(line)[1]), End: -1})
+
+		case freezeEndPattern.MatchString(line):
+			closeSegment(m.Freeze, parseFloat(freezeEndPattern.FindStringSubmatch(line)[1]))
+
+		case silenceStartPattern.MatchString(line):
+			m.Silence = append(m.Silence, Segment{Start: math.Max(0, parseFloat(silenceStartPattern.FindStringSubmatch(line)[1])), End: -1})
+
+		case silenceEndPattern.MatchString(line):
+			closeSegment(m.Silence, parseFloat(silenceEndPattern.FindStringSubmatch(line)[1]))
+
+		case idetPattern.MatchString(line):
+			match := idetPattern.FindStringSubmatch(line)
+			m.TFF, _ = strconv.Atoi(match[1])
+			m.BFF, _ = strconv.Atoi(match[2])
+			m.Progressive, _ = strconv.Atoi(match[3])
+			m.Undetermined, _ = strconv.Atoi(match[4])
+
+		case cropPattern.MatchString(line):
+			match := cropPattern.FindStringSubmatch(line)
+			var rect models.CropRect
+			rect.Width, _ = strconv.Atoi(match[1])
+			rect.Height, _ = strconv.Atoi(match[2])
+			rect.X, _ = strconv.Atoi(match[3])
+			rect.Y, _ = strconv.Atoi(match[4])
+			crops[rect]++
+			m.CropSamples++
+
+		case strings.Contains(line, "Summary:"):
+			// ebur128 prints its totals after a summary header
+			inSummary = true
+
+		case inSummary && loudnessPattern.MatchString(line):
+			m.IntegratedLoudness = parseLevel(loudnessPattern.FindStringSubmatch(line)[1])
+			m.HasLoudness = true
+
+		case inSummary && rangePattern.MatchString(line):
+			m.LoudnessRange = parseFloat(rangePattern.FindStringSubmatch(line)[1])
+
+		case inSummary && peakPattern.MatchString(line):
+			m.TruePeak = parseLevel(peakPattern.FindStringSubmatch(line)[1])
+		}
+	}
+
+	best := 0
+	for rect, count := range crops {
+		// Ties go to the larger picture so the result doesn't depend on map order
+		if count > best || (count == best && m.Crop != nil && rect.Width*rect.Height > m.Crop.Width*m.Crop.Height) {
+			r := rect
+			m.Crop, best = &r, count
+		}
+	}
+
+	return m
+}
+
+// closeSegment ends the last open segment
+func closeSegment(segments []Segment, end float64) {
+	for i := len(segments) - 1; i >= 0; i-- {
+		if segments[i].End < 0 {
+			segments[i].End = end
+			return
+		}
+	}
+}
+
+func parseFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+// parseLevel parses a dB/LUFS level, mapping -inf to ebur128's -70 floor
+func parseLevel(s string) float64 {
+	if s == "-inf" {
+		return -70
+	}
+	return parseFloat(s)
+}
@@ -0,0 +1,164 @@
+package qc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// Log excerpts below follow ffmpeg 6.1 stderr for the FilterGraph filters, trimmed to the
+// lines each filter prints (plus surrounding noise the parser must skip)
+
+const blackdetectLog = `Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'input.mp4':
+  Duration: 00:01:00.06, start: 0.000000, bitrate: 5012 kb/s
+[blackdetect @ 0x55d0c8a4b2c0] black_start:0 black_end:2.44 black_duration:2.44
+frame=  734 fps=245 q=-0.0 size=N/A time=00:00:24.44 bitrate=N/A speed=8.15x
+[blackdetect @ 0x55d0c8a4b2c0] black_start:58.1 black_end:60.06 black_duration:1.96
+`
+
+const freezedetectLog = `[freezedetect @ 0x5581d7a3e9c0] lavfi.freezedetect.freeze_start: 10.01
+[freezedetect @ 0x5581d7a3e9c0] lavfi.freezedetect.freeze_duration: 3.003
+[freezedetect @ 0x5581d7a3e9c0] lavfi.freezedetect.freeze_end: 13.013
+frame= 1500 fps=250 q=-0.0 size=N/A time=00:00:50.05 bitrate=N/A speed=8.34x
+[freezedetect @ 0x5581d7a3e9c0] lavfi.freezedetect.freeze_start: 57.5
+[out#0/null @ 0x5581d7a41b80] video:702kB audio:11250kB subtitle:0kB other streams:0kB global headers:0kB muxing overhead: unknown
+`
+
+const silencedetectLog = `[silencedetect @ 0x7f9c5c004a80] silence_start: -0.00133333
+[silencedetect @ 0x7f9c5c004a80] silence_end: 1.50467 | silence_duration: 1.506
+size=N/A time=00:00:29.98 bitrate=N/A speed=9.98x
+[silencedetect @ 0x7f9c5c004a80] silence_start: 30.2
+[silencedetect @ 0x7f9c5c004a80] silence_end: 32.75 | silence_duration: 2.55
+`
+
+const ebur128Log = `[Parsed_ebur128_1 @ 0x55e8c0f3c700] Summary:
+
+  Integrated loudness:
+    I:         -23.4 LUFS
+    Threshold: -33.6 LUFS
+
+  Loudness range:
+    LRA:         6.3 LU
+    Threshold: -43.5 LUFS
+    LRA low:   -27.1 LUFS
+    LRA high:  -20.8 LUFS
+
+  True peak:
+    Peak:       -1.2 dBFS
+`
+
+const ebur128SilentLog = `[Parsed_ebur128_1 @ 0x561f3a9b0cc0] Summary:
+
+  Integrated loudness:
+    I:         -70.0 LUFS
+    Threshold:   0.0 LUFS
+
+  Loudness range:
+    LRA:         0.0 LU
+    Threshold:   0.0 LUFS
+    LRA low:     0.0 LUFS
+    LRA high:    0.0 LUFS
+
+  True peak:
+    Peak:       -inf dBFS
+`
+
+const idetLog = `[Parsed_idet_3 @ 0x5612d7d2e140] Repeated Fields: Neither:  1501 Top:     0 Bottom:     0
+[Parsed_idet_3 @ 0x5612d7d2e140] Single frame detection: TFF:   312 BFF:     0 Progressive:  1078 Undetermined:   111
+[Parsed_idet_3 @ 0x5612d7d2e140] Multi frame detection: TFF:  1468 BFF:     0 Progressive:    31 Undetermined:     2
+`
+
+const cropdetectLog = `[Parsed_cropdetect_5 @ 0x55f1b8e2e6c0] x1:0 x2:1919 y1:138 y2:941 w:1920 h:800 x:0 y:140 pts:1 t:1.000000 limit:0.094118 crop=1920:800:0:140
+[Parsed_cropdetect_5 @ 0x55f1b8e2e6c0] x1:0 x2:1919 y1:134 y2:945 w:1920 h:808 x:0 y:136 pts:2 t:2.000000 limit:0.094118 crop=1920:808:0:136
+[Parsed_cropdetect_5 @ 0x55f1b8e2e6c0] x1:0 x2:1919 y1:138 y2:941 w:1920 h:800 x:0 y:140 pts:3 t:3.000000 limit:0.094118 crop=1920:800:0:140
+[Parsed_cropdetect_5 @ 0x55f1b8e2e6c0] x1:0 x2:1919 y1:138 y2:941 w:1920 h:800 x:0 y:140 pts:4 t:4.000000 limit:0.094118 crop=1920:800:0:140
+`
+
+func TestParseLog(t *testing.T) {
+	tests := []struct {
+		name string
+		log  string
+		want *Measurements
+	}{
+		{
+			name: "blackdetect",
+			log:  blackdetectLog,
+			want: &Measurements{Black: []Segment{{Start: 0, End: 2.44}, {Start: 58.1, End: 60.06}}},
+		},
+		{
+			name: "freezedetect with an unterminated freeze",
+			log:  freezedetectLog,
+			want: &Measurements{Freeze: []Segment{{Start: 10.01, End: 13.013}, {Start: 57.5, End: -1}}},
+		},
+		{
+			name: "silencedetect",
+			log:  silencedetectLog,
+			want: &Measurements{Silence: []Segment{{Start: 0, End: 1.50467}, {Start: 30.2, End: 32.75}}},
+		},
+		{
+			name: "ebur128 summary",
+			log:  ebur128Log,
+			want: &Measurements{HasLoudness: true, IntegratedLoudness: -23.4, LoudnessRange: 6.3, TruePeak: -1.2},
+		},
+		{
+			name: "ebur128 summary of silent audio",
+			log:  ebur128SilentLog,
+			want: &Measurements{HasLoudness: true, IntegratedLoudness: -70, LoudnessRange: 0, TruePeak: -70},
+		},
+		{
+			name: "idet",
+			log:  idetLog,
+			want: &Measurements{TFF: 1468, BFF: 0, Progressive: 31, Undetermined: 2},
+		},
+		{
+			name: "cropdetect",
+			log:  cropdetectLog,
+			want: &Measurements{Crop: &models.CropRect{Width: 1920, Height: 800, X: 0, Y: 140}, CropSamples: 4},
+		},
+		{
+			name: "no filter output",
+			log:  "Stream mapping:\n  Stream #0:0 (h264) -> split:default\nPress [q] to stop, [?] for help\n",
+			want: &Measurements{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseLog(tt.log); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLog() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLogCombined(t *testing.T) {
+	// All filters run in one decode, so their lines interleave
+	log := strings.Join([]string{blackdetectLog, silencedetectLog, freezedetectLog, idetLog, cropdetectLog, ebur128Log}, "")
+	m := ParseLog(log)
+
+	if len(m.Black) != 2 || len(m.Freeze) != 2 || len(m.Silence) != 2 {
+		t.Errorf("segments = %d black, %d freeze, %d silence; want 2 each", len(m.Black), len(m.Freeze), len(m.Silence))
+	}
+	if m.Freeze[1].End != -1 {
+		t.Errorf("trailing freeze closed at %v, want it open", m.Freeze[1].End)
+	}
+	if !m.HasLoudness || m.IntegratedLoudness != -23.4 || m.TruePeak != -1.2 {
+		t.Errorf("loudness = %v/%v, want -23.4 LUFS / -1.2 dBTP", m.IntegratedLoudness, m.TruePeak)
+	}
+	if m.TFF != 1468 || m.Crop == nil || m.Crop.Height != 800 {
+		t.Errorf("idet/crop = %d / %+v", m.TFF, m.Crop)
+	}
+}
+
+func TestParseLogIgnoresLevelsOutsideSummary(t *testing.T) {
+	// A loudness line before the ebur128 summary header isn't a total
+	m := ParseLog("    I:         -12.0 LUFS\n" + ebur128Log)
+	if m.IntegratedLoudness != -23.4 {
+		t.Errorf("IntegratedLoudness = %v, want the summary value -23.4", m.IntegratedLoudness)
+	}
+	if m := ParseLog("    I:         -12.0 LUFS\n"); m.HasLoudness {
+		t.Errorf("loudness read without a summary")
+	}
+}
@@ -0,0 +1,99 @@
+package qc
+
+import (
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// Profile presets
+const (
+	ProfileEBUR128   = "ebu_r128"  // European broadcast: -23 LUFS, -1 dBTP
+	ProfileATSCA85   = "atsc_a85"  // US broadcast: -24 LKFS, -2 dBTP
+	ProfileStreaming = "streaming" // Online platforms: -14 LUFS, progressive only
+)
+
+// presets are the built-in QC profiles
+var presets = map[string]models.QCProfile{
+	ProfileEBUR128: {
+		Name:               ProfileEBUR128,
+		MaxBlackDuration:   2.0,
+		MaxFreezeDuration:  2.0,
+		MaxSilenceDuration: 3.0,
+		SilenceThreshold:   -60,
+		TargetLoudness:     -23,
+		LoudnessTolerance:  1.0,
+		MaxTruePeak:        -1,
+		AllowInterlaced:    boolPtr(true),
+		AllowLetterbox:     boolPtr(true),
+	},
+	ProfileATSCA85: {
+		Name:               ProfileATSCA85,
+		MaxBlackDuration:   2.0,
+		MaxFreezeDuration:  2.0,
+		MaxSilenceDuration: 3.0,
+		SilenceThreshold:   -60,
+		TargetLoudness:     -24,
+		LoudnessTolerance:  2.0,
+		MaxTruePeak:        -2,
+		AllowInterlaced:    boolPtr(true),
+		AllowLetterbox:     boolPtr(true),
+	},
+	ProfileStreaming: {
+		Name:               ProfileStreaming,
+		MaxBlackDuration:   3.0,
+		MaxFreezeDuration:  5.0,
+		MaxSilenceDuration: 5.0,
+		SilenceThreshold:   -60,
+		TargetLoudness:     -14,
+		LoudnessTolerance:  2.0,
+		MaxTruePeak:        -1,
+		AllowInterlaced:    boolPtr(false),
+		AllowLetterbox:     boolPtr(false),
+	},
+}
+
+// ResolveProfile fills a requested profile from its preset
+// nil or an unknown name uses the EBU R128 preset.
+func ResolveProfile(requested *models.QCProfile) models.QCProfile {
+	if requested == nil {
+		return presets[ProfileEBUR128]
+	}
+
+	profile, ok := presets[requested.Name]
+	if !ok {
+		profile = presets[ProfileEBUR128]
+	}
+
+	if requested.MaxBlackDuration > 0 {
+		profile.MaxBlackDuration = requested.MaxBlackDuration
+	}
+	if requested.MaxFreezeDuration > 0 {
+		profile.MaxFreezeDuration = requested.MaxFreezeDuration
+	}
+	if requested.MaxSilenceDuration > 0 {
+		profile.MaxSilenceDuration = requested.MaxSilenceDuration
+	}
+	if requested.SilenceThreshold < 0 {
+		profile.SilenceThreshold = requested.SilenceThreshold
+	}
+	if requested.TargetLoudness < 0 {
+		profile.TargetLoudness = requested.TargetLoudness
+	}
+	if requested.LoudnessTolerance > 0 {
+		profile.LoudnessTolerance = requested.LoudnessTolerance
+	}
+	if requested.MaxTruePeak != 0 {
+		profile.MaxTruePeak = requested.MaxTruePeak
+	}
+	if requested.AllowInterlaced != nil {
+		profile.AllowInterlaced = boolPtr(*requested.AllowInterlaced)
+	}
+	if requested.AllowLetterbox != nil {
+		profile.AllowLetterbox = boolPtr(*requested.AllowLetterbox)
+	}
+
+	return profile
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
@@ -0,0 +1,83 @@
+package reframe
+
+import (
+	"math"
+	"strings"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// defaultClassWeight is the weight of classes not in the table
+const defaultClassWeight = 0.4
+
+// classWeights rank what the crop should follow: people first, then animals and vehicles
+var classWeights = map[string]float64{
+	"person":     1.0,
+	"face":       1.0,
+	"man":        1.0,
+	"woman":      1.0,
+	"child":      1.0,
+	"dog":        0.7,
+	"cat":        0.7,
+	"horse":      0.7,
+	"bird":       0.6,
+	"car":        0.5,
+	"motorcycle": 0.5,
+	"bicycle":    0.5,
+	"ball":       0.6,
+}
+
+// Observation is a subject box at a time, weighted by how much it should hold the frame
+type Observation struct {
+	Time   float64
+	Box    models.BoundingBox // Normalized, top-left origin
+	Weight float64
+}
+
+// Center returns the box centre (normalized)
+func (o Observation) Center() (float64, float64) {
+	return o.Box.X + o.Box.Width/2, o.Box.Y + o.Box.Height/2
+}
+
+// FromFrames builds observations from frame-analysis object detections
+func FromFrames(frames []models.FrameAnalysis) []Observation {
+	observations := make([]Observation, 0)
+	for _, frame := range frames {
+		for _, obj := range frame.Objects {
+			observations = append(observations, Observation{
+				Time:   frame.Timestamp,
+				Box:    obj.BoundingBox,
+				Weight: weight(obj.Label, obj.Confidence, obj.BoundingBox),
+			})
+		}
+	}
+	return observations
+}
+
+// FromTracks builds observations from tracked trajectories (denser than frame analysis)
+func FromTracks(tracks []models.ObjectTrack) []Observation {
+	observations := make([]Observation, 0)
+	for _, track := range tracks {
+		for _, point := range track.Trajectory {
+			observations = append(observations, Observation{
+				Time:   point.Timestamp,
+				Box:    point.BoundingBox,
+				Weight: weight(track.Class, point.Confidence, point.BoundingBox),
+			})
+		}
+	}
+	return observations
+}
+
+// weight scores a box by class, confidence and size (larger subjects hold the frame)
+func weight(label string, confidence float64, box models.BoundingBox) float64 {
+	classWeight := defaultClassWeight
+	if w, ok := classWeights[strings.ToLower(strings.TrimSpace(label))]; ok {
+		classWeight = w
+	}
+	if confidence <= 0 {
+		confidence = 0.5
+	}
+	area := math.Max(0, box.Width*box.Height)
+	return classWeight * confidence * math.Sqrt(area)
+}
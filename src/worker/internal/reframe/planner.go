@@ -0,0 +1,361 @@
+package reframe
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// Planner defaults
+const (
+	defaultPanSpeed  = 0.2  // Source widths (or heights) per second
+	defaultStep      = 0.2  // Seconds between keyframes
+	defaultSmoothing = 1.0  // Moving-average window (seconds)
+	defaultDeadZone  = 0.15 // Subject movement (fraction of the crop) below which a shot is locked off
+)
+
+// Plan is a crop path for one aspect ratio, exported as JSON alongside the render
+type Plan struct {
+	Format       string     `json:"format"` // "9:16", "1:1"
+	SourceWidth  int        `json:"sourceWidth"`
+	SourceHeight int        `json:"sourceHeight"`
+	CropWidth    int        `json:"cropWidth"`
+	CropHeight   int        `json:"cropHeight"`
+	Duration     float64    `json:"duration"`
+	PanSpeed     float64    `json:"panSpeed"` // Source widths per second
+	Shots        []ShotPath `json:"shots"`
+}
+
+// ShotPath is the crop window's movement within one shot
+// The window cuts with the picture at shot boundaries and moves smoothly inside a shot.
+type ShotPath struct {
+	Start     float64    `json:"start"`
+	End       float64    `json:"end"`
+	Subject   bool       `json:"subject"` // A subject was detected (otherwise the crop is centred)
+	Static    bool       `json:"static"`  // The subject barely moved so the crop is locked off
+	Keyframes []Keyframe `json:"keyframes"`
+}
+
+// Keyframe is the crop window's top-left corner (source pixels) at a time
+// Positions between keyframes are interpolated linearly.
+type Keyframe struct {
+	Time float64 `json:"time"`
+	X    int     `json:"x"`
+	Y    int     `json:"y"`
+}
+
+// Planner plans a smoothed, speed-limited crop window per shot that keeps the main subject in frame
+type Planner struct {
+	panSpeed  float64
+	step      float64
+	smoothing float64
+	deadZone  float64
+}
+
+// NewPlanner creates a planner with the default pan speed, smoothing and dead zone
+func NewPlanner() *Planner {
+	return &Planner{
+		panSpeed:  defaultPanSpeed,
+		step:      defaultStep,
+		smoothing: defaultSmoothing,
+		deadZone:  defaultDeadZone,
+	}
+}
+
+// SetPanSpeed limits how fast the crop window moves (source widths per second)
+func (p *Planner) SetPanSpeed(speed float64) {
+	if speed > 0 {
+		p.panSpeed = speed
+	}
+}
+
+// Aspect returns the width:height ratio of a social aspect ratio
+func Aspect(format string) (int, int, bool) {
+	switch format {
+	case models.AspectVertical:
+		return 9, 16, true
+	case models.AspectSquare:
+		return 1, 1, true
+	case models.AspectLandscape:
+		return 16, 9, true
+	}
+	return 0, 0, false
+}
+
+// CropSize returns the largest even-sized window of the given aspect that fits the source
+func CropSize(width, height, num, den int) (int, int) {
+	if width*den > height*num {
+		return even(height * num / den), even(height)
+	}
+	return even(width), even(width * den / num)
+}
+
+// Plan plans the crop path for one format
+// cuts are shot start times (seconds); observations may be in any order.
+func (p *Planner) Plan(format string, width, height int, duration float64, cuts []float64, observations []Observation) (*Plan, error) {
+	num, den, ok := Aspect(format)
+	if !ok {
+		return nil, fmt.Errorf("unsupported reframe format: %s", format)
+	}
+	if width <= 0 || height <= 0 || duration <= 0 {
+		return nil, fmt.Errorf("source size and duration are required")
+	}
+
+	cropW, cropH := CropSize(width, height, num, den)
+	plan := &Plan{
+		Format:       format,
+		SourceWidth:  width,
+		SourceHeight: height,
+		CropWidth:    cropW,
+		CropHeight:   cropH,
+		Duration:     duration,
+		PanSpeed:     p.panSpeed,
+		Shots:        make([]ShotPath, 0),
+	}
+
+	// Half the window in normalized source units (the centre's travel is limited to keep it inside)
+	halfW := float64(cropW) / float64(width) / 2
+	halfH := float64(cropH) / float64(height) / 2
+
+	sorted := make([]Observation, len(observations))
+	copy(sorted, observations)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time < sorted[j].Time })
+
+	for _, shot := range shotSpans(cuts, duration) {
+		path := ShotPath{Start: shot[0], End: shot[1]}
+
+		targets := subjectTargets(sorted, shot[0], shot[1], halfW)
+		if len(targets) == 0 {
+			path.Keyframes = []Keyframe{p.keyframe(shot[0], 0.5, 0.5, plan)}
+			plan.Shots = append(plan.Shots, path)
+			continue
+		}
+		path.Subject = true
+
+		// Sample the subject's centre on a regular grid through the shot
+		times := make([]float64, 0)
+		for k := 0; shot[0]+float64(k)*p.step < shot[1]; k++ {
+			times = append(times, shot[0]+float64(k)*p.step)
+		}
+		xs := make([]float64, len(times))
+		ys := make([]float64, len(times))
+		for i, t := range times {
+			xs[i], ys[i] = interpolate(targets, t)
+		}
+
+		// Lock off shots where the subject stays within the dead zone
+		if spread(xs) <= p.deadZone*2*halfW && spread(ys) <= p.deadZone*2*halfH {
+			path.Static = true
+			path.Keyframes = []Keyframe{p.keyframe(shot[0], mean(xs), mean(ys), plan)}
+			plan.Shots = append(plan.Shots, path)
+			continue
+		}
+
+		window := int(math.Round(p.smoothing / p.step))
+		xs = limitSpeed(smooth(xs, window), p.panSpeed*p.step)
+		ys = limitSpeed(smooth(ys, window), p.panSpeed*p.step)
+
+		for i, t := range times {
+			path.Keyframes = append(path.Keyframes, p.keyframe(t, xs[i], ys[i], plan))
+		}
+		path.Keyframes = dropRedundant(path.Keyframes)
+		plan.Shots = append(plan.Shots, path)
+	}
+
+	return plan, nil
+}
+
+// keyframe converts a normalized window centre to the window's top-left pixel, kept inside the frame
+func (p *Planner) keyframe(t, cx, cy float64, plan *Plan) Keyframe {
+	x := int(math.Round(cx*float64(plan.SourceWidth) - float64(plan.CropWidth)/2))
+	y := int(math.Round(cy*float64(plan.SourceHeight) - float64(plan.CropHeight)/2))
+	return Keyframe{
+		Time: t,
+		X:    clampInt(x, 0, plan.SourceWidth-plan.CropWidth),
+		Y:    clampInt(y, 0, plan.SourceHeight-plan.CropHeight),
+	}
+}
+
+// shotSpans turns shot start times into [start, end) spans covering the whole source
+func shotSpans(cuts []float64, duration float64) [][2]float64 {
+	starts := []float64{0}
+	for _, cut := range cuts {
+		if cut > starts[len(starts)-1] && cut < duration {
+			starts = append(starts, cut)
+		}
+	}
+
+	spans := make([][2]float64, 0, len(starts))
+	for i, start := range starts {
+		end := duration
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		spans = append(spans, [2]float64{start, end})
+	}
+	return spans
+}
+
+type target struct {
+	time float64
+	x, y float64
+}
+
+// subjectTargets returns, per observed moment in [start, end), the centre of the main subject:
+// the heaviest box plus any others close enough to share the crop, weighted by their own weight
+func subjectTargets(observations []Observation, start, end, halfW float64) []target {
+	targets := make([]target, 0)
+	for i := 0; i < len(observations); {
+		j := i
+		for j < len(observations) && observations[j].Time-observations[i].Time < 1e-3 {
+			j++
+		}
+		moment := observations[i:j]
+		i = j
+
+		if moment[0].Time < start || moment[0].Time >= end {
+			continue
+		}
+
+		main := moment[0]
+		for _, o := range moment[1:] {
+			if o.Weight > main.Weight {
+				main = o
+			}
+		}
+		if main.Weight <= 0 {
+			continue
+		}
+
+		mx, _ := main.Center()
+		sumW, sumX, sumY := 0.0, 0.0, 0.0
+		for _, o := range moment {
+			ox, oy := o.Center()
+			if o.Weight <= 0 || math.Abs(ox-mx) > halfW {
+				continue
+			}
+			sumW += o.Weight
+			sumX += o.Weight * ox
+			sumY += o.Weight * oy
+		}
+		targets = append(targets, target{time: moment[0].Time, x: sumX / sumW, y: sumY / sumW})
+	}
+	return targets
+}
+
+// interpolate returns the subject centre at t, holding the first/last target outside their range
+func interpolate(targets []target, t float64) (float64, float64) {
+	if t <= targets[0].time {
+		return targets[0].x, targets[0].y
+	}
+	for i := 1; i < len(targets); i++ {
+		if t <= targets[i].time {
+			a, b := targets[i-1], targets[i]
+			f := (t - a.time) / (b.time - a.time)
+			return a.x + f*(b.x-a.x), a.y + f*(b.y-a.y)
+		}
+	}
+	last := targets[len(targets)-1]
+	return last.x, last.y
+}
+
+// smooth applies a centred moving average of the given window (samples)
+func smooth(values []float64, window int) []float64 {
+	if window < 2 {
+		return values
+	}
+	half := window / 2
+	out := make([]float64, len(values))
+	for i := range values {
+		lo, hi := maxInt(0, i-half), minInt(len(values)-1, i+half)
+		sum := 0.0
+		for _, v := range values[lo : hi+1] {
+			sum += v
+		}
+		out[i] = sum / float64(hi-lo+1)
+	}
+	return out
+}
+
+// limitSpeed caps the change between samples at maxStep
+// Limiting forwards lags the subject and backwards leads it; averaging the two
+// keeps the limit while centring the pan on the subject's move.
+func limitSpeed(values []float64, maxStep float64) []float64 {
+	n := len(values)
+	if n < 2 {
+		return values
+	}
+
+	forward := make([]float64, n)
+	forward[0] = values[0]
+	for i := 1; i < n; i++ {
+		forward[i] = forward[i-1] + math.Max(-maxStep, math.Min(maxStep, values[i]-forward[i-1]))
+	}
+	backward := make([]float64, n)
+	backward[n-1] = values[n-1]
+	for i := n - 2; i >= 0; i-- {
+		backward[i] = backward[i+1] + math.Max(-maxStep, math.Min(maxStep, values[i]-backward[i+1]))
+	}
+
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = (forward[i] + backward[i]) / 2
+	}
+	return out
+}
+
+// dropRedundant removes keyframes that repeat both neighbours' positions
+func dropRedundant(keyframes []Keyframe) []Keyframe {
+	if len(keyframes) < 3 {
+		return keyframes
+	}
+	kept := []Keyframe{keyframes[0]}
+	for i := 1; i < len(keyframes)-1; i++ {
+		prev, k, next := keyframes[i-1], keyframes[i], keyframes[i+1]
+		if k.X == prev.X && k.Y == prev.Y && k.X == next.X && k.Y == next.Y {
+			continue
+		}
+		kept = append(kept, k)
+	}
+	return append(kept, keyframes[len(keyframes)-1])
+}
+
+func spread(values []float64) float64 {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	return hi - lo
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func even(n int) int {
+	return n &^ 1
+}
+
+func clampInt(v, lo, hi int) int {
+	return maxInt(lo, minInt(hi, v))
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
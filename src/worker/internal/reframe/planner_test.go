@@ -0,0 +1,169 @@
+package reframe
+
+import (
+	"math"
+	"testing"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// subjectAt returns a person-weighted observation centred on (cx, 0.5)
+func subjectAt(t, cx float64) Observation {
+	return Observation{
+		Time:   t,
+		Box:    models.BoundingBox{X: cx - 0.05, Y: 0.3, Width: 0.1, Height: 0.4},
+		Weight: 1,
+	}
+}
+
+// walk samples a subject every 0.1s over [start, end), placed by pos
+func walk(start, end float64, pos func(t float64) float64) []Observation {
+	observations := make([]Observation, 0)
+	for t := start; t < end; t += 0.1 {
+		observations = append(observations, subjectAt(t, pos(t)))
+	}
+	return observations
+}
+
+func TestPlanLimitsPanSpeed(t *testing.T) {
+	p := NewPlanner()
+	// The subject jumps across the frame halfway through the shot
+	observations := walk(0, 10, func(t float64) float64 {
+		if t < 5 {
+			return 0.2
+		}
+		return 0.8
+	})
+
+	plan, err := p.Plan(models.AspectVertical, 1920, 1080, 10, nil, observations)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if len(plan.Shots) != 1 || plan.Shots[0].Static || len(plan.Shots[0].Keyframes) < 3 {
+		t.Fatalf("shots = %+v, want one panning shot", plan.Shots)
+	}
+
+	keyframes := plan.Shots[0].Keyframes
+	for i := 1; i < len(keyframes); i++ {
+		a, k := keyframes[i-1], keyframes[i]
+		// One pixel of slack for rounding to the pixel grid
+		limit := p.panSpeed*(k.Time-a.Time)*float64(plan.SourceWidth) + 1
+		if dx := math.Abs(float64(k.X - a.X)); dx > limit {
+			t.Errorf("keyframe %d moves %.0fpx in %.1fs, limit %.1fpx", i, dx, k.Time-a.Time, limit)
+		}
+	}
+	first, last := keyframes[0], keyframes[len(keyframes)-1]
+	if first.X >= last.X {
+		t.Errorf("crop did not pan right: %d -> %d", first.X, last.X)
+	}
+}
+
+func TestLimitSpeed(t *testing.T) {
+	values := []float64{0, 0, 0, 1, 1, 1}
+	limited := limitSpeed(values, 0.25)
+	for i := 1; i < len(limited); i++ {
+		if step := math.Abs(limited[i] - limited[i-1]); step > 0.25+1e-9 {
+			t.Errorf("step %d = %v, want at most 0.25", i, step)
+		}
+	}
+	// Averaging the forward and backward passes centres the move on the jump
+	if math.Abs(limited[2]+limited[3]-1) > 1e-9 {
+		t.Errorf("pan not centred on the jump: %v", limited)
+	}
+}
+
+func TestPlanKeepsWindowInsideSource(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		pos    func(t float64) float64
+	}{
+		{"vertical at the left edge", models.AspectVertical, func(float64) float64 { return 0.01 }},
+		{"vertical at the right edge", models.AspectVertical, func(float64) float64 { return 0.99 }},
+		{"vertical sweeping edge to edge", models.AspectVertical, func(t float64) float64 { return t / 10 }},
+		{"square sweeping edge to edge", models.AspectSquare, func(t float64) float64 { return 1 - t/10 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := NewPlanner().Plan(tt.format, 1920, 1080, 10, []float64{4}, walk(0, 10, tt.pos))
+			if err != nil {
+				t.Fatalf("Plan: %v", err)
+			}
+			for _, shot := range plan.Shots {
+				for _, k := range shot.Keyframes {
+					if k.X < 0 || k.Y < 0 || k.X+plan.CropWidth > plan.SourceWidth || k.Y+plan.CropHeight > plan.SourceHeight {
+						t.Errorf("window %dx%d at (%d,%d) leaves the %dx%d source at %.1fs",
+							plan.CropWidth, plan.CropHeight, k.X, k.Y, plan.SourceWidth, plan.SourceHeight, k.Time)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPlanLocksOffStaticShots(t *testing.T) {
+	// The subject sways inside the dead zone
+	observations := walk(0, 6, func(t float64) float64 { return 0.3 + 0.02*math.Sin(t*3) })
+
+	plan, err := NewPlanner().Plan(models.AspectVertical, 1920, 1080, 6, nil, observations)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	shot := plan.Shots[0]
+	if !shot.Subject || !shot.Static || len(shot.Keyframes) != 1 {
+		t.Fatalf("shot = %+v, want one locked-off keyframe", shot)
+	}
+	if x := shot.Keyframes[0].X + plan.CropWidth/2; math.Abs(float64(x)-0.3*1920) > 0.02*1920 {
+		t.Errorf("locked-off window centred at %dpx, want near %dpx", x, int(0.3*1920))
+	}
+
+	// Without observations the crop is centred
+	plan, err = NewPlanner().Plan(models.AspectVertical, 1920, 1080, 6, nil, nil)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if shot := plan.Shots[0]; shot.Subject || len(shot.Keyframes) != 1 || shot.Keyframes[0].X != (1920-plan.CropWidth)/2 {
+		t.Errorf("shot without a subject = %+v, want a centred crop", shot)
+	}
+}
+
+func TestPlanResetsAtShotBoundaries(t *testing.T) {
+	// The subject is on the left in the first shot and on the right after the cut
+	observations := walk(0, 10, func(t float64) float64 {
+		if t < 5 {
+			return 0.2
+		}
+		return 0.8
+	})
+
+	plan, err := NewPlanner().Plan(models.AspectVertical, 1920, 1080, 10, []float64{5}, observations)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if len(plan.Shots) != 2 {
+		t.Fatalf("%d shots, want 2", len(plan.Shots))
+	}
+
+	before, after := plan.Shots[0], plan.Shots[1]
+	if before.Start != 0 || before.End != 5 || after.Start != 5 || after.End != 10 {
+		t.Errorf("spans = %v-%v, %v-%v; want 0-5, 5-10", before.Start, before.End, after.Start, after.End)
+	}
+	// Each shot frames its own subject from its first keyframe rather than panning across the cut
+	if x := before.Keyframes[0].X; x != 81 {
+		t.Errorf("first shot X = %d, want 81", x)
+	}
+	if k := after.Keyframes[0]; k.Time != 5 || k.X != 1233 {
+		t.Errorf("second shot starts at %+v, want X 1233 at 5s", k)
+	}
+}
+
+func TestPlanRejectsBadInput(t *testing.T) {
+	p := NewPlanner()
+	if _, err := p.Plan("4:5", 1920, 1080, 10, nil, nil); err == nil {
+		t.Error("unsupported format accepted")
+	}
+	if _, err := p.Plan(models.AspectSquare, 1920, 1080, 0, nil, nil); err == nil {
+		t.Error("zero duration accepted")
+	}
+}
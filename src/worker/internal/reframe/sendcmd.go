@@ -0,0 +1,69 @@
+package reframe
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// CropFilterName is the crop instance the sendcmd script drives
+const CropFilterName = "crop@reframe"
+
+// BuildFilter builds the reframe filter chain: sendcmd reading the command file,
+// the crop it drives (starting at the first keyframe) and an optional downscale
+func BuildFilter(plan *Plan, commandPath string, outWidth, outHeight int) string {
+	x, y := 0, 0
+	if len(plan.Shots) > 0 && len(plan.Shots[0].Keyframes) > 0 {
+		x, y = plan.Shots[0].Keyframes[0].X, plan.Shots[0].Keyframes[0].Y
+	}
+
+	filter := fmt.Sprintf("sendcmd=f='%s',%s=w=%d:h=%d:x=%d:y=%d",
+		strings.ReplaceAll(commandPath, "'", `'\''`), CropFilterName, plan.CropWidth, plan.CropHeight, x, y)
+	if outWidth > 0 && outHeight > 0 && (outWidth != plan.CropWidth || outHeight != plan.CropHeight) {
+		filter += fmt.Sprintf(",scale=%d:%d", outWidth, outHeight)
+	}
+	return filter
+}
+
+// BuildCommands writes the sendcmd script moving the crop along the plan
+// The window jumps at each shot start and, while panning, is moved every frame
+// (interpolating between keyframes) so the move is smooth rather than stepped.
+func BuildCommands(plan *Plan, fps float64) string {
+	if fps <= 0 {
+		fps = 25
+	}
+	frame := 1 / fps
+
+	var b strings.Builder
+	last := Keyframe{X: -1, Y: -1}
+	emit := func(t float64, x, y int) {
+		if x == last.X && y == last.Y {
+			return
+		}
+		fmt.Fprintf(&b, "%.3f %s x %d, %s y %d;\n", t, CropFilterName, x, CropFilterName, y)
+		last = Keyframe{Time: t, X: x, Y: y}
+	}
+
+	for _, shot := range plan.Shots {
+		if len(shot.Keyframes) == 0 {
+			continue
+		}
+		first := shot.Keyframes[0]
+		emit(shot.Start, first.X, first.Y)
+
+		for i := 1; i < len(shot.Keyframes); i++ {
+			a, k := shot.Keyframes[i-1], shot.Keyframes[i]
+			if a.X == k.X && a.Y == k.Y {
+				continue
+			}
+			for n := 1; a.Time+float64(n)*frame < k.Time; n++ {
+				t := a.Time + float64(n)*frame
+				f := (t - a.Time) / (k.Time - a.Time)
+				emit(t, a.X+int(math.Round(float64(k.X-a.X)*f)), a.Y+int(math.Round(float64(k.Y-a.Y)*f)))
+			}
+			emit(k.Time, k.X, k.Y)
+		}
+	}
+
+	return b.String()
+}
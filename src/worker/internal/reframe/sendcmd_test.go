@@ -0,0 +1,61 @@
+package reframe
+
+import "testing"
+
+func TestBuildCommands(t *testing.T) {
+	plan := &Plan{
+		Format:       "9:16",
+		SourceWidth:  1920,
+		SourceHeight: 1080,
+		CropWidth:    606,
+		CropHeight:   1080,
+		Duration:     3,
+		Shots: []ShotPath{
+			{Start: 0, End: 1, Subject: true, Keyframes: []Keyframe{
+				{Time: 0, X: 100},
+				{Time: 0.4, X: 140},
+				{Time: 1, X: 140},
+			}},
+			{Start: 1, End: 2, Subject: true, Static: true, Keyframes: []Keyframe{{Time: 1, X: 900}}},
+			{Start: 2, End: 3, Keyframes: []Keyframe{{Time: 2, X: 900}}},
+		},
+	}
+
+	// The pan is interpolated every frame, the hold adds nothing, the cut jumps
+	// and a shot that keeps the previous position isn't repeated
+	want := `0.000 crop@reframe x 100, crop@reframe y 0;
+0.100 crop@reframe x 110, crop@reframe y 0;
+0.200 crop@reframe x 120, crop@reframe y 0;
+0.300 crop@reframe x 130, crop@reframe y 0;
+0.400 crop@reframe x 140, crop@reframe y 0;
+1.000 crop@reframe x 900, crop@reframe y 0;
+`
+
+	if got := BuildCommands(plan, 10); got != want {
+		t.Errorf("BuildCommands() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestBuildFilter(t *testing.T) {
+	plan := &Plan{CropWidth: 606, CropHeight: 1080, Shots: []ShotPath{{Keyframes: []Keyframe{{X: 81}}}}}
+
+	tests := []struct {
+		name string
+		path string
+		outW int
+		outH int
+		want string
+	}{
+		{"crop only", "/tmp/job/cmds.txt", 606, 1080, "sendcmd=f='/tmp/job/cmds.txt',crop@reframe=w=606:h=1080:x=81:y=0"},
+		{"scaled", "/tmp/job/cmds.txt", 1080, 1920, "sendcmd=f='/tmp/job/cmds.txt',crop@reframe=w=606:h=1080:x=81:y=0,scale=1080:1920"},
+		{"quoted path", "/tmp/it's/cmds.txt", 0, 0, `sendcmd=f='/tmp/it'\''s/cmds.txt',crop@reframe=w=606:h=1080:x=81:y=0`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildFilter(plan, tt.path, tt.outW, tt.outH); got != tt.want {
+				t.Errorf("BuildFilter() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+	"github.com/adverant/nexus/videoagent-worker/internal/tracking"
+)
+
+// Overlay holds everything drawn on an annotated video (times in seconds, boxes normalized)
+type Overlay struct {
+	Width         int
+	Height        int
+	Duration      float64
+	Tracks        []models.ObjectTrack
+	TrackInterval float64 // Seconds between tracking samples (how long a box stays up)
+	Text          []TimedText
+	Subtitles     []models.SpeakerSegment
+	Scenes        []models.SceneDetection
+}
+
+// TimedText is an OCR box shown over a time range
+type TimedText struct {
+	Start       float64
+	End         float64
+	Text        string
+	BoundingBox models.BoundingBox
+}
+
+// overlayBox is a rectangle visible over a time range
+type overlayBox struct {
+	start, end float64
+	box        models.BoundingBox
+	color      color.RGBA
+	label      string
+}
+
+// maxBoxGap is the longest gap between track samples bridged by a single box (seconds)
+const maxBoxGap = 1.0
+
+// sceneMarkerDuration is how long a scene-boundary marker stays on screen (seconds)
+const sceneMarkerDuration = 1.5
+
+// OCRFromFrames turns per-frame OCR results into timed boxes
+// Each box stays up until the next analyzed frame (at most maxDuration seconds).
+func OCRFromFrames(frames []models.FrameAnalysis, maxDuration float64) []TimedText {
+	ordered := make([]models.FrameAnalysis, len(frames))
+	copy(ordered, frames)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Timestamp < ordered[j].Timestamp })
+
+	texts := make([]TimedText, 0)
+	for i, frame := range ordered {
+		end := frame.Timestamp + maxDuration
+		if i+1 < len(ordered) && ordered[i+1].Timestamp > frame.Timestamp {
+			end = math.Min(end, ordered[i+1].Timestamp)
+		}
+		for _, text := range frame.Text {
+			if strings.TrimSpace(text.Text) == "" || text.BoundingBox.Width <= 0 || text.BoundingBox.Height <= 0 {
+				continue
+			}
+			texts = append(texts, TimedText{
+				Start:       frame.Timestamp,
+				End:         end,
+				Text:        text.Text,
+				BoundingBox: text.BoundingBox,
+			})
+		}
+	}
+	return texts
+}
+
+// trackBoxes expands track samples into timed boxes
+func (o Overlay) trackBoxes() []overlayBox {
+	interval := o.TrackInterval
+	if interval <= 0 {
+		interval = 0.2
+	}
+
+	boxes := make([]overlayBox, 0)
+	for _, track := range o.Tracks {
+		c := tracking.TrackColor(track.TrackID)
+		label := track.TrackID + " " + track.Class
+		if track.IdentityID != "" {
+			label += " (" + track.IdentityID + ")"
+		}
+
+		for i, point := range track.Trajectory {
+			end := point.Timestamp + interval
+			if i+1 < len(track.Trajectory) {
+				next := track.Trajectory[i+1].Timestamp
+				if next > point.Timestamp && next-point.Timestamp <= maxBoxGap {
+					end = next
+				}
+			}
+			boxes = append(boxes, overlayBox{
+				start: point.Timestamp,
+				end:   end,
+				box:   point.BoundingBox,
+				color: c,
+				label: label,
+			})
+		}
+	}
+	return boxes
+}
+
+// sceneMarkers returns a labelled marker at the start of each scene
+func (o Overlay) sceneMarkers() []TimedText {
+	scenes := make([]models.SceneDetection, len(o.Scenes))
+	copy(scenes, o.Scenes)
+	sort.SliceStable(scenes, func(i, j int) bool { return scenes[i].StartTime < scenes[j].StartTime })
+
+	markers := make([]TimedText, 0, len(scenes))
+	for i, scene := range scenes {
+		label := fmt.Sprintf("Scene %d", i+1)
+		if scene.SceneType != "" {
+			label += " - " + scene.SceneType
+		}
+		markers = append(markers, TimedText{
+			Start: scene.StartTime,
+			End:   scene.StartTime + sceneMarkerDuration,
+			Text:  label,
+		})
+	}
+	return markers
+}
+
+// pixelRect converts a normalized box to integer pixel coordinates within the frame
+func (o Overlay) pixelRect(box models.BoundingBox) (int, int, int, int) {
+	x := clampInt(int(math.Round(box.X*float64(o.Width))), 0, o.Width-1)
+	y := clampInt(int(math.Round(box.Y*float64(o.Height))), 0, o.Height-1)
+	w := clampInt(int(math.Round(box.Width*float64(o.Width))), 1, o.Width-x)
+	h := clampInt(int(math.Round(box.Height*float64(o.Height))), 1, o.Height-y)
+	return x, y, w, h
+}
+
+// clampInt limits v to [lo, hi]
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
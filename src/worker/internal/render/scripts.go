@@ -0,0 +1,174 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// maxDrawboxFilters caps the drawbox fallback chain (ffmpeg evaluates every filter per frame)
+const maxDrawboxFilters = 4000
+
+// BuildASS generates an ASS subtitle script drawing track boxes, OCR boxes,
+// scene markers and (optionally) the transcript over the video
+func BuildASS(overlay Overlay, includeSubtitles bool) string {
+	var b strings.Builder
+
+	// Step 1: Header (script resolution equals video resolution so coordinates are pixels)
+	fmt.Fprintf(&b, "[Script Info]\nScriptType: v4.00+\nPlayResX: %d\nPlayResY: %d\nWrapStyle: 0\nScaledBorderAndShadow: yes\n\n",
+		overlay.Width, overlay.Height)
+
+	fontSize := maxFloat(12, float64(overlay.Height)/36)
+	b.WriteString("[V4+ Styles]\n")
+	b.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	fmt.Fprintf(&b, "Style: Box,Arial,%d,&HFF000000,&HFF000000,&H0000FF00,&HFF000000,0,0,0,0,100,100,0,0,1,2,0,7,0,0,0,1\n", int(fontSize))
+	fmt.Fprintf(&b, "Style: Label,Arial,%d,&H00FFFFFF,&H00FFFFFF,&H00000000,&H80000000,1,0,0,0,100,100,0,0,3,1,0,1,0,0,0,1\n", int(fontSize*0.7))
+	fmt.Fprintf(&b, "Style: OCR,Arial,%d,&H0000FFFF,&H0000FFFF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,3,1,0,1,0,0,0,1\n", int(fontSize*0.6))
+	fmt.Fprintf(&b, "Style: Subtitle,Arial,%d,&H00FFFFFF,&H00FFFFFF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,1,2,20,20,%d,1\n", int(fontSize), int(fontSize))
+	fmt.Fprintf(&b, "Style: Scene,Arial,%d,&H00FFFFFF,&H00FFFFFF,&H00000000,&HA0000000,1,0,0,0,100,100,0,0,3,2,0,9,20,20,20,1\n\n", int(fontSize*0.8))
+
+	b.WriteString("[Events]\nFormat: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+
+	// Step 2: Track boxes with labels in the track colour
+	for _, box := range overlay.trackBoxes() {
+		x, y, w, h := overlay.pixelRect(box.box)
+		writeASSBox(&b, box.start, box.end, x, y, w, h, box.color)
+		fmt.Fprintf(&b, "Dialogue: 1,%s,%s,Label,,0,0,0,,{\\an1\\pos(%d,%d)\\3c%s\\4c%s}%s\n",
+			assTime(box.start), assTime(box.end), x, y, assColor(box.color), assColor(box.color), escapeASS(box.label))
+	}
+
+	// Step 3: OCR boxes with the recognized text
+	ocrColor := color.RGBA{255, 255, 0, 255}
+	for _, text := range overlay.Text {
+		x, y, w, h := overlay.pixelRect(text.BoundingBox)
+		writeASSBox(&b, text.Start, text.End, x, y, w, h, ocrColor)
+		fmt.Fprintf(&b, "Dialogue: 1,%s,%s,OCR,,0,0,0,,{\\an7\\pos(%d,%d)}%s\n",
+			assTime(text.Start), assTime(text.End), x, y+h, escapeASS(text.Text))
+	}
+
+	// Step 4: Scene-boundary markers (top right)
+	for _, marker := range overlay.sceneMarkers() {
+		fmt.Fprintf(&b, "Dialogue: 2,%s,%s,Scene,,0,0,0,,%s\n",
+			assTime(marker.Start), assTime(marker.End), escapeASS(marker.Text))
+	}
+
+	// Step 5: Transcript
+	if includeSubtitles {
+		for _, segment := range orderedSegments(overlay.Subtitles) {
+			fmt.Fprintf(&b, "Dialogue: 3,%s,%s,Subtitle,,0,0,0,,%s\n",
+				assTime(segment.StartTime), assTime(segment.EndTime), escapeASS(subtitleText(segment)))
+		}
+	}
+
+	return b.String()
+}
+
+// writeASSBox writes an unfilled rectangle as an ASS vector drawing
+func writeASSBox(b *strings.Builder, start, end float64, x, y, w, h int, c color.RGBA) {
+	fmt.Fprintf(b, "Dialogue: 0,%s,%s,Box,,0,0,0,,{\\an7\\pos(%d,%d)\\1a&HFF&\\3c%s\\p1}m 0 0 l %d 0 %d %d 0 %d{\\p0}\n",
+		assTime(start), assTime(end), x, y, assColor(c), w, w, h, h)
+}
+
+// BuildDrawboxScript generates a drawbox filter chain for ffmpeg builds without libass
+// Only boxes and a scene-change bar are drawn; text needs libass or drawtext fonts.
+func BuildDrawboxScript(overlay Overlay) string {
+	filters := make([]string, 0)
+
+	for _, box := range overlay.trackBoxes() {
+		x, y, w, h := overlay.pixelRect(box.box)
+		filters = append(filters, fmt.Sprintf("drawbox=x=%d:y=%d:w=%d:h=%d:color=0x%02X%02X%02X:t=3:enable='between(t,%.3f,%.3f)'",
+			x, y, w, h, box.color.R, box.color.G, box.color.B, box.start, box.end))
+	}
+	for _, text := range overlay.Text {
+		x, y, w, h := overlay.pixelRect(text.BoundingBox)
+		filters = append(filters, fmt.Sprintf("drawbox=x=%d:y=%d:w=%d:h=%d:color=yellow:t=2:enable='between(t,%.3f,%.3f)'",
+			x, y, w, h, text.Start, text.End))
+	}
+	for _, marker := range overlay.sceneMarkers() {
+		filters = append(filters, fmt.Sprintf("drawbox=x=0:y=0:w=iw:h=12:color=white@0.8:t=fill:enable='between(t,%.3f,%.3f)'",
+			marker.Start, marker.End))
+	}
+
+	if len(filters) > maxDrawboxFilters {
+		log.Printf("Warning: drawbox overlay truncated to %d of %d boxes (ffmpeg with libass draws them all)",
+			maxDrawboxFilters, len(filters))
+		filters = filters[:maxDrawboxFilters]
+	}
+	if len(filters) == 0 {
+		return "null"
+	}
+	return strings.Join(filters, ",\n")
+}
+
+// BuildSRT generates an SRT subtitle file from the transcript
+func BuildSRT(segments []models.SpeakerSegment) string {
+	var b strings.Builder
+	index := 1
+	for _, segment := range orderedSegments(segments) {
+		text := strings.TrimSpace(subtitleText(segment))
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", index, srtTime(segment.StartTime), srtTime(segment.EndTime), text)
+		index++
+	}
+	return b.String()
+}
+
+// orderedSegments returns non-empty transcript segments ordered by start time
+func orderedSegments(segments []models.SpeakerSegment) []models.SpeakerSegment {
+	ordered := make([]models.SpeakerSegment, 0, len(segments))
+	for _, segment := range segments {
+		if strings.TrimSpace(segment.Text) != "" && segment.EndTime > segment.StartTime {
+			ordered = append(ordered, segment)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].StartTime < ordered[j].StartTime })
+	return ordered
+}
+
+// subtitleText formats a transcript segment, prefixed by the speaker when known
+func subtitleText(segment models.SpeakerSegment) string {
+	if segment.SpeakerID == "" {
+		return segment.Text
+	}
+	return segment.SpeakerID + ": " + segment.Text
+}
+
+// assTime formats seconds as H:MM:SS.cc
+func assTime(seconds float64) string {
+	cs := int(math.Round(math.Max(0, seconds) * 100))
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, cs/6000%60, cs/100%60, cs%100)
+}
+
+// srtTime formats seconds as HH:MM:SS,mmm
+func srtTime(seconds float64) string {
+	ms := int(math.Round(math.Max(0, seconds) * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// assColor formats a colour as an ASS &HBBGGRR& override
+func assColor(c color.RGBA) string {
+	return fmt.Sprintf("&H%02X%02X%02X&", c.B, c.G, c.R)
+}
+
+// escapeASS neutralizes override blocks and line breaks in dialogue text
+func escapeASS(text string) string {
+	text = strings.ReplaceAll(text, "\\", "\uFF3C") // No escape exists for a literal backslash
+	text = strings.ReplaceAll(text, "{", "(")
+	text = strings.ReplaceAll(text, "}", ")")
+	text = strings.ReplaceAll(text, "\r", "")
+	return strings.ReplaceAll(text, "\n", "\\N")
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"image"
 	"log"
 	"math"
 	"time"
@@ -59,6 +60,7 @@ type CameraMovement struct {
 	Trajectory        []TrajectoryPoint      `json:"trajectory,omitempty"`
 	Stabilization     StabilizationAnalysis  `json:"stabilization"`
 	Shake             ShakeAnalysis          `json:"shake"`
+	Measurement       *MotionMeasurement     `json:"measurement,omitempty"` // Set when measured from frames
 	Confidence        float64                `json:"confidence"`
 	Attributes        map[string]interface{} `json:"attributes"`
 	Timestamp         time.Time              `json:"timestamp"`
@@ -106,6 +108,7 @@ type MovementHistory struct {
 // CameraMovementDetector detects and analyzes camera movement
 type CameraMovementDetector struct {
 	mageAgent *clients.MageAgentClient
+	estimator *MotionEstimator
 	history   *MovementHistory
 }
 
@@ -113,6 +116,7 @@ type CameraMovementDetector struct {
 func NewCameraMovementDetector(mageAgent *clients.MageAgentClient) *CameraMovementDetector {
 	return &CameraMovementDetector{
 		mageAgent: mageAgent,
+		estimator: NewMotionEstimator(),
 		history: &MovementHistory{
 			Movements:  make([]CameraMovement, 0),
 			MaxHistory: 30, // Keep last 30 frames
@@ -121,6 +125,8 @@ func NewCameraMovementDetector(mageAgent *clients.MageAgentClient) *CameraMoveme
 }
 
 // DetectMovement detects camera movement in a frame
+// A still frame only hints at movement (motion blur, framing); use MeasureMovement when
+// consecutive frames are available.
 func (cmd *CameraMovementDetector) DetectMovement(ctx context.Context, frameData string) (*CameraMovement, error) {
 	startTime := time.Now()
 
@@ -206,17 +212,28 @@ Respond with JSON format:
 	}
 
 	movement.Timestamp = startTime
+	cmd.record(movement)
 
-	// Add to history
-	cmd.addToHistory(movement)
+	log.Printf("Detected camera movement: %s (speed: %s, smoothness: %s, confidence: %.2f)",
+		movement.PrimaryMovement, movement.Speed, movement.Smoothness, movement.Confidence)
 
-	// Enhance with trajectory analysis if we have history
-	if len(cmd.history.Movements) > 1 {
-		movement.Trajectory = cmd.computeTrajectory()
+	return movement, nil
+}
+
+// MeasureMovement measures camera movement from consecutive grayscale frames taken at
+// timestamps (seconds), without a vision request
+func (cmd *CameraMovementDetector) MeasureMovement(frames []*image.Gray, timestamps []float64) (*CameraMovement, error) {
+	movement, err := cmd.estimator.EstimateMovement(frames, timestamps)
+	if err != nil {
+		return nil, fmt.Errorf("motion estimation failed: %w", err)
 	}
 
-	log.Printf("Detected camera movement: %s (speed: %s, smoothness: %s, confidence: %.2f)",
-		movement.PrimaryMovement, movement.Speed, movement.Smoothness, movement.Confidence)
+	// The measured trajectory is kept; history only feeds statistics and change detection
+	cmd.addToHistory(movement)
+
+	log.Printf("Measured camera movement: %s (pan %.3f, tilt %.3f, zoom %.3f per second, jitter %.4f)",
+		movement.PrimaryMovement, movement.Measurement.PanRate, movement.Measurement.TiltRate,
+		movement.Measurement.ZoomRate, movement.Measurement.Jitter)
 
 	return movement, nil
 }
@@ -318,6 +335,16 @@ func (cmd *CameraMovementDetector) fallbackMovementDetection(frameData string) *
 	return movement
 }
 
+// record adds movement to history and fills its trajectory from the history
+func (cmd *CameraMovementDetector) record(movement *CameraMovement) {
+	cmd.addToHistory(movement)
+
+	// Enhance with trajectory analysis if we have history
+	if len(cmd.history.Movements) > 1 {
+		movement.Trajectory = cmd.computeTrajectory()
+	}
+}
+
 // addToHistory adds movement to history and maintains max size
 func (cmd *CameraMovementDetector) addToHistory(movement *CameraMovement) {
 	cmd.history.Movements = append(cmd.history.Movements, *movement)
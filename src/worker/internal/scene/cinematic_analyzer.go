@@ -0,0 +1,281 @@
+package scene
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/clients"
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// CinematicAnalyzer runs scene classification, shot composition, camera movement and
+// colour/lighting/mood analysis over a scene's keyframes and aggregates the results
+type CinematicAnalyzer struct {
+	mageAgent *clients.MageAgentClient
+	combined  *CombinedAnalyzer
+}
+
+// NewCinematicAnalyzer creates a new cinematic analyzer
+func NewCinematicAnalyzer(mageAgent *clients.MageAgentClient) *CinematicAnalyzer {
+	return &CinematicAnalyzer{
+		mageAgent: mageAgent,
+		combined:  NewCombinedAnalyzer(mageAgent),
+	}
+}
+
+// AnalyzeScene analyzes one scene from its keyframes (base64 JPEG, in time order)
+// measured is the camera movement measured from consecutive frames (see MeasureMovement)
+// and replaces the camera section of the vision requests; when nil the camera is judged
+// from the keyframes by the vision model.
+// Each keyframe takes one combined vision request; keyframes whose request fails are
+// skipped and an error is returned only when all fail.
+func (ca *CinematicAnalyzer) AnalyzeScene(ctx context.Context, keyframes []string, keyframeTimes []float64, measured *CameraMovement) (*models.SceneCinematics, error) {
+	if len(keyframes) == 0 {
+		return nil, fmt.Errorf("no keyframes")
+	}
+
+	// Camera movement keeps a per-detector history, so each scene gets its own
+	var camera *CameraMovementDetector
+	if measured == nil {
+		camera = NewCameraMovementDetector(ca.mageAgent)
+	}
+
+	classifications := make([]*SceneClassification, 0, len(keyframes))
+	compositions := make([]*ShotComposition, 0, len(keyframes))
+	movements := make([]*CameraMovement, 0, len(keyframes))
+	looks := make([]*ColorLightingMood, 0, len(keyframes))
+
+	for i, frameData := range keyframes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var analysis *FrameCinematics
+		var err error
+		if measured != nil {
+			analysis, err = ca.combined.AnalyzeFrameMeasured(ctx, frameData)
+		} else {
+			analysis, err = ca.combined.AnalyzeFrame(ctx, frameData, camera)
+		}
+		if err != nil {
+			log.Printf("Warning: cinematic analysis failed for keyframe %d: %v", i, err)
+			continue
+		}
+
+		classifications = append(classifications, analysis.Classification)
+		compositions = append(compositions, analysis.Composition)
+		if analysis.Movement != nil {
+			movements = append(movements, analysis.Movement)
+		}
+		looks = append(looks, analysis.Look)
+	}
+
+	if len(classifications)+len(compositions)+len(movements)+len(looks) == 0 {
+		return nil, fmt.Errorf("all cinematic analyses failed")
+	}
+
+	cinematics := &models.SceneCinematics{
+		KeyframeTimes:  keyframeTimes,
+		Classification: aggregateClassifications(classifications),
+		Composition:    aggregateCompositions(compositions),
+		Look:           aggregateLooks(looks),
+	}
+	if measured != nil {
+		cinematics.Camera = measuredCamera(measured)
+	} else {
+		cinematics.Camera = aggregateMovements(movements)
+	}
+
+	return cinematics, nil
+}
+
+// aggregateClassifications takes the most common scene type and setting details
+func aggregateClassifications(classifications []*SceneClassification) models.CinematicClassification {
+	types, settings, times, weather, locations := newVote(), newVote(), newVote(), newVote(), newVote()
+	confidence := 0.0
+	for _, c := range classifications {
+		types.add(string(c.PrimaryType), c.Confidence)
+		settings.add(c.Setting, c.Confidence)
+		times.add(c.TimeOfDay, c.Confidence)
+		weather.add(c.Weather, c.Confidence)
+		locations.add(c.LocationType, c.Confidence)
+		confidence += c.Confidence
+	}
+
+	return models.CinematicClassification{
+		SceneType:    types.winner(string(SceneTypeUnknown)),
+		Setting:      settings.winner(""),
+		TimeOfDay:    times.winner("unknown"),
+		Weather:      weather.winner(""),
+		LocationType: locations.winner(""),
+		Confidence:   mean(confidence, len(classifications)),
+	}
+}
+
+// aggregateCompositions takes the most common framing; techniques hold when most keyframes use them
+func aggregateCompositions(compositions []*ShotComposition) models.CinematicComposition {
+	sizes, angles, depths, balances := newVote(), newVote(), newVote(), newVote()
+	thirds, lines, symmetry := 0, 0, 0
+	confidence := 0.0
+	for _, c := range compositions {
+		sizes.add(string(c.ShotSize), c.Confidence)
+		angles.add(string(c.ShotAngle), c.Confidence)
+		depths.add(c.Depth, c.Confidence)
+		balances.add(c.Balance, c.Confidence)
+		if c.RuleOfThirds {
+			thirds++
+		}
+		if c.LeadingLines {
+			lines++
+		}
+		if c.Symmetry {
+			symmetry++
+		}
+		confidence += c.Confidence
+	}
+
+	n := len(compositions)
+	return models.CinematicComposition{
+		ShotSize:     sizes.winner(string(ShotSizeUnknown)),
+		ShotAngle:    angles.winner(string(ShotAngleUnknown)),
+		RuleOfThirds: n > 0 && thirds*2 > n,
+		LeadingLines: n > 0 && lines*2 > n,
+		Symmetry:     n > 0 && symmetry*2 > n,
+		Depth:        depths.winner(""),
+		Balance:      balances.winner(""),
+		Confidence:   mean(confidence, n),
+	}
+}
+
+// aggregateMovements takes the most common movement; the scene counts as stabilized
+// when most keyframes are
+func aggregateMovements(movements []*CameraMovement) models.CinematicCamera {
+	types, speeds, smoothness, shake := newVote(), newVote(), newVote(), newVote()
+	stabilized := 0
+	confidence := 0.0
+	for _, m := range movements {
+		types.add(string(m.PrimaryMovement), m.Confidence)
+		speeds.add(string(m.Speed), m.Confidence)
+		smoothness.add(string(m.Smoothness), m.Confidence)
+		shake.add(m.Shake.Intensity, m.Confidence)
+		if m.Stabilization.IsStabilized {
+			stabilized++
+		}
+		confidence += m.Confidence
+	}
+
+	n := len(movements)
+	return models.CinematicCamera{
+		Movement:   types.winner(string(MovementStatic)),
+		Speed:      speeds.winner(string(SpeedStill)),
+		Smoothness: smoothness.winner(""),
+		Stabilized: n > 0 && stabilized*2 > n,
+		Shake:      shake.winner("none"),
+		Confidence: mean(confidence, n),
+	}
+}
+
+// measuredCamera describes a camera movement measured from frames
+func measuredCamera(movement *CameraMovement) models.CinematicCamera {
+	camera := models.CinematicCamera{
+		Movement:   string(movement.PrimaryMovement),
+		Speed:      string(movement.Speed),
+		Smoothness: string(movement.Smoothness),
+		Stabilized: movement.Stabilization.IsStabilized,
+		Shake:      movement.Shake.Intensity,
+		Confidence: movement.Confidence,
+	}
+	if m := movement.Measurement; m != nil {
+		camera.Measured = true
+		camera.PanRate = m.PanRate
+		camera.TiltRate = m.TiltRate
+		camera.ZoomRate = m.ZoomRate
+		camera.Jitter = m.Jitter
+	}
+	return camera
+}
+
+// aggregateLooks takes the most common grading, lighting and mood and averages levels
+func aggregateLooks(looks []*ColorLightingMood) models.CinematicLook {
+	gradings, temperatures, setups, qualities, moods, colors := newVote(), newVote(), newVote(), newVote(), newVote(), newVote()
+	saturation, contrast, intensity, confidence := 0.0, 0.0, 0.0, 0.0
+	for _, l := range looks {
+		gradings.add(string(l.ColorGrading), l.Confidence)
+		temperatures.add(string(l.ColorTemperature), l.Confidence)
+		setups.add(string(l.LightingSetup), l.Confidence)
+		qualities.add(l.LightingQuality, l.Confidence)
+		moods.add(string(l.PrimaryMood), l.Confidence)
+		for _, color := range l.DominantColors {
+			colors.add(color, 1)
+		}
+		saturation += l.Saturation.Value
+		contrast += l.Contrast.Value
+		intensity += l.MoodIntensity
+		confidence += l.Confidence
+	}
+
+	n := len(looks)
+	return models.CinematicLook{
+		ColorGrading:     gradings.winner(string(GradingNatural)),
+		ColorTemperature: temperatures.winner(string(TempNeutral)),
+		DominantColors:   colors.top(5),
+		Saturation:       mean(saturation, n),
+		Contrast:         mean(contrast, n),
+		LightingSetup:    setups.winner(string(LightingNatural)),
+		LightingQuality:  qualities.winner(""),
+		Mood:             moods.winner(string(MoodNeutral)),
+		MoodIntensity:    mean(intensity, n),
+		Confidence:       mean(confidence, n),
+	}
+}
+
+// vote counts confidence-weighted votes for categorical values
+type vote struct {
+	weights map[string]float64
+	order   []string // First-seen order breaks ties
+}
+
+func newVote() *vote {
+	return &vote{weights: make(map[string]float64)}
+}
+
+// add votes for a value (empty and "unknown" values are ignored)
+func (v *vote) add(value string, weight float64) {
+	if value == "" || value == "unknown" {
+		return
+	}
+	if _, seen := v.weights[value]; !seen {
+		v.order = append(v.order, value)
+	}
+	// Zero-confidence results still count a little
+	v.weights[value] += weight + 0.01
+}
+
+// winner returns the value with the most weight, or fallback when nothing was voted for
+func (v *vote) winner(fallback string) string {
+	top := v.top(1)
+	if len(top) == 0 {
+		return fallback
+	}
+	return top[0]
+}
+
+// top returns up to n values by weight
+func (v *vote) top(n int) []string {
+	values := append([]string{}, v.order...)
+	sort.SliceStable(values, func(i, j int) bool { return v.weights[values[i]] > v.weights[values[j]] })
+	if len(values) > n {
+		values = values[:n]
+	}
+	return values
+}
+
+// mean returns sum/n, or 0 when n is 0
+func mean(sum float64, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/adverant/nexus/videoagent-worker/internal/clients"
+	"github.com/adverant/nexus/videoagent-worker/internal/imagestats"
 	"github.com/adverant/nexus/videoagent-worker/internal/models"
 )
 
@@ -304,6 +305,7 @@ Respond with JSON format:
 	}
 
 	analysis.Timestamp = startTime
+	applyMeasuredLook(analysis, frameData)
 
 	log.Printf("Analyzed color/lighting/mood: grading=%s, lighting=%s, mood=%s (confidence: %.2f)",
 		analysis.ColorGrading, analysis.LightingSetup, analysis.PrimaryMood, analysis.Confidence)
@@ -400,6 +402,51 @@ func (clma *ColorLightingMoodAnalyzer) parseAnalysisResponse(response string) (*
 	return analysis, nil
 }
 
+// applyMeasuredLook replaces the model's colour, saturation, contrast and temperature
+// readings with statistics measured from the frame (left unchanged if it can't be decoded)
+func applyMeasuredLook(analysis *ColorLightingMood, frameData string) {
+	stats, err := imagestats.AnalyzeBase64(frameData)
+	if err != nil {
+		log.Printf("Warning: failed to measure frame statistics: %v", err)
+		return
+	}
+
+	analysis.DominantColors = stats.ColorNames()
+	analysis.ColorPalette = make([]string, 0, len(stats.DominantColors))
+	for _, c := range stats.DominantColors {
+		analysis.ColorPalette = append(analysis.ColorPalette, c.Hex)
+	}
+	analysis.ColorTemperature = ColorTemperature(stats.TemperatureClass)
+
+	analysis.Saturation.Value = stats.Saturation
+	analysis.Saturation.Level = measuredLevel(stats.Saturation)
+	analysis.Contrast.Value = stats.Contrast
+	analysis.Contrast.Level = measuredLevel(stats.Contrast)
+
+	if analysis.Attributes == nil {
+		analysis.Attributes = make(map[string]interface{})
+	}
+	analysis.Attributes["measured"] = true
+	analysis.Attributes["colorTemperatureKelvin"] = stats.ColorTemperature
+	analysis.Attributes["luminance"] = stats.Luminance
+}
+
+// measuredLevel grades a 0-1 measurement as very_low .. very_high
+func measuredLevel(value float64) string {
+	switch {
+	case value < 0.15:
+		return "very_low"
+	case value < 0.35:
+		return "low"
+	case value < 0.55:
+		return "medium"
+	case value < 0.75:
+		return "high"
+	default:
+		return "very_high"
+	}
+}
+
 // fallbackAnalysis provides basic analysis when AI parsing fails
 func (clma *ColorLightingMoodAnalyzer) fallbackAnalysis(frameData string) *ColorLightingMood {
 	return &ColorLightingMood{
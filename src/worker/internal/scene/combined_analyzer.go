@@ -0,0 +1,349 @@
+package scene
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/clients"
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// Sections of a combined analysis
+const (
+	SectionScene       = "scene"
+	SectionComposition = "composition"
+	SectionCamera      = "camera"
+	SectionLook        = "look"
+)
+
+// FrameCinematics is the combined scene, composition, camera and colour/lighting/mood
+// analysis of one frame
+type FrameCinematics struct {
+	Classification *SceneClassification `json:"classification"`
+	Composition    *ShotComposition     `json:"composition"`
+	Movement       *CameraMovement      `json:"movement"`
+	Look           *ColorLightingMood   `json:"look"`
+	Fallbacks      []string             `json:"fallbacks,omitempty"` // Sections not taken from the combined response
+}
+
+// CombinedAnalyzer analyzes all four aspects of a frame with a single vision request
+// Sections missing or invalid in the response are filled by the dedicated analyzer
+// (one extra request for that section only) or, when disabled or failing, by its heuristic fallback.
+type CombinedAnalyzer struct {
+	mageAgent         *clients.MageAgentClient
+	classifier        *SceneClassifier
+	composition       *ShotCompositionAnalyzer
+	look              *ColorLightingMoodAnalyzer
+	dedicatedFallback bool
+}
+
+// NewCombinedAnalyzer creates a new combined analyzer
+func NewCombinedAnalyzer(mageAgent *clients.MageAgentClient) *CombinedAnalyzer {
+	return &CombinedAnalyzer{
+		mageAgent:         mageAgent,
+		classifier:        NewSceneClassifier(mageAgent),
+		composition:       NewShotCompositionAnalyzer(mageAgent),
+		look:              NewColorLightingMoodAnalyzer(mageAgent),
+		dedicatedFallback: true,
+	}
+}
+
+// SetDedicatedFallback sets whether invalid sections are re-requested with the dedicated prompt
+// When disabled, invalid sections use the heuristic fallback and no extra requests are made.
+func (ca *CombinedAnalyzer) SetDedicatedFallback(enabled bool) {
+	ca.dedicatedFallback = enabled
+}
+
+// promptSection is one section of the combined prompt: what to judge and its JSON shape
+type promptSection struct {
+	key          string
+	instructions string
+	example      string
+}
+
+var promptSections = []promptSection{
+	{
+		key: SectionScene,
+		instructions: `SCENE
+   - primaryType: interior, exterior, action, dialogue, establishing, montage, transition, cutaway
+   - setting: specific location (e.g., "modern office", "city street")
+   - timeOfDay: morning, afternoon, evening, night, unknown
+   - weather (if exterior): sunny, cloudy, rainy, snowy, unknown
+   - locationType: residential, commercial, industrial, natural, urban, rural, etc.`,
+		example: `  "scene": {
+    "primaryType": "...",
+    "setting": "...",
+    "timeOfDay": "...",
+    "weather": "...",
+    "locationType": "...",
+    "confidence": 0.0
+  }`,
+	},
+	{
+		key: SectionComposition,
+		instructions: `COMPOSITION
+   - shotSize: extreme_close_up, close_up, medium_close_up, medium_shot, medium_wide, wide_shot, extreme_wide
+   - shotAngle: eye_level, high_angle, low_angle, birds_eye, dutch
+   - ruleOfThirds, leadingLines, symmetry: true/false
+   - depth: shallow, moderate, deep
+   - focalPoint, subjectPlacement, balance (balanced, unbalanced, asymmetric)`,
+		example: `  "composition": {
+    "shotSize": "...",
+    "shotAngle": "...",
+    "ruleOfThirds": false,
+    "leadingLines": false,
+    "symmetry": false,
+    "depth": "...",
+    "focalPoint": "...",
+    "subjectPlacement": "...",
+    "balance": "...",
+    "confidence": 0.0
+  }`,
+	},
+	{
+		key: SectionCamera,
+		instructions: `CAMERA (as far as visible from motion blur, framing and perspective)
+   - primaryMovement: static, pan, tilt, zoom, dolly, tracking, crane, handheld
+   - speed: still, very_slow, slow, medium, fast, very_fast
+   - smoothness: very_smooth, smooth, medium, jerky, very_jerky
+   - stabilization and shake`,
+		example: `  "camera": {
+    "primaryMovement": "...",
+    "speed": "...",
+    "smoothness": "...",
+    "direction": {"horizontal": "left|right|none", "vertical": "up|down|none", "depth": "in|out|none", "angle": 0},
+    "stabilization": {"isStabilized": true, "quality": "...", "method": "..."},
+    "shake": {"hasShake": false, "intensity": "none|minimal|moderate|significant|extreme", "frequency": "...", "intentional": false},
+    "confidence": 0.0
+  }`,
+	},
+	{
+		key: SectionLook,
+		instructions: `LOOK (colours, saturation, contrast and colour temperature are measured separately)
+   - colorGrading: natural, warm, cool, desaturated, vibrant, high_contrast, low_contrast, monochrome, sepia, cinematic
+   - lightingSetup: three_point, natural, practical, hard_light, soft_light, backlight, rim_light, side_light, low_key, high_key, silhouette, chiaroscuro
+   - lightingDirection, lightingQuality (hard, soft, mixed)
+   - primaryMood: tense, peaceful, energetic, melancholy, hopeful, ominous, joyful, mysterious, romantic, nostalgic, surreal, neutral
+   - moodIntensity: 0.0-1.0`,
+		example: `  "look": {
+    "colorGrading": "...",
+    "lightingSetup": "...",
+    "lightingDirection": "...",
+    "lightingQuality": "...",
+    "primaryMood": "...",
+    "moodIntensity": 0.0,
+    "emotionalTone": "...",
+    "confidence": 0.0
+  }`,
+	},
+}
+
+var (
+	combinedPrompt = buildCombinedPrompt(true)
+	// measuredPrompt leaves out the camera, which was measured from consecutive frames
+	measuredPrompt = buildCombinedPrompt(false)
+)
+
+// buildCombinedPrompt numbers the requested sections and joins their JSON shapes
+func buildCombinedPrompt(includeCamera bool) string {
+	var instructions, examples []string
+	for _, section := range promptSections {
+		if section.key == SectionCamera && !includeCamera {
+			continue
+		}
+		instructions = append(instructions, fmt.Sprintf("%d. %s", len(instructions)+1, section.instructions))
+		examples = append(examples, section.example)
+	}
+
+	return "Analyze this video frame as a cinematographer. Answer every section.\n\n" +
+		strings.Join(instructions, "\n\n") +
+		"\n\nGive each section its own confidence (0.0-1.0). Respond with JSON only:\n{\n" +
+		strings.Join(examples, ",\n") +
+		"\n}"
+}
+
+// AnalyzeFrame analyzes a frame (base64 JPEG) with one vision request
+// camera carries the movement history of the shot; nil analyzes the frame on its own.
+// An error is returned only when the vision request itself fails.
+func (ca *CombinedAnalyzer) AnalyzeFrame(ctx context.Context, frameData string, camera *CameraMovementDetector) (*FrameCinematics, error) {
+	if camera == nil {
+		camera = NewCameraMovementDetector(ca.mageAgent)
+	}
+	return ca.analyzeFrame(ctx, frameData, camera)
+}
+
+// AnalyzeFrameMeasured analyzes a frame whose camera movement was measured from
+// consecutive frames: the camera section is neither requested nor re-requested, and
+// the result has no Movement
+func (ca *CombinedAnalyzer) AnalyzeFrameMeasured(ctx context.Context, frameData string) (*FrameCinematics, error) {
+	return ca.analyzeFrame(ctx, frameData, nil)
+}
+
+// analyzeFrame runs the combined request; a nil camera leaves out the camera section
+func (ca *CombinedAnalyzer) analyzeFrame(ctx context.Context, frameData string, camera *CameraMovementDetector) (*FrameCinematics, error) {
+	startTime := time.Now()
+
+	prompt := combinedPrompt
+	if camera == nil {
+		prompt = measuredPrompt
+	}
+	visionReq := models.MageAgentVisionRequest{
+		Image:     frameData,
+		Prompt:    prompt,
+		MaxTokens: 2000,
+	}
+
+	visionResp, err := ca.mageAgent.AnalyzeFrame(ctx, visionReq)
+	if err != nil {
+		return nil, fmt.Errorf("vision analysis failed: %w", err)
+	}
+
+	// Step 1: Split the response into sections (a missing or broken section is just invalid)
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(extractJSON(visionResp.Description)), &sections); err != nil {
+		log.Printf("Warning: failed to parse combined analysis response: %v", err)
+	}
+
+	result := &FrameCinematics{}
+
+	// Step 2: Parse and validate each section, falling back section by section
+	if classification, err := ca.classifier.parseClassificationResponse(string(sections[SectionScene])); err == nil && classification.PrimaryType != SceneTypeUnknown {
+		classification.Timestamp = startTime
+		result.Classification = classification
+	} else {
+		result.Classification = ca.fallbackClassification(ctx, frameData, visionResp.Description)
+		result.Fallbacks = append(result.Fallbacks, SectionScene)
+	}
+
+	if composition, err := ca.composition.parseCompositionResponse(string(sections[SectionComposition])); err == nil && composition.ShotSize != ShotSizeUnknown {
+		composition.Timestamp = startTime
+		result.Composition = composition
+	} else {
+		result.Composition = ca.fallbackComposition(ctx, frameData, visionResp.Description)
+		result.Fallbacks = append(result.Fallbacks, SectionComposition)
+	}
+
+	// No camera detector means the camera was measured from frames and wasn't requested
+	if camera != nil {
+		if movement, err := camera.parseMovementResponse(string(sections[SectionCamera])); err == nil && isValidMovement(movement) {
+			movement.Timestamp = startTime
+			camera.record(movement)
+			result.Movement = movement
+		} else {
+			result.Movement = ca.fallbackMovement(ctx, frameData, camera, startTime)
+			result.Fallbacks = append(result.Fallbacks, SectionCamera)
+		}
+	}
+
+	if look, err := ca.look.parseAnalysisResponse(string(sections[SectionLook])); err == nil && isValidLook(look) {
+		look.Timestamp = startTime
+		applyMeasuredLook(look, frameData)
+		result.Look = look
+	} else {
+		result.Look = ca.fallbackLook(ctx, frameData, startTime)
+		result.Fallbacks = append(result.Fallbacks, SectionLook)
+	}
+
+	if len(result.Fallbacks) > 0 {
+		log.Printf("Combined analysis fell back for sections: %v", result.Fallbacks)
+	}
+
+	return result, nil
+}
+
+// fallbackClassification uses the dedicated classifier, else keyword heuristics on the response
+func (ca *CombinedAnalyzer) fallbackClassification(ctx context.Context, frameData, description string) *SceneClassification {
+	if ca.dedicatedFallback {
+		classification, err := ca.classifier.ClassifyScene(ctx, frameData)
+		if err == nil {
+			return classification
+		}
+		log.Printf("Warning: dedicated scene classification failed: %v", err)
+	}
+	return ca.classifier.fallbackClassification(description)
+}
+
+// fallbackComposition uses the dedicated composition analyzer, else keyword heuristics on the response
+func (ca *CombinedAnalyzer) fallbackComposition(ctx context.Context, frameData, description string) *ShotComposition {
+	if ca.dedicatedFallback {
+		composition, err := ca.composition.AnalyzeComposition(ctx, frameData)
+		if err == nil {
+			return composition
+		}
+		log.Printf("Warning: dedicated shot composition failed: %v", err)
+	}
+	return ca.composition.fallbackComposition(description)
+}
+
+// fallbackMovement uses the dedicated movement detector, else a low-confidence static result
+func (ca *CombinedAnalyzer) fallbackMovement(ctx context.Context, frameData string, camera *CameraMovementDetector, startTime time.Time) *CameraMovement {
+	if ca.dedicatedFallback {
+		movement, err := camera.DetectMovement(ctx, frameData)
+		if err == nil {
+			return movement
+		}
+		log.Printf("Warning: dedicated camera movement detection failed: %v", err)
+	}
+	movement := camera.fallbackMovementDetection(frameData)
+	movement.Timestamp = startTime
+	camera.record(movement)
+	return movement
+}
+
+// fallbackLook uses the dedicated colour/lighting/mood analyzer, else a low-confidence neutral result
+func (ca *CombinedAnalyzer) fallbackLook(ctx context.Context, frameData string, startTime time.Time) *ColorLightingMood {
+	if ca.dedicatedFallback {
+		look, err := ca.look.Analyze(ctx, frameData)
+		if err == nil {
+			return look
+		}
+		log.Printf("Warning: dedicated colour/lighting/mood analysis failed: %v", err)
+	}
+	look := ca.look.fallbackAnalysis(frameData)
+	look.Timestamp = startTime
+	applyMeasuredLook(look, frameData)
+	return look
+}
+
+// isValidMovement checks the movement type and speed are known values
+func isValidMovement(movement *CameraMovement) bool {
+	switch movement.PrimaryMovement {
+	case MovementStatic, MovementPan, MovementTilt, MovementZoom, MovementDolly, MovementTracking, MovementCrane, MovementHandheld:
+	default:
+		return false
+	}
+
+	switch movement.Speed {
+	case SpeedStill, SpeedVerySlow, SpeedSlow, SpeedMedium, SpeedFast, SpeedVeryFast:
+		return true
+	}
+	return false
+}
+
+// isValidLook checks the grading, lighting setup and mood are known values
+func isValidLook(look *ColorLightingMood) bool {
+	switch look.ColorGrading {
+	case GradingNatural, GradingWarm, GradingCool, GradingDesaturated, GradingVibrant, GradingHighContrast,
+		GradingLowContrast, GradingMonochrome, GradingSepia, GradingCinematic:
+	default:
+		return false
+	}
+
+	switch look.LightingSetup {
+	case LightingThreePoint, LightingNatural, LightingPractical, LightingHardLight, LightingSoftLight, LightingBacklight,
+		LightingRimLight, LightingSideLight, LightingLowKey, LightingHighKey, LightingSilhouette, LightingChiaroscuro:
+	default:
+		return false
+	}
+
+	switch look.PrimaryMood {
+	case MoodTense, MoodPeaceful, MoodEnergetic, MoodMelancholy, MoodHopeful, MoodOminous, MoodJoyful,
+		MoodMysterious, MoodRomantic, MoodNostalgic, MoodSurreal, MoodNeutral:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,484 @@
+package scene
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+	"time"
+)
+
+// GlobalMotion is the dominant image motion between two consecutive frames
+type GlobalMotion struct {
+	DX      float64 // Content shift right (fraction of frame width)
+	DY      float64 // Content shift down (fraction of frame height)
+	Scale   float64 // Relative content growth (> 0 when zooming in)
+	Inliers float64 // Fraction of textured blocks agreeing with the motion (0-1)
+}
+
+// MotionMeasurement holds the measured camera motion of a frame sequence
+type MotionMeasurement struct {
+	PanRate      float64 `json:"panRate"`      // Frame widths per second (+ = right)
+	TiltRate     float64 `json:"tiltRate"`     // Frame heights per second (+ = up)
+	ZoomRate     float64 `json:"zoomRate"`     // Relative scale change per second (+ = in)
+	Jitter       float64 `json:"jitter"`       // RMS frame-to-frame change of the shift (fraction of frame)
+	ReversalRate float64 `json:"reversalRate"` // Direction reversals per second
+	FramePairs   int     `json:"framePairs"`   // Frame pairs with a reliable estimate
+	Inliers      float64 `json:"inliers"`      // Mean inlier fraction
+}
+
+// Classification thresholds for measured motion
+const (
+	panThreshold  = 0.02 // Frame widths/heights per second below which the camera counts as still
+	zoomThreshold = 0.01 // Relative scale change per second below which there is no zoom
+	shakeJitter   = 0.01 // Jitter from which the camera counts as shaking (~1.5px at 160px, above matching noise)
+)
+
+// MotionEstimator measures global camera motion from consecutive decoded frames by block
+// matching and a least-squares fit of translation and scale
+type MotionEstimator struct {
+	blockSize    int     // Block side (pixels)
+	searchRadius int     // Largest shift searched per axis (pixels)
+	minVariance  float64 // Blocks flatter than this are ignored (no texture to match)
+	minBlocks    int     // Fewest matched blocks for a reliable estimate
+	minInliers   float64 // Smallest fraction of matched blocks agreeing with the estimate
+}
+
+// NewMotionEstimator creates a motion estimator tuned for frames about 160 pixels wide
+func NewMotionEstimator() *MotionEstimator {
+	return &MotionEstimator{
+		blockSize:    8,
+		searchRadius: 8,
+		minVariance:  25,
+		minBlocks:    6,
+		minInliers:   0.5,
+	}
+}
+
+// blockVector is the matched shift of one block, relative to the frame centre
+type blockVector struct {
+	px, py float64 // Block centre relative to frame centre
+	vx, vy float64 // Shift (pixels)
+}
+
+// EstimatePair measures the global motion from prev to next
+// Returns false when the frames are too flat or too different to match reliably.
+func (me *MotionEstimator) EstimatePair(prev, next *image.Gray) (GlobalMotion, bool) {
+	bounds := prev.Bounds()
+	if next.Bounds().Dx() != bounds.Dx() || next.Bounds().Dy() != bounds.Dy() {
+		return GlobalMotion{}, false
+	}
+	w, h := bounds.Dx(), bounds.Dy()
+	bs, r := me.blockSize, me.searchRadius
+
+	// Step 1: Match textured blocks
+	vectors := make([]blockVector, 0)
+	for by := r; by+bs+r <= h; by += bs {
+		for bx := r; bx+bs+r <= w; bx += bs {
+			if blockVariance(prev, bx, by, bs) < me.minVariance {
+				continue
+			}
+			vx, vy, ok := me.matchBlock(prev, next, bx, by)
+			if !ok {
+				continue
+			}
+			vectors = append(vectors, blockVector{
+				px: float64(bx) + float64(bs)/2 - float64(w)/2,
+				py: float64(by) + float64(bs)/2 - float64(h)/2,
+				vx: vx,
+				vy: vy,
+			})
+		}
+	}
+	if len(vectors) < me.minBlocks {
+		return GlobalMotion{}, false
+	}
+
+	// Step 2: Fit translation + scale, then refit on the blocks that agree (moving subjects drop out)
+	tx, ty, s := fitSimilarity(vectors)
+	inliers := make([]blockVector, 0, len(vectors))
+	for _, v := range vectors {
+		if math.Hypot(v.vx-tx-s*v.px, v.vy-ty-s*v.py) <= 1.5 {
+			inliers = append(inliers, v)
+		}
+	}
+	// Without a majority the blocks matched noise (e.g. motion beyond the search window)
+	if len(inliers) < me.minBlocks || float64(len(inliers)) < me.minInliers*float64(len(vectors)) {
+		return GlobalMotion{}, false
+	}
+	tx, ty, s = fitSimilarity(inliers)
+
+	return GlobalMotion{
+		DX:      tx / float64(w),
+		DY:      ty / float64(h),
+		Scale:   s,
+		Inliers: float64(len(inliers)) / float64(len(vectors)),
+	}, true
+}
+
+// matchBlock finds the shift of a block of prev in next (sum of absolute differences,
+// refined to sub-pixel precision with a parabola through the neighbouring costs)
+func (me *MotionEstimator) matchBlock(prev, next *image.Gray, bx, by int) (float64, float64, bool) {
+	r := me.searchRadius
+	size := 2*r + 1
+	costs := make([]float64, size*size)
+
+	bestDX, bestDY, best := 0, 0, math.MaxFloat64
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			cost := me.blockSAD(prev, next, bx, by, dx, dy)
+			costs[(dy+r)*size+dx+r] = cost
+			// Prefer the smaller shift on ties (flat regions, repeated texture)
+			if cost < best || (cost == best && dx*dx+dy*dy < bestDX*bestDX+bestDY*bestDY) {
+				bestDX, bestDY, best = dx, dy, cost
+			}
+		}
+	}
+
+	// A match on the search border may be a larger motion cut off by the window
+	if bestDX == -r || bestDX == r || bestDY == -r || bestDY == r {
+		return 0, 0, false
+	}
+
+	cost := func(dx, dy int) float64 { return costs[(dy+r)*size+dx+r] }
+	subX := subPixel(cost(bestDX-1, bestDY), best, cost(bestDX+1, bestDY))
+	subY := subPixel(cost(bestDX, bestDY-1), best, cost(bestDX, bestDY+1))
+
+	return float64(bestDX) + subX, float64(bestDY) + subY, true
+}
+
+// blockSAD returns the sum of absolute differences between a block of prev and the
+// block of next shifted by (dx, dy)
+func (me *MotionEstimator) blockSAD(prev, next *image.Gray, bx, by, dx, dy int) float64 {
+	sum := 0
+	for y := 0; y < me.blockSize; y++ {
+		prevRow := prev.Pix[prev.PixOffset(bx, by+y):]
+		nextRow := next.Pix[next.PixOffset(bx+dx, by+y+dy):]
+		for x := 0; x < me.blockSize; x++ {
+			d := int(prevRow[x]) - int(nextRow[x])
+			if d < 0 {
+				d = -d
+			}
+			sum += d
+		}
+	}
+	return float64(sum)
+}
+
+// blockVariance returns the luma variance of a block
+func blockVariance(img *image.Gray, bx, by, bs int) float64 {
+	sum, sumSq := 0.0, 0.0
+	for y := 0; y < bs; y++ {
+		row := img.Pix[img.PixOffset(bx, by+y):]
+		for x := 0; x < bs; x++ {
+			v := float64(row[x])
+			sum += v
+			sumSq += v * v
+		}
+	}
+	n := float64(bs * bs)
+	mean := sum / n
+	return sumSq/n - mean*mean
+}
+
+// subPixel returns the offset (-0.5..0.5) of the minimum of a parabola through three costs
+func subPixel(left, center, right float64) float64 {
+	denom := left - 2*center + right
+	if denom <= 0 {
+		return 0
+	}
+	return math.Max(-0.5, math.Min(0.5, (left-right)/(2*denom)))
+}
+
+// fitSimilarity fits v = t + s*p to the block vectors by least squares
+func fitSimilarity(vectors []blockVector) (float64, float64, float64) {
+	n := float64(len(vectors))
+	var sumPX, sumPY, sumPP, sumVX, sumVY, sumPV float64
+	for _, v := range vectors {
+		sumPX += v.px
+		sumPY += v.py
+		sumPP += v.px*v.px + v.py*v.py
+		sumVX += v.vx
+		sumVY += v.vy
+		sumPV += v.px*v.vx + v.py*v.vy
+	}
+
+	s := 0.0
+	if denom := sumPP - (sumPX*sumPX+sumPY*sumPY)/n; denom > 1e-9 {
+		s = (sumPV - (sumPX*sumVX+sumPY*sumVY)/n) / denom
+	}
+	return (sumVX - sumPX*s) / n, (sumVY - sumPY*s) / n, s
+}
+
+// Measure measures the camera motion over consecutive frames taken at timestamps (seconds)
+func (me *MotionEstimator) Measure(frames []*image.Gray, timestamps []float64) (*MotionMeasurement, []GlobalMotion, error) {
+	if len(frames) != len(timestamps) {
+		return nil, nil, fmt.Errorf("got %d frames but %d timestamps", len(frames), len(timestamps))
+	}
+	if len(frames) < 2 {
+		return nil, nil, fmt.Errorf("need at least 2 frames, got %d", len(frames))
+	}
+
+	measurement := &MotionMeasurement{}
+	motions := make([]GlobalMotion, len(frames)-1)
+	valid := make([]bool, len(frames)-1)
+	totalTime := 0.0
+
+	for i := 0; i+1 < len(frames); i++ {
+		dt := timestamps[i+1] - timestamps[i]
+		if dt <= 0 {
+			continue
+		}
+		motion, ok := me.EstimatePair(frames[i], frames[i+1])
+		if !ok {
+			continue
+		}
+		motions[i], valid[i] = motion, true
+
+		measurement.PanRate += -motion.DX
+		measurement.TiltRate += motion.DY
+		measurement.ZoomRate += motion.Scale
+		measurement.Inliers += motion.Inliers
+		measurement.FramePairs++
+		totalTime += dt
+	}
+	if measurement.FramePairs == 0 {
+		return nil, nil, fmt.Errorf("no frame pair could be matched")
+	}
+
+	measurement.PanRate /= totalTime
+	measurement.TiltRate /= totalTime
+	measurement.ZoomRate /= totalTime
+	measurement.Inliers /= float64(measurement.FramePairs)
+
+	// Jitter and reversals compare consecutive shifts: a steady pan changes little between
+	// pairs, shake changes a lot and flips direction
+	sumSq, changes, reversals := 0.0, 0, 0
+	for i := 1; i < len(motions); i++ {
+		if !valid[i] || !valid[i-1] {
+			continue
+		}
+		ddx := motions[i].DX - motions[i-1].DX
+		ddy := motions[i].DY - motions[i-1].DY
+		sumSq += (ddx*ddx + ddy*ddy) / 2
+		changes++
+		if reversed(motions[i-1].DX, motions[i].DX) || reversed(motions[i-1].DY, motions[i].DY) {
+			reversals++
+		}
+	}
+	if changes > 0 {
+		measurement.Jitter = math.Sqrt(sumSq / float64(changes))
+	}
+	measurement.ReversalRate = float64(reversals) / totalTime
+
+	return measurement, motions, nil
+}
+
+// reversed reports whether two noticeable shifts point in opposite directions
+func reversed(a, b float64) bool {
+	noticeable := shakeJitter / 2
+	return math.Abs(a) > noticeable && math.Abs(b) > noticeable && (a > 0) != (b > 0)
+}
+
+// EstimateMovement measures the camera motion over consecutive frames and describes it
+// as a CameraMovement (type, speed, smoothness, direction, trajectory, stabilization, shake)
+func (me *MotionEstimator) EstimateMovement(frames []*image.Gray, timestamps []float64) (*CameraMovement, error) {
+	measurement, motions, err := me.Measure(frames, timestamps)
+	if err != nil {
+		return nil, err
+	}
+
+	movement := &CameraMovement{
+		PrimaryMovement: MovementStatic,
+		Speed:           measuredSpeed(measurement),
+		Smoothness:      measuredSmoothness(measurement.Jitter),
+		Trajectory:      measuredTrajectory(motions, timestamps),
+		Stabilization:   measuredStabilization(measurement),
+		Shake:           measuredShake(measurement),
+		Measurement:     measurement,
+		Confidence:      measurement.Inliers * math.Min(1, float64(measurement.FramePairs)/4),
+		Attributes:      map[string]interface{}{"measured": true},
+		Timestamp:       time.Now(),
+	}
+
+	// Step 1: The dominant motion relative to its threshold decides the type
+	scores := map[CameraMovementType]float64{
+		MovementPan:  math.Abs(measurement.PanRate) / panThreshold,
+		MovementTilt: math.Abs(measurement.TiltRate) / panThreshold,
+		MovementZoom: math.Abs(measurement.ZoomRate) / zoomThreshold,
+	}
+	types := []CameraMovementType{MovementPan, MovementTilt, MovementZoom}
+	sort.SliceStable(types, func(i, j int) bool { return scores[types[i]] > scores[types[j]] })
+
+	if scores[types[0]] >= 1 {
+		movement.PrimaryMovement = types[0]
+		if scores[types[1]] >= 1 {
+			secondary := types[1]
+			movement.SecondaryMovement = &secondary
+		}
+		movement.Direction = measuredDirection(measurement)
+	}
+
+	// Step 2: Strong shake makes a still camera handheld (or adds handheld to a move)
+	if measurement.Jitter >= 2*shakeJitter {
+		if movement.PrimaryMovement == MovementStatic {
+			movement.PrimaryMovement = MovementHandheld
+		} else if movement.SecondaryMovement == nil {
+			handheld := MovementHandheld
+			movement.SecondaryMovement = &handheld
+		}
+	}
+
+	return movement, nil
+}
+
+// measuredSpeed grades the fastest motion component
+func measuredSpeed(m *MotionMeasurement) MovementSpeed {
+	rate := math.Max(math.Abs(m.PanRate), math.Abs(m.TiltRate))
+	rate = math.Max(rate, 2*math.Abs(m.ZoomRate))
+	switch {
+	case rate < panThreshold:
+		return SpeedStill
+	case rate < 0.05:
+		return SpeedVerySlow
+	case rate < 0.12:
+		return SpeedSlow
+	case rate < 0.3:
+		return SpeedMedium
+	case rate < 0.7:
+		return SpeedFast
+	default:
+		return SpeedVeryFast
+	}
+}
+
+// measuredSmoothness grades how steady the motion is
+func measuredSmoothness(jitter float64) MovementSmoothness {
+	switch {
+	case jitter < shakeJitter/2:
+		return SmoothnessVerySmooth
+	case jitter < shakeJitter:
+		return SmoothnessSmooth
+	case jitter < 2*shakeJitter:
+		return SmoothnessMedium
+	case jitter < 4*shakeJitter:
+		return SmoothnessJerky
+	default:
+		return SmoothnessVeryJerky
+	}
+}
+
+// measuredDirection describes the direction of camera travel
+func measuredDirection(m *MotionMeasurement) *MovementDirection {
+	direction := &MovementDirection{Horizontal: "none", Vertical: "none", Depth: "none"}
+	if m.PanRate >= panThreshold {
+		direction.Horizontal = "right"
+	} else if m.PanRate <= -panThreshold {
+		direction.Horizontal = "left"
+	}
+	if m.TiltRate >= panThreshold {
+		direction.Vertical = "up"
+	} else if m.TiltRate <= -panThreshold {
+		direction.Vertical = "down"
+	}
+	if m.ZoomRate >= zoomThreshold {
+		direction.Depth = "in"
+	} else if m.ZoomRate <= -zoomThreshold {
+		direction.Depth = "out"
+	}
+
+	// 0 = right, 90 = up, 180 = left, 270 = down (a pure zoom has no angle)
+	if direction.Horizontal != "none" || direction.Vertical != "none" {
+		angle := math.Atan2(m.TiltRate, m.PanRate) * 180 / math.Pi
+		if angle < 0 {
+			angle += 360
+		}
+		direction.Angle = angle
+	}
+	return direction
+}
+
+// measuredTrajectory integrates the measured shifts into a camera path
+// Positions start at the frame centre (y grows downwards); timestamps are video time.
+func measuredTrajectory(motions []GlobalMotion, timestamps []float64) []TrajectoryPoint {
+	videoEpoch := time.Unix(0, 0).UTC()
+	at := func(seconds float64) time.Time {
+		return videoEpoch.Add(time.Duration(seconds * float64(time.Second)))
+	}
+
+	x, y, z := 0.5, 0.5, 0.0
+	trajectory := make([]TrajectoryPoint, 0, len(motions)+1)
+	trajectory = append(trajectory, TrajectoryPoint{X: x, Y: y, Z: z, Timestamp: at(timestamps[0])})
+	for i, motion := range motions {
+		// The camera moves against the content
+		x = math.Max(0, math.Min(1, x-motion.DX))
+		y = math.Max(0, math.Min(1, y-motion.DY))
+		z += motion.Scale
+		trajectory = append(trajectory, TrajectoryPoint{X: x, Y: y, Z: z, Timestamp: at(timestamps[i+1])})
+	}
+	return trajectory
+}
+
+// measuredStabilization grades stabilization from the jitter
+func measuredStabilization(m *MotionMeasurement) StabilizationAnalysis {
+	analysis := StabilizationAnalysis{
+		IsStabilized: m.Jitter < shakeJitter,
+		Method:       "unknown",
+		Confidence:   m.Inliers,
+	}
+
+	switch {
+	case m.Jitter < shakeJitter/2:
+		analysis.Quality = "excellent"
+	case m.Jitter < shakeJitter:
+		analysis.Quality = "good"
+	case m.Jitter < 2*shakeJitter:
+		analysis.Quality = "fair"
+	case m.Jitter < 4*shakeJitter:
+		analysis.Quality = "poor"
+	default:
+		analysis.Quality = "none"
+	}
+
+	still := math.Abs(m.PanRate) < panThreshold && math.Abs(m.TiltRate) < panThreshold && math.Abs(m.ZoomRate) < zoomThreshold
+	if still && m.Jitter < shakeJitter/2 {
+		analysis.Method = "tripod"
+	} else if m.Jitter >= 2*shakeJitter {
+		analysis.Method = "handheld"
+	}
+	return analysis
+}
+
+// measuredShake grades shake intensity from the jitter and frequency from direction reversals
+func measuredShake(m *MotionMeasurement) ShakeAnalysis {
+	shake := ShakeAnalysis{
+		HasShake:   m.Jitter >= shakeJitter,
+		Frequency:  "none",
+		Confidence: m.Inliers,
+	}
+
+	switch {
+	case m.Jitter < shakeJitter/2:
+		shake.Intensity = "none"
+	case m.Jitter < shakeJitter:
+		shake.Intensity = "minimal"
+	case m.Jitter < 2*shakeJitter:
+		shake.Intensity = "moderate"
+	case m.Jitter < 4*shakeJitter:
+		shake.Intensity = "significant"
+	default:
+		shake.Intensity = "extreme"
+	}
+
+	if shake.HasShake {
+		switch {
+		case m.ReversalRate < 1.5:
+			shake.Frequency = "low"
+		case m.ReversalRate < 4:
+			shake.Frequency = "medium"
+		default:
+			shake.Frequency = "high"
+		}
+	}
+	return shake
+}
@@ -0,0 +1,336 @@
+package scene
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+const (
+	testFrameWidth  = 160
+	testFrameHeight = 90
+)
+
+// texture is a smooth random luma field large enough to crop moving frames from
+type texture struct {
+	w, h int
+	pix  []float64
+}
+
+func newTexture(seed int64, w, h int) *texture {
+	rng := rand.New(rand.NewSource(seed))
+	raw := make([]float64, w*h)
+	for i := range raw {
+		raw[i] = rng.Float64() * 255
+	}
+
+	// 3x3 box blur so sub-pixel shifts change the block costs smoothly
+	t := &texture{w: w, h: h, pix: make([]float64, w*h)}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sum, n := 0.0, 0.0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if xx, yy := x+dx, y+dy; xx >= 0 && xx < w && yy >= 0 && yy < h {
+						sum += raw[yy*w+xx]
+						n++
+					}
+				}
+			}
+			t.pix[y*w+x] = sum / n
+		}
+	}
+	return t
+}
+
+// at samples the texture bilinearly
+func (t *texture) at(x, y float64) float64 {
+	x = math.Max(0, math.Min(float64(t.w-2), x))
+	y = math.Max(0, math.Min(float64(t.h-2), y))
+	x0, y0 := int(x), int(y)
+	fx, fy := x-float64(x0), y-float64(y0)
+	top := t.pix[y0*t.w+x0]*(1-fx) + t.pix[y0*t.w+x0+1]*fx
+	bottom := t.pix[(y0+1)*t.w+x0]*(1-fx) + t.pix[(y0+1)*t.w+x0+1]*fx
+	return top*(1-fy) + bottom*fy
+}
+
+// frame renders the camera view whose top-left corner is at (left, top) in the texture,
+// magnified by zoom about the view centre
+func (t *texture) frame(left, top, zoom float64) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, testFrameWidth, testFrameHeight))
+	cx, cy := float64(testFrameWidth)/2, float64(testFrameHeight)/2
+	for y := 0; y < testFrameHeight; y++ {
+		for x := 0; x < testFrameWidth; x++ {
+			sx := left + cx + (float64(x)-cx)/zoom
+			sy := top + cy + (float64(y)-cy)/zoom
+			img.Pix[img.PixOffset(x, y)] = uint8(math.Round(t.at(sx, sy)))
+		}
+	}
+	return img
+}
+
+// sequence renders one frame per camera position (1 fps)
+func (t *texture) sequence(positions [][2]float64) ([]*image.Gray, []float64) {
+	frames := make([]*image.Gray, len(positions))
+	timestamps := make([]float64, len(positions))
+	for i, p := range positions {
+		frames[i] = t.frame(p[0], p[1], 1)
+		timestamps[i] = float64(i)
+	}
+	return frames, timestamps
+}
+
+func TestEstimatePairTranslation(t *testing.T) {
+	tex := newTexture(1, 300, 200)
+	me := NewMotionEstimator()
+
+	tests := []struct {
+		name   string
+		dx, dy float64 // Camera movement (pixels)
+	}{
+		{"still", 0, 0},
+		{"right", 3, 0},
+		{"up and left", -2, -4},
+		{"sub-pixel", 1.5, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := tex.frame(60, 50, 1)
+			next := tex.frame(60+tt.dx, 50+tt.dy, 1)
+
+			motion, ok := me.EstimatePair(prev, next)
+			if !ok {
+				t.Fatal("no estimate for a textured pair")
+			}
+			// The content moves against the camera
+			if got := motion.DX * testFrameWidth; math.Abs(got+tt.dx) > 0.3 {
+				t.Errorf("content shift x = %.2fpx, want %.2f", got, -tt.dx)
+			}
+			if got := motion.DY * testFrameHeight; math.Abs(got+tt.dy) > 0.3 {
+				t.Errorf("content shift y = %.2fpx, want %.2f", got, -tt.dy)
+			}
+			if math.Abs(motion.Scale) > 0.005 {
+				t.Errorf("Scale = %.4f, want 0 for a pure translation", motion.Scale)
+			}
+			if motion.Inliers < 0.9 {
+				t.Errorf("Inliers = %.2f, want nearly all blocks", motion.Inliers)
+			}
+		})
+	}
+}
+
+func TestEstimatePairZoom(t *testing.T) {
+	tex := newTexture(2, 300, 200)
+	me := NewMotionEstimator()
+
+	motion, ok := me.EstimatePair(tex.frame(60, 50, 1), tex.frame(60, 50, 1.04))
+	if !ok {
+		t.Fatal("no estimate for a zoom")
+	}
+	if motion.Scale < 0.03 || motion.Scale > 0.05 {
+		t.Errorf("Scale = %.4f, want about 0.04", motion.Scale)
+	}
+	if math.Abs(motion.DX*testFrameWidth) > 0.3 || math.Abs(motion.DY*testFrameHeight) > 0.3 {
+		t.Errorf("shift = %.2f/%.2fpx, want none for a centred zoom", motion.DX*testFrameWidth, motion.DY*testFrameHeight)
+	}
+}
+
+func TestEstimatePairIgnoresMovingSubject(t *testing.T) {
+	tex := newTexture(3, 300, 200)
+	other := newTexture(4, 300, 200)
+	me := NewMotionEstimator()
+
+	// The camera pans 2px right while a subject in the left third moves 5px down
+	prev := tex.frame(60, 50, 1)
+	next := tex.frame(62, 50, 1)
+	prevSubject := other.frame(0, 0, 1)
+	nextSubject := other.frame(0, -5, 1)
+	for y := 20; y < 70; y++ {
+		for x := 16; x < 56; x++ {
+			prev.Pix[prev.PixOffset(x, y)] = prevSubject.Pix[prevSubject.PixOffset(x, y)]
+			next.Pix[next.PixOffset(x, y)] = nextSubject.Pix[nextSubject.PixOffset(x, y)]
+		}
+	}
+
+	motion, ok := me.EstimatePair(prev, next)
+	if !ok {
+		t.Fatal("no estimate with a moving subject")
+	}
+	if got := motion.DX * testFrameWidth; math.Abs(got+2) > 0.3 {
+		t.Errorf("content shift x = %.2fpx, want -2 (the background)", got)
+	}
+	if got := motion.DY * testFrameHeight; math.Abs(got) > 0.3 {
+		t.Errorf("content shift y = %.2fpx, want 0 (the background)", got)
+	}
+	if motion.Inliers >= 1 {
+		t.Errorf("Inliers = %.2f, want the subject's blocks rejected", motion.Inliers)
+	}
+}
+
+func TestEstimatePairRejectsUnmatchableFrames(t *testing.T) {
+	me := NewMotionEstimator()
+	flat := image.NewGray(image.Rect(0, 0, testFrameWidth, testFrameHeight))
+	for i := range flat.Pix {
+		flat.Pix[i] = 128
+	}
+	tex := newTexture(5, 300, 200)
+
+	if _, ok := me.EstimatePair(flat, flat); ok {
+		t.Error("estimated motion between flat frames")
+	}
+	if _, ok := me.EstimatePair(tex.frame(0, 0, 1), image.NewGray(image.Rect(0, 0, 80, 45))); ok {
+		t.Error("estimated motion between frames of different sizes")
+	}
+	// A shift beyond the search radius matches noise, which must not pass for a small shift
+	if motion, ok := me.EstimatePair(tex.frame(60, 50, 1), tex.frame(90, 50, 1)); ok {
+		t.Errorf("30px pan measured as %.2fpx", -motion.DX*testFrameWidth)
+	}
+}
+
+func TestEstimateMovementClassifiesCamera(t *testing.T) {
+	tex := newTexture(6, 400, 300)
+	me := NewMotionEstimator()
+
+	positions := func(step func(i int) (float64, float64)) [][2]float64 {
+		p := make([][2]float64, 8)
+		for i := range p {
+			dx, dy := step(i)
+			p[i] = [2]float64{100 + dx, 100 + dy}
+		}
+		return p
+	}
+
+	tests := []struct {
+		name       string
+		positions  [][2]float64
+		primary    CameraMovementType
+		horizontal string
+		vertical   string
+		shake      bool
+	}{
+		{
+			name:      "locked off",
+			positions: positions(func(i int) (float64, float64) { return 0, 0 }),
+			primary:   MovementStatic,
+		},
+		{
+			// Sub-pixel wobble is matching noise, not shake
+			name:      "still with sub-pixel wobble",
+			positions: positions(func(i int) (float64, float64) { return 0.4 * float64(i%2), 0.3 * float64((i/2)%2) }),
+			primary:   MovementStatic,
+		},
+		{
+			name:       "steady pan right",
+			positions:  positions(func(i int) (float64, float64) { return 4 * float64(i), 0 }),
+			primary:    MovementPan,
+			horizontal: "right",
+			vertical:   "none",
+		},
+		{
+			// Image y grows downwards, so the view moving up is a tilt up
+			name:       "steady tilt up",
+			positions:  positions(func(i int) (float64, float64) { return 0, -3 * float64(i) }),
+			primary:    MovementTilt,
+			horizontal: "none",
+			vertical:   "up",
+		},
+		{
+			name:      "handheld shake",
+			positions: positions(func(i int) (float64, float64) { return 3 * float64(i%2*2-1), 2 * float64((i/2)%2*2-1) }),
+			primary:   MovementHandheld,
+			shake:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames, timestamps := tex.sequence(tt.positions)
+			movement, err := me.EstimateMovement(frames, timestamps)
+			if err != nil {
+				t.Fatalf("EstimateMovement: %v", err)
+			}
+			if movement.PrimaryMovement != tt.primary {
+				t.Errorf("PrimaryMovement = %s, want %s (measurement %+v)", movement.PrimaryMovement, tt.primary, *movement.Measurement)
+			}
+			if tt.horizontal != "" {
+				if movement.Direction == nil {
+					t.Fatalf("no direction for a %s", tt.primary)
+				}
+				if movement.Direction.Horizontal != tt.horizontal || movement.Direction.Vertical != tt.vertical {
+					t.Errorf("direction = %s/%s, want %s/%s", movement.Direction.Horizontal, movement.Direction.Vertical, tt.horizontal, tt.vertical)
+				}
+			}
+			if movement.Shake.HasShake != tt.shake {
+				t.Errorf("HasShake = %v, want %v (jitter %.4f)", movement.Shake.HasShake, tt.shake, movement.Measurement.Jitter)
+			}
+			if !tt.shake && movement.Smoothness != SmoothnessVerySmooth && movement.Smoothness != SmoothnessSmooth {
+				t.Errorf("Smoothness = %s, want smooth for a steady camera", movement.Smoothness)
+			}
+			if len(movement.Trajectory) != len(frames) {
+				t.Errorf("trajectory has %d points, want one per frame", len(movement.Trajectory))
+			}
+		})
+	}
+}
+
+func TestMeasurePanRateAndErrors(t *testing.T) {
+	tex := newTexture(7, 400, 300)
+	me := NewMotionEstimator()
+
+	// 4px per 0.5s on a 160px frame = 0.05 frame widths per second
+	frames := make([]*image.Gray, 5)
+	timestamps := make([]float64, 5)
+	for i := range frames {
+		frames[i] = tex.frame(100+4*float64(i), 100, 1)
+		timestamps[i] = 10 + 0.5*float64(i)
+	}
+	measurement, motions, err := me.Measure(frames, timestamps)
+	if err != nil {
+		t.Fatalf("Measure: %v", err)
+	}
+	if math.Abs(measurement.PanRate-0.05) > 0.005 {
+		t.Errorf("PanRate = %.4f, want 0.05", measurement.PanRate)
+	}
+	if measurement.FramePairs != 4 || len(motions) != 4 {
+		t.Errorf("FramePairs = %d with %d motions, want 4", measurement.FramePairs, len(motions))
+	}
+	if measurement.ReversalRate != 0 {
+		t.Errorf("ReversalRate = %.2f, want 0 for a steady pan", measurement.ReversalRate)
+	}
+
+	errorCases := []struct {
+		name       string
+		frames     []*image.Gray
+		timestamps []float64
+		want       string
+	}{
+		{"mismatched timestamps", frames, timestamps[:2], "timestamps"},
+		{"single frame", frames[:1], timestamps[:1], "at least 2 frames"},
+		{"unmatchable", []*image.Gray{image.NewGray(image.Rect(0, 0, 160, 90)), image.NewGray(image.Rect(0, 0, 160, 90))}, []float64{0, 1}, "no frame pair"},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := me.Measure(tc.frames, tc.timestamps)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestCombinedPromptOmitsMeasuredCamera(t *testing.T) {
+	for _, section := range []string{`"scene"`, `"composition"`, `"camera"`, `"look"`, "4. LOOK"} {
+		if !strings.Contains(combinedPrompt, section) {
+			t.Errorf("combined prompt lacks %s", section)
+		}
+	}
+	if strings.Contains(measuredPrompt, `"camera"`) || strings.Contains(measuredPrompt, "CAMERA") {
+		t.Error("measured prompt still asks for the camera")
+	}
+	if !strings.Contains(measuredPrompt, "3. LOOK") {
+		t.Error("measured prompt sections are not renumbered")
+	}
+}
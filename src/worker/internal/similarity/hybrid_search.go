@@ -0,0 +1,476 @@
+package similarity
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// KeywordSearcher provides lexical lookups over stored analysis results
+// Implemented by storage.StorageManager (PostgreSQL full-text search)
+type KeywordSearcher interface {
+	SearchTranscripts(ctx context.Context, tenantID, query string, videoIDs []string, limit int) ([]models.KeywordMatch, error)
+	SearchTextExtractions(ctx context.Context, tenantID, query string, videoIDs []string, limit int) ([]models.KeywordMatch, error)
+	SearchObjectLabels(ctx context.Context, tenantID string, labels []string, videoIDs []string, limit int) ([]models.KeywordMatch, error)
+}
+
+// FusionMethod represents how component rankings are combined
+type FusionMethod string
+
+const (
+	FusionRRF      FusionMethod = "rrf"      // Reciprocal rank fusion
+	FusionWeighted FusionMethod = "weighted" // Weighted min-max normalised scores
+)
+
+// Hybrid retrieval components
+const (
+	ComponentVideoVector = "video_vector"
+	ComponentSceneVector = "scene_vector"
+	ComponentTranscript  = "transcript"
+	ComponentOCR         = "ocr"
+	ComponentObjects     = "objects"
+)
+
+// rrfK is the standard reciprocal rank fusion damping constant
+const rrfK = 60.0
+
+// DefaultComponentWeights returns the default weight for each hybrid component
+func DefaultComponentWeights() map[string]float64 {
+	return map[string]float64{
+		ComponentVideoVector: 1.0,
+		ComponentSceneVector: 0.8,
+		ComponentTranscript:  0.6,
+		ComponentOCR:         0.4,
+		ComponentObjects:     0.4,
+	}
+}
+
+// ComponentContribution describes how one retrieval component scored a result
+type ComponentContribution struct {
+	Component       string  `json:"component"`
+	Rank            int     `json:"rank"`            // 1-based rank within the component
+	RawScore        float64 `json:"rawScore"`        // Component-native score
+	NormalizedScore float64 `json:"normalizedScore"` // 0-1 within the component
+	Weight          float64 `json:"weight"`
+	Contribution    float64 `json:"contribution"` // Share of the fused score
+	Evidence        string  `json:"evidence,omitempty"`
+}
+
+// ResultContribution lists the per-component contributions for one result
+type ResultContribution struct {
+	VideoID    string                  `json:"videoId"`
+	FinalScore float64                 `json:"finalScore"`
+	Components []ComponentContribution `json:"components"`
+}
+
+// componentHit is a single ranked entry produced by a retrieval component
+type componentHit struct {
+	videoID  string
+	score    float64
+	evidence string
+	matched  int
+	payload  map[string]interface{}
+	vector   []float64
+	scenes   []SceneSearchResult
+}
+
+// componentResult is the ranked output of one retrieval component
+type componentResult struct {
+	name string
+	hits []componentHit
+	err  error
+}
+
+// fusedCandidate accumulates fused scores for one video
+type fusedCandidate struct {
+	videoID       string
+	score         float64
+	payload       map[string]interface{}
+	vector        []float64
+	scenes        []SceneSearchResult
+	objectMatches int
+	contributions []ComponentContribution
+}
+
+// SetKeywordSearcher enables transcript, OCR and object-label retrieval for hybrid search
+func (sa *SearchAPI) SetKeywordSearcher(searcher KeywordSearcher) {
+	sa.keywordSearcher = searcher
+}
+
+// hybridCandidates runs vector, keyword and object-label retrieval in parallel and fuses the rankings
+// Returned candidates are unsorted and unthresholded; SearchVideos handles ordering and pagination
+func (sa *SearchAPI) hybridCandidates(ctx context.Context, req VideoSearchRequest) ([]*fusedCandidate, *SearchExplanation, error) {
+	// Step 1: Query embedding (keyword components can still run if this fails for text queries)
+	queryEmbedding, embedErr := sa.getQueryEmbedding(ctx, req)
+	if embedErr != nil {
+		if req.QueryType != QueryTypeText || sa.keywordSearcher == nil {
+			return nil, nil, fmt.Errorf("failed to generate query embedding: %w", embedErr)
+		}
+		log.Printf("Warning: query embedding failed, continuing with keyword components only: %v", embedErr)
+	}
+
+	candidateLimit := maxSearchCandidates
+
+	qdrantFilter := sa.buildQdrantFilter(req.TenantID, req.Filters)
+	objectLabels := normalizeTerms(req.Filters.Objects)
+	if len(objectLabels) == 0 && req.QueryType == QueryTypeText {
+		objectLabels = queryObjectTerms(req.Query)
+	}
+
+	// Step 2: Run components in parallel
+	tasks := make(map[string]func() ([]componentHit, error))
+
+	if embedErr == nil {
+		tasks[ComponentVideoVector] = func() ([]componentHit, error) {
+			return sa.videoVectorComponent(ctx, queryEmbedding, candidateLimit, req.Options.ScoreThreshold, qdrantFilter)
+		}
+		tasks[ComponentSceneVector] = func() ([]componentHit, error) {
+			return sa.sceneVectorComponent(ctx, queryEmbedding, candidateLimit, req.Options.ScoreThreshold, qdrantFilter)
+		}
+	}
+
+	if sa.keywordSearcher != nil {
+		if req.QueryType == QueryTypeText && strings.TrimSpace(req.Query) != "" {
+			tasks[ComponentTranscript] = func() ([]componentHit, error) {
+				matches, err := sa.keywordSearcher.SearchTranscripts(ctx, req.TenantID, req.Query, req.Filters.VideoIDs, candidateLimit)
+				return keywordHits(matches), err
+			}
+			tasks[ComponentOCR] = func() ([]componentHit, error) {
+				matches, err := sa.keywordSearcher.SearchTextExtractions(ctx, req.TenantID, req.Query, req.Filters.VideoIDs, candidateLimit)
+				return keywordHits(matches), err
+			}
+		}
+		if len(objectLabels) > 0 {
+			tasks[ComponentObjects] = func() ([]componentHit, error) {
+				matches, err := sa.keywordSearcher.SearchObjectLabels(ctx, req.TenantID, objectLabels, req.Filters.VideoIDs, candidateLimit)
+				return keywordHits(matches), err
+			}
+		}
+	} else if len(req.Filters.Objects) > 0 {
+		log.Printf("Warning: object filter requested but no keyword searcher configured - filter not applied")
+	}
+
+	results := make(chan componentResult, len(tasks))
+	var wg sync.WaitGroup
+	for name, task := range tasks {
+		wg.Add(1)
+		go func(name string, task func() ([]componentHit, error)) {
+			defer wg.Done()
+			hits, err := task()
+			results <- componentResult{name: name, hits: hits, err: err}
+		}(name, task)
+	}
+	wg.Wait()
+	close(results)
+
+	components := make(map[string][]componentHit)
+	componentErrors := make(map[string]string)
+	for result := range results {
+		if result.err != nil {
+			log.Printf("Warning: hybrid component %s failed: %v", result.name, result.err)
+			componentErrors[result.name] = result.err.Error()
+			continue
+		}
+		components[result.name] = result.hits
+	}
+
+	if len(components) == 0 {
+		return nil, nil, fmt.Errorf("all hybrid search components failed")
+	}
+
+	// Step 3: Fuse rankings
+	method := req.Options.Fusion
+	if method == "" {
+		method = FusionRRF
+	}
+	weights := DefaultComponentWeights()
+	for name, weight := range req.Options.ComponentWeights {
+		weights[name] = weight
+	}
+
+	candidates := fuseComponents(components, weights, method)
+
+	// Step 4: Apply "must contain objects" filter
+	if len(req.Filters.Objects) > 0 && sa.keywordSearcher != nil {
+		required := len(normalizeTerms(req.Filters.Objects))
+		filtered := candidates[:0]
+		for _, candidate := range candidates {
+			if candidate.objectMatches >= required {
+				filtered = append(filtered, candidate)
+			}
+		}
+		candidates = filtered
+	}
+
+	var explanation *SearchExplanation
+	if req.Options.Explain {
+		explanation = sa.generateSearchExplanation(req)
+		explanation.MatchingStrategy = fmt.Sprintf("Hybrid retrieval fused with %s", method)
+		explanation.FusionMethod = string(method)
+
+		componentHits := make(map[string]int)
+		for name, hits := range components {
+			componentHits[name] = len(hits)
+		}
+		explanation.Attributes["componentHits"] = componentHits
+		explanation.Attributes["componentWeights"] = weights
+		if len(componentErrors) > 0 {
+			explanation.Attributes["componentErrors"] = componentErrors
+		}
+	}
+
+	return candidates, explanation, nil
+}
+
+// videoVectorComponent ranks videos by video-level embedding similarity
+// Videos below scoreThreshold similarity are dropped before fusion
+func (sa *SearchAPI) videoVectorComponent(ctx context.Context, queryEmbedding []float64, limit int, scoreThreshold float64, filter map[string]interface{}) ([]componentHit, error) {
+	results, err := sa.qdrantManager.SearchSimilarVideos(ctx, queryEmbedding, limit, scoreThreshold, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]componentHit, 0, len(results))
+	for _, result := range results {
+		videoID := sa.extractStringField(result.Payload, "video_id")
+		if videoID == "" {
+			videoID = result.ID
+		}
+		hits = append(hits, componentHit{
+			videoID:  videoID,
+			score:    result.Score,
+			evidence: fmt.Sprintf("video embedding similarity %.3f", result.Score),
+			payload:  result.Payload,
+			vector:   result.Vector,
+		})
+	}
+
+	return hits, nil
+}
+
+// sceneVectorComponent ranks videos by their best matching scene embedding
+// Scenes below scoreThreshold similarity are dropped before fusion
+func (sa *SearchAPI) sceneVectorComponent(ctx context.Context, queryEmbedding []float64, limit int, scoreThreshold float64, filter map[string]interface{}) ([]componentHit, error) {
+	results, err := sa.qdrantManager.SearchSimilarScenes(ctx, queryEmbedding, limit, scoreThreshold, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	byVideo := make(map[string]*componentHit)
+	order := make([]string, 0)
+
+	for _, result := range results {
+		scene := SceneSearchResult{
+			SceneID:    result.ID,
+			VideoID:    sa.extractStringField(result.Payload, "video_id"),
+			Score:      result.Score,
+			StartFrame: sa.extractIntField(result.Payload, "start_frame"),
+			EndFrame:   sa.extractIntField(result.Payload, "end_frame"),
+			Duration:   sa.extractFloatField(result.Payload, "duration"),
+			SceneType:  sa.extractStringField(result.Payload, "scene_type"),
+		}
+		if scene.VideoID == "" {
+			continue
+		}
+
+		hit, exists := byVideo[scene.VideoID]
+		if !exists {
+			hit = &componentHit{videoID: scene.VideoID}
+			byVideo[scene.VideoID] = hit
+			order = append(order, scene.VideoID)
+		}
+		if result.Score > hit.score {
+			hit.score = result.Score
+			hit.evidence = fmt.Sprintf("best scene %s similarity %.3f", scene.SceneID, result.Score)
+		}
+		if len(hit.scenes) < 5 {
+			hit.scenes = append(hit.scenes, scene)
+		}
+	}
+
+	hits := make([]componentHit, 0, len(order))
+	for _, videoID := range order {
+		hits = append(hits, *byVideo[videoID])
+	}
+
+	sortHits(hits)
+	return hits, nil
+}
+
+// keywordHits converts storage keyword matches into ranked component hits
+func keywordHits(matches []models.KeywordMatch) []componentHit {
+	hits := make([]componentHit, 0, len(matches))
+	for _, match := range matches {
+		evidence := match.Snippet
+		if match.Source == ComponentObjects {
+			evidence = fmt.Sprintf("labels: %s (%d detections)", match.Snippet, int(match.Score))
+		} else if match.Timestamp > 0 {
+			evidence = fmt.Sprintf("%s @%.1fs", match.Snippet, match.Timestamp)
+		}
+		hits = append(hits, componentHit{
+			videoID:  match.JobID,
+			score:    match.Score,
+			evidence: evidence,
+			matched:  match.MatchedTerms,
+		})
+	}
+
+	sortHits(hits)
+	return hits
+}
+
+// fuseComponents combines component rankings into one candidate list
+func fuseComponents(components map[string][]componentHit, weights map[string]float64, method FusionMethod) []*fusedCandidate {
+	// Iterate components in a fixed order so fused output is deterministic
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Normaliser so fused scores stay within 0-1
+	totalWeight := 0.0
+	for _, name := range names {
+		totalWeight += weights[name]
+	}
+	if totalWeight <= 0 {
+		totalWeight = 1.0
+	}
+	maxRRF := totalWeight / (rrfK + 1)
+
+	byVideo := make(map[string]*fusedCandidate)
+	candidates := make([]*fusedCandidate, 0)
+
+	for _, name := range names {
+		hits := components[name]
+		weight := weights[name]
+
+		minScore, maxScore := scoreRange(hits)
+
+		for rank, hit := range hits {
+			candidate, exists := byVideo[hit.videoID]
+			if !exists {
+				candidate = &fusedCandidate{videoID: hit.videoID}
+				byVideo[hit.videoID] = candidate
+				candidates = append(candidates, candidate)
+			}
+
+			normalized := 1.0
+			if maxScore > minScore {
+				normalized = (hit.score - minScore) / (maxScore - minScore)
+			}
+
+			var contribution float64
+			switch method {
+			case FusionWeighted:
+				contribution = weight * normalized / totalWeight
+			default:
+				contribution = weight / (rrfK + float64(rank+1)) / maxRRF
+			}
+
+			candidate.score += contribution
+			candidate.contributions = append(candidate.contributions, ComponentContribution{
+				Component:       name,
+				Rank:            rank + 1,
+				RawScore:        hit.score,
+				NormalizedScore: normalized,
+				Weight:          weight,
+				Contribution:    contribution,
+				Evidence:        hit.evidence,
+			})
+
+			if hit.payload != nil {
+				candidate.payload = hit.payload
+				candidate.vector = hit.vector
+			}
+			if len(hit.scenes) > 0 {
+				candidate.scenes = hit.scenes
+			}
+			if name == ComponentObjects {
+				candidate.objectMatches = hit.matched
+			}
+		}
+	}
+
+	return candidates
+}
+
+// scoreRange returns the min and max score of a hit list
+func scoreRange(hits []componentHit) (float64, float64) {
+	if len(hits) == 0 {
+		return 0, 0
+	}
+	minScore, maxScore := hits[0].score, hits[0].score
+	for _, hit := range hits[1:] {
+		if hit.score < minScore {
+			minScore = hit.score
+		}
+		if hit.score > maxScore {
+			maxScore = hit.score
+		}
+	}
+	return minScore, maxScore
+}
+
+// sortHits orders hits by score descending with video ID as tie-breaker
+func sortHits(hits []componentHit) {
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return hits[i].videoID < hits[j].videoID
+	})
+}
+
+// normalizeTerms lowercases, trims and de-duplicates terms
+func normalizeTerms(terms []string) []string {
+	seen := make(map[string]bool)
+	normalized := make([]string, 0, len(terms))
+	for _, term := range terms {
+		term = strings.ToLower(strings.Trim(term, " \t\n.,;:!?\"'()"))
+		if term == "" || seen[term] {
+			continue
+		}
+		seen[term] = true
+		normalized = append(normalized, term)
+	}
+	return normalized
+}
+
+// queryStopwords are common words that never name a detected object
+var queryStopwords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "of": true, "in": true,
+	"on": true, "at": true, "to": true, "for": true, "with": true, "from": true, "by": true,
+	"is": true, "are": true, "was": true, "were": true, "be": true, "it": true, "its": true,
+	"this": true, "that": true, "these": true, "those": true, "some": true, "any": true,
+	"who": true, "what": true, "where": true, "when": true, "which": true, "how": true,
+	"video": true, "videos": true, "clip": true, "clips": true, "scene": true, "scenes": true,
+	"show": true, "shows": true, "showing": true, "find": true, "me": true, "there": true,
+}
+
+// queryObjectTerms derives object labels from a free-text query, dropping stopwords
+// and one-letter terms so they don't rank videos on labels nobody asked for
+func queryObjectTerms(query string) []string {
+	terms := make([]string, 0)
+	for _, term := range normalizeTerms(strings.Fields(query)) {
+		if len(term) < 2 || queryStopwords[term] {
+			continue
+		}
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+// describeContributions renders a one-line explanation of a fused result
+func describeContributions(candidate *fusedCandidate) string {
+	parts := make([]string, 0, len(candidate.contributions))
+	for _, c := range candidate.contributions {
+		parts = append(parts, fmt.Sprintf("%s #%d (+%.3f)", c.Component, c.Rank, c.Contribution))
+	}
+	return fmt.Sprintf("Fused score %.3f from %s", candidate.score, strings.Join(parts, ", "))
+}
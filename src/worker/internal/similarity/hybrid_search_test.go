@@ -0,0 +1,238 @@
+package similarity
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// fusionComponents are fixed component rankings shared by the fusion tests
+//
+//	video_vector: v1 0.9, v2 0.7, v3 0.5
+//	transcript:   v2 4.0, v3 2.0, v4 1.0
+//	objects:      v3 6 (2 labels), v1 2 (1 label)
+func fusionComponents() map[string][]componentHit {
+	return map[string][]componentHit{
+		ComponentVideoVector: {
+			{videoID: "v1", score: 0.9, evidence: "video embedding similarity 0.900"},
+			{videoID: "v2", score: 0.7, evidence: "video embedding similarity 0.700"},
+			{videoID: "v3", score: 0.5, evidence: "video embedding similarity 0.500"},
+		},
+		ComponentTranscript: {
+			{videoID: "v2", score: 4.0, evidence: "red car"},
+			{videoID: "v3", score: 2.0, evidence: "car"},
+			{videoID: "v4", score: 1.0, evidence: "red"},
+		},
+		ComponentObjects: {
+			{videoID: "v3", score: 6, matched: 2, evidence: "labels: car, person (6 detections)"},
+			{videoID: "v1", score: 2, matched: 1, evidence: "labels: car (2 detections)"},
+		},
+	}
+}
+
+// fusionWeights overrides the transcript weight so no two videos tie
+func fusionWeights() map[string]float64 {
+	weights := DefaultComponentWeights()
+	weights[ComponentTranscript] = 0.6
+	weights[ComponentObjects] = 0.5
+	return weights
+}
+
+func TestFuseComponents(t *testing.T) {
+	// Weights present in the components sum to 1.0 + 0.6 + 0.5
+	const totalWeight = 2.1
+	const maxRRF = totalWeight / (rrfK + 1)
+
+	tests := []struct {
+		name   string
+		method FusionMethod
+		order  []string
+		scores map[string]float64
+	}{
+		{
+			name:   "reciprocal rank fusion",
+			method: FusionRRF,
+			order:  []string{"v3", "v2", "v1", "v4"},
+			scores: map[string]float64{
+				"v1": (1.0/61 + 0.5/62) / maxRRF,
+				"v2": (1.0/62 + 0.6/61) / maxRRF,
+				"v3": (1.0/63 + 0.6/62 + 0.5/61) / maxRRF,
+				"v4": (0.6 / 63) / maxRRF,
+			},
+		},
+		{
+			name:   "weighted min-max",
+			method: FusionWeighted,
+			order:  []string{"v2", "v1", "v3", "v4"},
+			scores: map[string]float64{
+				"v1": (1.0*1 + 0.5*0) / totalWeight,
+				"v2": (1.0*0.5 + 0.6*1) / totalWeight,
+				"v3": (1.0*0 + 0.6*(1.0/3) + 0.5*1) / totalWeight,
+				"v4": (0.6 * 0) / totalWeight,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := fuseComponents(fusionComponents(), fusionWeights(), tt.method)
+			sortCandidates(candidates)
+
+			if len(candidates) != len(tt.order) {
+				t.Fatalf("got %d candidates, want %d", len(candidates), len(tt.order))
+			}
+			for i, candidate := range candidates {
+				if candidate.videoID != tt.order[i] {
+					t.Errorf("rank %d = %s, want %s", i+1, candidate.videoID, tt.order[i])
+				}
+				if want := tt.scores[candidate.videoID]; math.Abs(candidate.score-want) > 1e-9 {
+					t.Errorf("%s score = %v, want %v", candidate.videoID, candidate.score, want)
+				}
+				if candidate.score < 0 || candidate.score > 1 {
+					t.Errorf("%s score %v outside [0,1]", candidate.videoID, candidate.score)
+				}
+
+				// Contributions explain the whole fused score
+				sum := 0.0
+				for _, contribution := range candidate.contributions {
+					sum += contribution.Contribution
+				}
+				if math.Abs(sum-candidate.score) > 1e-9 {
+					t.Errorf("%s contributions sum to %v, score is %v", candidate.videoID, sum, candidate.score)
+				}
+			}
+		})
+	}
+}
+
+func TestFuseComponentsExplainsContributions(t *testing.T) {
+	tests := []struct {
+		method FusionMethod
+		want   []ComponentContribution
+	}{
+		{
+			method: FusionRRF,
+			want: []ComponentContribution{
+				{Component: ComponentObjects, Rank: 1, RawScore: 6, NormalizedScore: 1, Weight: 0.5, Contribution: 0.5 / 61 / (2.1 / 61)},
+				{Component: ComponentTranscript, Rank: 2, RawScore: 2, NormalizedScore: 1.0 / 3, Weight: 0.6, Contribution: 0.6 / 62 / (2.1 / 61)},
+				{Component: ComponentVideoVector, Rank: 3, RawScore: 0.5, NormalizedScore: 0, Weight: 1.0, Contribution: 1.0 / 63 / (2.1 / 61)},
+			},
+		},
+		{
+			method: FusionWeighted,
+			want: []ComponentContribution{
+				{Component: ComponentObjects, Rank: 1, RawScore: 6, NormalizedScore: 1, Weight: 0.5, Contribution: 0.5 / 2.1},
+				{Component: ComponentTranscript, Rank: 2, RawScore: 2, NormalizedScore: 1.0 / 3, Weight: 0.6, Contribution: 0.6 / 3 / 2.1},
+				{Component: ComponentVideoVector, Rank: 3, RawScore: 0.5, NormalizedScore: 0, Weight: 1.0, Contribution: 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			var v3 *fusedCandidate
+			for _, candidate := range fuseComponents(fusionComponents(), fusionWeights(), tt.method) {
+				if candidate.videoID == "v3" {
+					v3 = candidate
+				}
+			}
+			if v3 == nil {
+				t.Fatal("v3 missing from fused candidates")
+			}
+			if v3.objectMatches != 2 {
+				t.Errorf("objectMatches = %d, want 2", v3.objectMatches)
+			}
+
+			// Components are explained in name order
+			if len(v3.contributions) != len(tt.want) {
+				t.Fatalf("got %d contributions, want %d", len(v3.contributions), len(tt.want))
+			}
+			for i, got := range v3.contributions {
+				want := tt.want[i]
+				if got.Component != want.Component || got.Rank != want.Rank || got.RawScore != want.RawScore || got.Weight != want.Weight {
+					t.Errorf("contribution %d = %+v, want %+v", i, got, want)
+				}
+				if math.Abs(got.NormalizedScore-want.NormalizedScore) > 1e-9 || math.Abs(got.Contribution-want.Contribution) > 1e-9 {
+					t.Errorf("%s normalized/contribution = %v/%v, want %v/%v", got.Component, got.NormalizedScore, got.Contribution, want.NormalizedScore, want.Contribution)
+				}
+				if got.Evidence == "" {
+					t.Errorf("%s contribution has no evidence", got.Component)
+				}
+			}
+		})
+	}
+}
+
+func TestFuseComponentsZeroWeights(t *testing.T) {
+	weights := map[string]float64{ComponentVideoVector: 0, ComponentTranscript: 0, ComponentObjects: 0}
+	for _, method := range []FusionMethod{FusionRRF, FusionWeighted} {
+		for _, candidate := range fuseComponents(fusionComponents(), weights, method) {
+			if candidate.score != 0 {
+				t.Errorf("%s: %s score = %v with zero weights, want 0", method, candidate.videoID, candidate.score)
+			}
+		}
+	}
+}
+
+// fakeKeywordSearcher returns fixed matches for each keyword component
+type fakeKeywordSearcher struct {
+	transcripts []models.KeywordMatch
+	objects     []models.KeywordMatch
+	labels      []string
+}
+
+func (s *fakeKeywordSearcher) SearchTranscripts(ctx context.Context, tenantID, query string, videoIDs []string, limit int) ([]models.KeywordMatch, error) {
+	return s.transcripts, nil
+}
+
+func (s *fakeKeywordSearcher) SearchTextExtractions(ctx context.Context, tenantID, query string, videoIDs []string, limit int) ([]models.KeywordMatch, error) {
+	return []models.KeywordMatch{}, nil
+}
+
+func (s *fakeKeywordSearcher) SearchObjectLabels(ctx context.Context, tenantID string, labels []string, videoIDs []string, limit int) ([]models.KeywordMatch, error) {
+	s.labels = labels
+	return s.objects, nil
+}
+
+func TestHybridObjectFilterRequiresAllLabels(t *testing.T) {
+	_, qm := newFakeQdrant(t)
+	qm.InitializeCollections(context.Background())
+
+	searcher := &fakeKeywordSearcher{
+		transcripts: []models.KeywordMatch{
+			{JobID: "v1", Source: ComponentTranscript, Score: 3, MatchedTerms: 2, Snippet: "a dog and a person"},
+			{JobID: "v2", Source: ComponentTranscript, Score: 1, MatchedTerms: 1, Snippet: "dog"},
+		},
+		objects: []models.KeywordMatch{
+			{JobID: "v2", Source: ComponentObjects, Score: 9, MatchedTerms: 2, Snippet: "dog, person"},
+			{JobID: "v1", Source: ComponentObjects, Score: 4, MatchedTerms: 1, Snippet: "dog"},
+		},
+	}
+	sa := &SearchAPI{qdrantManager: qm}
+	sa.SetKeywordSearcher(searcher)
+
+	candidates, _, err := sa.hybridCandidates(context.Background(), VideoSearchRequest{
+		TenantID:       "org:acme",
+		QueryType:      QueryTypeText,
+		Query:          "dog with a person",
+		QueryEmbedding: unitVector(0),
+		Filters:        SearchFilters{Objects: []string{"Dog", "person", "dog"}},
+	})
+	if err != nil {
+		t.Fatalf("hybridCandidates: %v", err)
+	}
+
+	// v1 matches the transcript but only one of the two required labels
+	if len(candidates) != 1 || candidates[0].videoID != "v2" {
+		ids := make([]string, 0, len(candidates))
+		for _, candidate := range candidates {
+			ids = append(ids, candidate.videoID)
+		}
+		t.Fatalf("candidates = %v, want only v2", ids)
+	}
+	if len(searcher.labels) != 2 || searcher.labels[0] != "dog" || searcher.labels[1] != "person" {
+		t.Errorf("object labels = %v, want normalised [dog person]", searcher.labels)
+	}
+}
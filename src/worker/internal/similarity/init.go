@@ -6,6 +6,7 @@ import (
 	"log"
 
 	"github.com/adverant/nexus/videoagent-worker/internal/clients"
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
 )
 
 // SimilarityModule encapsulates all similarity search components
@@ -69,6 +70,50 @@ func (sm *SimilarityModule) InitializeCollections(ctx context.Context) error {
 	return nil
 }
 
+// IndexVideo embeds a processed video and its scenes and stores them under the job's tenant
+// frames are base64-encoded images in time order; scenes with cinematic analysis
+// (may be empty) label the scene embeddings they overlap
+func (sm *SimilarityModule) IndexVideo(ctx context.Context, job *models.JobPayload, frames []string, metadata VideoMetadata, scenes []models.SceneDetection) (*VideoEmbedding, []SceneEmbedding, error) {
+	if job.TenantID() == "" {
+		return nil, nil, fmt.Errorf("job %s has no tenant (user or organisation) to index under", job.JobID)
+	}
+	if len(frames) == 0 {
+		return nil, nil, fmt.Errorf("no frames to embed")
+	}
+
+	videoEmbedding, err := sm.VideoEmbedder.GenerateEmbedding(ctx, job, frames, metadata)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to embed video: %w", err)
+	}
+
+	// Scenes inherit the video's owner
+	sceneEmbeddings, err := sm.SceneEmbedder.GenerateSceneEmbeddings(ctx, job.JobID, frames, videoEmbedding)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to embed scenes: %w", err)
+	}
+
+	// Scene type, mood and look from cinematic analysis of the detected scenes
+	// Scene embedding frames index the sampled frames, which span metadata.Duration
+	if len(scenes) > 0 {
+		frameRate := 0.0
+		if metadata.Duration > 0 {
+			frameRate = float64(len(frames)) / metadata.Duration
+		}
+		ApplySceneCinematics(sceneEmbeddings, scenes, frameRate)
+	}
+
+	if err := sm.QdrantManager.InsertVideoEmbedding(ctx, videoEmbedding); err != nil {
+		return nil, nil, err
+	}
+	if len(sceneEmbeddings) > 0 {
+		if err := sm.QdrantManager.InsertSceneEmbeddingsBatch(ctx, sceneEmbeddings); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return videoEmbedding, sceneEmbeddings, nil
+}
+
 // HealthCheck verifies all components are operational
 func (sm *SimilarityModule) HealthCheck(ctx context.Context) error {
 	// TODO: Implement health checks for each component
@@ -0,0 +1,94 @@
+package similarity
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// InteractionSource provides the interactions and interaction events recorded by tracking
+// Implemented by storage.StorageManager
+type InteractionSource interface {
+	QueryInteractions(ctx context.Context, tenantID string, query models.InteractionQuery) ([]models.InteractionRecord, error)
+	GetInteractionEvents(ctx context.Context, tenantID, jobID, interactionID string) ([]models.InteractionEventRecord, error)
+}
+
+// InteractionSearchRequest filters a video's recorded interactions
+// e.g. {Query: {JobID: X, Type: "handoff", Class: "person"}} finds handoffs between people
+type InteractionSearchRequest struct {
+	TenantID      string                  `json:"tenantId"` // Required: only this tenant's videos are searched
+	Query         models.InteractionQuery `json:"query"`
+	IncludeEvents bool                    `json:"includeEvents"` // Attach each interaction's state changes
+}
+
+// InteractionSearchResponse represents interaction search results
+type InteractionSearchResponse struct {
+	Interactions   []InteractionResult `json:"interactions"`
+	TotalFound     int                 `json:"totalFound"`
+	ProcessingTime float64             `json:"processingTimeMs"`
+}
+
+// InteractionResult is a recorded interaction with its state changes
+type InteractionResult struct {
+	models.InteractionRecord
+	Events []models.InteractionEventRecord `json:"events,omitempty"`
+}
+
+// SetInteractionSource enables search over recorded interactions
+func (sa *SearchAPI) SetInteractionSource(source InteractionSource) {
+	sa.interactionSource = source
+}
+
+// SearchInteractions returns a video's interactions matching the query, ordered by start time
+func (sa *SearchAPI) SearchInteractions(ctx context.Context, req InteractionSearchRequest) (*InteractionSearchResponse, error) {
+	startTime := time.Now()
+
+	if sa.interactionSource == nil {
+		return nil, fmt.Errorf("interaction search requires an interaction source (not configured)")
+	}
+	if req.TenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+	if req.Query.JobID == "" {
+		return nil, fmt.Errorf("job ID is required")
+	}
+
+	// Step 1: Query matching interactions
+	records, err := sa.interactionSource.QueryInteractions(ctx, req.TenantID, req.Query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query interactions: %w", err)
+	}
+
+	results := make([]InteractionResult, len(records))
+	for i, record := range records {
+		results[i] = InteractionResult{InteractionRecord: record}
+	}
+
+	// Step 2: Attach state changes (one query for the whole job)
+	if req.IncludeEvents && len(results) > 0 {
+		events, err := sa.interactionSource.GetInteractionEvents(ctx, req.TenantID, req.Query.JobID, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load interaction events: %w", err)
+		}
+
+		byInteraction := make(map[string][]models.InteractionEventRecord)
+		for _, event := range events {
+			byInteraction[event.InteractionID] = append(byInteraction[event.InteractionID], event)
+		}
+		for i := range results {
+			results[i].Events = byInteraction[results[i].InteractionID]
+		}
+	}
+
+	processingTime := time.Since(startTime).Milliseconds()
+	log.Printf("Interaction search completed: %d interactions for job %s in %dms", len(results), req.Query.JobID, processingTime)
+
+	return &InteractionSearchResponse{
+		Interactions:   results,
+		TotalFound:     len(results),
+		ProcessingTime: float64(processingTime),
+	}, nil
+}
@@ -0,0 +1,137 @@
+package similarity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// fakeInteractionSource serves one tenant's interactions and records the queries it receives
+type fakeInteractionSource struct {
+	tenantID     string
+	interactions []models.InteractionRecord
+	events       []models.InteractionEventRecord
+	queries      []models.InteractionQuery
+	eventLoads   int
+}
+
+func (s *fakeInteractionSource) QueryInteractions(ctx context.Context, tenantID string, query models.InteractionQuery) ([]models.InteractionRecord, error) {
+	s.queries = append(s.queries, query)
+	if tenantID != s.tenantID {
+		return []models.InteractionRecord{}, nil
+	}
+	matches := make([]models.InteractionRecord, 0)
+	for _, interaction := range s.interactions {
+		if query.Type == "" || interaction.Type == query.Type {
+			matches = append(matches, interaction)
+		}
+	}
+	return matches, nil
+}
+
+func (s *fakeInteractionSource) GetInteractionEvents(ctx context.Context, tenantID, jobID, interactionID string) ([]models.InteractionEventRecord, error) {
+	s.eventLoads++
+	if tenantID != s.tenantID {
+		return []models.InteractionEventRecord{}, nil
+	}
+	return s.events, nil
+}
+
+func newInteractionSource() *fakeInteractionSource {
+	return &fakeInteractionSource{
+		tenantID: "tenant-a",
+		interactions: []models.InteractionRecord{
+			{InteractionID: "interaction_1", Type: "meeting", StartTime: 1, EndTime: 3, Duration: 2},
+			{InteractionID: "interaction_2", Type: "handoff", StartTime: 5, EndTime: 6.5, Duration: 1.5},
+		},
+		events: []models.InteractionEventRecord{
+			{EventID: "e1", InteractionID: "interaction_1", EventType: "started", Timestamp: 1},
+			{EventID: "e2", InteractionID: "interaction_2", EventType: "started", InteractionType: "meeting", Timestamp: 5},
+			{EventID: "e3", InteractionID: "interaction_1", EventType: "ended", Timestamp: 3},
+			{EventID: "e4", InteractionID: "interaction_2", EventType: "changed", InteractionType: "handoff", Timestamp: 5.5},
+		},
+	}
+}
+
+func TestSearchInteractionsAttachesEvents(t *testing.T) {
+	source := newInteractionSource()
+	sa := &SearchAPI{}
+	sa.SetInteractionSource(source)
+
+	resp, err := sa.SearchInteractions(context.Background(), InteractionSearchRequest{
+		TenantID:      "tenant-a",
+		Query:         models.InteractionQuery{JobID: "job-1", Type: "handoff", Class: "person"},
+		IncludeEvents: true,
+	})
+	if err != nil {
+		t.Fatalf("SearchInteractions: %v", err)
+	}
+	if resp.TotalFound != 1 || resp.Interactions[0].InteractionID != "interaction_2" {
+		t.Fatalf("interactions = %+v, want interaction_2 only", resp.Interactions)
+	}
+	events := resp.Interactions[0].Events
+	if len(events) != 2 || events[0].EventID != "e2" || events[1].EventID != "e4" {
+		t.Errorf("events = %+v, want e2 then e4", events)
+	}
+	if len(source.queries) != 1 || source.queries[0].Class != "person" {
+		t.Errorf("queries = %+v, want the request's query passed through", source.queries)
+	}
+	if source.eventLoads != 1 {
+		t.Errorf("event loads = %d, want one per search", source.eventLoads)
+	}
+}
+
+func TestSearchInteractionsWithoutEvents(t *testing.T) {
+	source := newInteractionSource()
+	sa := &SearchAPI{}
+	sa.SetInteractionSource(source)
+
+	resp, err := sa.SearchInteractions(context.Background(), InteractionSearchRequest{
+		TenantID: "tenant-a",
+		Query:    models.InteractionQuery{JobID: "job-1"},
+	})
+	if err != nil {
+		t.Fatalf("SearchInteractions: %v", err)
+	}
+	if resp.TotalFound != 2 || resp.Interactions[0].Events != nil {
+		t.Errorf("interactions = %+v, want both without events", resp.Interactions)
+	}
+	if source.eventLoads != 0 {
+		t.Errorf("events loaded without IncludeEvents")
+	}
+
+	// Other tenants see nothing
+	resp, err = sa.SearchInteractions(context.Background(), InteractionSearchRequest{
+		TenantID:      "tenant-b",
+		Query:         models.InteractionQuery{JobID: "job-1"},
+		IncludeEvents: true,
+	})
+	if err != nil || resp.TotalFound != 0 {
+		t.Errorf("tenant-b search = %+v, %v; want no interactions", resp, err)
+	}
+}
+
+func TestSearchInteractionsValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		source InteractionSource
+		req    InteractionSearchRequest
+	}{
+		{"no source", nil, InteractionSearchRequest{TenantID: "tenant-a", Query: models.InteractionQuery{JobID: "job-1"}}},
+		{"no tenant", newInteractionSource(), InteractionSearchRequest{Query: models.InteractionQuery{JobID: "job-1"}}},
+		{"no job", newInteractionSource(), InteractionSearchRequest{TenantID: "tenant-a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sa := &SearchAPI{}
+			if tt.source != nil {
+				sa.SetInteractionSource(tt.source)
+			}
+			if _, err := sa.SearchInteractions(context.Background(), tt.req); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
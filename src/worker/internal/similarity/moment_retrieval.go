@@ -0,0 +1,585 @@
+package similarity
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// TimelineSource provides time-coded frame descriptions and transcript segments for a video,
+// with the embeddings stored for them at ingest
+// Implemented by storage.StorageManager
+type TimelineSource interface {
+	GetFrameTimeline(ctx context.Context, tenantID, videoID string) ([]models.FrameAnalysis, error)
+	GetTranscriptSegments(ctx context.Context, tenantID, videoID string) ([]models.SpeakerSegment, error)
+	GetTimelineEmbeddings(ctx context.Context, tenantID, videoID string) (map[string]map[string][]float64, error)
+}
+
+// MomentSearchRequest represents a temporal moment retrieval request
+type MomentSearchRequest struct {
+	TenantID         string   `json:"tenantId"`         // Required: only this tenant's videos are searched
+	Query            string   `json:"query"`            // Natural-language query
+	VideoIDs         []string `json:"videoIds"`         // Videos to search (empty = top hybrid search hits)
+	CandidateVideos  int      `json:"candidateVideos"`  // Videos taken from search when VideoIDs is empty (default 5)
+	Limit            int      `json:"limit"`            // Max moments returned (default 10)
+	WindowSize       float64  `json:"windowSize"`       // Sliding window length in seconds (default 4)
+	WindowStride     float64  `json:"windowStride"`     // Sliding window step in seconds (default 1)
+	MergeGap         float64  `json:"mergeGap"`         // Merge high-scoring windows closer than this (seconds, default 1)
+	MinScore         float64  `json:"minScore"`         // Absolute window score floor (0 = adaptive only)
+	VisualWeight     float64  `json:"visualWeight"`     // Frame score weight (default 0.6)
+	TranscriptWeight float64  `json:"transcriptWeight"` // Transcript score weight (default 0.4)
+}
+
+// MomentSearchResponse represents moment retrieval results
+type MomentSearchResponse struct {
+	Moments        []Moment `json:"moments"`
+	TotalFound     int      `json:"totalFound"`
+	Query          string   `json:"query"`
+	VideosSearched int      `json:"videosSearched"`
+	ProcessingTime float64  `json:"processingTimeMs"`
+}
+
+// Moment is a time range within a video that matches the query
+type Moment struct {
+	VideoID         string   `json:"videoId"`
+	Start           float64  `json:"start"` // Seconds
+	End             float64  `json:"end"`   // Seconds
+	Score           float64  `json:"score"` // Peak window score (0-1)
+	MeanScore       float64  `json:"meanScore"`
+	VisualScore     float64  `json:"visualScore"`
+	TranscriptScore float64  `json:"transcriptScore"`
+	PeakTimestamp   float64  `json:"peakTimestamp"` // Best matching frame timestamp
+	Rank            int      `json:"rank"`
+	FrameIDs        []string `json:"frameIds,omitempty"`
+	Description     string   `json:"description,omitempty"` // Best matching frame description
+	Transcript      string   `json:"transcript,omitempty"`  // Transcript overlapping the moment
+}
+
+// timedScore is a scored item spanning [start, end] seconds
+type timedScore struct {
+	start float64
+	end   float64
+	at    float64 // Representative timestamp (frame time)
+	score float64
+	id    string
+	text  string
+}
+
+// momentWindow is one scored sliding window
+type momentWindow struct {
+	start      float64
+	end        float64
+	visual     float64
+	transcript float64
+	score      float64
+}
+
+// SetTimelineSource enables moment retrieval over stored frame and transcript timelines
+func (sa *SearchAPI) SetTimelineSource(source TimelineSource) {
+	sa.timelineSource = source
+}
+
+// SearchMoments returns precise time ranges matching a natural-language query
+func (sa *SearchAPI) SearchMoments(ctx context.Context, req MomentSearchRequest) (*MomentSearchResponse, error) {
+	startTime := time.Now()
+
+	if sa.timelineSource == nil {
+		return nil, fmt.Errorf("moment retrieval requires a timeline source (not configured)")
+	}
+	if req.TenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, fmt.Errorf("moment query is empty")
+	}
+
+	sa.applyMomentDefaults(&req)
+
+	// Step 1: Embed the query
+	queryEmbedding, err := sa.textToEmbedding(ctx, req.Query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed moment query: %w", err)
+	}
+
+	// Step 2: Choose candidate videos
+	videoIDs := req.VideoIDs
+	if len(videoIDs) == 0 {
+		searchResp, err := sa.SearchVideos(ctx, VideoSearchRequest{
+			TenantID:       req.TenantID,
+			QueryType:      QueryTypeText,
+			Query:          req.Query,
+			QueryEmbedding: queryEmbedding,
+			Limit:          req.CandidateVideos,
+			Options:        SearchOptions{Hybrid: true},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("candidate video search failed: %w", err)
+		}
+		for _, result := range searchResp.Results {
+			videoIDs = append(videoIDs, result.VideoID)
+		}
+	}
+
+	// Step 3: Score each video's timeline
+	moments := make([]Moment, 0)
+	for _, videoID := range videoIDs {
+		videoMoments, err := sa.findMomentsInVideo(ctx, videoID, queryEmbedding, req)
+		if err != nil {
+			log.Printf("Warning: moment retrieval failed for video %s: %v", videoID, err)
+			continue
+		}
+		moments = append(moments, videoMoments...)
+	}
+
+	// Step 4: Rank across videos (score desc, then video and start for stability)
+	sort.Slice(moments, func(i, j int) bool {
+		if moments[i].Score != moments[j].Score {
+			return moments[i].Score > moments[j].Score
+		}
+		if moments[i].VideoID != moments[j].VideoID {
+			return moments[i].VideoID < moments[j].VideoID
+		}
+		return moments[i].Start < moments[j].Start
+	})
+
+	totalFound := len(moments)
+	if len(moments) > req.Limit {
+		moments = moments[:req.Limit]
+	}
+	for i := range moments {
+		moments[i].Rank = i + 1
+	}
+
+	processingTime := time.Since(startTime).Milliseconds()
+	log.Printf("Moment search completed: %d moments across %d videos in %dms", totalFound, len(videoIDs), processingTime)
+
+	return &MomentSearchResponse{
+		Moments:        moments,
+		TotalFound:     totalFound,
+		Query:          req.Query,
+		VideosSearched: len(videoIDs),
+		ProcessingTime: float64(processingTime),
+	}, nil
+}
+
+// applyMomentDefaults fills unset moment request parameters
+func (sa *SearchAPI) applyMomentDefaults(req *MomentSearchRequest) {
+	if req.CandidateVideos <= 0 {
+		req.CandidateVideos = 5
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+	if req.WindowSize <= 0 {
+		req.WindowSize = 4.0
+	}
+	if req.WindowStride <= 0 {
+		req.WindowStride = 1.0
+	}
+	if req.MergeGap <= 0 {
+		req.MergeGap = 1.0
+	}
+	if req.VisualWeight <= 0 && req.TranscriptWeight <= 0 {
+		req.VisualWeight = 0.6
+		req.TranscriptWeight = 0.4
+	}
+}
+
+// findMomentsInVideo scores a single video's timeline with a sliding window
+func (sa *SearchAPI) findMomentsInVideo(ctx context.Context, videoID string, queryEmbedding []float64, req MomentSearchRequest) ([]Moment, error) {
+	frames, err := sa.timelineSource.GetFrameTimeline(ctx, req.TenantID, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load frames: %w", err)
+	}
+
+	segments, err := sa.timelineSource.GetTranscriptSegments(ctx, req.TenantID, videoID)
+	if err != nil {
+		log.Printf("Warning: transcript unavailable for video %s: %v", videoID, err)
+		segments = nil
+	}
+
+	stored, err := sa.timelineSource.GetTimelineEmbeddings(ctx, req.TenantID, videoID)
+	if err != nil {
+		log.Printf("Warning: stored timeline embeddings unavailable for video %s: %v", videoID, err)
+		stored = nil
+	}
+
+	// Step 1: Score frames and transcript segments against the query
+	frameScores, err := sa.scoreFrames(ctx, frames, stored[models.EmbeddingKindFrame], queryEmbedding)
+	if err != nil {
+		return nil, err
+	}
+
+	segmentScores, err := sa.scoreSegments(ctx, segments, stored[models.EmbeddingKindSegment], queryEmbedding)
+	if err != nil {
+		log.Printf("Warning: transcript scoring failed for video %s: %v", videoID, err)
+		segmentScores = nil
+	}
+
+	if len(frameScores) == 0 && len(segmentScores) == 0 {
+		return []Moment{}, nil
+	}
+
+	// Step 2: Slide a window across the timeline
+	duration := 0.0
+	for _, fs := range frameScores {
+		duration = math.Max(duration, fs.end)
+	}
+	for _, ss := range segmentScores {
+		duration = math.Max(duration, ss.end)
+	}
+
+	windows := slideWindows(frameScores, segmentScores, duration, req)
+	if len(windows) == 0 {
+		return []Moment{}, nil
+	}
+
+	// Step 3: Keep high-scoring windows and merge adjacent ones into moments
+	threshold := adaptiveThreshold(windows, req.MinScore)
+	moments := mergeWindows(windows, threshold, req.MergeGap)
+
+	// Step 4: Attach evidence (best frame, overlapping transcript)
+	for i := range moments {
+		moments[i].VideoID = videoID
+		attachMomentEvidence(&moments[i], frameScores, segmentScores)
+	}
+
+	return moments, nil
+}
+
+// scoreFrames scores frame descriptions and assigns each frame a time span around its timestamp
+// Embeddings stored at ingest are reused; only frames without one are embedded here
+func (sa *SearchAPI) scoreFrames(ctx context.Context, frames []models.FrameAnalysis, stored map[string][]float64, queryEmbedding []float64) ([]timedScore, error) {
+	described := make([]models.FrameAnalysis, 0, len(frames))
+	for _, frame := range frames {
+		if strings.TrimSpace(frame.Description) != "" {
+			described = append(described, frame)
+		}
+	}
+	if len(described) == 0 {
+		return []timedScore{}, nil
+	}
+
+	keys := make([]string, len(described))
+	texts := make([]string, len(described))
+	for i, frame := range described {
+		keys[i] = frame.FrameID
+		texts[i] = frame.Description
+	}
+
+	embeddings, err := sa.timelineEmbeddings(ctx, keys, texts, stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed frame descriptions: %w", err)
+	}
+
+	// Each frame covers the span halfway to its neighbours
+	scores := make([]timedScore, len(described))
+	for i, frame := range described {
+		start := frame.Timestamp
+		end := frame.Timestamp
+		if i > 0 {
+			start = (described[i-1].Timestamp + frame.Timestamp) / 2
+		}
+		if i < len(described)-1 {
+			end = (frame.Timestamp + described[i+1].Timestamp) / 2
+		} else if i > 0 {
+			end = frame.Timestamp + (frame.Timestamp-described[i-1].Timestamp)/2
+		}
+		if i == 0 {
+			start = math.Max(0, frame.Timestamp-(end-frame.Timestamp))
+		}
+
+		scores[i] = timedScore{
+			start: start,
+			end:   end,
+			at:    frame.Timestamp,
+			score: clampUnit(cosineSimilarity(queryEmbedding, embeddings[i])),
+			id:    frame.FrameID,
+			text:  frame.Description,
+		}
+	}
+
+	return scores, nil
+}
+
+// scoreSegments scores transcript segments against the query
+// Embeddings stored at ingest are reused; only segments without one are embedded here
+func (sa *SearchAPI) scoreSegments(ctx context.Context, segments []models.SpeakerSegment, stored map[string][]float64, queryEmbedding []float64) ([]timedScore, error) {
+	usable := make([]models.SpeakerSegment, 0, len(segments))
+	for _, segment := range segments {
+		if strings.TrimSpace(segment.Text) != "" && segment.EndTime > segment.StartTime {
+			usable = append(usable, segment)
+		}
+	}
+	if len(usable) == 0 {
+		return []timedScore{}, nil
+	}
+
+	keys := make([]string, len(usable))
+	texts := make([]string, len(usable))
+	for i, segment := range usable {
+		keys[i] = models.SegmentKey(segment)
+		texts[i] = segment.Text
+	}
+
+	embeddings, err := sa.timelineEmbeddings(ctx, keys, texts, stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed transcript segments: %w", err)
+	}
+
+	scores := make([]timedScore, len(usable))
+	for i, segment := range usable {
+		scores[i] = timedScore{
+			start: segment.StartTime,
+			end:   segment.EndTime,
+			at:    segment.StartTime,
+			score: clampUnit(cosineSimilarity(queryEmbedding, embeddings[i])),
+			text:  segment.Text,
+		}
+	}
+
+	return scores, nil
+}
+
+// timelineEmbeddings returns one embedding per text, taking stored vectors by key and
+// embedding the rest (videos processed before embeddings were stored at ingest)
+func (sa *SearchAPI) timelineEmbeddings(ctx context.Context, keys, texts []string, stored map[string][]float64) ([][]float64, error) {
+	embeddings := make([][]float64, len(texts))
+	missing := make([]int, 0)
+	for i, key := range keys {
+		if vector, ok := stored[key]; ok && len(vector) == EmbeddingDimension {
+			embeddings[i] = vector
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return embeddings, nil
+	}
+
+	missingTexts := make([]string, len(missing))
+	for j, i := range missing {
+		missingTexts[j] = texts[i]
+	}
+	generated, err := sa.graphragClient.GenerateEmbeddingBatch(ctx, missingTexts, "document")
+	if err != nil {
+		return nil, err
+	}
+	if len(generated) != len(missing) {
+		return nil, fmt.Errorf("embedding count mismatch: got %d, expected %d", len(generated), len(missing))
+	}
+	for j, i := range missing {
+		embeddings[i] = generated[j]
+	}
+
+	log.Printf("Embedded %d/%d timeline texts without stored embeddings", len(missing), len(texts))
+	return embeddings, nil
+}
+
+// slideWindows scores fixed-length windows by overlap-weighted frame and transcript scores
+func slideWindows(frameScores, segmentScores []timedScore, duration float64, req MomentSearchRequest) []momentWindow {
+	if duration <= 0 {
+		return []momentWindow{}
+	}
+
+	windowSize := math.Min(req.WindowSize, duration)
+	windows := make([]momentWindow, 0)
+
+	for start := 0.0; start < duration; start += req.WindowStride {
+		end := math.Min(start+windowSize, duration)
+		if end-start < windowSize/2 && len(windows) > 0 {
+			break
+		}
+
+		visual, hasVisual := overlapWeightedScore(frameScores, start, end)
+		transcript, hasTranscript := maxOverlapScore(segmentScores, start, end)
+
+		// Only weight modalities that cover this window
+		weightSum := 0.0
+		score := 0.0
+		if hasVisual {
+			score += req.VisualWeight * visual
+			weightSum += req.VisualWeight
+		}
+		if hasTranscript {
+			score += req.TranscriptWeight * transcript
+			weightSum += req.TranscriptWeight
+		}
+		if weightSum > 0 {
+			score /= weightSum
+		}
+
+		windows = append(windows, momentWindow{
+			start:      start,
+			end:        end,
+			visual:     visual,
+			transcript: transcript,
+			score:      score,
+		})
+	}
+
+	return windows
+}
+
+// overlapWeightedScore averages item scores weighted by their overlap with [start, end]
+func overlapWeightedScore(items []timedScore, start, end float64) (float64, bool) {
+	total := 0.0
+	weight := 0.0
+	for _, item := range items {
+		overlap := math.Min(end, item.end) - math.Max(start, item.start)
+		if overlap <= 0 {
+			// Zero-length spans (single frame) count if they fall inside the window
+			if item.end == item.start && item.at >= start && item.at <= end {
+				overlap = 1e-3
+			} else {
+				continue
+			}
+		}
+		total += item.score * overlap
+		weight += overlap
+	}
+	if weight == 0 {
+		return 0, false
+	}
+	return total / weight, true
+}
+
+// maxOverlapScore returns the best score among items overlapping [start, end]
+func maxOverlapScore(items []timedScore, start, end float64) (float64, bool) {
+	best := 0.0
+	found := false
+	for _, item := range items {
+		if item.end > start && item.start < end {
+			if !found || item.score > best {
+				best = item.score
+			}
+			found = true
+		}
+	}
+	return best, found
+}
+
+// adaptiveThreshold picks the score a window must reach to be part of a moment
+// Uses one standard deviation above the mean, bounded by the absolute floor and the peak
+func adaptiveThreshold(windows []momentWindow, minScore float64) float64 {
+	mean := 0.0
+	peak := 0.0
+	for _, w := range windows {
+		mean += w.score
+		peak = math.Max(peak, w.score)
+	}
+	mean /= float64(len(windows))
+
+	variance := 0.0
+	for _, w := range windows {
+		variance += (w.score - mean) * (w.score - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(windows)))
+
+	threshold := math.Min(mean+stdDev, peak)
+	return math.Max(threshold, minScore)
+}
+
+// mergeWindows merges consecutive above-threshold windows into moments
+func mergeWindows(windows []momentWindow, threshold, mergeGap float64) []Moment {
+	moments := make([]Moment, 0)
+	var current *Moment
+	var count int
+
+	flush := func() {
+		if current != nil {
+			current.MeanScore /= float64(count)
+			moments = append(moments, *current)
+			current = nil
+		}
+	}
+
+	for _, w := range windows {
+		if w.score < threshold || w.score <= 0 {
+			continue
+		}
+
+		if current != nil && w.start <= current.End+mergeGap {
+			current.End = math.Max(current.End, w.end)
+			current.MeanScore += w.score
+			count++
+			if w.score > current.Score {
+				current.Score = w.score
+				current.VisualScore = w.visual
+				current.TranscriptScore = w.transcript
+			}
+			continue
+		}
+
+		flush()
+		current = &Moment{
+			Start:           w.start,
+			End:             w.end,
+			Score:           w.score,
+			MeanScore:       w.score,
+			VisualScore:     w.visual,
+			TranscriptScore: w.transcript,
+		}
+		count = 1
+	}
+	flush()
+
+	return moments
+}
+
+// attachMomentEvidence adds the best frame and overlapping transcript to a moment
+func attachMomentEvidence(moment *Moment, frameScores, segmentScores []timedScore) {
+	bestScore := -1.0
+	for _, fs := range frameScores {
+		if fs.at < moment.Start || fs.at > moment.End {
+			continue
+		}
+		moment.FrameIDs = append(moment.FrameIDs, fs.id)
+		if fs.score > bestScore {
+			bestScore = fs.score
+			moment.PeakTimestamp = fs.at
+			moment.Description = fs.text
+		}
+	}
+	if bestScore < 0 {
+		moment.PeakTimestamp = (moment.Start + moment.End) / 2
+	}
+
+	texts := make([]string, 0)
+	for _, ss := range segmentScores {
+		if ss.end > moment.Start && ss.start < moment.End {
+			texts = append(texts, ss.text)
+		}
+	}
+	moment.Transcript = strings.Join(texts, " ")
+}
+
+// cosineSimilarity computes cosine similarity between two vectors
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	dot, normA, normB := 0.0, 0.0, 0.0
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// clampUnit clamps a value to 0-1
+func clampUnit(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
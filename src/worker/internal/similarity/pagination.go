@@ -0,0 +1,164 @@
+package similarity
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// maxSearchCandidates caps how many matches are ranked per query
+// TotalFound is exact below this cap and a lower bound at it
+const maxSearchCandidates = 1000
+
+// searchCursor is the decoded form of an opaque pagination cursor
+// It records the last returned (score, video ID) so the next page starts strictly after it
+type searchCursor struct {
+	Score       float64 `json:"s"`
+	VideoID     string  `json:"v"`
+	Fingerprint string  `json:"f"` // Query fingerprint - cursors are only valid for the same query
+}
+
+// pageInfo describes the page selected from a ranked candidate list
+type pageInfo struct {
+	offset     int
+	nextCursor string
+	hasMore    bool
+	truncated  bool
+}
+
+// filterCandidatesByScore drops candidates below the score threshold (0 = keep all)
+func filterCandidatesByScore(candidates []*fusedCandidate, threshold float64) []*fusedCandidate {
+	if threshold <= 0 {
+		return candidates
+	}
+
+	filtered := candidates[:0]
+	for _, candidate := range candidates {
+		if candidate.score >= threshold {
+			filtered = append(filtered, candidate)
+		}
+	}
+	return filtered
+}
+
+// sortCandidates orders candidates by score descending, then video ID ascending
+func sortCandidates(candidates []*fusedCandidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidateBefore(candidates[i].score, candidates[i].videoID, candidates[j].score, candidates[j].videoID)
+	})
+}
+
+// candidateBefore reports whether (scoreA, idA) sorts before (scoreB, idB)
+func candidateBefore(scoreA float64, idA string, scoreB float64, idB string) bool {
+	if scoreA != scoreB {
+		return scoreA > scoreB
+	}
+	return idA < idB
+}
+
+// paginateCandidates selects a page from sorted candidates using either a cursor or an offset
+func paginateCandidates(candidates []*fusedCandidate, req VideoSearchRequest, limit int) ([]*fusedCandidate, pageInfo, error) {
+	info := pageInfo{truncated: len(candidates) >= maxSearchCandidates}
+	fingerprint := queryFingerprint(req)
+
+	// Step 1: Resolve the start position
+	start := 0
+	if req.Cursor != "" {
+		cursor, err := decodeSearchCursor(req.Cursor)
+		if err != nil {
+			return nil, info, err
+		}
+		if cursor.Fingerprint != fingerprint {
+			return nil, info, fmt.Errorf("cursor does not belong to this query")
+		}
+
+		// Keyset pagination: first candidate strictly after the cursor position
+		start = sort.Search(len(candidates), func(i int) bool {
+			return candidateBefore(cursor.Score, cursor.VideoID, candidates[i].score, candidates[i].videoID)
+		})
+	} else {
+		if req.Offset < 0 {
+			return nil, info, fmt.Errorf("offset must be non-negative: %d", req.Offset)
+		}
+		start = req.Offset
+	}
+
+	if start > len(candidates) {
+		start = len(candidates)
+	}
+
+	end := start + limit
+	if end > len(candidates) {
+		end = len(candidates)
+	}
+
+	page := candidates[start:end]
+	info.offset = start
+	info.hasMore = end < len(candidates)
+
+	// Step 2: Cursor for the next page
+	if info.hasMore && len(page) > 0 {
+		last := page[len(page)-1]
+		info.nextCursor = encodeSearchCursor(searchCursor{
+			Score:       last.score,
+			VideoID:     last.videoID,
+			Fingerprint: fingerprint,
+		})
+	}
+
+	return page, info, nil
+}
+
+// queryFingerprint hashes the parts of a request that affect ranking
+func queryFingerprint(req VideoSearchRequest) string {
+	data, _ := json.Marshal(struct {
+		TenantID         string             `json:"n"`
+		QueryType        SearchQueryType    `json:"t"`
+		Query            string             `json:"q"`
+		QueryEmbedding   []float64          `json:"e,omitempty"`
+		Filters          SearchFilters      `json:"f"`
+		ScoreThreshold   float64            `json:"s"`
+		Hybrid           bool               `json:"h"`
+		Fusion           FusionMethod       `json:"m"`
+		ComponentWeights map[string]float64 `json:"w,omitempty"`
+		FusedThreshold   float64            `json:"fs,omitempty"`
+	}{
+		TenantID:         req.TenantID,
+		QueryType:        req.QueryType,
+		Query:            req.Query,
+		QueryEmbedding:   req.QueryEmbedding,
+		Filters:          req.Filters,
+		ScoreThreshold:   req.Options.ScoreThreshold,
+		Hybrid:           req.Options.Hybrid || req.Options.ReRank,
+		Fusion:           req.Options.Fusion,
+		ComponentWeights: req.Options.ComponentWeights,
+		FusedThreshold:   req.Options.FusedScoreThreshold,
+	})
+
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:8])
+}
+
+// encodeSearchCursor serialises a cursor to an opaque URL-safe string
+func encodeSearchCursor(cursor searchCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeSearchCursor parses an opaque cursor string
+func decodeSearchCursor(encoded string) (searchCursor, error) {
+	var cursor searchCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	return cursor, nil
+}
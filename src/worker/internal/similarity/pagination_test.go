@@ -0,0 +1,152 @@
+package similarity
+
+import (
+	"strings"
+	"testing"
+)
+
+// rankedCandidates returns sorted candidates with three- and two-way score ties
+func rankedCandidates() []*fusedCandidate {
+	candidates := []*fusedCandidate{
+		{videoID: "g", score: 0.1},
+		{videoID: "d", score: 0.8},
+		{videoID: "a", score: 0.9},
+		{videoID: "f", score: 0.5},
+		{videoID: "c", score: 0.8},
+		{videoID: "e", score: 0.5},
+		{videoID: "b", score: 0.8},
+	}
+	sortCandidates(candidates)
+	return candidates
+}
+
+func candidateIDs(candidates []*fusedCandidate) string {
+	ids := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		ids[i] = candidate.videoID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestPaginateCandidates(t *testing.T) {
+	req := VideoSearchRequest{TenantID: "org:acme", QueryType: QueryTypeText, Query: "red car"}
+	cursorAt := func(score float64, videoID string) string {
+		return encodeSearchCursor(searchCursor{Score: score, VideoID: videoID, Fingerprint: queryFingerprint(req)})
+	}
+
+	otherQuery := req
+	otherQuery.Query = "blue car"
+
+	tests := []struct {
+		name       string
+		cursor     string
+		offset     int
+		limit      int
+		wantPage   string
+		wantOffset int
+		hasMore    bool
+		wantErr    string
+	}{
+		{name: "first page", limit: 3, wantPage: "a,b,c", wantOffset: 0, hasMore: true},
+		{name: "offset page", offset: 3, limit: 2, wantPage: "d,e", wantOffset: 3, hasMore: true},
+		{name: "cursor inside a score tie", cursor: cursorAt(0.8, "b"), limit: 2, wantPage: "c,d", wantOffset: 2, hasMore: true},
+		{name: "cursor at the end of a tie", cursor: cursorAt(0.8, "d"), limit: 2, wantPage: "e,f", wantOffset: 4, hasMore: true},
+		{name: "cursor between tied IDs", cursor: cursorAt(0.8, "bb"), limit: 2, wantPage: "c,d", wantOffset: 2, hasMore: true},
+		{name: "cursor for a removed score", cursor: cursorAt(0.85, "zz"), limit: 2, wantPage: "b,c", wantOffset: 1, hasMore: true},
+		{name: "exact last page", offset: 5, limit: 2, wantPage: "f,g", wantOffset: 5, hasMore: false},
+		{name: "cursor on the last candidate", cursor: cursorAt(0.1, "g"), limit: 2, wantPage: "", wantOffset: 7, hasMore: false},
+		{name: "offset past the end", offset: 50, limit: 2, wantPage: "", wantOffset: 7, hasMore: false},
+		{name: "negative offset", offset: -1, limit: 2, wantErr: "offset must be non-negative"},
+		{name: "cursor from another query", cursor: encodeSearchCursor(searchCursor{Score: 0.8, VideoID: "b", Fingerprint: queryFingerprint(otherQuery)}), limit: 2, wantErr: "does not belong"},
+		{name: "malformed cursor", cursor: "not a cursor!", limit: 2, wantErr: "invalid cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pageReq := req
+			pageReq.Cursor = tt.cursor
+			pageReq.Offset = tt.offset
+
+			page, info, err := paginateCandidates(rankedCandidates(), pageReq, tt.limit)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("paginateCandidates: %v", err)
+			}
+
+			if got := candidateIDs(page); got != tt.wantPage {
+				t.Errorf("page = %q, want %q", got, tt.wantPage)
+			}
+			if info.offset != tt.wantOffset {
+				t.Errorf("offset = %d, want %d", info.offset, tt.wantOffset)
+			}
+			if info.hasMore != tt.hasMore {
+				t.Errorf("hasMore = %v, want %v", info.hasMore, tt.hasMore)
+			}
+			if (info.nextCursor != "") != tt.hasMore {
+				t.Errorf("nextCursor = %q with hasMore = %v", info.nextCursor, info.hasMore)
+			}
+			if info.truncated {
+				t.Errorf("truncated below maxSearchCandidates")
+			}
+		})
+	}
+}
+
+func TestPaginateCandidatesCursorWalkMatchesOffsetWalk(t *testing.T) {
+	req := VideoSearchRequest{TenantID: "org:acme", QueryType: QueryTypeText, Query: "red car"}
+
+	for _, limit := range []int{1, 2, 3, 7, 10} {
+		// Plain offset walk
+		offsetWalk := make([]*fusedCandidate, 0)
+		for offset := 0; ; offset += limit {
+			pageReq := req
+			pageReq.Offset = offset
+			page, info, err := paginateCandidates(rankedCandidates(), pageReq, limit)
+			if err != nil {
+				t.Fatalf("limit %d offset %d: %v", limit, offset, err)
+			}
+			offsetWalk = append(offsetWalk, page...)
+			if !info.hasMore {
+				break
+			}
+		}
+
+		// Cursor walk
+		cursorWalk := make([]*fusedCandidate, 0)
+		seen := make(map[string]bool)
+		pageReq := req
+		for pages := 0; ; pages++ {
+			if pages > len(rankedCandidates()) {
+				t.Fatalf("limit %d: cursor walk did not terminate", limit)
+			}
+			page, info, err := paginateCandidates(rankedCandidates(), pageReq, limit)
+			if err != nil {
+				t.Fatalf("limit %d page %d: %v", limit, pages, err)
+			}
+			for _, candidate := range page {
+				if seen[candidate.videoID] {
+					t.Errorf("limit %d: %s returned twice", limit, candidate.videoID)
+				}
+				seen[candidate.videoID] = true
+			}
+			cursorWalk = append(cursorWalk, page...)
+			if !info.hasMore {
+				break
+			}
+			pageReq.Cursor = info.nextCursor
+		}
+
+		want := candidateIDs(rankedCandidates())
+		if got := candidateIDs(offsetWalk); got != want {
+			t.Errorf("limit %d: offset walk = %q, want %q", limit, got, want)
+		}
+		if got := candidateIDs(cursorWalk); got != want {
+			t.Errorf("limit %d: cursor walk = %q, want %q", limit, got, want)
+		}
+	}
+}
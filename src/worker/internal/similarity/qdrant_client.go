@@ -0,0 +1,243 @@
+package similarity
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultQdrantPort is Qdrant's REST port
+const defaultQdrantPort = "6333"
+
+// pointIDField keeps a point's original string ID in its payload
+// (Qdrant only accepts unsigned integers and UUIDs as point IDs)
+const pointIDField = "point_id"
+
+// QdrantClient is a minimal client for the Qdrant REST API
+type QdrantClient struct {
+	endpoint   string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// newQdrantClient creates a REST client; endpoint may be a bare host ("localhost")
+func newQdrantClient(endpoint, apiKey string) *QdrantClient {
+	return &QdrantClient{
+		endpoint: normalizeQdrantEndpoint(endpoint),
+		apiKey:   apiKey,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// normalizeQdrantEndpoint adds the http scheme and REST port when missing
+func normalizeQdrantEndpoint(endpoint string) string {
+	endpoint = strings.TrimRight(strings.TrimSpace(endpoint), "/")
+	if endpoint == "" {
+		endpoint = "localhost"
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	if parsed, err := url.Parse(endpoint); err == nil && parsed.Port() == "" && parsed.Path == "" {
+		parsed.Host = parsed.Host + ":" + defaultQdrantPort
+		endpoint = parsed.String()
+	}
+	return endpoint
+}
+
+// qdrantPointID maps a string ID onto a stable UUID
+func qdrantPointID(id string) string {
+	sum := sha1.Sum([]byte(id))
+	h := hex.EncodeToString(sum[:16])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+// qdrantStatusError is a non-2xx Qdrant response
+type qdrantStatusError struct {
+	status int
+	body   string
+}
+
+func (e *qdrantStatusError) Error() string {
+	return fmt.Sprintf("qdrant returned HTTP %d: %s", e.status, e.body)
+}
+
+// do sends a JSON request and decodes the "result" field of the response into out (if non-nil)
+func (c *QdrantClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal qdrant request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create qdrant request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("api-key", c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("qdrant request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read qdrant response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &qdrantStatusError{status: resp.StatusCode, body: strings.TrimSpace(string(data))}
+	}
+	if out == nil {
+		return nil
+	}
+
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("failed to parse qdrant response: %w", err)
+	}
+	if err := json.Unmarshal(envelope.Result, out); err != nil {
+		return fmt.Errorf("failed to parse qdrant result: %w", err)
+	}
+	return nil
+}
+
+// collectionExists reports whether a collection exists
+func (c *QdrantClient) collectionExists(ctx context.Context, collection string) (bool, error) {
+	err := c.do(ctx, http.MethodGet, "/collections/"+url.PathEscape(collection), nil, nil)
+	if statusErr, ok := err.(*qdrantStatusError); ok && statusErr.status == http.StatusNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// createCollection creates a collection with one unnamed vector
+func (c *QdrantClient) createCollection(ctx context.Context, config CollectionConfig) error {
+	body := map[string]interface{}{
+		"vectors": map[string]interface{}{
+			"size":     config.VectorSize,
+			"distance": config.Distance,
+		},
+		"on_disk_payload": config.OnDiskPayload,
+		"hnsw_config": map[string]interface{}{
+			"m":                   config.HnswConfig.M,
+			"ef_construct":        config.HnswConfig.EfConstruct,
+			"full_scan_threshold": config.HnswConfig.FullScanThreshold,
+		},
+	}
+	return c.do(ctx, http.MethodPut, "/collections/"+url.PathEscape(config.Name), body, nil)
+}
+
+// createKeywordIndex creates a keyword payload index (idempotent in Qdrant)
+func (c *QdrantClient) createKeywordIndex(ctx context.Context, collection, field string) error {
+	body := map[string]interface{}{
+		"field_name":   field,
+		"field_schema": "keyword",
+	}
+	return c.do(ctx, http.MethodPut, "/collections/"+url.PathEscape(collection)+"/index?wait=true", body, nil)
+}
+
+// upsertPoints inserts or replaces points, keeping each original ID in the payload
+func (c *QdrantClient) upsertPoints(ctx context.Context, collection string, points []Point) error {
+	wire := make([]map[string]interface{}, len(points))
+	for i, point := range points {
+		payload := make(map[string]interface{}, len(point.Payload)+1)
+		for k, v := range point.Payload {
+			payload[k] = v
+		}
+		payload[pointIDField] = point.ID
+
+		wire[i] = map[string]interface{}{
+			"id":      qdrantPointID(point.ID),
+			"vector":  point.Vector,
+			"payload": payload,
+		}
+	}
+	return c.do(ctx, http.MethodPut, "/collections/"+url.PathEscape(collection)+"/points?wait=true",
+		map[string]interface{}{"points": wire}, nil)
+}
+
+// search runs a filtered vector search
+func (c *QdrantClient) search(ctx context.Context, collection string, params SearchParams) ([]SearchResult, error) {
+	body := map[string]interface{}{
+		"vector":       params.Query,
+		"limit":        params.Limit,
+		"with_payload": params.WithPayload,
+		"with_vector":  params.WithVector,
+	}
+	if params.ScoreThreshold > 0 {
+		body["score_threshold"] = params.ScoreThreshold
+	}
+	if len(params.Filter) > 0 {
+		body["filter"] = params.Filter
+	}
+
+	var hits []struct {
+		ID      interface{}            `json:"id"`
+		Score   float64                `json:"score"`
+		Payload map[string]interface{} `json:"payload"`
+		Vector  []float64              `json:"vector"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/collections/"+url.PathEscape(collection)+"/points/search", body, &hits); err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		id := fmt.Sprint(hit.ID)
+		if original, ok := hit.Payload[pointIDField].(string); ok && original != "" {
+			id = original
+		}
+		results = append(results, SearchResult{
+			ID:      id,
+			Score:   hit.Score,
+			Payload: hit.Payload,
+			Vector:  hit.Vector,
+		})
+	}
+	return results, nil
+}
+
+// deletePoints deletes points by original ID
+func (c *QdrantClient) deletePoints(ctx context.Context, collection string, ids []string) error {
+	uuids := make([]string, len(ids))
+	for i, id := range ids {
+		uuids[i] = qdrantPointID(id)
+	}
+	return c.do(ctx, http.MethodPost, "/collections/"+url.PathEscape(collection)+"/points/delete?wait=true",
+		map[string]interface{}{"points": uuids}, nil)
+}
+
+// deleteByFilter deletes points matching a filter
+func (c *QdrantClient) deleteByFilter(ctx context.Context, collection string, filter map[string]interface{}) error {
+	return c.do(ctx, http.MethodPost, "/collections/"+url.PathEscape(collection)+"/points/delete?wait=true",
+		map[string]interface{}{"filter": filter}, nil)
+}
+
+// collectionInfo returns a collection's point counts and config
+func (c *QdrantClient) collectionInfo(ctx context.Context, collection string) (map[string]interface{}, error) {
+	var info map[string]interface{}
+	if err := c.do(ctx, http.MethodGet, "/collections/"+url.PathEscape(collection), nil, &info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
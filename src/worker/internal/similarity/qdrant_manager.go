@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
 	"time"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
 )
 
 // QdrantManager manages Qdrant collections for video similarity search
@@ -12,15 +15,14 @@ type QdrantManager struct {
 	client             *QdrantClient
 	videoCollection    string
 	sceneCollection    string
+	personCollection   string
 	embeddingDimension int
+	personDimension    int
 	distanceMetric     string
 }
 
-// QdrantClient represents a Qdrant client (placeholder for actual implementation)
-type QdrantClient struct {
-	endpoint string
-	apiKey   string
-}
+// PersonEmbeddingDimension is the size of person re-ID appearance embeddings
+const PersonEmbeddingDimension = 128
 
 // CollectionConfig represents Qdrant collection configuration
 type CollectionConfig struct {
@@ -67,17 +69,23 @@ type Point struct {
 // NewQdrantManager creates a new Qdrant manager
 func NewQdrantManager(endpoint, apiKey string) *QdrantManager {
 	return &QdrantManager{
-		client: &QdrantClient{
-			endpoint: endpoint,
-			apiKey:   apiKey,
-		},
+		client:             newQdrantClient(endpoint, apiKey),
 		videoCollection:    "video_embeddings",
 		sceneCollection:    "scene_embeddings",
+		personCollection:   "person_identities",
 		embeddingDimension: EmbeddingDimension, // 1024-D (VoyageAI voyage-3)
+		personDimension:    PersonEmbeddingDimension,
 		distanceMetric:     "Cosine",
 	}
 }
 
+// SetPersonDimension overrides the person re-ID embedding size (call before InitializeCollections)
+func (qm *QdrantManager) SetPersonDimension(dimension int) {
+	if dimension > 0 {
+		qm.personDimension = dimension
+	}
+}
+
 // InitializeCollections initializes Qdrant collections
 func (qm *QdrantManager) InitializeCollections(ctx context.Context) error {
 	log.Printf("Initializing Qdrant collections...")
@@ -128,32 +136,73 @@ func (qm *QdrantManager) InitializeCollections(ctx context.Context) error {
 
 	log.Printf("Created scene collection: %s", qm.sceneCollection)
 
+	// Initialize person re-ID gallery collection
+	personConfig := CollectionConfig{
+		Name:          qm.personCollection,
+		VectorSize:    qm.personDimension,
+		Distance:      qm.distanceMetric,
+		OnDiskPayload: true,
+		HnswConfig: HnswConfig{
+			M:                 16,
+			EfConstruct:       100,
+			FullScanThreshold: 10000,
+		},
+		Metadata: map[string]interface{}{
+			"description": "Person appearance embeddings for cross-video re-identification",
+			"created_at":  time.Now().Format(time.RFC3339),
+		},
+	}
+
+	if err := qm.createCollection(ctx, personConfig); err != nil {
+		return fmt.Errorf("failed to create person collection: %w", err)
+	}
+
+	log.Printf("Created person collection: %s", qm.personCollection)
+
+	// Payload indexes for tenant scoping (every search filters on tenant_id)
+	for _, collection := range []string{qm.videoCollection, qm.sceneCollection} {
+		for _, field := range []string{"tenant_id", "video_id"} {
+			if err := qm.CreateIndex(ctx, collection, field); err != nil {
+				return fmt.Errorf("failed to index %s on %s: %w", field, collection, err)
+			}
+		}
+	}
+	for _, field := range []string{"tenant_id", "identity_id"} {
+		if err := qm.CreateIndex(ctx, qm.personCollection, field); err != nil {
+			return fmt.Errorf("failed to index %s on %s: %w", field, qm.personCollection, err)
+		}
+	}
+
 	return nil
 }
 
-// createCollection creates a Qdrant collection
+// createCollection creates a Qdrant collection unless it already exists
 func (qm *QdrantManager) createCollection(ctx context.Context, config CollectionConfig) error {
-	// Placeholder - would use actual Qdrant client
+	exists, err := qm.client.collectionExists(ctx, config.Name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		log.Printf("Collection %s already exists", config.Name)
+		return nil
+	}
+
 	log.Printf("Creating collection: %s (dimension: %d, distance: %s)",
 		config.Name, config.VectorSize, config.Distance)
-
-	// TODO: Implement actual Qdrant API call
-	// Example:
-	// req := &qdrant.CreateCollection{
-	//     Name:       config.Name,
-	//     VectorSize: config.VectorSize,
-	//     Distance:   config.Distance,
-	//     HnswConfig: config.HnswConfig,
-	// }
-	// _, err := qm.client.CreateCollection(ctx, req)
-
-	return nil
+	return qm.client.createCollection(ctx, config)
 }
 
 // InsertVideoEmbedding inserts a video embedding into Qdrant
 func (qm *QdrantManager) InsertVideoEmbedding(ctx context.Context, embedding *VideoEmbedding) error {
+	if embedding.TenantID == "" {
+		return fmt.Errorf("video embedding %s has no tenant ID", embedding.VideoID)
+	}
+
 	payload := map[string]interface{}{
 		"video_id":      embedding.VideoID,
+		"tenant_id":     embedding.TenantID,
+		"user_id":       embedding.UserID,
+		"org_id":        embedding.OrgID,
 		"frame_count":   embedding.FrameCount,
 		"duration":      embedding.Duration,
 		"generated_at":  embedding.GeneratedAt.Format(time.RFC3339),
@@ -178,9 +227,16 @@ func (qm *QdrantManager) InsertVideoEmbedding(ctx context.Context, embedding *Vi
 
 // InsertSceneEmbedding inserts a scene embedding into Qdrant
 func (qm *QdrantManager) InsertSceneEmbedding(ctx context.Context, embedding *SceneEmbedding) error {
+	if embedding.TenantID == "" {
+		return fmt.Errorf("scene embedding %s has no tenant ID", embedding.SceneID)
+	}
+
 	payload := map[string]interface{}{
 		"scene_id":    embedding.SceneID,
 		"video_id":    embedding.VideoID,
+		"tenant_id":   embedding.TenantID,
+		"user_id":     embedding.UserID,
+		"org_id":      embedding.OrgID,
 		"start_frame": embedding.StartFrame,
 		"end_frame":   embedding.EndFrame,
 		"duration":    embedding.Duration,
@@ -213,9 +269,16 @@ func (qm *QdrantManager) InsertSceneEmbeddingsBatch(ctx context.Context, embeddi
 	points := make([]Point, len(embeddings))
 
 	for i, embedding := range embeddings {
+		if embedding.TenantID == "" {
+			return fmt.Errorf("scene embedding %s has no tenant ID", embedding.SceneID)
+		}
+
 		payload := map[string]interface{}{
 			"scene_id":    embedding.SceneID,
 			"video_id":    embedding.VideoID,
+			"tenant_id":   embedding.TenantID,
+			"user_id":     embedding.UserID,
+			"org_id":      embedding.OrgID,
 			"start_frame": embedding.StartFrame,
 			"end_frame":   embedding.EndFrame,
 			"duration":    embedding.Duration,
@@ -244,33 +307,94 @@ func (qm *QdrantManager) InsertSceneEmbeddingsBatch(ctx context.Context, embeddi
 	return nil
 }
 
-// insertPoint inserts a single point into collection
-func (qm *QdrantManager) insertPoint(ctx context.Context, collection string, point Point) error {
-	// Placeholder - would use actual Qdrant client
-	log.Printf("Inserting point %s into collection %s", point.ID, collection)
-
-	// TODO: Implement actual Qdrant API call
-	// Example:
-	// req := &qdrant.UpsertPoints{
-	//     CollectionName: collection,
-	//     Points: []*qdrant.PointStruct{
-	//         {
-	//             Id:      point.ID,
-	//             Vector:  point.Vector,
-	//             Payload: point.Payload,
-	//         },
-	//     },
-	// }
-	// _, err := qm.client.Upsert(ctx, req)
+// UpsertPersonIdentity inserts or replaces a re-ID gallery identity's appearance embedding
+func (qm *QdrantManager) UpsertPersonIdentity(ctx context.Context, identity *models.GalleryIdentity) error {
+	if identity.TenantID == "" {
+		return fmt.Errorf("gallery identity %s has no tenant ID", identity.IdentityID)
+	}
+	if len(identity.Features) != qm.personDimension {
+		return fmt.Errorf("gallery identity %s has %d-D features, expected %d",
+			identity.IdentityID, len(identity.Features), qm.personDimension)
+	}
+
+	point := Point{
+		ID:     identity.IdentityID,
+		Vector: identity.Features,
+		Payload: map[string]interface{}{
+			"tenant_id":   identity.TenantID,
+			"identity_id": identity.IdentityID,
+			"last_seen":   identity.LastSeen.Format(time.RFC3339),
+		},
+	}
+
+	if err := qm.insertPoint(ctx, qm.personCollection, point); err != nil {
+		return fmt.Errorf("failed to upsert person identity: %w", err)
+	}
 
 	return nil
 }
 
+// SearchPersonIdentities finds a tenant's gallery identities with similar appearance
+func (qm *QdrantManager) SearchPersonIdentities(ctx context.Context, tenantID string, features []float64, limit int, scoreThreshold float64) ([]models.GalleryMatch, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	params := SearchParams{
+		Query:          features,
+		Limit:          limit,
+		ScoreThreshold: scoreThreshold,
+		Filter: map[string]interface{}{
+			"must": []map[string]interface{}{tenantCondition(tenantID)},
+		},
+		WithPayload: true,
+		WithVector:  false,
+	}
+
+	results, err := qm.search(ctx, qm.personCollection, params)
+	if err != nil {
+		return nil, fmt.Errorf("person search failed: %w", err)
+	}
+
+	matches := make([]models.GalleryMatch, 0, len(results))
+	for _, result := range results {
+		matches = append(matches, models.GalleryMatch{
+			Identity:   models.GalleryIdentity{TenantID: tenantID, IdentityID: result.ID},
+			Similarity: result.Score,
+		})
+	}
+	return matches, nil
+}
+
+// DeletePersonIdentity removes a gallery identity's embedding (tenant-scoped)
+func (qm *QdrantManager) DeletePersonIdentity(ctx context.Context, tenantID, identityID string) error {
+	if tenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+
+	filter := map[string]interface{}{
+		"must": []map[string]interface{}{
+			tenantCondition(tenantID),
+			{
+				"key":   "identity_id",
+				"match": map[string]interface{}{"value": identityID},
+			},
+		},
+	}
+
+	if err := qm.deletePointsByFilter(ctx, qm.personCollection, filter); err != nil {
+		return fmt.Errorf("failed to delete person identity: %w", err)
+	}
+	return nil
+}
+
+// insertPoint inserts (or replaces) a single point in a collection
+func (qm *QdrantManager) insertPoint(ctx context.Context, collection string, point Point) error {
+	return qm.client.upsertPoints(ctx, collection, []Point{point})
+}
+
 // insertPointsBatch inserts multiple points in batch
 func (qm *QdrantManager) insertPointsBatch(ctx context.Context, collection string, points []Point) error {
-	// Placeholder - would use actual Qdrant client
-	log.Printf("Inserting %d points into collection %s", len(points), collection)
-
 	// Batch in chunks of 100
 	batchSize := 100
 	for i := 0; i < len(points); i += batchSize {
@@ -279,20 +403,21 @@ func (qm *QdrantManager) insertPointsBatch(ctx context.Context, collection strin
 			end = len(points)
 		}
 
-		batch := points[i:end]
-		// TODO: Implement actual Qdrant batch upsert
-		log.Printf("Batch insert: %d-%d/%d (batch size: %d)", i, end, len(points), len(batch))
+		if err := qm.client.upsertPoints(ctx, collection, points[i:end]); err != nil {
+			return fmt.Errorf("batch %d-%d/%d failed: %w", i, end, len(points), err)
+		}
 	}
 
 	return nil
 }
 
 // SearchSimilarVideos searches for similar videos
-func (qm *QdrantManager) SearchSimilarVideos(ctx context.Context, queryEmbedding []float64, limit int, filter map[string]interface{}) ([]SearchResult, error) {
+// scoreThreshold <= 0 disables the minimum similarity cut-off
+func (qm *QdrantManager) SearchSimilarVideos(ctx context.Context, queryEmbedding []float64, limit int, scoreThreshold float64, filter map[string]interface{}) ([]SearchResult, error) {
 	params := SearchParams{
 		Query:          queryEmbedding,
 		Limit:          limit,
-		ScoreThreshold: 0.7, // 70% similarity minimum
+		ScoreThreshold: scoreThreshold,
 		Filter:         filter,
 		WithPayload:    true,
 		WithVector:     false,
@@ -308,11 +433,12 @@ func (qm *QdrantManager) SearchSimilarVideos(ctx context.Context, queryEmbedding
 }
 
 // SearchSimilarScenes searches for similar scenes
-func (qm *QdrantManager) SearchSimilarScenes(ctx context.Context, queryEmbedding []float64, limit int, filter map[string]interface{}) ([]SearchResult, error) {
+// scoreThreshold <= 0 disables the minimum similarity cut-off
+func (qm *QdrantManager) SearchSimilarScenes(ctx context.Context, queryEmbedding []float64, limit int, scoreThreshold float64, filter map[string]interface{}) ([]SearchResult, error) {
 	params := SearchParams{
 		Query:          queryEmbedding,
 		Limit:          limit,
-		ScoreThreshold: 0.7,
+		ScoreThreshold: scoreThreshold,
 		Filter:         filter,
 		WithPayload:    true,
 		WithVector:     false,
@@ -329,36 +455,36 @@ func (qm *QdrantManager) SearchSimilarScenes(ctx context.Context, queryEmbedding
 
 // search performs vector similarity search
 func (qm *QdrantManager) search(ctx context.Context, collection string, params SearchParams) ([]SearchResult, error) {
-	// Placeholder - would use actual Qdrant client
-	log.Printf("Searching collection %s with limit %d", collection, params.Limit)
-
-	// TODO: Implement actual Qdrant search
-	// Example:
-	// req := &qdrant.SearchPoints{
-	//     CollectionName: collection,
-	//     Vector:         params.Query,
-	//     Limit:          params.Limit,
-	//     ScoreThreshold: &params.ScoreThreshold,
-	//     Filter:         params.Filter,
-	//     WithPayload:    params.WithPayload,
-	//     WithVector:     params.WithVector,
-	// }
-	// resp, err := qm.client.Search(ctx, req)
-
-	// Return empty results for now
-	return []SearchResult{}, nil
+	results, err := qm.client.search(ctx, collection, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Qdrant orders by score only; break ties by point ID so pagination is stable
+	sortSearchResults(results)
+	return results, nil
+}
+
+// sortSearchResults orders results by score descending, then ID ascending
+func sortSearchResults(results []SearchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
 }
 
 // DeleteVideo deletes a video and all its scenes
-func (qm *QdrantManager) DeleteVideo(ctx context.Context, videoID string) error {
-	// Delete video point
-	if err := qm.deletePoint(ctx, qm.videoCollection, videoID); err != nil {
-		return fmt.Errorf("failed to delete video: %w", err)
+// Deletion is by tenant-scoped filter so a tenant cannot remove another tenant's points
+func (qm *QdrantManager) DeleteVideo(ctx context.Context, tenantID, videoID string) error {
+	if tenantID == "" {
+		return fmt.Errorf("tenant ID is required")
 	}
 
-	// Delete all scene points for this video
 	filter := map[string]interface{}{
 		"must": []map[string]interface{}{
+			tenantCondition(tenantID),
 			{
 				"key":   "video_id",
 				"match": map[string]interface{}{"value": videoID},
@@ -366,6 +492,12 @@ func (qm *QdrantManager) DeleteVideo(ctx context.Context, videoID string) error
 		},
 	}
 
+	// Delete video point
+	if err := qm.deletePointsByFilter(ctx, qm.videoCollection, filter); err != nil {
+		return fmt.Errorf("failed to delete video: %w", err)
+	}
+
+	// Delete all scene points for this video
 	if err := qm.deletePointsByFilter(ctx, qm.sceneCollection, filter); err != nil {
 		return fmt.Errorf("failed to delete scenes: %w", err)
 	}
@@ -376,32 +508,21 @@ func (qm *QdrantManager) DeleteVideo(ctx context.Context, videoID string) error
 
 // deletePoint deletes a single point
 func (qm *QdrantManager) deletePoint(ctx context.Context, collection, pointID string) error {
-	// Placeholder
-	log.Printf("Deleting point %s from collection %s", pointID, collection)
-	return nil
+	return qm.client.deletePoints(ctx, collection, []string{pointID})
 }
 
 // deletePointsByFilter deletes points matching filter
 func (qm *QdrantManager) deletePointsByFilter(ctx context.Context, collection string, filter map[string]interface{}) error {
-	// Placeholder
-	log.Printf("Deleting points by filter from collection %s", collection)
-	return nil
+	return qm.client.deleteByFilter(ctx, collection, filter)
 }
 
 // GetCollectionInfo gets collection information
 func (qm *QdrantManager) GetCollectionInfo(ctx context.Context, collection string) (map[string]interface{}, error) {
-	// Placeholder
-	log.Printf("Getting info for collection %s", collection)
-
-	info := map[string]interface{}{
-		"name":             collection,
-		"vectors_count":    0,
-		"indexed_vectors":  0,
-		"points_count":     0,
-		"segments_count":   0,
-		"config":           nil,
+	info, err := qm.client.collectionInfo(ctx, collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get info for collection %s: %w", collection, err)
 	}
-
+	info["name"] = collection
 	return info, nil
 }
 
@@ -420,18 +541,18 @@ func (qm *QdrantManager) GetStatistics(ctx context.Context) (map[string]interfac
 	stats := map[string]interface{}{
 		"video_collection": videoInfo,
 		"scene_collection": sceneInfo,
-		"total_videos":     0,
-		"total_scenes":     0,
+		"total_videos":     videoInfo["points_count"],
+		"total_scenes":     sceneInfo["points_count"],
 	}
 
 	return stats, nil
 }
 
-// CreateIndex creates an index for faster search (if not auto-indexed)
+// CreateIndex creates a keyword payload index on a field (tenant_id, video_id, ...)
+// Filtered searches on unindexed fields are slow and, in Qdrant's strict mode, rejected
 func (qm *QdrantManager) CreateIndex(ctx context.Context, collection string, field string) error {
-	// Placeholder
 	log.Printf("Creating index on field %s in collection %s", field, collection)
-	return nil
+	return qm.client.createKeywordIndex(ctx, collection, field)
 }
 
 // OptimizeCollection optimizes collection storage and indexes
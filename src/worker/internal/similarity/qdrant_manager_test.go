@@ -0,0 +1,400 @@
+package similarity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/clients"
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// fakeQdrant is an in-memory Qdrant REST server supporting the calls QdrantManager makes
+// Filters support "must" conditions with match value/any on top-level payload keys.
+type fakeQdrant struct {
+	mu          sync.Mutex
+	collections map[string]map[string]fakePoint // collection -> point ID -> point
+	indexes     map[string][]string             // collection -> indexed fields
+}
+
+type fakePoint struct {
+	ID      string                 `json:"id"`
+	Vector  []float64              `json:"vector"`
+	Payload map[string]interface{} `json:"payload"`
+}
+
+func newFakeQdrant(t *testing.T) (*fakeQdrant, *QdrantManager) {
+	t.Helper()
+	fake := &fakeQdrant{
+		collections: make(map[string]map[string]fakePoint),
+		indexes:     make(map[string][]string),
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, NewQdrantManager(server.URL, "")
+}
+
+func (f *fakeQdrant) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "collections" {
+		http.NotFound(w, r)
+		return
+	}
+	name := parts[1]
+	action := strings.Join(parts[2:], "/")
+
+	var body map[string]interface{}
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	switch {
+	case r.Method == http.MethodGet && action == "":
+		points, ok := f.collections[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		reply(w, map[string]interface{}{"points_count": len(points)})
+	case r.Method == http.MethodPut && action == "":
+		f.collections[name] = make(map[string]fakePoint)
+		reply(w, true)
+	case r.Method == http.MethodPut && action == "index":
+		f.indexes[name] = append(f.indexes[name], body["field_name"].(string))
+		reply(w, map[string]interface{}{"status": "completed"})
+	case r.Method == http.MethodPut && action == "points":
+		raw, _ := json.Marshal(body["points"])
+		var points []fakePoint
+		json.Unmarshal(raw, &points)
+		for _, p := range points {
+			f.collections[name][p.ID] = p
+		}
+		reply(w, map[string]interface{}{"status": "completed"})
+	case r.Method == http.MethodPost && action == "points/search":
+		raw, _ := json.Marshal(body["vector"])
+		var query []float64
+		json.Unmarshal(raw, &query)
+		limit := int(body["limit"].(float64))
+		filter, _ := body["filter"].(map[string]interface{})
+
+		hits := make([]map[string]interface{}, 0)
+		for _, p := range f.collections[name] {
+			if !matchesFilter(p.Payload, filter) {
+				continue
+			}
+			hits = append(hits, map[string]interface{}{"id": p.ID, "score": cosine(query, p.Vector), "payload": p.Payload})
+		}
+		if len(hits) > limit {
+			hits = hits[:limit]
+		}
+		reply(w, hits)
+	case r.Method == http.MethodPost && action == "points/delete":
+		filter, _ := body["filter"].(map[string]interface{})
+		for id, p := range f.collections[name] {
+			if filter != nil && matchesFilter(p.Payload, filter) {
+				delete(f.collections[name], id)
+			}
+		}
+		reply(w, map[string]interface{}{"status": "completed"})
+	default:
+		http.Error(w, fmt.Sprintf("unsupported %s %s", r.Method, r.URL.Path), http.StatusBadRequest)
+	}
+}
+
+func reply(w http.ResponseWriter, result interface{}) {
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "result": result})
+}
+
+func matchesFilter(payload, filter map[string]interface{}) bool {
+	must, _ := filter["must"].([]interface{})
+	for _, c := range must {
+		condition := c.(map[string]interface{})
+		match, ok := condition["match"].(map[string]interface{})
+		if !ok {
+			return false
+		}
+		value := payload[condition["key"].(string)]
+		if want, ok := match["value"]; ok && value != want {
+			return false
+		}
+		if any, ok := match["any"].([]interface{}); ok {
+			found := false
+			for _, want := range any {
+				found = found || value == want
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func cosine(a, b []float64) float64 {
+	dot, na, nb := 0.0, 0.0, 0.0
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+// unitVector returns a 1024-D vector pointing mostly along axis
+func unitVector(axis int) []float64 {
+	v := make([]float64, EmbeddingDimension)
+	v[axis] = 1
+	return v
+}
+
+func ownedEmbedding(videoID, userID, orgID string, axis int) *VideoEmbedding {
+	embedding := &VideoEmbedding{VideoID: videoID, Embedding: unitVector(axis), GeneratedAt: time.Now()}
+	embedding.SetOwner(&models.JobPayload{JobID: videoID, UserID: userID, OrgID: orgID})
+	return embedding
+}
+
+func TestInitializeCollectionsIndexesTenant(t *testing.T) {
+	fake, qm := newFakeQdrant(t)
+	if err := qm.InitializeCollections(context.Background()); err != nil {
+		t.Fatalf("InitializeCollections: %v", err)
+	}
+
+	for _, collection := range []string{"video_embeddings", "scene_embeddings", "person_identities"} {
+		if _, ok := fake.collections[collection]; !ok {
+			t.Errorf("collection %s was not created", collection)
+		}
+		indexed := strings.Join(fake.indexes[collection], ",")
+		if !strings.Contains(indexed, "tenant_id") {
+			t.Errorf("collection %s has no tenant_id payload index (indexes: %s)", collection, indexed)
+		}
+	}
+
+	// A second start finds the collections and leaves their points alone
+	fake.collections["video_embeddings"]["x"] = fakePoint{ID: "x"}
+	if err := qm.InitializeCollections(context.Background()); err != nil {
+		t.Fatalf("second InitializeCollections: %v", err)
+	}
+	if len(fake.collections["video_embeddings"]) != 1 {
+		t.Errorf("re-initializing recreated the video collection")
+	}
+}
+
+func TestInsertRejectsUnownedEmbedding(t *testing.T) {
+	_, qm := newFakeQdrant(t)
+	qm.InitializeCollections(context.Background())
+
+	err := qm.InsertVideoEmbedding(context.Background(), &VideoEmbedding{VideoID: "v1", Embedding: unitVector(0)})
+	if err == nil {
+		t.Fatal("expected an error inserting an embedding without a tenant")
+	}
+}
+
+func TestSearchIsTenantScoped(t *testing.T) {
+	_, qm := newFakeQdrant(t)
+	ctx := context.Background()
+	qm.InitializeCollections(ctx)
+
+	for _, e := range []*VideoEmbedding{
+		ownedEmbedding("alice-video", "alice", "", 0),
+		ownedEmbedding("acme-video", "bob", "acme", 0),
+		ownedEmbedding("acme-other", "carol", "acme", 1),
+	} {
+		if err := qm.InsertVideoEmbedding(ctx, e); err != nil {
+			t.Fatalf("InsertVideoEmbedding(%s): %v", e.VideoID, err)
+		}
+	}
+
+	api := NewSearchAPI(nil, nil, qm, nil)
+	tests := []struct {
+		tenant string
+		want   []string
+	}{
+		{models.TenantIDFor("alice", ""), []string{"alice-video"}},
+		{models.TenantIDFor("bob", "acme"), []string{"acme-video", "acme-other"}},
+		{models.TenantIDFor("bob", ""), nil},
+	}
+
+	for _, tt := range tests {
+		resp, err := api.SearchVideos(ctx, VideoSearchRequest{
+			TenantID:       tt.tenant,
+			QueryType:      QueryTypeEmbedding,
+			QueryEmbedding: unitVector(0),
+			Limit:          10,
+		})
+		if err != nil {
+			t.Fatalf("SearchVideos(%s): %v", tt.tenant, err)
+		}
+		got := make([]string, 0)
+		for _, r := range resp.Results {
+			got = append(got, r.VideoID)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("tenant %s: got %v, want %v", tt.tenant, got, tt.want)
+		}
+	}
+
+	if _, err := api.SearchVideos(ctx, VideoSearchRequest{QueryType: QueryTypeEmbedding, QueryEmbedding: unitVector(0)}); err == nil {
+		t.Error("expected an error searching without a tenant")
+	}
+}
+
+func TestDeleteVideoIsTenantScoped(t *testing.T) {
+	fake, qm := newFakeQdrant(t)
+	ctx := context.Background()
+	qm.InitializeCollections(ctx)
+	qm.InsertVideoEmbedding(ctx, ownedEmbedding("shared-id", "alice", "", 0))
+
+	if err := qm.DeleteVideo(ctx, models.TenantIDFor("mallory", ""), "shared-id"); err != nil {
+		t.Fatalf("DeleteVideo: %v", err)
+	}
+	if len(fake.collections["video_embeddings"]) != 1 {
+		t.Fatal("another tenant deleted the video")
+	}
+
+	if err := qm.DeleteVideo(ctx, models.TenantIDFor("alice", ""), "shared-id"); err != nil {
+		t.Fatalf("DeleteVideo: %v", err)
+	}
+	if len(fake.collections["video_embeddings"]) != 0 {
+		t.Fatal("the owning tenant could not delete the video")
+	}
+}
+
+// failingMageAgent answers every task submission with success=false, so frame
+// descriptions fail fast and the embedder falls back to zero vectors
+func failingMageAgent(t *testing.T) *clients.MageAgentClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": "unavailable"})
+	}))
+	t.Cleanup(server.Close)
+	return clients.NewMageAgentClient(server.URL, 5*time.Second)
+}
+
+func TestIndexVideoStampsOwner(t *testing.T) {
+	fake, qm := newFakeQdrant(t)
+	ctx := context.Background()
+	qm.InitializeCollections(ctx)
+
+	graphrag, _ := clients.NewGraphRAGClient("http://127.0.0.1:1")
+	embedder := NewVideoEmbedder(failingMageAgent(t), graphrag)
+	module := &SimilarityModule{
+		VideoEmbedder: embedder,
+		SceneEmbedder: NewSceneEmbedder(embedder),
+		QdrantManager: qm,
+	}
+
+	job := &models.JobPayload{JobID: "job-1", UserID: "alice", OrgID: "acme"}
+	video, scenes, err := module.IndexVideo(ctx, job, []string{"frame-a", "frame-b"}, VideoMetadata{}, nil)
+	if err != nil {
+		t.Fatalf("IndexVideo: %v", err)
+	}
+	if video.TenantID != "org:acme" || video.UserID != "alice" || video.OrgID != "acme" {
+		t.Errorf("video owner = %q/%q/%q, want org:acme/alice/acme", video.TenantID, video.UserID, video.OrgID)
+	}
+	if len(scenes) == 0 {
+		t.Fatal("no scene embeddings generated")
+	}
+
+	for _, collection := range []string{"video_embeddings", "scene_embeddings"} {
+		if len(fake.collections[collection]) == 0 {
+			t.Errorf("nothing indexed in %s", collection)
+		}
+		for _, p := range fake.collections[collection] {
+			if p.Payload["tenant_id"] != "org:acme" {
+				t.Errorf("%s point %v has tenant_id %v", collection, p.Payload[pointIDField], p.Payload["tenant_id"])
+			}
+		}
+	}
+
+	if _, _, err := module.IndexVideo(ctx, &models.JobPayload{JobID: "job-2"}, []string{"frame"}, VideoMetadata{}, nil); err == nil {
+		t.Error("expected an error indexing a job without an owner")
+	}
+}
+
+func TestIndexVideoAppliesSceneCinematics(t *testing.T) {
+	fake, qm := newFakeQdrant(t)
+	ctx := context.Background()
+	qm.InitializeCollections(ctx)
+
+	graphrag, _ := clients.NewGraphRAGClient("http://127.0.0.1:1")
+	embedder := NewVideoEmbedder(failingMageAgent(t), graphrag)
+	module := &SimilarityModule{
+		VideoEmbedder: embedder,
+		SceneEmbedder: NewSceneEmbedder(embedder),
+		QdrantManager: qm,
+	}
+
+	// Four frames sampled over 120s: the scene embedding spans 0-120s, not 0-0.13s at 30fps
+	scenes := []models.SceneDetection{
+		{StartTime: 0, EndTime: 10, Cinematic: &models.SceneCinematics{
+			Classification: models.CinematicClassification{SceneType: "establishing"},
+		}},
+		{StartTime: 10, EndTime: 120, Cinematic: &models.SceneCinematics{
+			Classification: models.CinematicClassification{SceneType: "dialogue"},
+			Look:           models.CinematicLook{Mood: "tense"},
+		}},
+	}
+	job := &models.JobPayload{JobID: "job-1", UserID: "alice"}
+	frames := []string{"frame-a", "frame-b", "frame-c", "frame-d"}
+	_, embeddings, err := module.IndexVideo(ctx, job, frames, VideoMetadata{Duration: 120}, scenes)
+	if err != nil {
+		t.Fatalf("IndexVideo: %v", err)
+	}
+	if len(embeddings) == 0 {
+		t.Fatal("no scene embeddings generated")
+	}
+	for _, embedding := range embeddings {
+		if embedding.SceneType != "dialogue" || embedding.Semantics.Mood != "tense" {
+			t.Errorf("scene %s type/mood = %q/%q, want dialogue/tense", embedding.SceneID, embedding.SceneType, embedding.Semantics.Mood)
+		}
+	}
+
+	// The indexed payloads carry the analysis
+	for _, p := range fake.collections["scene_embeddings"] {
+		if p.Payload["scene_type"] != "dialogue" {
+			t.Errorf("indexed scene_type = %v, want dialogue", p.Payload["scene_type"])
+		}
+	}
+}
+
+func TestQdrantPointIDIsStableUUID(t *testing.T) {
+	a, b := qdrantPointID("job-1_scene_1"), qdrantPointID("job-1_scene_1")
+	if a != b {
+		t.Fatalf("point IDs differ for the same input: %s vs %s", a, b)
+	}
+	if len(a) != 36 || strings.Count(a, "-") != 4 {
+		t.Errorf("point ID %q is not a UUID", a)
+	}
+	if a == qdrantPointID("job-1_scene_2") {
+		t.Error("different inputs mapped to the same point ID")
+	}
+}
+
+func TestNormalizeQdrantEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"localhost":               "http://localhost:6333",
+		"qdrant:6333":             "http://qdrant:6333",
+		"http://qdrant":           "http://qdrant:6333",
+		"https://q.example.com/":  "https://q.example.com:6333",
+		"http://qdrant:7000":      "http://qdrant:7000",
+		"https://q.example.com/x": "https://q.example.com/x",
+	}
+	for in, want := range tests {
+		if got := normalizeQdrantEndpoint(in); got != want {
+			t.Errorf("normalizeQdrantEndpoint(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
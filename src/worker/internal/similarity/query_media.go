@@ -0,0 +1,279 @@
+package similarity
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+	"github.com/adverant/nexus/videoagent-worker/internal/utils"
+)
+
+const (
+	maxQueryImageBytes      = 20 * 1024 * 1024  // 20MB
+	maxQueryClipBytes       = 500 * 1024 * 1024 // 500MB
+	defaultClipSampleFrames = 8
+	maxClipSampleFrames     = 32
+	queryFetchTimeout       = 60 * time.Second
+	maxQueryRedirects       = 5
+)
+
+// queryMediaClient fetches user-supplied query URLs
+// Every connection (including redirects) is checked after DNS resolution so a
+// query can't reach loopback, private or link-local services.
+var queryMediaClient = newQueryMediaClient(rejectInternalAddress)
+
+// newQueryMediaClient builds the query media client with control vetting every dialled address
+func newQueryMediaClient(control func(network, address string, c syscall.RawConn) error) *http.Client {
+	return &http.Client{
+		Timeout: queryFetchTimeout,
+		Transport: &http.Transport{
+			Proxy: nil,
+			DialContext: (&net.Dialer{
+				Timeout: 10 * time.Second,
+				Control: control,
+			}).DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: 30 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxQueryRedirects {
+				return fmt.Errorf("too many redirects")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// rejectInternalAddress refuses connections to non-public addresses
+func rejectInternalAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", address, err)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("invalid address %q", address)
+	}
+	if !isPublicAddress(ip) {
+		return fmt.Errorf("refusing to fetch query media from non-public address %s", ip)
+	}
+	return nil
+}
+
+// isPublicAddress reports whether ip is a routable, non-internal address
+func isPublicAddress(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// SetFFmpegHelper enables video-clip queries (frame sampling requires FFmpeg)
+func (sa *SearchAPI) SetFFmpegHelper(ffmpeg *utils.FFmpegHelper) {
+	sa.ffmpeg = ffmpeg
+}
+
+// imageQueryEmbedding embeds a query image given as base64 (optionally a data URI) or an http(s) URL
+func (sa *SearchAPI) imageQueryEmbedding(ctx context.Context, query string) ([]float64, error) {
+	imageData, err := sa.loadQueryImage(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load query image: %w", err)
+	}
+
+	embedding, features, err := sa.videoEmbedder.GenerateQueryEmbedding(ctx, imageData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query image: %w", err)
+	}
+
+	log.Printf("Generated image query embedding (scene: %s, objects: %v)", features.Scene, features.Objects)
+	return embedding, nil
+}
+
+// clipQueryEmbedding samples frames from a query clip (base64 or http(s) URL) and aggregates their embeddings
+func (sa *SearchAPI) clipQueryEmbedding(ctx context.Context, query string, sampleFrames int) ([]float64, error) {
+	if sa.ffmpeg == nil {
+		return nil, fmt.Errorf("video query requires FFmpeg (not configured)")
+	}
+
+	if sampleFrames <= 0 {
+		sampleFrames = defaultClipSampleFrames
+	}
+	if sampleFrames > maxClipSampleFrames {
+		sampleFrames = maxClipSampleFrames
+	}
+
+	queryID := "query_" + models.NewJobID()
+
+	// Step 1: Materialise the clip locally
+	clipPath, err := sa.saveQueryClip(ctx, query, queryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load query clip: %w", err)
+	}
+	defer sa.ffmpeg.Cleanup(clipPath)
+
+	if err := sa.ffmpeg.ValidateVideo(clipPath); err != nil {
+		return nil, fmt.Errorf("invalid query clip: %w", err)
+	}
+
+	duration, err := sa.ffmpeg.GetVideoDuration(clipPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query clip duration: %w", err)
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("query clip has no duration (%.2fs)", duration)
+	}
+
+	// Step 2: Sample frames uniformly across the clip
+	outputDir := filepath.Join(filepath.Dir(clipPath), queryID+"_frames")
+	defer os.RemoveAll(outputDir)
+
+	framePaths, err := sa.ffmpeg.ExtractFrames(clipPath, "uniform", 0, sampleFrames, duration, outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample query clip frames: %w", err)
+	}
+	if len(framePaths) == 0 {
+		return nil, fmt.Errorf("no frames sampled from query clip")
+	}
+
+	frames := make([]string, 0, len(framePaths))
+	timestamps := make([]float64, 0, len(framePaths))
+	interval := duration / float64(len(framePaths))
+
+	for i, framePath := range framePaths {
+		encoded, err := sa.ffmpeg.EncodeFrameToBase64(framePath)
+		if err != nil {
+			log.Printf("Warning: failed to encode query clip frame %s: %v", framePath, err)
+			continue
+		}
+		frames = append(frames, encoded)
+		timestamps = append(timestamps, float64(i)*interval)
+	}
+
+	// Step 3: Embed and aggregate
+	embedding, frameEmbeddings, err := sa.videoEmbedder.GenerateClipQueryEmbedding(ctx, frames, timestamps)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Generated clip query embedding from %d/%d frames (%.1fs clip)", len(frameEmbeddings), len(framePaths), duration)
+	return embedding, nil
+}
+
+// loadQueryImage returns base64 image data for a base64, data URI or http(s) URL query
+func (sa *SearchAPI) loadQueryImage(ctx context.Context, query string) (string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", fmt.Errorf("empty image query")
+	}
+
+	if isRemoteQuery(query) {
+		data, err := fetchQueryMedia(ctx, query, "image/", maxQueryImageBytes)
+		if err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(data), nil
+	}
+
+	encoded := stripDataURI(query)
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("image query is neither an http(s) URL nor valid base64: %w", err)
+	}
+	if len(decoded) > maxQueryImageBytes {
+		return "", fmt.Errorf("image query too large: %d bytes (max %d)", len(decoded), maxQueryImageBytes)
+	}
+
+	return encoded, nil
+}
+
+// saveQueryClip writes a base64 or http(s) URL query clip to a temp file
+func (sa *SearchAPI) saveQueryClip(ctx context.Context, query, queryID string) (string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", fmt.Errorf("empty video query")
+	}
+
+	var data []byte
+	if isRemoteQuery(query) {
+		fetched, err := fetchQueryMedia(ctx, query, "video/", maxQueryClipBytes)
+		if err != nil {
+			return "", err
+		}
+		data = fetched
+	} else {
+		decoded, err := base64.StdEncoding.DecodeString(stripDataURI(query))
+		if err != nil {
+			return "", fmt.Errorf("video query is neither an http(s) URL nor valid base64: %w", err)
+		}
+		if len(decoded) > maxQueryClipBytes {
+			return "", fmt.Errorf("video query too large: %d bytes (max %d)", len(decoded), maxQueryClipBytes)
+		}
+		data = decoded
+	}
+
+	// SaveVideoFromBuffer accepts raw bytes as well as base64
+	return sa.ffmpeg.SaveVideoFromBuffer(data, queryID)
+}
+
+// fetchQueryMedia downloads query media with a size cap and content-type check
+// Only public addresses are fetched (see queryMediaClient).
+func fetchQueryMedia(ctx context.Context, url, contentTypePrefix string, maxBytes int64) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid media URL: %w", err)
+	}
+
+	resp, err := queryMediaClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: HTTP %d", url, resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" && !strings.HasPrefix(contentType, contentTypePrefix) && !strings.HasPrefix(contentType, "application/octet-stream") {
+		return nil, fmt.Errorf("unexpected content type %q (expected %s*)", contentType, contentTypePrefix)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", url, err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("media at %s exceeds %d bytes", url, maxBytes)
+	}
+
+	return data, nil
+}
+
+// isRemoteQuery reports whether a query references media over http(s)
+func isRemoteQuery(query string) bool {
+	return strings.HasPrefix(query, "http://") || strings.HasPrefix(query, "https://")
+}
+
+// stripDataURI removes a "data:<mime>;base64," prefix if present
+func stripDataURI(query string) string {
+	if strings.HasPrefix(query, "data:") {
+		if idx := strings.Index(query, ","); idx != -1 {
+			return query[idx+1:]
+		}
+	}
+	return query
+}
@@ -0,0 +1,100 @@
+package similarity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+)
+
+func TestRejectInternalAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		allowed bool
+	}{
+		{"IPv4 loopback", "127.0.0.1:80", false},
+		{"IPv4 loopback range", "127.8.9.10:443", false},
+		{"RFC1918 10/8", "10.1.2.3:80", false},
+		{"RFC1918 172.16/12", "172.20.0.5:80", false},
+		{"RFC1918 192.168/16", "192.168.1.1:8080", false},
+		{"link-local metadata", "169.254.169.254:80", false},
+		{"IPv6 loopback", "[::1]:80", false},
+		{"IPv6 unique local", "[fd12:3456:789a::1]:80", false},
+		{"IPv6 link-local", "[fe80::1]:80", false},
+		{"IPv4-mapped loopback", "[::ffff:127.0.0.1]:80", false},
+		{"IPv4-mapped private", "[::ffff:10.0.0.1]:80", false},
+		{"IPv4 unspecified", "0.0.0.0:80", false},
+		{"IPv6 unspecified", "[::]:80", false},
+		{"IPv4 multicast", "224.0.0.1:80", false},
+		{"IPv6 multicast", "[ff02::1]:80", false},
+		{"hostname", "localhost:80", false},
+		{"missing port", "8.8.8.8", false},
+		{"public IPv4", "93.184.216.34:443", true},
+		{"public IPv6", "[2606:2800:220:1:248:1893:25c8:1946]:443", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rejectInternalAddress("tcp", tt.address, nil)
+			if (err == nil) != tt.allowed {
+				t.Errorf("rejectInternalAddress(%s) = %v, allowed = %v", tt.address, err, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestFetchQueryMediaRefusesLoopback(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Write([]byte("jpeg"))
+	}))
+	defer server.Close()
+
+	_, err := fetchQueryMedia(context.Background(), server.URL+"/query.jpg", "image/", maxQueryImageBytes)
+	if err == nil || !strings.Contains(err.Error(), "non-public address") {
+		t.Fatalf("fetch from %s: %v, want the loopback address refused", server.URL, err)
+	}
+	if atomic.LoadInt32(&hits) != 0 {
+		t.Errorf("loopback server received %d requests", hits)
+	}
+}
+
+func TestFetchQueryMediaRefusesRedirectToLoopback(t *testing.T) {
+	var internalHits int32
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&internalHits, 1)
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Write([]byte("secret"))
+	}))
+	defer internal.Close()
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internal.URL+"/latest/meta-data", http.StatusFound)
+	}))
+	defer origin.Close()
+
+	// Treat the origin as a public host; every other address goes through the real guard
+	originAddr := origin.Listener.Addr().String()
+	previous := queryMediaClient
+	queryMediaClient = newQueryMediaClient(func(network, address string, c syscall.RawConn) error {
+		if address == originAddr {
+			return nil
+		}
+		return rejectInternalAddress(network, address, c)
+	})
+	t.Cleanup(func() { queryMediaClient = previous })
+
+	_, err := fetchQueryMedia(context.Background(), origin.URL+"/query.jpg", "image/", maxQueryImageBytes)
+	if err == nil || !strings.Contains(err.Error(), "non-public address") {
+		t.Fatalf("fetch via redirect: %v, want the redirect target refused", err)
+	}
+	if atomic.LoadInt32(&internalHits) != 0 {
+		t.Errorf("redirect target received %d requests", internalHits)
+	}
+}
@@ -8,12 +8,17 @@ import (
 	"log"
 	"math"
 	"time"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
 )
 
 // SceneEmbedding represents a scene-level embedding
 type SceneEmbedding struct {
 	SceneID       string                 `json:"sceneId"`
 	VideoID       string                 `json:"videoId"`
+	TenantID      string                 `json:"tenantId"`       // Inherited from the video embedding
+	UserID        string                 `json:"userId,omitempty"`
+	OrgID         string                 `json:"orgId,omitempty"`
 	StartFrame    int                    `json:"startFrame"`
 	EndFrame      int                    `json:"endFrame"`
 	Duration      float64                `json:"duration"`       // Seconds
@@ -63,6 +68,7 @@ type SceneVisual struct {
 	Contrast        float64   `json:"contrast"`        // Average contrast
 	Saturation      float64   `json:"saturation"`      // Color saturation
 	DominantColors  []string  `json:"dominantColors"`  // Dominant colors
+	ColorProfile    string    `json:"colorProfile"`    // warm, cool, vibrant, neutral (measured)
 	Composition     string    `json:"composition"`     // Overall composition style
 	Depth           string    `json:"depth"`           // Depth perception
 }
@@ -218,6 +224,9 @@ func (se *SceneEmbedder) generateSceneEmbedding(ctx context.Context, videoID str
 	scene := &SceneEmbedding{
 		SceneID:    sceneID,
 		VideoID:    videoID,
+		TenantID:   videoEmbedding.TenantID,
+		UserID:     videoEmbedding.UserID,
+		OrgID:      videoEmbedding.OrgID,
 		StartFrame: startFrame,
 		EndFrame:   endFrame,
 		Duration:   duration,
@@ -386,13 +395,18 @@ func (se *SceneEmbedder) extractVisual(frames []FrameEmbedding) SceneVisual {
 
 	avgBrightness := 0.0
 	avgContrast := 0.0
-	avgSaturation := 0.5 // Placeholder
+	avgSaturation := 0.0
 
 	colorMap := make(map[string]int)
+	profileMap := make(map[string]int)
 
 	for _, frame := range frames {
 		avgBrightness += frame.Features.Brightness
 		avgContrast += frame.Features.Contrast
+		avgSaturation += frame.Features.Saturation
+		if frame.Features.ColorProfile != "" {
+			profileMap[frame.Features.ColorProfile]++
+		}
 
 		for _, color := range frame.Features.DominantColors {
 			colorMap[color]++
@@ -402,8 +416,13 @@ func (se *SceneEmbedder) extractVisual(frames []FrameEmbedding) SceneVisual {
 	n := float64(len(frames))
 	avgBrightness /= n
 	avgContrast /= n
+	avgSaturation /= n
 
 	dominantColors := se.videoEmbedder.topN(colorMap, 5)
+	colorProfile := se.videoEmbedder.determineColorProfile(dominantColors)
+	if profiles := se.videoEmbedder.topN(profileMap, 1); len(profiles) > 0 {
+		colorProfile = profiles[0]
+	}
 
 	return SceneVisual{
 		ColorGrading:   "natural",
@@ -412,6 +431,7 @@ func (se *SceneEmbedder) extractVisual(frames []FrameEmbedding) SceneVisual {
 		Contrast:       avgContrast,
 		Saturation:     avgSaturation,
 		DominantColors: dominantColors,
+		ColorProfile:   colorProfile,
 		Composition:    "balanced",
 		Depth:          "medium",
 	}
@@ -500,3 +520,55 @@ func (se *SceneEmbedder) computeSceneHash(embedding []float64) string {
 	hash := sha256.Sum256(data)
 	return hex.EncodeToString(hash[:])
 }
+
+// ApplySceneCinematics copies cinematic analysis from detected scenes into scene embeddings
+// Each embedding takes the analyzed scene it overlaps most in time; frames are converted
+// to seconds with frameRate (30fps when 0). Embeddings without an analyzed scene are unchanged.
+func ApplySceneCinematics(embeddings []SceneEmbedding, scenes []models.SceneDetection, frameRate float64) {
+	if frameRate <= 0 {
+		frameRate = 30.0
+	}
+
+	for e := range embeddings {
+		embedding := &embeddings[e]
+		start := float64(embedding.StartFrame) / frameRate
+		end := float64(embedding.EndFrame) / frameRate
+
+		var best *models.SceneCinematics
+		bestOverlap := 0.0
+		for i := range scenes {
+			if scenes[i].Cinematic == nil {
+				continue
+			}
+			overlap := math.Min(end, scenes[i].EndTime) - math.Max(start, scenes[i].StartTime)
+			if overlap > bestOverlap {
+				best = scenes[i].Cinematic
+				bestOverlap = overlap
+			}
+		}
+		if best == nil {
+			continue
+		}
+
+		embedding.SceneType = best.Classification.SceneType
+		embedding.Semantics.Time = best.Classification.TimeOfDay
+		if best.Classification.Weather != "" {
+			embedding.Semantics.Weather = best.Classification.Weather
+		}
+		embedding.Semantics.Mood = best.Look.Mood
+
+		embedding.Visual.ColorGrading = best.Look.ColorGrading
+		embedding.Visual.Lighting = best.Look.LightingSetup
+		embedding.Visual.Saturation = best.Look.Saturation
+		embedding.Visual.Contrast = best.Look.Contrast
+		if len(best.Look.DominantColors) > 0 {
+			embedding.Visual.DominantColors = best.Look.DominantColors
+		}
+		embedding.Visual.Composition = best.Composition.ShotSize
+		if best.Composition.Depth != "" {
+			embedding.Visual.Depth = best.Composition.Depth
+		}
+
+		embedding.Motion.CameraMotion = best.Camera.Movement
+	}
+}
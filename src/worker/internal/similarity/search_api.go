@@ -4,18 +4,22 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"sort"
 	"time"
 
 	"github.com/adverant/nexus/videoagent-worker/internal/clients"
+	"github.com/adverant/nexus/videoagent-worker/internal/utils"
 )
 
 // SearchAPI provides video similarity search functionality
 type SearchAPI struct {
 	videoEmbedder  *VideoEmbedder
 	sceneEmbedder  *SceneEmbedder
-	qdrantManager  *QdrantManager
-	graphragClient *clients.GraphRAGClient
+	qdrantManager   *QdrantManager
+	graphragClient  *clients.GraphRAGClient
+	keywordSearcher KeywordSearcher     // Optional: enables transcript/OCR/object components
+	ffmpeg          *utils.FFmpegHelper // Optional: enables video-clip queries
+	timelineSource  TimelineSource      // Optional: enables moment retrieval
+	interactionSource InteractionSource // Optional: enables interaction search
 }
 
 // NewSearchAPI creates a new search API
@@ -30,10 +34,13 @@ func NewSearchAPI(videoEmbedder *VideoEmbedder, sceneEmbedder *SceneEmbedder, qd
 
 // VideoSearchRequest represents a video search request
 type VideoSearchRequest struct {
+	TenantID       string                 `json:"tenantId"`       // Required: results are restricted to this tenant (models.TenantIDFor)
 	QueryType      SearchQueryType        `json:"queryType"`      // text, video, image, embedding
-	Query          string                 `json:"query"`          // Text query or data
-	QueryEmbedding []float64              `json:"queryEmbedding"` // Pre-computed embedding
+	Query          string                 `json:"query"`          // Text, or base64/URL media for image and video queries
+	QueryEmbedding []float64              `json:"queryEmbedding"` // Pre-computed embedding (also reused for text queries)
 	Limit          int                    `json:"limit"`
+	Offset         int                    `json:"offset"`         // Results to skip (ignored when Cursor is set)
+	Cursor         string                 `json:"cursor"`         // Opaque cursor from a previous response's NextCursor
 	Filters        SearchFilters          `json:"filters"`
 	Options        SearchOptions          `json:"options"`
 }
@@ -75,15 +82,26 @@ type SearchOptions struct {
 	IncludeScenes   bool    `json:"includeScenes"`   // Include scene-level results
 	IncludeMetadata bool    `json:"includeMetadata"` // Include full metadata
 	IncludeEmbedding bool   `json:"includeEmbedding"` // Include embeddings
-	ScoreThreshold  float64 `json:"scoreThreshold"`  // Minimum similarity score
-	ReRank          bool    `json:"reRank"`          // Apply re-ranking
+	ScoreThreshold  float64 `json:"scoreThreshold"`  // Minimum vector similarity (0 = no threshold); applied per vector component before hybrid fusion
+	ReRank          bool    `json:"reRank"`          // Apply re-ranking (hybrid fusion)
 	Explain         bool    `json:"explain"`         // Include explanation
+	ClipSampleFrames int    `json:"clipSampleFrames"` // Frames sampled from video-clip queries (default 8)
+
+	// Hybrid retrieval
+	Hybrid              bool               `json:"hybrid"`              // Fuse vector, keyword and object-label retrieval
+	Fusion              FusionMethod       `json:"fusion"`              // rrf (default), weighted
+	ComponentWeights    map[string]float64 `json:"componentWeights"`    // Overrides DefaultComponentWeights
+	FusedScoreThreshold float64            `json:"fusedScoreThreshold"` // Minimum fused 0-1 score (0 = no threshold)
 }
 
 // VideoSearchResponse represents search response
 type VideoSearchResponse struct {
 	Results      []VideoSearchResult `json:"results"`
-	TotalFound   int                 `json:"totalFound"`
+	TotalFound   int                 `json:"totalFound"`           // Matches above the score threshold
+	TotalIsLowerBound bool           `json:"totalIsLowerBound,omitempty"` // More than maxSearchCandidates matched
+	Offset       int                 `json:"offset"`               // Position of the first result
+	NextCursor   string              `json:"nextCursor,omitempty"` // Pass as Cursor to fetch the next page
+	HasMore      bool                `json:"hasMore"`
 	Query        string              `json:"query"`
 	ProcessingTime float64           `json:"processingTimeMs"`
 	Explanation  *SearchExplanation  `json:"explanation,omitempty"`
@@ -120,6 +138,8 @@ type SearchExplanation struct {
 	MatchingStrategy string                 `json:"matchingStrategy"`
 	FiltersApplied   []string               `json:"filtersApplied"`
 	ReRankingApplied bool                   `json:"reRankingApplied"`
+	FusionMethod     string                 `json:"fusionMethod,omitempty"`
+	Contributions    []ResultContribution   `json:"contributions,omitempty"` // Per-result component breakdown
 	Attributes       map[string]interface{} `json:"attributes"`
 }
 
@@ -127,120 +147,192 @@ type SearchExplanation struct {
 func (sa *SearchAPI) SearchVideos(ctx context.Context, req VideoSearchRequest) (*VideoSearchResponse, error) {
 	startTime := time.Now()
 
-	// Generate query embedding
-	queryEmbedding, err := sa.getQueryEmbedding(ctx, req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+	if req.TenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
 	}
 
-	// Build Qdrant filter
-	qdrantFilter := sa.buildQdrantFilter(req.Filters)
-
-	// Search Qdrant
 	limit := req.Limit
 	if limit <= 0 {
 		limit = 10
 	}
 
-	results, err := sa.qdrantManager.SearchSimilarVideos(ctx, queryEmbedding, limit, qdrantFilter)
+	// Step 1: Rank every match (up to maxSearchCandidates) so totals and pages are consistent
+	var candidates []*fusedCandidate
+	var explanation *SearchExplanation
+	var queryEmbedding []float64
+	var err error
+
+	if req.Options.Hybrid || req.Options.ReRank {
+		// Hybrid retrieval (re-ranking is performed by rank fusion)
+		candidates, explanation, err = sa.hybridCandidates(ctx, req)
+	} else {
+		candidates, queryEmbedding, err = sa.vectorCandidates(ctx, req)
+		if err == nil && req.Options.Explain {
+			explanation = sa.generateSearchExplanation(req)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 2: Score threshold and stable ordering (score desc, video ID asc)
+	// Hybrid scores are fused ranks, so the similarity threshold was applied per component
+	if req.Options.Hybrid || req.Options.ReRank {
+		candidates = filterCandidatesByScore(candidates, req.Options.FusedScoreThreshold)
+	} else {
+		candidates = filterCandidatesByScore(candidates, req.Options.ScoreThreshold)
+	}
+	sortCandidates(candidates)
+
+	// Step 3: Select the requested page
+	page, pageInfo, err := paginateCandidates(candidates, req, limit)
 	if err != nil {
-		return nil, fmt.Errorf("qdrant search failed: %w", err)
+		return nil, err
 	}
 
-	// Convert to VideoSearchResult
-	videoResults := make([]VideoSearchResult, 0, len(results))
+	// Step 4: Build results for the page only
+	videoResults := make([]VideoSearchResult, 0, len(page))
+	for i, candidate := range page {
+		attributes := candidate.payload
+		if attributes == nil {
+			attributes = make(map[string]interface{})
+		}
 
-	for i, result := range results {
 		videoResult := VideoSearchResult{
-			VideoID: result.ID,
-			Score:   result.Score,
-			Rank:    i + 1,
-			Attributes: result.Payload,
+			VideoID:    candidate.videoID,
+			Score:      candidate.score,
+			Rank:       pageInfo.offset + i + 1,
+			Attributes: attributes,
 		}
 
 		// Include metadata if requested
-		if req.Options.IncludeMetadata {
-			metadata := sa.extractVideoMetadata(result.Payload)
+		if req.Options.IncludeMetadata && candidate.payload != nil {
+			metadata := sa.extractVideoMetadata(candidate.payload)
 			videoResult.Metadata = &metadata
 		}
 
 		// Include embedding if requested
 		if req.Options.IncludeEmbedding {
-			videoResult.Embedding = result.Vector
+			videoResult.Embedding = candidate.vector
 		}
 
-		// Search scenes if requested
+		// Include matched scenes if requested (hybrid search already collected them)
 		if req.Options.IncludeScenes {
-			sceneResults, err := sa.searchScenesForVideo(ctx, result.ID, queryEmbedding, 5, req.Filters)
-			if err != nil {
-				log.Printf("Warning: Scene search failed for video %s: %v", result.ID, err)
+			if len(candidate.scenes) > 0 || queryEmbedding == nil {
+				videoResult.MatchedScenes = candidate.scenes
 			} else {
-				videoResult.MatchedScenes = sceneResults
+				sceneResults, err := sa.searchScenesForVideo(ctx, req.TenantID, candidate.videoID, queryEmbedding, 5, req.Options.ScoreThreshold)
+				if err != nil {
+					log.Printf("Warning: Scene search failed for video %s: %v", candidate.videoID, err)
+				} else {
+					videoResult.MatchedScenes = sceneResults
+				}
 			}
 		}
 
 		// Generate explanation if requested
 		if req.Options.Explain {
-			videoResult.Explanation = sa.generateResultExplanation(result, req)
+			if len(candidate.contributions) > 0 {
+				videoResult.Explanation = describeContributions(candidate)
+				explanation.Contributions = append(explanation.Contributions, ResultContribution{
+					VideoID:    candidate.videoID,
+					FinalScore: candidate.score,
+					Components: candidate.contributions,
+				})
+			} else {
+				videoResult.Explanation = sa.generateResultExplanation(SearchResult{ID: candidate.videoID, Score: candidate.score}, req)
+			}
 		}
 
 		videoResults = append(videoResults, videoResult)
 	}
 
-	// Apply re-ranking if requested
-	if req.Options.ReRank {
-		videoResults = sa.reRankResults(videoResults, req)
-	}
+	processingTime := time.Since(startTime).Milliseconds()
 
-	// Filter by score threshold
-	if req.Options.ScoreThreshold > 0 {
-		filtered := make([]VideoSearchResult, 0)
-		for _, result := range videoResults {
-			if result.Score >= req.Options.ScoreThreshold {
-				filtered = append(filtered, result)
-			}
-		}
-		videoResults = filtered
+	response := &VideoSearchResponse{
+		Results:           videoResults,
+		TotalFound:        len(candidates),
+		TotalIsLowerBound: pageInfo.truncated,
+		Offset:            pageInfo.offset,
+		NextCursor:        pageInfo.nextCursor,
+		HasMore:           pageInfo.hasMore,
+		Query:             req.Query,
+		ProcessingTime:    float64(processingTime),
+		Explanation:       explanation,
 	}
 
-	processingTime := time.Since(startTime).Milliseconds()
+	log.Printf("Search completed: %d results (offset %d, %d total) in %dms", len(videoResults), pageInfo.offset, len(candidates), processingTime)
 
-	response := &VideoSearchResponse{
-		Results:        videoResults,
-		TotalFound:     len(videoResults),
-		Query:          req.Query,
-		ProcessingTime: float64(processingTime),
+	return response, nil
+}
+
+// vectorCandidates ranks videos by video-level embedding similarity only
+func (sa *SearchAPI) vectorCandidates(ctx context.Context, req VideoSearchRequest) ([]*fusedCandidate, []float64, error) {
+	// Generate query embedding
+	queryEmbedding, err := sa.getQueryEmbedding(ctx, req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate query embedding: %w", err)
 	}
 
-	// Add explanation if requested
-	if req.Options.Explain {
-		response.Explanation = sa.generateSearchExplanation(req)
+	// Build Qdrant filter
+	qdrantFilter := sa.buildQdrantFilter(req.TenantID, req.Filters)
+
+	// Search Qdrant
+	results, err := sa.qdrantManager.SearchSimilarVideos(ctx, queryEmbedding, maxSearchCandidates, req.Options.ScoreThreshold, qdrantFilter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("qdrant search failed: %w", err)
 	}
 
-	log.Printf("Search completed: found %d results in %dms", len(videoResults), processingTime)
+	candidates := make([]*fusedCandidate, 0, len(results))
+	for _, result := range results {
+		candidates = append(candidates, &fusedCandidate{
+			videoID: result.ID,
+			score:   result.Score,
+			payload: result.Payload,
+			vector:  result.Vector,
+		})
+	}
 
-	return response, nil
+	return candidates, queryEmbedding, nil
 }
 
 // SearchScenes searches for similar scenes
 func (sa *SearchAPI) SearchScenes(ctx context.Context, req VideoSearchRequest) ([]SceneSearchResult, error) {
+	if req.TenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
 	queryEmbedding, err := sa.getQueryEmbedding(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
 	}
 
-	qdrantFilter := sa.buildQdrantFilter(req.Filters)
+	qdrantFilter := sa.buildQdrantFilter(req.TenantID, req.Filters)
 
 	limit := req.Limit
 	if limit <= 0 {
 		limit = 20
 	}
+	if req.Offset < 0 {
+		return nil, fmt.Errorf("offset must be non-negative: %d", req.Offset)
+	}
 
-	results, err := sa.qdrantManager.SearchSimilarScenes(ctx, queryEmbedding, limit, qdrantFilter)
+	results, err := sa.qdrantManager.SearchSimilarScenes(ctx, queryEmbedding, req.Offset+limit, req.Options.ScoreThreshold, qdrantFilter)
 	if err != nil {
 		return nil, fmt.Errorf("scene search failed: %w", err)
 	}
 
+	// Stable order, then skip to the requested offset
+	sortSearchResults(results)
+	if req.Offset >= len(results) {
+		results = results[:0]
+	} else {
+		results = results[req.Offset:]
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
 	sceneResults := make([]SceneSearchResult, 0, len(results))
 
 	for _, result := range results {
@@ -281,17 +373,19 @@ func (sa *SearchAPI) getQueryEmbedding(ctx context.Context, req VideoSearchReque
 		return req.QueryEmbedding, nil
 
 	case QueryTypeText:
-		// Generate embedding from text (would use text-to-embedding model)
+		// Reuse a pre-computed query embedding when supplied alongside the text
+		if len(req.QueryEmbedding) == EmbeddingDimension {
+			return req.QueryEmbedding, nil
+		}
 		return sa.textToEmbedding(ctx, req.Query)
 
 	case QueryTypeImage:
-		// Generate embedding from single image
-		embedding, _, err := sa.videoEmbedder.generateSingleFrameEmbedding(ctx, req.Query)
-		return embedding, err
+		// Describe-then-embed a single image (base64, data URI or URL)
+		return sa.imageQueryEmbedding(ctx, req.Query)
 
 	case QueryTypeVideo:
-		// Would need to process video and generate embedding
-		return nil, fmt.Errorf("video query not yet implemented")
+		// Sample frames from the clip and aggregate their embeddings
+		return sa.clipQueryEmbedding(ctx, req.Query, req.Options.ClipSampleFrames)
 
 	default:
 		return nil, fmt.Errorf("unknown query type: %s", req.QueryType)
@@ -318,8 +412,9 @@ func (sa *SearchAPI) textToEmbedding(ctx context.Context, text string) ([]float6
 }
 
 // buildQdrantFilter builds Qdrant filter from SearchFilters
-func (sa *SearchAPI) buildQdrantFilter(filters SearchFilters) map[string]interface{} {
-	must := make([]map[string]interface{}, 0)
+// The tenant condition is always present so no query can read another tenant's points
+func (sa *SearchAPI) buildQdrantFilter(tenantID string, filters SearchFilters) map[string]interface{} {
+	must := []map[string]interface{}{tenantCondition(tenantID)}
 
 	// Video IDs filter
 	if len(filters.VideoIDs) > 0 {
@@ -352,16 +447,27 @@ func (sa *SearchAPI) buildQdrantFilter(filters SearchFilters) map[string]interfa
 		})
 	}
 
-	// Color profile filter
+	// Color profile filter (measured from frames, see imagestats; videos and scenes keep it under different keys)
 	if filters.ColorProfile != "" {
-		must = append(must, map[string]interface{}{
-			"key":   "metadata.color_profile",
-			"match": map[string]interface{}{"value": filters.ColorProfile},
-		})
+		must = append(must, anyField(
+			[]string{"metadata.colorProfile", "visual.colorProfile"},
+			"match", map[string]interface{}{"value": filters.ColorProfile},
+		))
+	}
+
+	// Brightness filters (mean measured luma, 0-1)
+	if filters.MinBrightness > 0 {
+		must = append(must, anyField(
+			[]string{"metadata.avgBrightness", "visual.brightness"},
+			"range", map[string]interface{}{"gte": filters.MinBrightness},
+		))
 	}
 
-	if len(must) == 0 {
-		return nil
+	if filters.MaxBrightness > 0 {
+		must = append(must, anyField(
+			[]string{"metadata.avgBrightness", "visual.brightness"},
+			"range", map[string]interface{}{"lte": filters.MaxBrightness},
+		))
 	}
 
 	return map[string]interface{}{
@@ -369,11 +475,32 @@ func (sa *SearchAPI) buildQdrantFilter(filters SearchFilters) map[string]interfa
 	}
 }
 
+// anyField builds a condition that holds when any of the payload keys satisfies it
+func anyField(keys []string, kind string, condition map[string]interface{}) map[string]interface{} {
+	should := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		should = append(should, map[string]interface{}{
+			"key": key,
+			kind:  condition,
+		})
+	}
+	return map[string]interface{}{"should": should}
+}
+
+// tenantCondition builds the Qdrant match condition on the tenant_id payload field
+func tenantCondition(tenantID string) map[string]interface{} {
+	return map[string]interface{}{
+		"key":   "tenant_id",
+		"match": map[string]interface{}{"value": tenantID},
+	}
+}
+
 // searchScenesForVideo searches scenes within a specific video
-func (sa *SearchAPI) searchScenesForVideo(ctx context.Context, videoID string, queryEmbedding []float64, limit int, filters SearchFilters) ([]SceneSearchResult, error) {
-	// Add video ID to filter
+func (sa *SearchAPI) searchScenesForVideo(ctx context.Context, tenantID, videoID string, queryEmbedding []float64, limit int, scoreThreshold float64) ([]SceneSearchResult, error) {
+	// Add tenant and video ID to filter
 	sceneFilter := map[string]interface{}{
 		"must": []map[string]interface{}{
+			tenantCondition(tenantID),
 			{
 				"key":   "video_id",
 				"match": map[string]interface{}{"value": videoID},
@@ -381,7 +508,7 @@ func (sa *SearchAPI) searchScenesForVideo(ctx context.Context, videoID string, q
 		},
 	}
 
-	results, err := sa.qdrantManager.SearchSimilarScenes(ctx, queryEmbedding, limit, sceneFilter)
+	results, err := sa.qdrantManager.SearchSimilarScenes(ctx, queryEmbedding, limit, scoreThreshold, sceneFilter)
 	if err != nil {
 		return nil, err
 	}
@@ -402,49 +529,6 @@ func (sa *SearchAPI) searchScenesForVideo(ctx context.Context, videoID string, q
 	return sceneResults, nil
 }
 
-// reRankResults applies re-ranking to search results
-func (sa *SearchAPI) reRankResults(results []VideoSearchResult, req VideoSearchRequest) []VideoSearchResult {
-	// Simple re-ranking based on multiple factors
-	for i := range results {
-		score := results[i].Score
-
-		// Boost score based on metadata matching
-		if results[i].Metadata != nil {
-			// Boost if tags match query
-			for _, tag := range results[i].Metadata.Tags {
-				if contains(req.Query, tag) {
-					score *= 1.1
-				}
-			}
-
-			// Boost if scene types match filters
-			if len(req.Filters.SceneTypes) > 0 {
-				for _, sceneType := range results[i].Metadata.DominantScenes {
-					for _, filterType := range req.Filters.SceneTypes {
-						if sceneType == filterType {
-							score *= 1.05
-						}
-					}
-				}
-			}
-		}
-
-		results[i].Score = score
-	}
-
-	// Re-sort by new scores
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Score > results[j].Score
-	})
-
-	// Update ranks
-	for i := range results {
-		results[i].Rank = i + 1
-	}
-
-	return results
-}
-
 // generateResultExplanation generates explanation for a result
 func (sa *SearchAPI) generateResultExplanation(result SearchResult, req VideoSearchRequest) string {
 	return fmt.Sprintf("Similarity score: %.2f - Matched based on visual and semantic features", result.Score)
@@ -463,12 +547,15 @@ func (sa *SearchAPI) generateSearchExplanation(req VideoSearchRequest) *SearchEx
 	if len(req.Filters.SceneTypes) > 0 {
 		filtersApplied = append(filtersApplied, "scene_types")
 	}
+	if len(req.Filters.Objects) > 0 {
+		filtersApplied = append(filtersApplied, "objects")
+	}
 
 	return &SearchExplanation{
 		QueryProcessing:  fmt.Sprintf("Query type: %s", req.QueryType),
 		MatchingStrategy: "Cosine similarity on 1024-dimensional embeddings (VoyageAI voyage-3)",
 		FiltersApplied:   filtersApplied,
-		ReRankingApplied: req.Options.ReRank,
+		ReRankingApplied: req.Options.ReRank || req.Options.Hybrid,
 		Attributes:       make(map[string]interface{}),
 	}
 }
@@ -543,9 +630,3 @@ func (sa *SearchAPI) extractFloatField(payload map[string]interface{}, key strin
 	}
 	return 0.0
 }
-
-// contains checks if text contains substring (case-insensitive)
-func contains(text, substr string) bool {
-	// Simple case-insensitive check
-	return len(substr) > 0 && len(text) > 0
-}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/adverant/nexus/videoagent-worker/internal/clients"
+	"github.com/adverant/nexus/videoagent-worker/internal/imagestats"
 	"github.com/adverant/nexus/videoagent-worker/internal/models"
 )
 
@@ -20,6 +21,9 @@ const EmbeddingDimension = 1024
 // VideoEmbedding represents a video-level embedding
 type VideoEmbedding struct {
 	VideoID         string                 `json:"videoId"`
+	TenantID        string                 `json:"tenantId"`         // Owning tenant (models.TenantIDFor) - required for indexing
+	UserID          string                 `json:"userId,omitempty"`
+	OrgID           string                 `json:"orgId,omitempty"`
 	Embedding       []float64              `json:"embedding"`        // 1024-dimensional vector (VoyageAI voyage-3)
 	FrameCount      int                    `json:"frameCount"`
 	Duration        float64                `json:"duration"`         // Seconds
@@ -30,6 +34,13 @@ type VideoEmbedding struct {
 	Hash            string                 `json:"hash"`             // Content hash
 }
 
+// SetOwner stamps the job's owner onto the embedding so its points are tenant-scoped
+func (e *VideoEmbedding) SetOwner(payload *models.JobPayload) {
+	e.TenantID = payload.TenantID()
+	e.UserID = payload.UserID
+	e.OrgID = payload.OrgID
+}
+
 // FrameEmbedding represents a single frame embedding
 type FrameEmbedding struct {
 	FrameNum    int       `json:"frameNum"`
@@ -40,12 +51,17 @@ type FrameEmbedding struct {
 }
 
 // FrameFeatures represents extracted visual features
+// Colour and exposure fields are measured from the decoded frame (see imagestats);
+// motion, objects and scene come from the vision model.
 type FrameFeatures struct {
 	ColorHistogram   []float64              `json:"colorHistogram"`   // RGB histogram (3x64 bins)
 	DominantColors   []string               `json:"dominantColors"`   // Top 5 colors
 	Brightness       float64                `json:"brightness"`       // 0-1
 	Contrast         float64                `json:"contrast"`         // 0-1
 	Sharpness        float64                `json:"sharpness"`        // 0-1
+	Saturation       float64                `json:"saturation"`       // 0-1
+	ColorTemperature float64                `json:"colorTemperature"` // Kelvin
+	ColorProfile     string                 `json:"colorProfile"`     // warm, cool, vibrant, neutral
 	Motion           float64                `json:"motion"`           // 0-1, amount of motion
 	Objects          []string               `json:"objects"`          // Detected objects
 	Scene            string                 `json:"scene"`            // Scene type
@@ -57,6 +73,7 @@ type VideoMetadata struct {
 	Title           string                 `json:"title"`
 	Description     string                 `json:"description"`
 	Tags            []string               `json:"tags"`
+	Duration        float64                `json:"duration"`         // Seconds spanned by the frames (0 = unknown)
 	DominantScenes  []string               `json:"dominantScenes"`   // Most common scene types
 	DominantObjects []string               `json:"dominantObjects"`  // Most common objects
 	AvgBrightness   float64                `json:"avgBrightness"`
@@ -88,9 +105,10 @@ func NewVideoEmbedder(mageAgent *clients.MageAgentClient, graphragClient *client
 	}
 }
 
-// GenerateEmbedding generates embedding for an entire video
-func (ve *VideoEmbedder) GenerateEmbedding(ctx context.Context, videoID string, frames []string, metadata VideoMetadata) (*VideoEmbedding, error) {
+// GenerateEmbedding generates embedding for an entire video, owned by the job's tenant
+func (ve *VideoEmbedder) GenerateEmbedding(ctx context.Context, job *models.JobPayload, frames []string, metadata VideoMetadata) (*VideoEmbedding, error) {
 	startTime := time.Now()
+	videoID := job.JobID
 
 	// Sample frames
 	sampledFrames, sampledIndices := ve.sampleFrames(frames)
@@ -123,6 +141,7 @@ func (ve *VideoEmbedder) GenerateEmbedding(ctx context.Context, videoID string,
 		Metadata:        computedMetadata,
 		Hash:            hash,
 	}
+	embedding.SetOwner(job)
 
 	elapsed := time.Since(startTime).Seconds()
 	log.Printf("Generated video embedding for %s: %d frames, %.2fs elapsed",
@@ -210,22 +229,94 @@ func (ve *VideoEmbedder) generateFrameBatch(ctx context.Context, frames []string
 // 2. Generate VoyageAI embedding from description via GraphRAG
 func (ve *VideoEmbedder) generateSingleFrameEmbedding(ctx context.Context, frameData string) ([]float64, FrameFeatures, error) {
 	// Step 1: Get visual analysis from MageAgent
+	description, features, err := ve.describeFrame(ctx, frameData)
+	if err != nil {
+		return nil, FrameFeatures{}, err
+	}
+
+	// Step 2: Generate embedding from description via GraphRAG (VoyageAI voyage-3)
+	embedding, err := ve.graphragClient.GenerateEmbedding(ctx, description, "document")
+	if err != nil {
+		log.Printf("Failed to generate embedding via GraphRAG: %v", err)
+		// Return default embedding as fallback
+		return ve.generateDefaultEmbedding(), features, nil
+	}
+
+	// Validate embedding dimensions
+	if len(embedding) != EmbeddingDimension {
+		log.Printf("Warning: Unexpected embedding dimension: got %d, expected %d", len(embedding), EmbeddingDimension)
+		return ve.generateDefaultEmbedding(), features, nil
+	}
+
+	return embedding, features, nil
+}
+
+// GenerateQueryEmbedding embeds a query image through the same describe-then-embed path as library frames
+// Unlike library frames, failures are returned instead of falling back to a default vector
+func (ve *VideoEmbedder) GenerateQueryEmbedding(ctx context.Context, frameData string) ([]float64, FrameFeatures, error) {
+	description, features, err := ve.describeFrame(ctx, frameData)
+	if err != nil {
+		return nil, FrameFeatures{}, err
+	}
+
+	// Embed as "document" so query frames live in the same space as indexed frame descriptions
+	embedding, err := ve.graphragClient.GenerateEmbedding(ctx, description, "document")
+	if err != nil {
+		return nil, FrameFeatures{}, fmt.Errorf("failed to embed frame description: %w", err)
+	}
+
+	if len(embedding) != EmbeddingDimension {
+		return nil, FrameFeatures{}, fmt.Errorf("unexpected embedding dimension: got %d, expected %d", len(embedding), EmbeddingDimension)
+	}
+
+	return embedding, features, nil
+}
+
+// GenerateClipQueryEmbedding embeds sampled clip frames and aggregates them into one query vector
+// Frames that fail are skipped rather than zero-filled so they don't dilute the aggregate
+func (ve *VideoEmbedder) GenerateClipQueryEmbedding(ctx context.Context, frames []string, timestamps []float64) ([]float64, []FrameEmbedding, error) {
+	frameEmbeddings := make([]FrameEmbedding, 0, len(frames))
+
+	for i, frame := range frames {
+		embedding, features, err := ve.GenerateQueryEmbedding(ctx, frame)
+		if err != nil {
+			log.Printf("Warning: Clip frame %d embedding failed: %v", i, err)
+			continue
+		}
+
+		timestamp := 0.0
+		if i < len(timestamps) {
+			timestamp = timestamps[i]
+		}
+
+		frameEmbeddings = append(frameEmbeddings, FrameEmbedding{
+			FrameNum:   i,
+			Timestamp:  timestamp,
+			Embedding:  embedding,
+			Confidence: 0.9,
+			Features:   features,
+		})
+	}
+
+	if len(frameEmbeddings) == 0 {
+		return nil, nil, fmt.Errorf("no clip frames could be embedded (%d sampled)", len(frames))
+	}
+
+	return ve.aggregateEmbeddings(frameEmbeddings), frameEmbeddings, nil
+}
+
+// describeFrame asks MageAgent for a searchable description and visual features of a frame
+func (ve *VideoEmbedder) describeFrame(ctx context.Context, frameData string) (string, FrameFeatures, error) {
+	// Colour and exposure are measured locally, so the model is only asked for content
 	prompt := `Analyze this video frame and describe its visual content in detail, including:
-1. Visual features (color, brightness, contrast, sharpness)
-2. Detected objects (list up to 10)
-3. Scene type (indoor/outdoor/action/dialogue/etc)
-4. Motion estimation (low, medium, high)
-5. Dominant colors (top 5)
+1. Detected objects (list up to 10)
+2. Scene type (indoor/outdoor/action/dialogue/etc)
+3. Motion estimation (low, medium, high)
 
 Respond with JSON:
 {
   "description": "Detailed text description of the frame for semantic search",
   "features": {
-    "colorHistogram": [192 float values for RGB histogram],
-    "dominantColors": ["color1", "color2", "color3", "color4", "color5"],
-    "brightness": 0.0-1.0,
-    "contrast": 0.0-1.0,
-    "sharpness": 0.0-1.0,
     "motion": 0.0-1.0,
     "objects": ["object1", "object2", ...],
     "scene": "indoor|outdoor|action|dialogue|...",
@@ -241,7 +332,7 @@ Respond with JSON:
 
 	visionResp, err := ve.mageAgent.AnalyzeFrame(ctx, visionReq)
 	if err != nil {
-		return nil, FrameFeatures{}, fmt.Errorf("vision analysis failed: %w", err)
+		return "", FrameFeatures{}, fmt.Errorf("vision analysis failed: %w", err)
 	}
 
 	// Parse vision response
@@ -253,21 +344,34 @@ Respond with JSON:
 		features = ve.generateDefaultFeatures()
 	}
 
-	// Step 2: Generate embedding from description via GraphRAG (VoyageAI voyage-3)
-	embedding, err := ve.graphragClient.GenerateEmbedding(ctx, description, "document")
-	if err != nil {
-		log.Printf("Failed to generate embedding via GraphRAG: %v", err)
-		// Return default embedding as fallback
-		return ve.generateDefaultEmbedding(), features, nil
+	if description == "" {
+		return "", FrameFeatures{}, fmt.Errorf("vision analysis returned empty description")
 	}
 
-	// Validate embedding dimensions
-	if len(embedding) != EmbeddingDimension {
-		log.Printf("Warning: Unexpected embedding dimension: got %d, expected %d", len(embedding), EmbeddingDimension)
-		return ve.generateDefaultEmbedding(), features, nil
+	if stats, err := imagestats.AnalyzeBase64(frameData); err != nil {
+		log.Printf("Warning: failed to measure frame statistics: %v", err)
+	} else {
+		applyMeasuredFeatures(&features, stats)
 	}
 
-	return embedding, features, nil
+	return description, features, nil
+}
+
+// applyMeasuredFeatures replaces colour and exposure features with measured statistics
+func applyMeasuredFeatures(features *FrameFeatures, stats *imagestats.Stats) {
+	features.ColorHistogram = stats.RGBHistogram
+	features.DominantColors = stats.ColorNames()
+	features.Brightness = stats.Luminance
+	features.Contrast = stats.Contrast
+	features.Sharpness = stats.Sharpness
+	features.Saturation = stats.Saturation
+	features.ColorTemperature = stats.ColorTemperature
+	features.ColorProfile = stats.ColorProfile
+
+	if features.Attributes == nil {
+		features.Attributes = make(map[string]interface{})
+	}
+	features.Attributes["measured"] = true
 }
 
 // parseFrameAnalysis parses AI vision response into description and features
@@ -403,6 +507,7 @@ func (ve *VideoEmbedder) computeVideoMetadata(frameEmbeddings []FrameEmbedding,
 	sceneMap := make(map[string]int)
 	objectMap := make(map[string]int)
 	colorMap := make(map[string]int)
+	profileMap := make(map[string]int)
 
 	for _, frame := range frameEmbeddings {
 		avgBrightness += frame.Features.Brightness
@@ -418,6 +523,10 @@ func (ve *VideoEmbedder) computeVideoMetadata(frameEmbeddings []FrameEmbedding,
 		for _, color := range frame.Features.DominantColors {
 			colorMap[color]++
 		}
+
+		if frame.Features.ColorProfile != "" {
+			profileMap[frame.Features.ColorProfile]++
+		}
 	}
 
 	n := float64(len(frameEmbeddings))
@@ -430,8 +539,11 @@ func (ve *VideoEmbedder) computeVideoMetadata(frameEmbeddings []FrameEmbedding,
 	dominantObjects := ve.topN(objectMap, 10)
 	dominantColors := ve.topN(colorMap, 5)
 
-	// Determine color profile
+	// Determine color profile (measured per frame when available)
 	colorProfile := ve.determineColorProfile(dominantColors)
+	if profiles := ve.topN(profileMap, 1); len(profiles) > 0 {
+		colorProfile = profiles[0]
+	}
 
 	return VideoMetadata{
 		Title:           inputMetadata.Title,
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// QueryInteractions returns a job's recorded interactions matching the query, ordered by start time
+// e.g. {JobID: X, Type: "handoff", Class: "person"} lists handoffs between person tracks.
+// Jobs outside the tenant yield no interactions
+func (sm *StorageManager) QueryInteractions(ctx context.Context, tenantID string, query models.InteractionQuery) ([]models.InteractionRecord, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+	if query.JobID == "" {
+		return nil, fmt.Errorf("job ID is required")
+	}
+
+	conditions := []string{"job_id = $1", tenantJobsClause(2)}
+	args := []interface{}{query.JobID, tenantID}
+
+	if query.Type != "" {
+		args = append(args, query.Type)
+		conditions = append(conditions, fmt.Sprintf("interaction_type = $%d", len(args)))
+	}
+	if query.Class != "" {
+		// Every participant has the class (all classes are contained in [class])
+		classJSON, _ := json.Marshal([]string{query.Class})
+		args = append(args, string(classJSON))
+		conditions = append(conditions, fmt.Sprintf("classes IS NOT NULL AND classes <@ $%d::jsonb", len(args)))
+	}
+	if query.TrackID != "" {
+		args = append(args, query.TrackID)
+		conditions = append(conditions, fmt.Sprintf("participants ? $%d", len(args)))
+	}
+	if query.MinDuration > 0 {
+		args = append(args, query.MinDuration)
+		conditions = append(conditions, fmt.Sprintf("COALESCE(duration, end_time - start_time) >= $%d", len(args)))
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+	args = append(args, limit)
+
+	sqlQuery := fmt.Sprintf(`
+		SELECT interaction_id, interaction_type, participants, COALESCE(classes, '[]'::jsonb),
+			start_time, end_time, COALESCE(duration, end_time - start_time),
+			COALESCE(start_frame, 0), COALESCE(end_frame, 0), COALESCE(confidence, 0),
+			COALESCE(significance, 0), COALESCE(description, '')
+		FROM videoagent.interaction_events
+		WHERE %s
+		ORDER BY start_time ASC, interaction_id ASC
+		LIMIT $%d
+	`, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := sm.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query interactions: %w", err)
+	}
+	defer rows.Close()
+
+	interactions := make([]models.InteractionRecord, 0)
+	for rows.Next() {
+		var interaction models.InteractionRecord
+		var participantsJSON, classesJSON []byte
+		if err := rows.Scan(
+			&interaction.InteractionID,
+			&interaction.Type,
+			&participantsJSON,
+			&classesJSON,
+			&interaction.StartTime,
+			&interaction.EndTime,
+			&interaction.Duration,
+			&interaction.StartFrame,
+			&interaction.EndFrame,
+			&interaction.Confidence,
+			&interaction.Significance,
+			&interaction.Description,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan interaction: %w", err)
+		}
+		if err := json.Unmarshal(participantsJSON, &interaction.Participants); err != nil {
+			return nil, fmt.Errorf("failed to decode participants of %s: %w", interaction.InteractionID, err)
+		}
+		if err := json.Unmarshal(classesJSON, &interaction.Classes); err != nil {
+			return nil, fmt.Errorf("failed to decode classes of %s: %w", interaction.InteractionID, err)
+		}
+		interactions = append(interactions, interaction)
+	}
+
+	return interactions, rows.Err()
+}
+
+// GetInteractionEvents returns the state changes of a job's interactions ordered by time
+// An empty interactionID returns the events of every interaction in the job.
+// Jobs outside the tenant yield no events
+func (sm *StorageManager) GetInteractionEvents(ctx context.Context, tenantID, jobID, interactionID string) ([]models.InteractionEventRecord, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	query := `
+		SELECT event_id, interaction_id, event_type, interaction_type, participants, timestamp,
+			COALESCE(frame_number, 0), COALESCE(significance, 0), COALESCE(proximity, 0)
+		FROM videoagent.interaction_event_log
+		WHERE job_id = $1 AND ` + tenantJobsClause(2) + ` AND ($3 = '' OR interaction_id = $3)
+		ORDER BY timestamp ASC, id ASC
+	`
+
+	rows, err := sm.db.QueryContext(ctx, query, jobID, tenantID, interactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query interaction events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]models.InteractionEventRecord, 0)
+	for rows.Next() {
+		var event models.InteractionEventRecord
+		var participantsJSON []byte
+		if err := rows.Scan(
+			&event.EventID,
+			&event.InteractionID,
+			&event.EventType,
+			&event.InteractionType,
+			&participantsJSON,
+			&event.Timestamp,
+			&event.FrameNumber,
+			&event.Significance,
+			&event.Proximity,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan interaction event: %w", err)
+		}
+		if err := json.Unmarshal(participantsJSON, &event.Participants); err != nil {
+			return nil, fmt.Errorf("failed to decode participants of %s: %w", event.EventID, err)
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+	"github.com/lib/pq"
+)
+
+// Keyword search backs the lexical side of similarity.SearchAPI hybrid retrieval.
+// Video IDs in the similarity module are job IDs, so all lookups are keyed on job_id
+// and restricted to jobs owned by the caller's tenant (see models.TenantIDFor).
+// The tsvector expressions must match the GIN indexes created in initSchema.
+
+// tenantJobsClause restricts job_id to the tenant passed as the given parameter
+func tenantJobsClause(param int) string {
+	return fmt.Sprintf("job_id IN (SELECT job_id FROM videoagent.jobs WHERE tenant_id = $%d)", param)
+}
+
+// SearchTranscripts runs a full-text search over audio transcriptions
+func (sm *StorageManager) SearchTranscripts(ctx context.Context, tenantID, query string, jobIDs []string, limit int) ([]models.KeywordMatch, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+	if strings.TrimSpace(query) == "" {
+		return []models.KeywordMatch{}, nil
+	}
+
+	sqlQuery := `
+		SELECT job_id,
+			ts_rank(to_tsvector('simple', COALESCE(transcription, '')), plainto_tsquery('simple', $1)) AS score,
+			ts_headline('simple', COALESCE(transcription, ''), plainto_tsquery('simple', $1), 'MaxFragments=1, MaxWords=20, MinWords=5') AS snippet
+		FROM videoagent.audio_analysis
+		WHERE to_tsvector('simple', COALESCE(transcription, '')) @@ plainto_tsquery('simple', $1)
+			AND (cardinality($2::text[]) = 0 OR job_id = ANY($2))
+			AND ` + tenantJobsClause(4) + `
+		ORDER BY score DESC, job_id ASC
+		LIMIT $3
+	`
+
+	rows, err := sm.db.QueryContext(ctx, sqlQuery, query, pq.Array(nonNilStrings(jobIDs)), limit, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("transcript search failed: %w", err)
+	}
+	defer rows.Close()
+
+	matches := make([]models.KeywordMatch, 0)
+	for rows.Next() {
+		match := models.KeywordMatch{Source: "transcript", MatchedTerms: 1}
+		if err := rows.Scan(&match.JobID, &match.Score, &match.Snippet); err != nil {
+			return nil, fmt.Errorf("failed to scan transcript match: %w", err)
+		}
+		matches = append(matches, match)
+	}
+
+	return matches, rows.Err()
+}
+
+// SearchTextExtractions runs a full-text search over OCR text, aggregated per job
+func (sm *StorageManager) SearchTextExtractions(ctx context.Context, tenantID, query string, jobIDs []string, limit int) ([]models.KeywordMatch, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+	if strings.TrimSpace(query) == "" {
+		return []models.KeywordMatch{}, nil
+	}
+
+	sqlQuery := `
+		SELECT job_id,
+			SUM(ts_rank(to_tsvector('simple', text), plainto_tsquery('simple', $1))) AS score,
+			COUNT(*) AS hits,
+			MIN(COALESCE(timestamp, 0)) AS first_seen,
+			(ARRAY_AGG(text ORDER BY confidence DESC))[1] AS snippet
+		FROM videoagent.text_extractions
+		WHERE to_tsvector('simple', text) @@ plainto_tsquery('simple', $1)
+			AND (cardinality($2::text[]) = 0 OR job_id = ANY($2))
+			AND ` + tenantJobsClause(4) + `
+		GROUP BY job_id
+		ORDER BY score DESC, job_id ASC
+		LIMIT $3
+	`
+
+	rows, err := sm.db.QueryContext(ctx, sqlQuery, query, pq.Array(nonNilStrings(jobIDs)), limit, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("OCR text search failed: %w", err)
+	}
+	defer rows.Close()
+
+	matches := make([]models.KeywordMatch, 0)
+	for rows.Next() {
+		match := models.KeywordMatch{Source: "ocr"}
+		if err := rows.Scan(&match.JobID, &match.Score, &match.MatchedTerms, &match.Timestamp, &match.Snippet); err != nil {
+			return nil, fmt.Errorf("failed to scan OCR match: %w", err)
+		}
+		matches = append(matches, match)
+	}
+
+	return matches, rows.Err()
+}
+
+// SearchObjectLabels finds jobs containing any of the given object labels (case-insensitive)
+// MatchedTerms holds the number of distinct labels found, Score the total detection count
+func (sm *StorageManager) SearchObjectLabels(ctx context.Context, tenantID string, labels []string, jobIDs []string, limit int) ([]models.KeywordMatch, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	normalized := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if label = strings.ToLower(strings.TrimSpace(label)); label != "" {
+			normalized = append(normalized, label)
+		}
+	}
+	if len(normalized) == 0 {
+		return []models.KeywordMatch{}, nil
+	}
+
+	sqlQuery := `
+		SELECT job_id,
+			COUNT(DISTINCT LOWER(label)) AS matched_labels,
+			COUNT(*) AS hits,
+			MIN(COALESCE(timestamp, 0)) AS first_seen,
+			STRING_AGG(DISTINCT LOWER(label), ', ') AS labels
+		FROM videoagent.objects
+		WHERE LOWER(label) = ANY($1)
+			AND (cardinality($2::text[]) = 0 OR job_id = ANY($2))
+			AND ` + tenantJobsClause(4) + `
+		GROUP BY job_id
+		ORDER BY matched_labels DESC, hits DESC, job_id ASC
+		LIMIT $3
+	`
+
+	rows, err := sm.db.QueryContext(ctx, sqlQuery, pq.Array(normalized), pq.Array(nonNilStrings(jobIDs)), limit, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("object label search failed: %w", err)
+	}
+	defer rows.Close()
+
+	matches := make([]models.KeywordMatch, 0)
+	for rows.Next() {
+		var hits int
+		var snippet sql.NullString
+		match := models.KeywordMatch{Source: "objects"}
+		if err := rows.Scan(&match.JobID, &match.MatchedTerms, &hits, &match.Timestamp, &snippet); err != nil {
+			return nil, fmt.Errorf("failed to scan object match: %w", err)
+		}
+		match.Score = float64(hits)
+		match.Snippet = snippet.String
+		matches = append(matches, match)
+	}
+
+	return matches, rows.Err()
+}
+
+// nonNilStrings ensures an empty (not NULL) array is sent to PostgreSQL
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
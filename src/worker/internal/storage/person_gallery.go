@@ -0,0 +1,400 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// Person re-ID gallery persistence (see tracking.PersonGallery).
+// Every query is scoped by tenant_id; appearances and video counts are derived from sightings.
+
+// galleryIdentityColumns selects an identity with counts aggregated from its sightings
+const galleryIdentityColumns = `
+	g.tenant_id, g.identity_id, g.features, g.attributes, COALESCE(g.confidence, 0), g.aliases,
+	g.first_seen, g.last_seen,
+	(SELECT COUNT(*) FROM videoagent.person_gallery_sightings s
+		WHERE s.tenant_id = g.tenant_id AND s.identity_id = g.identity_id),
+	(SELECT COUNT(DISTINCT s.job_id) FROM videoagent.person_gallery_sightings s
+		WHERE s.tenant_id = g.tenant_id AND s.identity_id = g.identity_id)`
+
+// ListGalleryIdentities returns every gallery identity of a tenant, most recently seen first
+func (sm *StorageManager) ListGalleryIdentities(ctx context.Context, tenantID string) ([]models.GalleryIdentity, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	query := `SELECT ` + galleryIdentityColumns + `
+		FROM videoagent.person_gallery g
+		WHERE g.tenant_id = $1
+		ORDER BY g.last_seen DESC, g.identity_id ASC`
+
+	rows, err := sm.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list gallery identities: %w", err)
+	}
+	defer rows.Close()
+
+	identities := make([]models.GalleryIdentity, 0)
+	for rows.Next() {
+		identity, err := scanGalleryIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, *identity)
+	}
+
+	return identities, rows.Err()
+}
+
+// GetGalleryIdentity returns a gallery identity, or nil if it does not exist for the tenant
+func (sm *StorageManager) GetGalleryIdentity(ctx context.Context, tenantID, identityID string) (*models.GalleryIdentity, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	query := `SELECT ` + galleryIdentityColumns + `
+		FROM videoagent.person_gallery g
+		WHERE g.tenant_id = $1 AND g.identity_id = $2`
+
+	identity, err := scanGalleryIdentity(sm.db.QueryRowContext(ctx, query, tenantID, identityID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanGalleryIdentity scans a row selected with galleryIdentityColumns
+func scanGalleryIdentity(row rowScanner) (*models.GalleryIdentity, error) {
+	var identity models.GalleryIdentity
+	var featuresJSON, attributesJSON, aliasesJSON []byte
+
+	err := row.Scan(
+		&identity.TenantID,
+		&identity.IdentityID,
+		&featuresJSON,
+		&attributesJSON,
+		&identity.Confidence,
+		&aliasesJSON,
+		&identity.FirstSeen,
+		&identity.LastSeen,
+		&identity.Appearances,
+		&identity.VideoCount,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan gallery identity: %w", err)
+	}
+
+	json.Unmarshal(featuresJSON, &identity.Features)
+	if len(attributesJSON) > 0 {
+		json.Unmarshal(attributesJSON, &identity.Attributes)
+	}
+	if len(aliasesJSON) > 0 {
+		json.Unmarshal(aliasesJSON, &identity.Aliases)
+	}
+	if identity.Attributes == nil {
+		identity.Attributes = make(map[string]interface{})
+	}
+	if identity.Aliases == nil {
+		identity.Aliases = []string{}
+	}
+
+	return &identity, nil
+}
+
+// SaveGalleryIdentity inserts or updates a gallery identity
+func (sm *StorageManager) SaveGalleryIdentity(ctx context.Context, identity *models.GalleryIdentity) error {
+	if identity.TenantID == "" {
+		return fmt.Errorf("gallery identity %s has no tenant ID", identity.IdentityID)
+	}
+
+	featuresJSON, _ := json.Marshal(identity.Features)
+	attributesJSON, _ := json.Marshal(identity.Attributes)
+	aliasesJSON, _ := json.Marshal(identity.Aliases)
+
+	query := `
+		INSERT INTO videoagent.person_gallery (
+			tenant_id, identity_id, features, attributes, confidence, aliases, first_seen, last_seen
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (tenant_id, identity_id) DO UPDATE SET
+			features = EXCLUDED.features,
+			attributes = EXCLUDED.attributes,
+			confidence = EXCLUDED.confidence,
+			aliases = EXCLUDED.aliases,
+			first_seen = EXCLUDED.first_seen,
+			last_seen = EXCLUDED.last_seen,
+			updated_at = CURRENT_TIMESTAMP
+	`
+
+	_, err := sm.db.ExecContext(ctx, query,
+		identity.TenantID,
+		identity.IdentityID,
+		featuresJSON,
+		attributesJSON,
+		identity.Confidence,
+		aliasesJSON,
+		identity.FirstSeen,
+		identity.LastSeen,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save gallery identity %s: %w", identity.IdentityID, err)
+	}
+
+	return nil
+}
+
+// DeleteGalleryIdentity deletes a gallery identity and its sightings
+func (sm *StorageManager) DeleteGalleryIdentity(ctx context.Context, tenantID, identityID string) error {
+	if tenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+
+	_, err := sm.db.ExecContext(ctx,
+		`DELETE FROM videoagent.person_gallery WHERE tenant_id = $1 AND identity_id = $2`,
+		tenantID, identityID)
+	if err != nil {
+		return fmt.Errorf("failed to delete gallery identity %s: %w", identityID, err)
+	}
+
+	return nil
+}
+
+// AddGallerySightings records sightings, replacing any earlier sighting of the same track
+func (sm *StorageManager) AddGallerySightings(ctx context.Context, sightings []models.GallerySighting) error {
+	if len(sightings) == 0 {
+		return nil
+	}
+
+	tx, err := sm.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO videoagent.person_gallery_sightings (
+			tenant_id, identity_id, job_id, track_id, start_time, end_time, confidence, features, seen_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (tenant_id, job_id, track_id) DO UPDATE SET
+			identity_id = EXCLUDED.identity_id,
+			start_time = EXCLUDED.start_time,
+			end_time = EXCLUDED.end_time,
+			confidence = EXCLUDED.confidence,
+			features = EXCLUDED.features,
+			seen_at = EXCLUDED.seen_at
+	`
+
+	for _, sighting := range sightings {
+		if sighting.TenantID == "" {
+			return fmt.Errorf("sighting %s/%s has no tenant ID", sighting.JobID, sighting.TrackID)
+		}
+
+		featuresJSON, _ := json.Marshal(sighting.Features)
+		_, err := tx.ExecContext(ctx, query,
+			sighting.TenantID,
+			sighting.IdentityID,
+			sighting.JobID,
+			sighting.TrackID,
+			sighting.StartTime,
+			sighting.EndTime,
+			sighting.Confidence,
+			featuresJSON,
+			sighting.SeenAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store sighting %s/%s: %w", sighting.JobID, sighting.TrackID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit sightings: %w", err)
+	}
+
+	return nil
+}
+
+// ListGallerySightings returns the sightings of a gallery identity in chronological order
+func (sm *StorageManager) ListGallerySightings(ctx context.Context, tenantID, identityID string) ([]models.GallerySighting, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	query := `
+		SELECT tenant_id, identity_id, job_id, track_id,
+			COALESCE(start_time, 0), COALESCE(end_time, 0), COALESCE(confidence, 0), features, seen_at
+		FROM videoagent.person_gallery_sightings
+		WHERE tenant_id = $1 AND identity_id = $2
+		ORDER BY seen_at ASC, job_id ASC, track_id ASC
+	`
+
+	rows, err := sm.db.QueryContext(ctx, query, tenantID, identityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sightings: %w", err)
+	}
+	defer rows.Close()
+
+	sightings := make([]models.GallerySighting, 0)
+	for rows.Next() {
+		var sighting models.GallerySighting
+		var featuresJSON []byte
+		if err := rows.Scan(
+			&sighting.TenantID,
+			&sighting.IdentityID,
+			&sighting.JobID,
+			&sighting.TrackID,
+			&sighting.StartTime,
+			&sighting.EndTime,
+			&sighting.Confidence,
+			&featuresJSON,
+			&sighting.SeenAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan sighting: %w", err)
+		}
+		if len(featuresJSON) > 0 {
+			json.Unmarshal(featuresJSON, &sighting.Features)
+		}
+		sightings = append(sightings, sighting)
+	}
+
+	return sightings, rows.Err()
+}
+
+// DeleteGallerySightingsForJob removes a job's sightings (before reprocessing it)
+func (sm *StorageManager) DeleteGallerySightingsForJob(ctx context.Context, tenantID, jobID string) error {
+	if tenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+
+	_, err := sm.db.ExecContext(ctx,
+		`DELETE FROM videoagent.person_gallery_sightings WHERE tenant_id = $1 AND job_id = $2`,
+		tenantID, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to delete sightings for job %s: %w", jobID, err)
+	}
+
+	return nil
+}
+
+// MoveGallerySightings reassigns sightings from one identity to another
+// With no keys every sighting moves. Returns the number of sightings moved.
+func (sm *StorageManager) MoveGallerySightings(ctx context.Context, tenantID, fromID, toID string, keys []models.SightingKey) (int, error) {
+	if tenantID == "" {
+		return 0, fmt.Errorf("tenant ID is required")
+	}
+
+	if len(keys) == 0 {
+		result, err := sm.db.ExecContext(ctx, `
+			UPDATE videoagent.person_gallery_sightings SET identity_id = $3
+			WHERE tenant_id = $1 AND identity_id = $2`,
+			tenantID, fromID, toID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to move sightings: %w", err)
+		}
+		moved, _ := result.RowsAffected()
+		return int(moved), nil
+	}
+
+	tx, err := sm.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	moved := 0
+	for _, key := range keys {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE videoagent.person_gallery_sightings SET identity_id = $3
+			WHERE tenant_id = $1 AND identity_id = $2 AND job_id = $4 AND track_id = $5`,
+			tenantID, fromID, toID, key.JobID, key.TrackID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to move sighting %s/%s: %w", key.JobID, key.TrackID, err)
+		}
+		n, _ := result.RowsAffected()
+		moved += int(n)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit sighting move: %w", err)
+	}
+
+	return moved, nil
+}
+
+// PruneGallery enforces gallery retention for a tenant
+// Identities last seen before olderThan (when non-zero) are removed, then the least recently
+// seen identities beyond maxIdentities (when positive). Returns the removed identity IDs.
+func (sm *StorageManager) PruneGallery(ctx context.Context, tenantID string, maxIdentities int, olderThan time.Time) ([]string, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	removed := make([]string, 0)
+
+	if !olderThan.IsZero() {
+		ids, err := sm.deleteGalleryIdentities(ctx, `
+			DELETE FROM videoagent.person_gallery
+			WHERE tenant_id = $1 AND last_seen < $2
+			RETURNING identity_id`, tenantID, olderThan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prune expired identities: %w", err)
+		}
+		removed = append(removed, ids...)
+	}
+
+	if maxIdentities > 0 {
+		ids, err := sm.deleteGalleryIdentities(ctx, `
+			DELETE FROM videoagent.person_gallery
+			WHERE tenant_id = $1 AND identity_id IN (
+				SELECT identity_id FROM videoagent.person_gallery
+				WHERE tenant_id = $1
+				ORDER BY last_seen DESC, identity_id ASC
+				OFFSET $2
+			)
+			RETURNING identity_id`, tenantID, maxIdentities)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prune excess identities: %w", err)
+		}
+		removed = append(removed, ids...)
+	}
+
+	return removed, nil
+}
+
+// deleteGalleryIdentities runs a DELETE ... RETURNING identity_id statement
+func (sm *StorageManager) deleteGalleryIdentities(ctx context.Context, query string, args ...interface{}) ([]string, error) {
+	rows, err := sm.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// nullString stores empty strings as NULL
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// StoreQCReport stores a job's technical QC report, replacing any earlier one
+func (sm *StorageManager) StoreQCReport(ctx context.Context, jobID string, report *models.QCReport) error {
+	if report == nil {
+		return nil
+	}
+
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal QC report: %w", err)
+	}
+
+	query := `
+		INSERT INTO videoagent.qc_reports (job_id, profile, passed, errors, warnings, report)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (job_id) DO UPDATE SET
+			profile = EXCLUDED.profile,
+			passed = EXCLUDED.passed,
+			errors = EXCLUDED.errors,
+			warnings = EXCLUDED.warnings,
+			report = EXCLUDED.report,
+			created_at = CURRENT_TIMESTAMP
+	`
+
+	_, err = sm.db.ExecContext(ctx, query,
+		jobID,
+		report.Profile.Name,
+		report.Passed,
+		report.Errors,
+		report.Warnings,
+		reportJSON,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store QC report: %w", err)
+	}
+
+	return nil
+}
+
+// GetQCReport returns a job's technical QC report, or nil when QC wasn't run
+// Jobs outside the tenant yield no report
+func (sm *StorageManager) GetQCReport(ctx context.Context, tenantID, jobID string) (*models.QCReport, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	var reportJSON []byte
+	err := sm.db.QueryRowContext(ctx,
+		`SELECT report FROM videoagent.qc_reports WHERE job_id = $1 AND `+tenantJobsClause(2),
+		jobID, tenantID,
+	).Scan(&reportJSON)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query QC report: %w", err)
+	}
+
+	var report models.QCReport
+	if err := json.Unmarshal(reportJSON, &report); err != nil {
+		return nil, fmt.Errorf("failed to decode QC report: %w", err)
+	}
+	return &report, nil
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// StoreScenes stores a job's detected scenes with their cinematic analysis
+// Existing scenes for the job are replaced so reprocessing is idempotent
+func (sm *StorageManager) StoreScenes(ctx context.Context, jobID string, scenes []models.SceneDetection) error {
+	tx, err := sm.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM videoagent.scenes WHERE job_id = $1`, jobID); err != nil {
+		return fmt.Errorf("failed to clear scenes: %w", err)
+	}
+
+	query := `
+		INSERT INTO videoagent.scenes (
+			scene_id, job_id, start_time, end_time, start_frame, end_frame, description,
+			key_frame_id, scene_type, confidence, cinematic
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	for _, scene := range scenes {
+		var cinematicJSON []byte
+		if scene.Cinematic != nil {
+			cinematicJSON, err = json.Marshal(scene.Cinematic)
+			if err != nil {
+				return fmt.Errorf("failed to marshal cinematic analysis for scene %s: %w", scene.SceneID, err)
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, query,
+			scene.SceneID,
+			jobID,
+			scene.StartTime,
+			scene.EndTime,
+			scene.StartFrame,
+			scene.EndFrame,
+			scene.Description,
+			nullString(scene.KeyFrameID),
+			scene.SceneType,
+			scene.Confidence,
+			cinematicJSON,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store scene %s: %w", scene.SceneID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit scenes: %w", err)
+	}
+
+	return nil
+}
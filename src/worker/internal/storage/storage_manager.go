@@ -161,6 +161,16 @@ func (sm *StorageManager) initSchema() error {
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 
+	-- Frame description and transcript segment embeddings (moment retrieval)
+	CREATE TABLE IF NOT EXISTS videoagent.timeline_embeddings (
+		job_id VARCHAR(255) NOT NULL REFERENCES videoagent.jobs(job_id) ON DELETE CASCADE,
+		kind VARCHAR(20) NOT NULL,
+		item_key VARCHAR(255) NOT NULL,
+		embedding JSONB NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (job_id, kind, item_key)
+	);
+
 	-- Scene detections
 	CREATE TABLE IF NOT EXISTS videoagent.scenes (
 		scene_id VARCHAR(255) PRIMARY KEY,
@@ -176,6 +186,131 @@ func (sm *StorageManager) initSchema() error {
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 
+	-- Object tracks (multi-object tracking)
+	CREATE TABLE IF NOT EXISTS videoagent.tracks (
+		job_id VARCHAR(255) NOT NULL REFERENCES videoagent.jobs(job_id) ON DELETE CASCADE,
+		track_id VARCHAR(255) NOT NULL,
+		class VARCHAR(50) NOT NULL,
+		identity_id VARCHAR(255),
+		start_time FLOAT NOT NULL,
+		end_time FLOAT NOT NULL,
+		start_frame INT NOT NULL,
+		end_frame INT NOT NULL,
+		frame_count INT NOT NULL,
+		mean_confidence FLOAT,
+		pattern VARCHAR(50),
+		total_distance FLOAT,
+		average_speed FLOAT,
+		trajectory JSONB,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (job_id, track_id)
+	);
+
+	-- Person identities (re-ID across tracks)
+	CREATE TABLE IF NOT EXISTS videoagent.person_identities (
+		job_id VARCHAR(255) NOT NULL REFERENCES videoagent.jobs(job_id) ON DELETE CASCADE,
+		identity_id VARCHAR(255) NOT NULL,
+		track_ids JSONB NOT NULL,
+		appearances INT NOT NULL,
+		start_time FLOAT,
+		end_time FLOAT,
+		confidence FLOAT,
+		attributes JSONB,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (job_id, identity_id)
+	);
+
+	-- Interactions between tracks
+	CREATE TABLE IF NOT EXISTS videoagent.interaction_events (
+		job_id VARCHAR(255) NOT NULL REFERENCES videoagent.jobs(job_id) ON DELETE CASCADE,
+		interaction_id VARCHAR(255) NOT NULL,
+		interaction_type VARCHAR(50) NOT NULL,
+		participants JSONB NOT NULL,
+		start_time FLOAT NOT NULL,
+		end_time FLOAT NOT NULL,
+		start_frame INT,
+		end_frame INT,
+		confidence FLOAT,
+		significance FLOAT,
+		description TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (job_id, interaction_id)
+	);
+
+	-- Interaction state changes (started, changed, ended) of recorded interactions
+	CREATE TABLE IF NOT EXISTS videoagent.interaction_event_log (
+		id BIGSERIAL PRIMARY KEY,
+		job_id VARCHAR(255) NOT NULL REFERENCES videoagent.jobs(job_id) ON DELETE CASCADE,
+		event_id VARCHAR(255) NOT NULL,
+		interaction_id VARCHAR(255) NOT NULL,
+		event_type VARCHAR(50) NOT NULL,
+		interaction_type VARCHAR(50) NOT NULL,
+		participants JSONB NOT NULL,
+		timestamp FLOAT NOT NULL,
+		frame_number INT,
+		significance FLOAT,
+		proximity FLOAT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Zone/line events evaluated on tracks
+	CREATE TABLE IF NOT EXISTS videoagent.spatial_events (
+		id BIGSERIAL PRIMARY KEY,
+		job_id VARCHAR(255) NOT NULL REFERENCES videoagent.jobs(job_id) ON DELETE CASCADE,
+		event_type VARCHAR(50) NOT NULL,
+		rule_id VARCHAR(255) NOT NULL,
+		track_id VARCHAR(255) NOT NULL,
+		class VARCHAR(50),
+		timestamp FLOAT NOT NULL,
+		frame_number INT,
+		x FLOAT,
+		y FLOAT,
+		direction VARCHAR(50),
+		dwell_time FLOAT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Tracking visual summaries (PNG heatmaps/overlays, heatmap grids and timeline JSON)
+	CREATE TABLE IF NOT EXISTS videoagent.tracking_visuals (
+		job_id VARCHAR(255) NOT NULL REFERENCES videoagent.jobs(job_id) ON DELETE CASCADE,
+		kind VARCHAR(50) NOT NULL,
+		class VARCHAR(50) NOT NULL,
+		content_type VARCHAR(100) NOT NULL,
+		data BYTEA NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (job_id, kind, class)
+	);
+
+	-- Person re-ID gallery (tenant-wide identities linked across videos)
+	CREATE TABLE IF NOT EXISTS videoagent.person_gallery (
+		tenant_id VARCHAR(255) NOT NULL,
+		identity_id VARCHAR(255) NOT NULL,
+		features JSONB NOT NULL,
+		attributes JSONB,
+		confidence FLOAT,
+		aliases JSONB,
+		first_seen TIMESTAMP NOT NULL,
+		last_seen TIMESTAMP NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (tenant_id, identity_id)
+	);
+
+	-- Gallery sightings (one per person track in a job)
+	CREATE TABLE IF NOT EXISTS videoagent.person_gallery_sightings (
+		tenant_id VARCHAR(255) NOT NULL,
+		identity_id VARCHAR(255) NOT NULL,
+		job_id VARCHAR(255) NOT NULL REFERENCES videoagent.jobs(job_id) ON DELETE CASCADE,
+		track_id VARCHAR(255) NOT NULL,
+		start_time FLOAT,
+		end_time FLOAT,
+		confidence FLOAT,
+		features JSONB,
+		seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (tenant_id, job_id, track_id),
+		FOREIGN KEY (tenant_id, identity_id) REFERENCES videoagent.person_gallery(tenant_id, identity_id) ON DELETE CASCADE
+	);
+
 	-- Content classification
 	CREATE TABLE IF NOT EXISTS videoagent.classifications (
 		job_id VARCHAR(255) PRIMARY KEY REFERENCES videoagent.jobs(job_id) ON DELETE CASCADE,
@@ -216,6 +351,17 @@ func (sm *StorageManager) initSchema() error {
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 
+	-- Technical QC reports
+	CREATE TABLE IF NOT EXISTS videoagent.qc_reports (
+		job_id VARCHAR(255) PRIMARY KEY REFERENCES videoagent.jobs(job_id) ON DELETE CASCADE,
+		profile VARCHAR(50) NOT NULL,
+		passed BOOLEAN NOT NULL,
+		errors INT NOT NULL,
+		warnings INT NOT NULL,
+		report JSONB NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
 	-- Google Drive OAuth tokens
 	CREATE TABLE IF NOT EXISTS videoagent.gdrive_tokens (
 		user_id VARCHAR(255) PRIMARY KEY,
@@ -234,11 +380,29 @@ func (sm *StorageManager) initSchema() error {
 		return fmt.Errorf("failed to create tables: %w", err)
 	}
 
+	// Tenant scoping columns (added after initial release, so applied as migrations)
+	migrationStatements := []string{
+		`ALTER TABLE videoagent.jobs ADD COLUMN IF NOT EXISTS org_id VARCHAR(255)`,
+		`ALTER TABLE videoagent.jobs ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(255)`,
+		`UPDATE videoagent.jobs SET tenant_id = 'user:' || user_id WHERE tenant_id IS NULL AND user_id <> ''`,
+		`ALTER TABLE videoagent.person_identities ADD COLUMN IF NOT EXISTS gallery_identity_id VARCHAR(255)`,
+		`ALTER TABLE videoagent.scenes ADD COLUMN IF NOT EXISTS cinematic JSONB`,
+		`ALTER TABLE videoagent.interaction_events ADD COLUMN IF NOT EXISTS classes JSONB`,
+		`ALTER TABLE videoagent.interaction_events ADD COLUMN IF NOT EXISTS duration FLOAT`,
+	}
+
+	for _, stmt := range migrationStatements {
+		if _, err := sm.db.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to apply migration: %w (statement: %s)", err, stmt)
+		}
+	}
+
 	// Step 2: Create indexes separately (PostgreSQL-native approach)
 	// Using IF NOT EXISTS to make index creation idempotent
 	indexStatements := []string{
 		// Jobs table indexes
 		`CREATE INDEX IF NOT EXISTS idx_jobs_user_id ON videoagent.jobs(user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_jobs_tenant_id ON videoagent.jobs(tenant_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_jobs_status ON videoagent.jobs(status)`,
 		`CREATE INDEX IF NOT EXISTS idx_jobs_created_at ON videoagent.jobs(created_at)`,
 
@@ -250,16 +414,32 @@ func (sm *StorageManager) initSchema() error {
 		`CREATE INDEX IF NOT EXISTS idx_objects_job_id ON videoagent.objects(job_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_objects_frame_id ON videoagent.objects(frame_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_objects_label ON videoagent.objects(label)`,
+		`CREATE INDEX IF NOT EXISTS idx_objects_label_lower ON videoagent.objects(LOWER(label))`,
 
 		// Text extractions table indexes
 		`CREATE INDEX IF NOT EXISTS idx_text_job_id ON videoagent.text_extractions(job_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_text_frame_id ON videoagent.text_extractions(frame_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_text_fts ON videoagent.text_extractions USING GIN (to_tsvector('simple', text))`,
+
+		// Audio analysis full-text index (hybrid keyword search)
+		`CREATE INDEX IF NOT EXISTS idx_audio_transcription_fts ON videoagent.audio_analysis USING GIN (to_tsvector('simple', COALESCE(transcription, '')))`,
 
 		// Scenes table indexes
 		`CREATE INDEX IF NOT EXISTS idx_scenes_job_id ON videoagent.scenes(job_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_scenes_start_time ON videoagent.scenes(start_time)`,
 		`CREATE INDEX IF NOT EXISTS idx_scenes_end_time ON videoagent.scenes(end_time)`,
 
+		// Tracking table indexes
+		`CREATE INDEX IF NOT EXISTS idx_tracks_class ON videoagent.tracks(job_id, class)`,
+		`CREATE INDEX IF NOT EXISTS idx_tracks_identity_id ON videoagent.tracks(job_id, identity_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_interaction_events_type ON videoagent.interaction_events(job_id, interaction_type)`,
+		`CREATE INDEX IF NOT EXISTS idx_interaction_event_log_interaction ON videoagent.interaction_event_log(job_id, interaction_id, timestamp)`,
+		`CREATE INDEX IF NOT EXISTS idx_spatial_events_rule ON videoagent.spatial_events(job_id, rule_id, timestamp)`,
+
+		// Re-ID gallery indexes
+		`CREATE INDEX IF NOT EXISTS idx_person_gallery_last_seen ON videoagent.person_gallery(tenant_id, last_seen)`,
+		`CREATE INDEX IF NOT EXISTS idx_gallery_sightings_identity ON videoagent.person_gallery_sightings(tenant_id, identity_id)`,
+
 		// Model usage table indexes
 		`CREATE INDEX IF NOT EXISTS idx_usage_job_id ON videoagent.model_usage(job_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_usage_model ON videoagent.model_usage(model_id)`,
@@ -323,11 +503,13 @@ func (sm *StorageManager) initQdrantCollection() error {
 // StoreJob stores job information
 func (sm *StorageManager) StoreJob(ctx context.Context, job *models.JobPayload) error {
 	query := `
-		INSERT INTO videoagent.jobs (job_id, user_id, session_id, video_url, source_type, status, options, metadata, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO videoagent.jobs (job_id, user_id, session_id, video_url, source_type, status, options, metadata, created_at, org_id, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		ON CONFLICT (job_id) DO UPDATE SET
 			status = EXCLUDED.status,
-			metadata = EXCLUDED.metadata
+			metadata = EXCLUDED.metadata,
+			org_id = EXCLUDED.org_id,
+			tenant_id = EXCLUDED.tenant_id
 	`
 
 	optionsJSON, err := json.Marshal(job.Options)
@@ -350,6 +532,8 @@ func (sm *StorageManager) StoreJob(ctx context.Context, job *models.JobPayload)
 		optionsJSON,
 		metadataJSON,
 		job.EnqueuedAt,
+		job.OrgID,
+		job.TenantID(),
 	)
 
 	return err
@@ -515,6 +699,40 @@ func (sm *StorageManager) StoreObjects(ctx context.Context, jobID, frameID strin
 	return nil
 }
 
+// StoreTextExtractions stores OCR results for a frame
+func (sm *StorageManager) StoreTextExtractions(ctx context.Context, jobID, frameID string, texts []models.TextExtraction) error {
+	if len(texts) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO videoagent.text_extractions (text_id, frame_id, job_id, text, confidence, bounding_box, language, timestamp)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (text_id) DO NOTHING
+	`
+
+	for _, txt := range texts {
+		bboxJSON, _ := json.Marshal(txt.BoundingBox)
+
+		_, err := sm.db.ExecContext(ctx, query,
+			txt.TextID,
+			frameID,
+			jobID,
+			txt.Text,
+			txt.Confidence,
+			bboxJSON,
+			txt.Language,
+			txt.Timestamp,
+		)
+
+		if err != nil {
+			return fmt.Errorf("failed to store text extraction: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // StoreAudioAnalysis stores audio transcription and analysis
 func (sm *StorageManager) StoreAudioAnalysis(ctx context.Context, jobID string, analysis *models.AudioAnalysis) error {
 	query := `
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// GetFrameTimeline returns a job's analysed frames ordered by timestamp
+// Only the fields needed for temporal search are populated (no objects/text/embedding)
+// Jobs outside the tenant yield no frames
+func (sm *StorageManager) GetFrameTimeline(ctx context.Context, tenantID, jobID string) ([]models.FrameAnalysis, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	query := `
+		SELECT frame_id, timestamp, frame_number, COALESCE(file_path, ''), COALESCE(description, ''), COALESCE(confidence, 0)
+		FROM videoagent.frames
+		WHERE job_id = $1 AND ` + tenantJobsClause(2) + `
+		ORDER BY timestamp ASC, frame_number ASC
+	`
+
+	rows, err := sm.db.QueryContext(ctx, query, jobID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query frames: %w", err)
+	}
+	defer rows.Close()
+
+	frames := make([]models.FrameAnalysis, 0)
+	for rows.Next() {
+		var frame models.FrameAnalysis
+		if err := rows.Scan(&frame.FrameID, &frame.Timestamp, &frame.FrameNumber, &frame.FilePath, &frame.Description, &frame.Confidence); err != nil {
+			return nil, fmt.Errorf("failed to scan frame: %w", err)
+		}
+		frames = append(frames, frame)
+	}
+
+	return frames, rows.Err()
+}
+
+// GetTranscriptSegments returns a job's diarized transcript segments ordered by start time
+// Jobs outside the tenant yield no segments
+func (sm *StorageManager) GetTranscriptSegments(ctx context.Context, tenantID, jobID string) ([]models.SpeakerSegment, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	var speakersJSON []byte
+	err := sm.db.QueryRowContext(ctx,
+		`SELECT speakers FROM videoagent.audio_analysis WHERE job_id = $1 AND `+tenantJobsClause(2),
+		jobID, tenantID,
+	).Scan(&speakersJSON)
+
+	if err == sql.ErrNoRows {
+		return []models.SpeakerSegment{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transcript: %w", err)
+	}
+
+	segments := make([]models.SpeakerSegment, 0)
+	if len(speakersJSON) > 0 {
+		if err := json.Unmarshal(speakersJSON, &segments); err != nil {
+			return nil, fmt.Errorf("failed to decode transcript segments: %w", err)
+		}
+	}
+
+	sort.SliceStable(segments, func(i, j int) bool {
+		return segments[i].StartTime < segments[j].StartTime
+	})
+
+	return segments, nil
+}
+
+// StoreTimelineEmbeddings stores a job's frame description and transcript segment embeddings
+// Re-processing a job replaces the stored vectors
+func (sm *StorageManager) StoreTimelineEmbeddings(ctx context.Context, jobID string, embeddings []models.TimelineEmbedding) error {
+	if len(embeddings) == 0 {
+		return nil
+	}
+
+	tx, err := sm.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO videoagent.timeline_embeddings (job_id, kind, item_key, embedding)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (job_id, kind, item_key) DO UPDATE SET
+			embedding = EXCLUDED.embedding
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, embedding := range embeddings {
+		vectorJSON, _ := json.Marshal(embedding.Embedding)
+		if _, err := stmt.ExecContext(ctx, jobID, embedding.Kind, embedding.Key, vectorJSON); err != nil {
+			return fmt.Errorf("failed to store timeline embedding %s/%s: %w", embedding.Kind, embedding.Key, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetTimelineEmbeddings returns a job's stored timeline embeddings keyed by kind, then item key
+// Jobs outside the tenant (or processed before embeddings were stored) yield none
+func (sm *StorageManager) GetTimelineEmbeddings(ctx context.Context, tenantID, jobID string) (map[string]map[string][]float64, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	query := `
+		SELECT kind, item_key, embedding
+		FROM videoagent.timeline_embeddings
+		WHERE job_id = $1 AND ` + tenantJobsClause(2)
+
+	rows, err := sm.db.QueryContext(ctx, query, jobID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timeline embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	embeddings := map[string]map[string][]float64{
+		models.EmbeddingKindFrame:   {},
+		models.EmbeddingKindSegment: {},
+	}
+	for rows.Next() {
+		var kind, key string
+		var vectorJSON []byte
+		if err := rows.Scan(&kind, &key, &vectorJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan timeline embedding: %w", err)
+		}
+		var vector []float64
+		if err := json.Unmarshal(vectorJSON, &vector); err != nil {
+			return nil, fmt.Errorf("failed to decode timeline embedding %s/%s: %w", kind, key, err)
+		}
+		if embeddings[kind] == nil {
+			embeddings[kind] = map[string][]float64{}
+		}
+		embeddings[kind][key] = vector
+	}
+
+	return embeddings, rows.Err()
+}
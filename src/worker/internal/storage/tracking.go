@@ -0,0 +1,249 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// StoreTrackingAnalysis stores tracks, person identities, interactions, spatial events and
+// visual summaries for a job
+// Existing tracking rows for the job are replaced so reprocessing is idempotent
+func (sm *StorageManager) StoreTrackingAnalysis(ctx context.Context, jobID string, analysis *models.TrackingAnalysis) error {
+	if analysis == nil {
+		return nil
+	}
+
+	tx, err := sm.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"tracks", "person_identities", "interaction_events", "interaction_event_log", "spatial_events", "tracking_visuals"} {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM videoagent.%s WHERE job_id = $1", table), jobID); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", table, err)
+		}
+	}
+
+	trackQuery := `
+		INSERT INTO videoagent.tracks (
+			job_id, track_id, class, identity_id, start_time, end_time, start_frame, end_frame,
+			frame_count, mean_confidence, pattern, total_distance, average_speed, trajectory
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	for _, track := range analysis.Tracks {
+		trajectoryJSON, _ := json.Marshal(track.Trajectory)
+
+		_, err := tx.ExecContext(ctx, trackQuery,
+			jobID,
+			track.TrackID,
+			track.Class,
+			track.IdentityID,
+			track.StartTime,
+			track.EndTime,
+			track.StartFrame,
+			track.EndFrame,
+			track.FrameCount,
+			track.MeanConfidence,
+			track.Pattern,
+			track.TotalDistance,
+			track.AverageSpeed,
+			trajectoryJSON,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store track %s: %w", track.TrackID, err)
+		}
+	}
+
+	identityQuery := `
+		INSERT INTO videoagent.person_identities (
+			job_id, identity_id, track_ids, appearances, start_time, end_time, confidence, attributes,
+			gallery_identity_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	for _, identity := range analysis.Identities {
+		trackIDsJSON, _ := json.Marshal(identity.TrackIDs)
+		attributesJSON, _ := json.Marshal(identity.Attributes)
+
+		_, err := tx.ExecContext(ctx, identityQuery,
+			jobID,
+			identity.IdentityID,
+			trackIDsJSON,
+			identity.Appearances,
+			identity.StartTime,
+			identity.EndTime,
+			identity.Confidence,
+			attributesJSON,
+			nullString(identity.GalleryID),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store identity %s: %w", identity.IdentityID, err)
+		}
+	}
+
+	interactionQuery := `
+		INSERT INTO videoagent.interaction_events (
+			job_id, interaction_id, interaction_type, participants, classes, start_time, end_time,
+			duration, start_frame, end_frame, confidence, significance, description
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	for _, interaction := range analysis.Interactions {
+		participantsJSON, _ := json.Marshal(interaction.Participants)
+		classesJSON, _ := json.Marshal(nonNilStrings(interaction.Classes))
+
+		_, err := tx.ExecContext(ctx, interactionQuery,
+			jobID,
+			interaction.InteractionID,
+			interaction.Type,
+			participantsJSON,
+			classesJSON,
+			interaction.StartTime,
+			interaction.EndTime,
+			interaction.Duration,
+			interaction.StartFrame,
+			interaction.EndFrame,
+			interaction.Confidence,
+			interaction.Significance,
+			interaction.Description,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store interaction %s: %w", interaction.InteractionID, err)
+		}
+	}
+
+	eventQuery := `
+		INSERT INTO videoagent.interaction_event_log (
+			job_id, event_id, interaction_id, event_type, interaction_type, participants,
+			timestamp, frame_number, significance, proximity
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	for _, event := range analysis.InteractionEvents {
+		participantsJSON, _ := json.Marshal(event.Participants)
+
+		_, err := tx.ExecContext(ctx, eventQuery,
+			jobID,
+			event.EventID,
+			event.InteractionID,
+			event.EventType,
+			event.InteractionType,
+			participantsJSON,
+			event.Timestamp,
+			event.FrameNumber,
+			event.Significance,
+			event.Proximity,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store interaction event %s: %w", event.EventID, err)
+		}
+	}
+
+	spatialQuery := `
+		INSERT INTO videoagent.spatial_events (
+			job_id, event_type, rule_id, track_id, class, timestamp, frame_number, x, y,
+			direction, dwell_time
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	for _, event := range analysis.SpatialEvents {
+		_, err := tx.ExecContext(ctx, spatialQuery,
+			jobID,
+			event.Type,
+			event.RuleID,
+			event.TrackID,
+			event.Class,
+			event.Timestamp,
+			event.FrameNumber,
+			event.Position.X,
+			event.Position.Y,
+			nullString(event.Direction),
+			event.DwellTime,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store spatial event for %s: %w", event.TrackID, err)
+		}
+	}
+
+	if analysis.Visuals != nil {
+		if err := storeTrackingVisuals(ctx, tx, jobID, analysis.Visuals); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tracking analysis: %w", err)
+	}
+
+	return nil
+}
+
+// Tracking visual kinds stored besides the rendered images
+const (
+	TrackingVisualHeatmapGrid = "heatmap_grid"
+	TrackingVisualTimeline    = "timeline"
+)
+
+// storeTrackingVisuals stores rendered images, heatmap grids and the timeline
+func storeTrackingVisuals(ctx context.Context, tx *sql.Tx, jobID string, visuals *models.TrackingVisuals) error {
+	query := `
+		INSERT INTO videoagent.tracking_visuals (job_id, kind, class, content_type, data)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (job_id, kind, class) DO UPDATE SET
+			content_type = EXCLUDED.content_type,
+			data = EXCLUDED.data
+	`
+
+	for _, img := range visuals.Images {
+		if _, err := tx.ExecContext(ctx, query, jobID, img.Kind, img.Class, img.ContentType, img.Data); err != nil {
+			return fmt.Errorf("failed to store %s image for %s: %w", img.Kind, img.Class, err)
+		}
+	}
+
+	for _, heatmap := range visuals.Heatmaps {
+		gridJSON, err := json.Marshal(heatmap)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s heatmap: %w", heatmap.Class, err)
+		}
+		if _, err := tx.ExecContext(ctx, query, jobID, TrackingVisualHeatmapGrid, heatmap.Class, "application/json", gridJSON); err != nil {
+			return fmt.Errorf("failed to store %s heatmap grid: %w", heatmap.Class, err)
+		}
+	}
+
+	timelineJSON, err := json.Marshal(visuals.Timeline)
+	if err != nil {
+		return fmt.Errorf("failed to marshal track timeline: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, jobID, TrackingVisualTimeline, "all", "application/json", timelineJSON); err != nil {
+		return fmt.Errorf("failed to store track timeline: %w", err)
+	}
+
+	return nil
+}
+
+// GetTrackingVisual returns a stored visual summary and its content type
+// kind is "heatmap", "trajectories", "heatmap_grid" or "timeline"; class is a track class or "all".
+// Returns nil data when the job has no such visual.
+func (sm *StorageManager) GetTrackingVisual(ctx context.Context, jobID, kind, class string) ([]byte, string, error) {
+	var data []byte
+	var contentType string
+
+	err := sm.db.QueryRowContext(ctx, `
+		SELECT data, content_type FROM videoagent.tracking_visuals
+		WHERE job_id = $1 AND kind = $2 AND class = $3
+	`, jobID, kind, class).Scan(&data, &contentType)
+	if err == sql.ErrNoRows {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get tracking visual: %w", err)
+	}
+
+	return data, contentType, nil
+}
@@ -0,0 +1,157 @@
+package summary
+
+import (
+	"context"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// keyMomentObjective asks for bullets the parser below can read back
+const keyMomentObjective = "key moments: the 3-10 most important moments, one per line, " +
+	"each starting with its [HH:MM:SS] timestamp from the sources followed by one short sentence"
+
+// momentPattern matches "[00:01:23] text", "- 01:23 - text" and similar bullet lines
+var momentPattern = regexp.MustCompile(`^\s*(?:[-*•]|\d+[.)])?\s*\[?((?:\d{1,2}:)?\d{1,2}:\d{2})\]?\s*[-–—:]?\s*(.+)$`)
+
+// keyMoments asks for time-coded bullets over the scene summaries (or chapters when
+// those are too long) and falls back to one moment per chapter when none parse
+func (s *Summarizer) keyMoments(ctx context.Context, units []*unit, chapters []models.ChapterSummary, duration float64) []models.KeyMoment {
+	sources := unitLines(units)
+	if size(sources) > s.budget {
+		sources = make([]string, 0, len(chapters))
+		for _, c := range chapters {
+			sources = append(sources, "["+Timecode(c.StartTime)+"] "+c.Summary)
+		}
+	}
+
+	moments := make([]models.KeyMoment, 0)
+	if len(sources) > 0 {
+		text, err := s.reduce(ctx, sources, keyMomentObjective, 0)
+		if err != nil {
+			log.Printf("Warning: key moment synthesis failed: %v", err)
+			// Non-fatal - continue with chapter starts
+		} else {
+			moments = parseMoments(text, duration)
+		}
+	}
+
+	if len(moments) == 0 {
+		for _, c := range chapters {
+			moments = append(moments, models.KeyMoment{Timestamp: c.StartTime, Text: c.Title})
+		}
+	}
+
+	for i := range moments {
+		moments[i].Timecode = Timecode(moments[i].Timestamp)
+		if u := unitAt(units, moments[i].Timestamp); u != nil {
+			moments[i].SceneID = u.sceneID
+		}
+	}
+	return moments
+}
+
+// parseMoments reads time-coded bullet lines, dropping times past the end of the video
+func parseMoments(text string, duration float64) []models.KeyMoment {
+	moments := make([]models.KeyMoment, 0)
+	seen := make(map[int]bool)
+	for _, line := range strings.Split(text, "\n") {
+		match := momentPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		t, ok := parseTimecode(match[1])
+		if !ok || (duration > 0 && t > duration) || seen[int(t)] {
+			continue
+		}
+		seen[int(t)] = true
+		moments = append(moments, models.KeyMoment{Timestamp: t, Text: strings.TrimSpace(match[2])})
+	}
+
+	sort.SliceStable(moments, func(i, j int) bool { return moments[i].Timestamp < moments[j].Timestamp })
+	return moments
+}
+
+// parseTimecode reads HH:MM:SS or MM:SS as seconds
+func parseTimecode(tc string) (float64, bool) {
+	total := 0
+	for _, part := range strings.Split(tc, ":") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, false
+		}
+		total = total*60 + n
+	}
+	return float64(total), true
+}
+
+// storyboard picks one representative frame per scene (its keyframe, else the
+// frame nearest its middle), spread evenly when there are more scenes than panels
+func (s *Summarizer) storyboard(units []*unit, posters []models.PosterFrame) []models.StoryboardFrame {
+	thumbnails := make(map[string]string)
+	for _, p := range posters {
+		if p.SceneID == "" || len(p.Thumbnails) == 0 {
+			continue
+		}
+		smallest := p.Thumbnails[0]
+		for _, t := range p.Thumbnails[1:] {
+			if t.Width < smallest.Width {
+				smallest = t
+			}
+		}
+		thumbnails[p.SceneID] = smallest.Path
+	}
+
+	panels := make([]models.StoryboardFrame, 0)
+	for _, u := range units {
+		frame := representative(u)
+		if frame == nil {
+			continue
+		}
+		caption := firstSentence(u.summary, 120)
+		if caption == "" {
+			caption = firstSentence(frame.Description, 120)
+		}
+		panels = append(panels, models.StoryboardFrame{
+			SceneID:       u.sceneID,
+			FrameID:       frame.FrameID,
+			Timestamp:     frame.Timestamp,
+			Timecode:      Timecode(frame.Timestamp),
+			FilePath:      frame.FilePath,
+			ThumbnailPath: thumbnails[u.sceneID],
+			Caption:       caption,
+		})
+	}
+
+	if len(panels) <= s.maxStoryboard {
+		return panels
+	}
+	spread := make([]models.StoryboardFrame, 0, s.maxStoryboard)
+	for i := 0; i < s.maxStoryboard; i++ {
+		spread = append(spread, panels[i*len(panels)/s.maxStoryboard])
+	}
+	return spread
+}
+
+// representative returns the unit's keyframe, else its frame nearest the middle
+func representative(u *unit) *models.FrameAnalysis {
+	if len(u.frames) == 0 {
+		return nil
+	}
+	middle := (u.start + u.end) / 2
+	best := 0
+	for i := range u.frames {
+		if u.keyFrameID != "" && u.frames[i].FrameID == u.keyFrameID {
+			return &u.frames[i]
+		}
+		if math.Abs(u.frames[i].Timestamp-middle) < math.Abs(u.frames[best].Timestamp-middle) {
+			best = i
+		}
+	}
+	return &u.frames[best]
+}
@@ -0,0 +1,361 @@
+package summary
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// Summarizer defaults
+const (
+	defaultBudget        = 12000 // Characters of sources per synthesis request
+	defaultChapterLength = 300.0 // Seconds
+	defaultStoryboard    = 24    // Frames
+	defaultConcurrency   = 4     // Scene summaries requested at once
+	maxReduceDepth       = 4     // Reduce passes before falling back to concatenation
+)
+
+// Synthesizer condenses sources into text (MageAgentClient satisfies it)
+type Synthesizer interface {
+	Synthesize(ctx context.Context, sources []string, format string, objective string) (string, error)
+}
+
+// Input is the material a video is summarized from
+type Input struct {
+	Metadata       string // One-line technical description
+	Duration       float64
+	Scenes         []models.SceneDetection // Empty summarizes fixed windows instead
+	Frames         []models.FrameAnalysis
+	Transcript     []models.SpeakerSegment
+	TranscriptText string               // Used when there are no timed segments
+	Posters        []models.PosterFrame // Scene posters (optional), used for storyboard thumbnails
+}
+
+// Summarizer builds hierarchical summaries: scenes are summarized (map), then
+// chapters from their scenes and the video from its chapters (reduce)
+// Sources longer than the budget are split and reduced in several passes so no
+// request overflows the synthesis context. A Summarizer runs one Summarize at a time.
+type Summarizer struct {
+	synth         Synthesizer
+	budget        int
+	chapterLength float64
+	maxStoryboard int
+	concurrency   int
+	calls         int32
+}
+
+// NewSummarizer creates a summarizer with the default budget and chapter length
+func NewSummarizer(synth Synthesizer) *Summarizer {
+	return &Summarizer{
+		synth:         synth,
+		budget:        defaultBudget,
+		chapterLength: defaultChapterLength,
+		maxStoryboard: defaultStoryboard,
+		concurrency:   defaultConcurrency,
+	}
+}
+
+// SetChapterLength sets the target chapter length in seconds
+func (s *Summarizer) SetChapterLength(seconds float64) {
+	if seconds > 0 {
+		s.chapterLength = seconds
+	}
+}
+
+// SetBudget sets the maximum characters of sources per synthesis request
+func (s *Summarizer) SetBudget(chars int) {
+	if chars > 0 {
+		s.budget = chars
+	}
+}
+
+// Summarize produces the scene, chapter and video summaries, key moments and storyboard
+func (s *Summarizer) Summarize(ctx context.Context, in Input) (*models.VideoSummary, error) {
+	atomic.StoreInt32(&s.calls, 0)
+	units := buildUnits(in)
+
+	// Step 1: Summarize each scene (map)
+	s.summarizeUnits(ctx, units)
+
+	result := &models.VideoSummary{
+		Scenes:     make([]models.SceneSummary, 0, len(units)),
+		Chapters:   make([]models.ChapterSummary, 0),
+		KeyMoments: make([]models.KeyMoment, 0),
+	}
+	for _, u := range units {
+		if u.summary == "" {
+			continue
+		}
+		result.Scenes = append(result.Scenes, models.SceneSummary{
+			SceneID:   u.sceneID,
+			SceneType: u.sceneType,
+			StartTime: u.start,
+			EndTime:   u.end,
+			Summary:   u.summary,
+		})
+	}
+
+	// Step 2: Summarize chapters from their scenes (reduce)
+	for _, chapter := range groupChapters(units, s.chapterLength) {
+		lines := unitLines(chapter)
+		if len(lines) == 0 {
+			continue
+		}
+
+		text := chapter[0].summary
+		if len(lines) > 1 {
+			var err error
+			text, err = s.reduce(ctx, lines, "chapter summary: what happens in this part of the video, in 2-3 sentences", 0)
+			if err != nil {
+				log.Printf("Warning: chapter summary failed: %v", err)
+				// Non-fatal - continue with the chapter's scene summaries
+				text = strings.Join(summaries(chapter), " ")
+			}
+		}
+
+		cs := models.ChapterSummary{
+			Index:     len(result.Chapters) + 1,
+			Title:     firstSentence(text, 60),
+			StartTime: chapter[0].start,
+			EndTime:   chapter[len(chapter)-1].end,
+			Summary:   text,
+		}
+		for _, u := range chapter {
+			if u.sceneID != "" {
+				cs.SceneIDs = append(cs.SceneIDs, u.sceneID)
+			}
+		}
+		result.Chapters = append(result.Chapters, cs)
+	}
+
+	// Step 3: Summarize the whole video from its chapters (reduce)
+	sources := make([]string, 0)
+	if in.Metadata != "" {
+		sources = append(sources, in.Metadata)
+	}
+	for _, c := range result.Chapters {
+		sources = append(sources, fmt.Sprintf("Chapter %d [%s-%s]: %s", c.Index, Timecode(c.StartTime), Timecode(c.EndTime), c.Summary))
+	}
+	if len(in.Transcript) == 0 && strings.TrimSpace(in.TranscriptText) != "" {
+		// Untimed transcripts can't be placed in scenes; condense them on their own
+		transcript, err := s.reduce(ctx, chunk("Transcription: "+in.TranscriptText, s.budget), "summary of a video's spoken content", 0)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, "Spoken content: "+transcript)
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("nothing to summarize")
+	}
+	synopsis, err := s.reduce(ctx, sources, "video summary", 0)
+	if err != nil {
+		return nil, err
+	}
+	result.Synopsis = synopsis
+
+	// Step 4: Pick time-coded key moments
+	result.KeyMoments = s.keyMoments(ctx, units, result.Chapters, in.Duration)
+
+	// Step 5: Lay out the storyboard
+	result.Storyboard = s.storyboard(units, in.Posters)
+
+	result.SynthesisCalls = int(atomic.LoadInt32(&s.calls))
+	return result, nil
+}
+
+// summarizeUnits summarizes every unit with material, a few at a time
+// A unit with only a scene description (or a single frame description) keeps it as-is.
+func (s *Summarizer) summarizeUnits(ctx context.Context, units []*unit) {
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, s.concurrency)
+
+	for _, u := range units {
+		descriptions := frameLines(u.frames)
+		if len(u.transcript) == 0 && len(descriptions) <= 1 {
+			u.summary = u.desc
+			if u.summary == "" && len(u.frames) > 0 {
+				u.summary = strings.TrimSpace(u.frames[0].Description)
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func(u *unit, descriptions []string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			sources := []string{u.label()}
+			if u.desc != "" {
+				sources = append(sources, "Scene description: "+u.desc)
+			}
+			sources = append(sources, descriptions...)
+
+			// Long transcripts are condensed in chunks first (map), then kept whole when they fit
+			if transcript := strings.Join(u.transcript, "\n"); transcript != "" {
+				if len(transcript) > s.budget/2 {
+					condensed, err := s.reduce(ctx, chunk(transcript, s.budget/2), "summary of a transcript excerpt, keeping who said what", 0)
+					if err != nil {
+						log.Printf("Warning: transcript summary failed for %s: %v", u.label(), err)
+						// Non-fatal - continue with the transcript's start
+						condensed = truncate(transcript, s.budget/4)
+					}
+					transcript = condensed
+				}
+				sources = append(sources, "Transcript:\n"+transcript)
+			}
+
+			summary, err := s.reduce(ctx, sources, "summary of one scene of a video in 1-2 sentences", 0)
+			if err != nil {
+				log.Printf("Warning: scene summary failed for %s: %v", u.label(), err)
+				// Non-fatal - continue with the scene description
+				summary = u.desc
+			}
+			u.summary = strings.TrimSpace(summary)
+		}(u, descriptions)
+	}
+
+	wg.Wait()
+}
+
+// reduce synthesizes sources into one text; sources over the budget are
+// synthesized in batches and the batch results reduced again
+func (s *Summarizer) reduce(ctx context.Context, sources []string, objective string, depth int) (string, error) {
+	if size(sources) <= s.budget || len(sources) == 1 || depth >= maxReduceDepth {
+		return s.synthesize(ctx, sources, objective)
+	}
+
+	partials := make([]string, 0)
+	for _, batch := range batches(sources, s.budget) {
+		partial, err := s.synthesize(ctx, batch, objective)
+		if err != nil {
+			return "", err
+		}
+		partials = append(partials, partial)
+	}
+	return s.reduce(ctx, partials, objective, depth+1)
+}
+
+func (s *Summarizer) synthesize(ctx context.Context, sources []string, objective string) (string, error) {
+	atomic.AddInt32(&s.calls, 1)
+	text, err := s.synth.Synthesize(ctx, trim(sources, s.budget), "summary", objective)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(text), nil
+}
+
+// unitLines lists the summarized units as time-coded lines
+func unitLines(units []*unit) []string {
+	lines := make([]string, 0, len(units))
+	for _, u := range units {
+		if u.summary != "" {
+			lines = append(lines, fmt.Sprintf("[%s] %s", Timecode(u.start), u.summary))
+		}
+	}
+	return lines
+}
+
+func summaries(units []*unit) []string {
+	texts := make([]string, 0, len(units))
+	for _, u := range units {
+		if u.summary != "" {
+			texts = append(texts, u.summary)
+		}
+	}
+	return texts
+}
+
+// frameLines lists the frame descriptions as time-coded lines
+func frameLines(frames []models.FrameAnalysis) []string {
+	lines := make([]string, 0, len(frames))
+	for _, f := range frames {
+		if desc := strings.TrimSpace(f.Description); desc != "" {
+			lines = append(lines, fmt.Sprintf("[%s] %s", Timecode(f.Timestamp), desc))
+		}
+	}
+	return lines
+}
+
+// chunk splits text into pieces of at most max characters at line (or word) breaks
+func chunk(text string, max int) []string {
+	chunks := make([]string, 0)
+	var current strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		for len(line) > max {
+			cut := strings.LastIndex(line[:max], " ")
+			if cut <= 0 {
+				cut = max
+			}
+			if current.Len() > 0 {
+				chunks = append(chunks, current.String())
+				current.Reset()
+			}
+			chunks = append(chunks, line[:cut])
+			line = line[cut:]
+		}
+		if current.Len()+len(line) > max {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	if strings.TrimSpace(current.String()) != "" {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// batches groups sources so each batch stays within max characters
+func batches(sources []string, max int) [][]string {
+	groups := make([][]string, 0)
+	current := make([]string, 0)
+	total := 0
+	for _, source := range sources {
+		if len(current) > 0 && total+len(source) > max {
+			groups = append(groups, current)
+			current, total = make([]string, 0), 0
+		}
+		current = append(current, source)
+		total += len(source)
+	}
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+	return groups
+}
+
+// trim shortens each source evenly when together they exceed max
+// (a single oversized source, or the last resort at the reduce depth limit)
+func trim(sources []string, max int) []string {
+	if size(sources) <= max {
+		return sources
+	}
+	per := max / len(sources)
+	trimmed := make([]string, len(sources))
+	for i, source := range sources {
+		trimmed[i] = truncate(source, per)
+	}
+	return trimmed
+}
+
+// truncate cuts text to at most max characters
+func truncate(text string, max int) string {
+	if runes := []rune(text); len(runes) > max {
+		return string(runes[:max])
+	}
+	return text
+}
+
+func size(sources []string) int {
+	total := 0
+	for _, source := range sources {
+		total += len(source)
+	}
+	return total
+}
@@ -0,0 +1,146 @@
+package summary
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// windowLength splits videos without detected scenes into fixed windows (seconds)
+const windowLength = 60.0
+
+// unit is the smallest summarized span: a scene, or a fixed window without scenes
+type unit struct {
+	index      int
+	sceneID    string
+	sceneType  string
+	start      float64
+	end        float64
+	keyFrameID string
+	desc       string
+	frames     []models.FrameAnalysis
+	transcript []string // Speaker lines in order
+	summary    string
+}
+
+// label names the unit for synthesis sources and captions
+func (u *unit) label() string {
+	name := fmt.Sprintf("Scene %d", u.index+1)
+	if u.sceneType != "" {
+		name += " (" + u.sceneType + ")"
+	}
+	return fmt.Sprintf("%s, %s-%s", name, Timecode(u.start), Timecode(u.end))
+}
+
+// buildUnits assigns frames and transcript segments to scenes (or windows) by time
+func buildUnits(in Input) []*unit {
+	units := make([]*unit, 0)
+	if len(in.Scenes) > 0 {
+		scenes := make([]models.SceneDetection, len(in.Scenes))
+		copy(scenes, in.Scenes)
+		sort.SliceStable(scenes, func(i, j int) bool { return scenes[i].StartTime < scenes[j].StartTime })
+		for i, s := range scenes {
+			end := math.Max(s.EndTime, in.Duration)
+			if i+1 < len(scenes) {
+				end = scenes[i+1].StartTime
+			}
+			units = append(units, &unit{
+				sceneID:    s.SceneID,
+				sceneType:  s.SceneType,
+				start:      s.StartTime,
+				end:        end,
+				keyFrameID: s.KeyFrameID,
+				desc:       strings.TrimSpace(s.Description),
+			})
+		}
+	} else {
+		duration := in.Duration
+		for _, f := range in.Frames {
+			duration = math.Max(duration, f.Timestamp)
+		}
+		for start := 0.0; start < duration || len(units) == 0; start += windowLength {
+			units = append(units, &unit{start: start, end: math.Min(start+windowLength, math.Max(duration, start+1))})
+		}
+	}
+	for i, u := range units {
+		u.index = i
+	}
+
+	for _, f := range in.Frames {
+		if u := unitAt(units, f.Timestamp); u != nil {
+			u.frames = append(u.frames, f)
+		}
+	}
+	for _, seg := range in.Transcript {
+		text := strings.TrimSpace(seg.Text)
+		if text == "" {
+			continue
+		}
+		if u := unitAt(units, (seg.StartTime+seg.EndTime)/2); u != nil {
+			line := fmt.Sprintf("[%s] %s", Timecode(seg.StartTime), text)
+			if seg.SpeakerID != "" {
+				line = fmt.Sprintf("[%s] %s: %s", Timecode(seg.StartTime), seg.SpeakerID, text)
+			}
+			u.transcript = append(u.transcript, line)
+		}
+	}
+
+	return units
+}
+
+// unitAt returns the unit containing t (the last starting at or before it)
+func unitAt(units []*unit, t float64) *unit {
+	var found *unit
+	for _, u := range units {
+		if u.start <= t+1e-6 {
+			found = u
+		}
+	}
+	return found
+}
+
+// groupChapters cuts consecutive units into chapters of roughly the target length
+func groupChapters(units []*unit, length float64) [][]*unit {
+	chapters := make([][]*unit, 0)
+	current := make([]*unit, 0)
+	for _, u := range units {
+		current = append(current, u)
+		if u.end-current[0].start >= length {
+			chapters = append(chapters, current)
+			current = make([]*unit, 0)
+		}
+	}
+	if len(current) > 0 {
+		// A short tail joins the previous chapter rather than standing alone
+		if len(chapters) > 0 && current[len(current)-1].end-current[0].start < length/3 {
+			chapters[len(chapters)-1] = append(chapters[len(chapters)-1], current...)
+		} else {
+			chapters = append(chapters, current)
+		}
+	}
+	return chapters
+}
+
+// Timecode formats seconds as HH:MM:SS
+func Timecode(seconds float64) string {
+	total := int(math.Max(0, seconds))
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, total/60%60, total%60)
+}
+
+// firstSentence returns the first sentence of text, cut to max characters
+func firstSentence(text string, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	for i, r := range text {
+		if (r == '.' || r == '!' || r == '?') && (i+1 == len(text) || text[i+1] == ' ') && i > 0 {
+			text = text[:i]
+			break
+		}
+	}
+	if runes := []rune(text); len(runes) > max {
+		text = strings.TrimSpace(string(runes[:max-1])) + "…"
+	}
+	return text
+}
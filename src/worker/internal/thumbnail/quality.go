@@ -0,0 +1,215 @@
+package thumbnail
+
+import (
+	"math"
+	"strings"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/imagestats"
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// Blank-frame thresholds: near-black frames and frames with almost no luma variation
+// (fades, slates, solid colour) never make a poster
+const (
+	blankLuminance = 0.06
+	blankContrast  = 0.025
+)
+
+// Weights sets how much each component contributes to the quality score
+type Weights struct {
+	Sharpness   float64 `json:"sharpness"`
+	Exposure    float64 `json:"exposure"`
+	Subject     float64 `json:"subject"`
+	Composition float64 `json:"composition"`
+}
+
+// DefaultWeights favours sharp, well exposed frames with a clear subject
+func DefaultWeights() Weights {
+	return Weights{
+		Sharpness:   0.35,
+		Exposure:    0.25,
+		Subject:     0.25,
+		Composition: 0.15,
+	}
+}
+
+// Scorer rates frames as poster/keyframe candidates from measured image
+// statistics and the objects already detected in the frame
+type Scorer struct {
+	weights Weights
+}
+
+// NewScorer creates a scorer with the default weights
+func NewScorer() *Scorer {
+	return &Scorer{
+		weights: DefaultWeights(),
+	}
+}
+
+// SetWeights sets the component weights (they are normalized to sum to 1)
+func (s *Scorer) SetWeights(weights Weights) {
+	s.weights = weights
+}
+
+// Score rates one frame
+func (s *Scorer) Score(frame models.FrameAnalysis, stats *imagestats.Stats) models.FrameQuality {
+	quality := models.FrameQuality{
+		FrameID:   frame.FrameID,
+		Timestamp: frame.Timestamp,
+	}
+	if stats == nil {
+		return quality
+	}
+
+	// Step 1: Reject blank frames outright
+	if stats.Luminance < blankLuminance || stats.RMSContrast < blankContrast {
+		quality.Blank = true
+		return quality
+	}
+
+	// Step 2: Score the components
+	quality.Sharpness = stats.Sharpness
+	quality.Exposure = exposureScore(stats)
+	subject, found := bestSubject(frame.Objects)
+	if found {
+		quality.Subject = subjectScore(subject)
+		quality.Composition = compositionScore(subject.BoundingBox)
+	} else {
+		// Nothing detected: no subject credit, and placement can't be judged either way
+		quality.Composition = 0.5
+	}
+
+	// Step 3: Combine
+	total := s.weights.Sharpness + s.weights.Exposure + s.weights.Subject + s.weights.Composition
+	if total <= 0 {
+		return quality
+	}
+	quality.Score = (s.weights.Sharpness*quality.Sharpness +
+		s.weights.Exposure*quality.Exposure +
+		s.weights.Subject*quality.Subject +
+		s.weights.Composition*quality.Composition) / total
+
+	return quality
+}
+
+// Best returns the index of the highest-scoring non-blank frame in qualities[start:end+1],
+// or -1 when every frame in the range is blank
+func Best(qualities []models.FrameQuality, start, end int) int {
+	if start < 0 {
+		start = 0
+	}
+	if end >= len(qualities) {
+		end = len(qualities) - 1
+	}
+
+	best := -1
+	for i := start; i <= end; i++ {
+		if qualities[i].Blank {
+			continue
+		}
+		if best < 0 || qualities[i].Score > qualities[best].Score {
+			best = i
+		}
+	}
+	return best
+}
+
+// exposureScore rewards mid-tone brightness and penalizes clipped shadows and highlights
+func exposureScore(stats *imagestats.Stats) float64 {
+	// Brightness: 1 at 0.45 luma, falling to 0 at black or white
+	deviation := math.Abs(stats.Luminance-0.45) / 0.55
+	brightness := 1 - deviation*deviation
+
+	// Clipping: share of pixels in the darkest and brightest value bins
+	clipped := 0.0
+	if n := len(stats.ValueHistogram); n > 0 {
+		clipped = stats.ValueHistogram[0] + stats.ValueHistogram[n-1]
+	}
+
+	return clamp01(brightness * (1 - math.Min(1, 2*clipped)))
+}
+
+// Subject labels ranked above generic objects: people and faces make the best posters
+var (
+	personLabels = map[string]bool{"person": true, "people": true, "face": true, "man": true, "woman": true, "child": true, "boy": true, "girl": true}
+	animalLabels = map[string]bool{"animal": true, "dog": true, "cat": true, "horse": true, "bird": true}
+)
+
+// subjectWeight ranks a detection label by its words
+func subjectWeight(label string) float64 {
+	weight := 0.5
+	for _, word := range strings.Fields(strings.ToLower(label)) {
+		word = strings.TrimSuffix(word, "s")
+		switch {
+		case personLabels[word]:
+			return 1.0
+		case animalLabels[word]:
+			weight = 0.8
+		}
+	}
+	return weight
+}
+
+// bestSubject picks the detection with the highest subject score
+func bestSubject(objects []models.ObjectDetection) (models.ObjectDetection, bool) {
+	var best models.ObjectDetection
+	bestScore := -1.0
+	for _, obj := range objects {
+		if obj.BoundingBox.Width <= 0 || obj.BoundingBox.Height <= 0 {
+			continue
+		}
+		if score := subjectScore(obj); score > bestScore {
+			best, bestScore = obj, score
+		}
+	}
+	return best, bestScore >= 0
+}
+
+// subjectScore rates a detection by label, confidence and size
+// Subjects filling 10-60% of the frame score fully; tiny or frame-filling ones less.
+func subjectScore(obj models.ObjectDetection) float64 {
+	area := obj.BoundingBox.Width * obj.BoundingBox.Height
+	size := 1.0
+	switch {
+	case area < 0.1:
+		size = area / 0.1
+	case area > 0.6:
+		size = math.Max(0.3, 1-(area-0.6)/0.4)
+	}
+
+	confidence := obj.Confidence
+	if confidence <= 0 {
+		confidence = 0.5 // Detections without a confidence
+	}
+
+	return clamp01(subjectWeight(obj.Label) * confidence * size)
+}
+
+// compositionScore rates how close the subject's centre is to a rule-of-thirds
+// intersection or the frame centre
+func compositionScore(box models.BoundingBox) float64 {
+	cx := box.X + box.Width/2
+	cy := box.Y + box.Height/2
+
+	points := [][2]float64{
+		{1.0 / 3, 1.0 / 3}, {2.0 / 3, 1.0 / 3},
+		{1.0 / 3, 2.0 / 3}, {2.0 / 3, 2.0 / 3},
+		{0.5, 0.5},
+	}
+	nearest := math.MaxFloat64
+	for _, p := range points {
+		nearest = math.Min(nearest, math.Hypot(cx-p[0], cy-p[1]))
+	}
+
+	// Subjects cut by the frame edge lose half their credit
+	score := 1 - math.Min(1, nearest/0.3)
+	if box.X <= 0.01 || box.Y <= 0.01 || box.X+box.Width >= 0.99 || box.Y+box.Height >= 0.99 {
+		score *= 0.5
+	}
+	return clamp01(score)
+}
+
+// clamp01 limits a value to 0-1
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
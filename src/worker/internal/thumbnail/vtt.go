@@ -0,0 +1,66 @@
+package thumbnail
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// SpriteGrid describes how scrubbing thumbnails are laid out on sprite sheets
+// Tile i of the video sits on sheet i / (Columns*Rows), filled row by row.
+type SpriteGrid struct {
+	Interval   float64 // Seconds per tile
+	TileWidth  int
+	TileHeight int
+	Columns    int
+	Rows       int
+}
+
+// TilesPerSheet returns how many tiles fit on one sheet
+func (g SpriteGrid) TilesPerSheet() int {
+	return g.Columns * g.Rows
+}
+
+// TileCount returns how many tiles cover duration seconds
+func (g SpriteGrid) TileCount(duration float64) int {
+	if g.Interval <= 0 || duration <= 0 {
+		return 0
+	}
+	return int(math.Ceil(duration / g.Interval))
+}
+
+// BuildSpriteVTT builds a WebVTT thumbnail track: one cue per tile pointing at its
+// region of a sheet with a media fragment (sheet.jpg#xywh=x,y,w,h)
+// sheetNames are the sheet URLs as the player will resolve them (usually relative to the VTT file).
+func BuildSpriteVTT(grid SpriteGrid, sheetNames []string, duration float64) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+
+	perSheet := grid.TilesPerSheet()
+	if perSheet <= 0 {
+		return b.String()
+	}
+
+	for i := 0; i < grid.TileCount(duration); i++ {
+		sheet := i / perSheet
+		if sheet >= len(sheetNames) {
+			break
+		}
+		tile := i % perSheet
+		x := (tile % grid.Columns) * grid.TileWidth
+		y := (tile / grid.Columns) * grid.TileHeight
+
+		start := float64(i) * grid.Interval
+		end := math.Min(start+grid.Interval, duration)
+		fmt.Fprintf(&b, "%s --> %s\n%s#xywh=%d,%d,%d,%d\n\n",
+			vttTimestamp(start), vttTimestamp(end), sheetNames[sheet], x, y, grid.TileWidth, grid.TileHeight)
+	}
+
+	return b.String()
+}
+
+// vttTimestamp formats seconds as HH:MM:SS.mmm
+func vttTimestamp(seconds float64) string {
+	ms := int64(math.Round(seconds * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
@@ -0,0 +1,300 @@
+package tracking
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png" // Register PNG decoding for frames
+	"io"
+	"math"
+	"net/http"
+	"time"
+)
+
+// AppearanceEmbedder turns a person crop into an appearance embedding for re-ID
+type AppearanceEmbedder interface {
+	Embed(ctx context.Context, crop image.Image) ([]float64, error)
+	Name() string
+}
+
+// Histogram embedding layout: upper and lower body regions, each with
+// 12 hue x 4 saturation chromatic bins plus 16 brightness bins for achromatic pixels
+const (
+	histogramHueBins        = 12
+	histogramSatBins        = 4
+	histogramValueBins      = 16
+	histogramRegionBins     = histogramHueBins*histogramSatBins + histogramValueBins
+	histogramMinSaturation  = 0.15 // Below this a pixel counts as grey
+	histogramMaxSamplesAxis = 96   // Pixel sampling cap per axis
+)
+
+// HistogramEmbedder computes HSV colour histograms in Go (offline fallback, no model needed)
+// Clothing colour is the dominant cue, so identities in similar outfits will collide.
+type HistogramEmbedder struct{}
+
+// NewHistogramEmbedder creates a colour-histogram embedder
+func NewHistogramEmbedder() *HistogramEmbedder {
+	return &HistogramEmbedder{}
+}
+
+// Name returns the embedder name
+func (e *HistogramEmbedder) Name() string {
+	return "hsv_histogram"
+}
+
+// Dimension returns the embedding size (matches similarity.PersonEmbeddingDimension)
+func (e *HistogramEmbedder) Dimension() int {
+	return 2 * histogramRegionBins
+}
+
+// Embed computes an L2-normalised histogram of the crop's upper and lower halves
+func (e *HistogramEmbedder) Embed(ctx context.Context, crop image.Image) ([]float64, error) {
+	bounds := crop.Bounds()
+	if bounds.Dx() <= 0 || bounds.Dy() <= 0 {
+		return nil, fmt.Errorf("empty crop")
+	}
+
+	stepX := maxInt(1, bounds.Dx()/histogramMaxSamplesAxis)
+	stepY := maxInt(1, bounds.Dy()/histogramMaxSamplesAxis)
+	midY := bounds.Min.Y + bounds.Dy()/2
+
+	embedding := make([]float64, e.Dimension())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		offset := 0
+		if y >= midY {
+			offset = histogramRegionBins
+		}
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			r, g, b, _ := crop.At(x, y).RGBA()
+			h, s, v := rgbToHSV(float64(r)/65535, float64(g)/65535, float64(b)/65535)
+			embedding[offset+histogramBin(h, s, v)]++
+		}
+	}
+
+	// Each region sums to 1, then the whole vector has unit length (cosine-ready)
+	for region := 0; region < 2; region++ {
+		normalizeL1(embedding[region*histogramRegionBins : (region+1)*histogramRegionBins])
+	}
+	normalizeL2(embedding)
+
+	return embedding, nil
+}
+
+// histogramBin maps an HSV colour to its bin within a region
+func histogramBin(h, s, v float64) int {
+	if s < histogramMinSaturation || v < 0.1 {
+		return histogramHueBins*histogramSatBins + minInt(int(v*histogramValueBins), histogramValueBins-1)
+	}
+	hueBin := minInt(int(h/360*histogramHueBins), histogramHueBins-1)
+	satBin := minInt(int((s-histogramMinSaturation)/(1-histogramMinSaturation)*histogramSatBins), histogramSatBins-1)
+	return hueBin*histogramSatBins + satBin
+}
+
+// rgbToHSV converts RGB (0-1) to hue (degrees), saturation and value (0-1)
+func rgbToHSV(r, g, b float64) (float64, float64, float64) {
+	maxC := math.Max(r, math.Max(g, b))
+	minC := math.Min(r, math.Min(g, b))
+	delta := maxC - minC
+
+	var h float64
+	switch {
+	case delta == 0:
+		h = 0
+	case maxC == r:
+		h = 60 * math.Mod((g-b)/delta, 6)
+	case maxC == g:
+		h = 60 * ((b-r)/delta + 2)
+	default:
+		h = 60 * ((r-g)/delta + 4)
+	}
+	if h < 0 {
+		h += 360
+	}
+
+	s := 0.0
+	if maxC > 0 {
+		s = delta / maxC
+	}
+	return h, s, maxC
+}
+
+// HTTPAppearanceEmbedder calls a dedicated re-ID embedding server (e.g. OSNet behind Triton)
+//
+// Request (POST, application/json): {"image": "<base64 JPEG crop>"}
+// Response: {"embedding": [...]}
+type HTTPAppearanceEmbedder struct {
+	endpoint   string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewHTTPAppearanceEmbedder creates an embedder for the given endpoint
+func NewHTTPAppearanceEmbedder(endpoint, apiKey string, timeout time.Duration) *HTTPAppearanceEmbedder {
+	return &HTTPAppearanceEmbedder{
+		endpoint: endpoint,
+		apiKey:   apiKey,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// Name returns the embedder name
+func (e *HTTPAppearanceEmbedder) Name() string {
+	return "http"
+}
+
+// Embed posts the crop to the embedding server and returns the L2-normalised embedding
+func (e *HTTPAppearanceEmbedder) Embed(ctx context.Context, crop image.Image) ([]float64, error) {
+	encoded, err := encodeCrop(crop)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(map[string]string{"image": encoded})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal embedding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embedding server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Embedding []float64 `json:"embedding"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse embedding response: %w", err)
+	}
+	if len(result.Embedding) == 0 {
+		return nil, fmt.Errorf("embedding server returned an empty embedding")
+	}
+
+	normalizeL2(result.Embedding)
+	return result.Embedding, nil
+}
+
+// cropFrame decodes a base64 frame and cuts out a normalized bounding box
+// The box is padded slightly so the whole person is kept despite loose detections
+func cropFrame(frameData string, bbox BoundingBox) (image.Image, error) {
+	data, err := base64.StdEncoding.DecodeString(frameData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode frame: %w", err)
+	}
+
+	frame, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode frame image: %w", err)
+	}
+
+	const padding = 0.05
+	box := clampBox(BoundingBox{
+		X:      bbox.X - bbox.Width*padding,
+		Y:      bbox.Y - bbox.Height*padding,
+		Width:  bbox.Width * (1 + 2*padding),
+		Height: bbox.Height * (1 + 2*padding),
+	})
+
+	bounds := frame.Bounds()
+	rect := image.Rect(
+		bounds.Min.X+int(box.X*float64(bounds.Dx())),
+		bounds.Min.Y+int(box.Y*float64(bounds.Dy())),
+		bounds.Min.X+int(math.Ceil((box.X+box.Width)*float64(bounds.Dx()))),
+		bounds.Min.Y+int(math.Ceil((box.Y+box.Height)*float64(bounds.Dy()))),
+	).Intersect(bounds)
+	if rect.Empty() {
+		return nil, fmt.Errorf("bounding box is outside the frame")
+	}
+
+	if sub, ok := frame.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(rect), nil
+	}
+
+	// Fallback for image types without SubImage: copy the region
+	cropped := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			cropped.Set(x-rect.Min.X, y-rect.Min.Y, frame.At(x, y))
+		}
+	}
+	return cropped, nil
+}
+
+// encodeCrop encodes a crop as base64 JPEG
+func encodeCrop(crop image.Image) (string, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, crop, &jpeg.Options{Quality: 90}); err != nil {
+		return "", fmt.Errorf("failed to encode crop: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// normalizeL1 scales values to sum to 1 (no-op for all zeros)
+func normalizeL1(values []float64) {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	if sum == 0 {
+		return
+	}
+	for i := range values {
+		values[i] /= sum
+	}
+}
+
+// normalizeL2 scales values to unit length (no-op for all zeros)
+func normalizeL2(values []float64) {
+	norm := 0.0
+	for _, v := range values {
+		norm += v * v
+	}
+	if norm == 0 {
+		return
+	}
+	norm = math.Sqrt(norm)
+	for i := range values {
+		values[i] /= norm
+	}
+}
+
+// minInt returns the smaller of two ints
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// maxInt returns the larger of two ints
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,108 @@
+package tracking
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// DetectionFrame is a frame submitted to a Detector
+type DetectionFrame struct {
+	Number int       // Sequential frame number within the tracker (1-based)
+	Time   time.Time // Frame time (video time for offline processing)
+	Data   string    // Base64-encoded image (may be empty for replay)
+}
+
+// Detector produces object detections for a frame
+// Implementations return every detection they find; the tracker applies its own
+// confidence thresholds so ByteTrack can use low-score detections.
+type Detector interface {
+	Detect(ctx context.Context, frame DetectionFrame) ([]Detection, error)
+	Name() string
+}
+
+// labelClasses maps common detector labels (COCO/VOC) to tracking classes
+var labelClasses = map[string]ObjectClass{
+	"person":     ClassPerson,
+	"pedestrian": ClassPerson,
+	"people":     ClassPerson,
+	"car":        ClassVehicle,
+	"truck":      ClassVehicle,
+	"bus":        ClassVehicle,
+	"motorcycle": ClassVehicle,
+	"motorbike":  ClassVehicle,
+	"bicycle":    ClassVehicle,
+	"train":      ClassVehicle,
+	"boat":       ClassVehicle,
+	"airplane":   ClassVehicle,
+	"aeroplane":  ClassVehicle,
+	"vehicle":    ClassVehicle,
+	"bird":       ClassAnimal,
+	"cat":        ClassAnimal,
+	"dog":        ClassAnimal,
+	"horse":      ClassAnimal,
+	"sheep":      ClassAnimal,
+	"cow":        ClassAnimal,
+	"elephant":   ClassAnimal,
+	"bear":       ClassAnimal,
+	"zebra":      ClassAnimal,
+	"giraffe":    ClassAnimal,
+	"animal":     ClassAnimal,
+	"object":     ClassObject,
+}
+
+// classForLabel maps a detector label to a tracking class
+// Unrecognised labels are generic objects; an empty label is unknown
+func classForLabel(label string) ObjectClass {
+	label = strings.ToLower(strings.TrimSpace(label))
+	if label == "" {
+		return ClassUnknown
+	}
+	if class, exists := labelClasses[label]; exists {
+		return class
+	}
+	return ClassObject
+}
+
+// sanitizeDetections drops malformed boxes, clamps boxes to the frame and normalises classes
+func sanitizeDetections(detections []Detection) []Detection {
+	valid := make([]Detection, 0, len(detections))
+	for _, det := range detections {
+		box := clampBox(det.BoundingBox)
+		if box.Width <= 0 || box.Height <= 0 {
+			continue
+		}
+		det.BoundingBox = box
+
+		switch det.Class {
+		case ClassPerson, ClassVehicle, ClassAnimal, ClassObject:
+		default:
+			det.Class = ClassUnknown
+		}
+		if det.Attributes == nil {
+			det.Attributes = make(map[string]interface{})
+		}
+		valid = append(valid, det)
+	}
+	return valid
+}
+
+// clampBox restricts a normalized box to the unit square
+func clampBox(box BoundingBox) BoundingBox {
+	x1 := clampUnit(box.X)
+	y1 := clampUnit(box.Y)
+	x2 := clampUnit(box.X + box.Width)
+	y2 := clampUnit(box.Y + box.Height)
+	return BoundingBox{X: x1, Y: y1, Width: x2 - x1, Height: y2 - y1}
+}
+
+// clampUnit restricts a value to [0, 1]
+func clampUnit(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
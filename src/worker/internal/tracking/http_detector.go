@@ -0,0 +1,152 @@
+package tracking
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPDetector calls a dedicated detection server (e.g. a Triton or ONNX Runtime wrapper)
+//
+// Request (POST, application/json):
+//
+//	{"image": "<base64>", "frame": 12, "confidenceThreshold": 0.1}
+//
+// Response:
+//
+//	{"width": 1920, "height": 1080, "detections": [
+//	  {"label": "car", "score": 0.91, "bbox": [x1, y1, x2, y2], "features": [...]}
+//	]}
+//
+// Boxes are corner coordinates in pixels when width/height are given, otherwise normalized 0-1.
+// Labels are mapped to tracking classes (COCO names are recognised); features are optional
+// appearance embeddings used by DeepSORT.
+type HTTPDetector struct {
+	endpoint            string
+	apiKey              string
+	confidenceThreshold float64
+	httpClient          *http.Client
+}
+
+// httpDetectionRequest is the detection server request body
+type httpDetectionRequest struct {
+	Image               string  `json:"image"`
+	Frame               int     `json:"frame"`
+	ConfidenceThreshold float64 `json:"confidenceThreshold"`
+}
+
+// httpDetectionResponse is the detection server response body
+type httpDetectionResponse struct {
+	Width      float64             `json:"width"`
+	Height     float64             `json:"height"`
+	Detections []httpDetectionItem `json:"detections"`
+}
+
+// httpDetectionItem is a single detection returned by the server
+type httpDetectionItem struct {
+	Label    string                 `json:"label"`
+	Score    float64                `json:"score"`
+	BBox     []float64              `json:"bbox"`
+	Features []float64              `json:"features"`
+	Extra    map[string]interface{} `json:"attributes"`
+}
+
+// NewHTTPDetector creates a detector for the given endpoint
+// The confidence threshold is passed to the server so it can skip NMS work on weak boxes;
+// it should not exceed the tracker's low-confidence threshold.
+func NewHTTPDetector(endpoint, apiKey string, timeout time.Duration) *HTTPDetector {
+	return &HTTPDetector{
+		endpoint:            endpoint,
+		apiKey:              apiKey,
+		confidenceThreshold: 0.1,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// Name returns the detector name
+func (d *HTTPDetector) Name() string {
+	return "http"
+}
+
+// Detect posts the frame to the detection server
+func (d *HTTPDetector) Detect(ctx context.Context, frame DetectionFrame) ([]Detection, error) {
+	payload, err := json.Marshal(httpDetectionRequest{
+		Image:               frame.Data,
+		Frame:               frame.Number,
+		ConfidenceThreshold: d.confidenceThreshold,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal detection request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", d.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create detection request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if d.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+d.apiKey)
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("detection request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read detection response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("detection server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result httpDetectionResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse detection response: %w", err)
+	}
+
+	return d.convertDetections(result), nil
+}
+
+// convertDetections maps server detections to normalized tracker detections
+func (d *HTTPDetector) convertDetections(result httpDetectionResponse) []Detection {
+	detections := make([]Detection, 0, len(result.Detections))
+	for _, item := range result.Detections {
+		if len(item.BBox) != 4 {
+			continue
+		}
+
+		x1, y1, x2, y2 := item.BBox[0], item.BBox[1], item.BBox[2], item.BBox[3]
+		if result.Width > 0 && result.Height > 0 {
+			x1 /= result.Width
+			x2 /= result.Width
+			y1 /= result.Height
+			y2 /= result.Height
+		}
+
+		attributes := make(map[string]interface{}, len(item.Extra)+1)
+		for key, value := range item.Extra {
+			attributes[key] = value
+		}
+		attributes["label"] = item.Label
+
+		detections = append(detections, Detection{
+			Class:       classForLabel(item.Label),
+			Confidence:  item.Score,
+			BoundingBox: BoundingBox{X: x1, Y: y1, Width: x2 - x1, Height: y2 - y1},
+			Features:    item.Features,
+			Attributes:  attributes,
+		})
+	}
+
+	return sanitizeDetections(detections)
+}
@@ -0,0 +1,104 @@
+package tracking
+
+import "math"
+
+// forbiddenCost marks pairs that must not be assigned (kept finite so potentials stay well-defined)
+const forbiddenCost = 1e6
+
+// hungarianAssign solves the rectangular assignment problem, minimising total cost
+// Returns the column assigned to each row (-1 when unassigned or only forbidden pairs remain)
+func hungarianAssign(cost [][]float64) []int {
+	n := len(cost)
+	assignment := make([]int, n)
+	for i := range assignment {
+		assignment[i] = -1
+	}
+	if n == 0 || len(cost[0]) == 0 {
+		return assignment
+	}
+	m := len(cost[0])
+
+	// The potentials algorithm below requires rows <= columns
+	if n > m {
+		transposed := make([][]float64, m)
+		for j := range transposed {
+			transposed[j] = make([]float64, n)
+			for i := 0; i < n; i++ {
+				transposed[j][i] = cost[i][j]
+			}
+		}
+		for j, i := range hungarianAssign(transposed) {
+			if i >= 0 {
+				assignment[i] = j
+			}
+		}
+		return assignment
+	}
+
+	// Kuhn-Munkres with row/column potentials (1-indexed; column 0 is a sentinel)
+	u := make([]float64, n+1)
+	v := make([]float64, m+1)
+	p := make([]int, m+1)   // p[j] = row assigned to column j
+	way := make([]int, m+1) // Augmenting path back-pointers
+
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		minv := make([]float64, m+1)
+		used := make([]bool, m+1)
+		for j := range minv {
+			minv[j] = math.Inf(1)
+		}
+
+		for {
+			used[j0] = true
+			i0 := p[j0]
+			delta := math.Inf(1)
+			j1 := 0
+
+			for j := 1; j <= m; j++ {
+				if used[j] {
+					continue
+				}
+				cur := cost[i0-1][j-1] - u[i0] - v[j]
+				if cur < minv[j] {
+					minv[j] = cur
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+
+			for j := 0; j <= m; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+
+		// Augment along the path
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+
+	for j := 1; j <= m; j++ {
+		if p[j] > 0 && cost[p[j]-1][j-1] < forbiddenCost {
+			assignment[p[j]-1] = j - 1
+		}
+	}
+
+	return assignment
+}
@@ -0,0 +1,84 @@
+package tracking
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHungarianAssign(t *testing.T) {
+	tests := []struct {
+		name string
+		cost [][]float64
+		want []int
+	}{
+		{
+			name: "empty",
+			cost: [][]float64{},
+			want: []int{},
+		},
+		{
+			name: "no columns",
+			cost: [][]float64{{}, {}},
+			want: []int{-1, -1},
+		},
+		{
+			name: "identity",
+			cost: [][]float64{
+				{0, 1},
+				{1, 0},
+			},
+			want: []int{0, 1},
+		},
+		{
+			name: "optimal beats greedy",
+			// Greedy takes (0,0)=1 then (1,1)=10; optimal is (0,1)+(1,0)=2+2
+			cost: [][]float64{
+				{1, 2},
+				{2, 10},
+			},
+			want: []int{1, 0},
+		},
+		{
+			name: "three by three",
+			cost: [][]float64{
+				{4, 1, 3},
+				{2, 0, 5},
+				{3, 2, 2},
+			},
+			want: []int{1, 0, 2},
+		},
+		{
+			name: "more columns than rows",
+			cost: [][]float64{
+				{5, 1, 9},
+				{2, 8, 7},
+			},
+			want: []int{1, 0},
+		},
+		{
+			name: "more rows than columns",
+			cost: [][]float64{
+				{5, 1},
+				{2, 8},
+				{0.5, 0.5},
+			},
+			want: []int{1, -1, 0},
+		},
+		{
+			name: "forbidden pairs stay unassigned",
+			cost: [][]float64{
+				{forbiddenCost, 0.2},
+				{forbiddenCost, forbiddenCost},
+			},
+			want: []int{1, -1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hungarianAssign(tt.cost); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hungarianAssign() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
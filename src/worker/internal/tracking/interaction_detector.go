@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"sort"
 	"time"
 
 	"github.com/adverant/nexus/videoagent-worker/internal/clients"
@@ -34,6 +35,9 @@ type Interaction struct {
 	Participants    []string               `json:"participants"`    // Track IDs involved
 	StartTime       time.Time              `json:"startTime"`
 	EndTime         *time.Time             `json:"endTime,omitempty"`
+	LastSeen        time.Time              `json:"lastSeen"`        // Last frame the interaction was observed in
+	StartFrame      int                    `json:"startFrame"`
+	EndFrame        int                    `json:"endFrame"`        // Last frame observed
 	Duration        float64                `json:"duration"`        // Seconds
 	Confidence      float64                `json:"confidence"`      // 0-1
 	Proximity       float64                `json:"proximity"`       // Distance between objects
@@ -49,7 +53,7 @@ type InteractionEvent struct {
 	Interaction   *Interaction           `json:"interaction"`
 	Timestamp     time.Time              `json:"timestamp"`
 	FrameNum      int                    `json:"frameNum"`
-	EventType     string                 `json:"eventType"`     // started, ongoing, ended
+	EventType     string                 `json:"eventType"`     // started, ongoing, changed, ended
 	Significance  float64                `json:"significance"`  // 0-1, how significant
 	Attributes    map[string]interface{} `json:"attributes"`
 }
@@ -62,14 +66,19 @@ type ProximityThreshold struct {
 }
 
 // InteractionDetector detects and analyzes interactions between tracked objects
+// Times come from the frames (DetectInteractionsAt), so offline processing measures video time.
 type InteractionDetector struct {
 	mageAgent           *clients.MageAgentClient
 	interactions        map[string]*Interaction // Active interactions
 	history             []Interaction          // Completed interactions
 	nextInteractionID   int
 	proximityThreshold  ProximityThreshold
+	classThresholds     map[ObjectClass]ProximityThreshold // Per-class overrides
 	minDuration         time.Duration // Minimum duration to consider
+	staleAfter          time.Duration // Interactions not observed for this long are ended
+	historyLimit        int           // Completed interactions kept (0 = unlimited)
 	maxInteractions     int
+	holders             map[string]string // Object track ID -> person track ID holding it
 }
 
 // NewInteractionDetector creates a new interaction detector
@@ -84,15 +93,70 @@ func NewInteractionDetector(mageAgent *clients.MageAgentClient) *InteractionDete
 			Medium: 0.3,
 			Far:    0.5,
 		},
+		classThresholds: make(map[ObjectClass]ProximityThreshold),
 		minDuration:     time.Millisecond * 500, // 0.5 seconds
+		staleAfter:      time.Second * 5,
+		historyLimit:    1000,
 		maxInteractions: 100,
+		holders:         make(map[string]string),
 	}
 }
 
-// DetectInteractions detects interactions in current frame
+// SetProximityThreshold sets the default distance thresholds
+func (id *InteractionDetector) SetProximityThreshold(threshold ProximityThreshold) {
+	id.proximityThreshold = threshold
+}
+
+// SetClassProximityThreshold sets distance thresholds for pairs involving a class
+// (e.g. vehicles interact from further away than people). Mixed pairs use the
+// larger threshold of the two classes.
+func (id *InteractionDetector) SetClassProximityThreshold(class ObjectClass, threshold ProximityThreshold) {
+	id.classThresholds[class] = threshold
+}
+
+// SetMinDuration sets the minimum duration for an interaction to be kept in history
+func (id *InteractionDetector) SetMinDuration(minDuration time.Duration) {
+	id.minDuration = minDuration
+}
+
+// SetHistoryLimit sets how many completed interactions are kept (0 keeps all)
+func (id *InteractionDetector) SetHistoryLimit(limit int) {
+	id.historyLimit = limit
+}
+
+// thresholdFor returns the distance thresholds for a pair of classes
+func (id *InteractionDetector) thresholdFor(class1, class2 ObjectClass) ProximityThreshold {
+	threshold1, ok1 := id.classThresholds[class1]
+	threshold2, ok2 := id.classThresholds[class2]
+	switch {
+	case ok1 && ok2:
+		return ProximityThreshold{
+			Near:   math.Max(threshold1.Near, threshold2.Near),
+			Medium: math.Max(threshold1.Medium, threshold2.Medium),
+			Far:    math.Max(threshold1.Far, threshold2.Far),
+		}
+	case ok1:
+		return threshold1
+	case ok2:
+		return threshold2
+	default:
+		return id.proximityThreshold
+	}
+}
+
+// DetectInteractions detects interactions in current frame (live streams, timed by wall clock)
 func (id *InteractionDetector) DetectInteractions(ctx context.Context, tracks []TrackedObject, frameData string, frameNum int) ([]InteractionEvent, error) {
+	return id.DetectInteractionsAt(ctx, tracks, frameData, frameNum, time.Now())
+}
+
+// DetectInteractionsAt detects interactions in a frame captured at frameTime
+// Use video time for offline processing so durations reflect the video, not processing speed
+func (id *InteractionDetector) DetectInteractionsAt(ctx context.Context, tracks []TrackedObject, frameData string, frameNum int, frameTime time.Time) ([]InteractionEvent, error) {
 	events := make([]InteractionEvent, 0)
 
+	// Objects that changed hands between two visible people this frame
+	handoffs := id.updateHolders(tracks)
+
 	// Check all pairs of tracks
 	for i := 0; i < len(tracks); i++ {
 		for j := i + 1; j < len(tracks); j++ {
@@ -104,7 +168,12 @@ func (id *InteractionDetector) DetectInteractions(ctx context.Context, tracks []
 			relativeVel := id.computeRelativeVelocity(track1.Velocity, track2.Velocity)
 
 			// Detect interaction type
-			interactionType := id.classifyInteraction(track1, track2, proximity, relativeVel)
+			threshold := id.thresholdFor(track1.Class, track2.Class)
+			interactionType := id.classifyInteraction(track1, track2, proximity, relativeVel, threshold)
+			exchange, handedOver := handoffs[pairKey(track1.TrackID, track2.TrackID)]
+			if handedOver && proximity < threshold.Medium {
+				interactionType = InteractionHandoff
+			}
 
 			if interactionType != InteractionNone {
 				// Check if this is a continuation of existing interaction
@@ -115,30 +184,44 @@ func (id *InteractionDetector) DetectInteractions(ctx context.Context, tracks []
 					interaction := id.interactions[existingID]
 					interaction.Proximity = proximity
 					interaction.RelativeSpeed = relativeVel
+					id.observeInteraction(interaction, frameNum, frameTime)
+
+					// A handoff stays a handoff while the pair keeps interacting
+					if interaction.Type == InteractionHandoff {
+						interactionType = InteractionHandoff
+					}
 
 					// Check if type changed
+					eventType := "ongoing"
 					if interaction.Type != interactionType {
 						interaction.Type = interactionType
 						interaction.Description = id.describeInteraction(interactionType, track1, track2)
+						eventType = "changed"
+					}
+					if handedOver && interactionType == InteractionHandoff {
+						exchange.annotate(interaction)
 					}
 
 					events = append(events, InteractionEvent{
-						EventID:      fmt.Sprintf("event_%d_%d", id.nextInteractionID, frameNum),
+						EventID:      fmt.Sprintf("event_%s_%d", interaction.InteractionID, frameNum),
 						Interaction:  interaction,
-						Timestamp:    time.Now(),
+						Timestamp:    frameTime,
 						FrameNum:     frameNum,
-						EventType:    "ongoing",
+						EventType:    eventType,
 						Significance: id.computeSignificance(interaction),
 						Attributes:   map[string]interface{}{},
 					})
 				} else {
 					// Create new interaction
-					interaction := id.createInteraction(interactionType, track1, track2, proximity, relativeVel)
+					interaction := id.createInteraction(interactionType, track1, track2, proximity, relativeVel, frameNum, frameTime)
+					if interactionType == InteractionHandoff {
+						exchange.annotate(interaction)
+					}
 
 					events = append(events, InteractionEvent{
-						EventID:      fmt.Sprintf("event_%d_%d", interaction.InteractionID, frameNum),
+						EventID:      fmt.Sprintf("event_%s_%d", interaction.InteractionID, frameNum),
 						Interaction:  interaction,
-						Timestamp:    time.Now(),
+						Timestamp:    frameTime,
 						FrameNum:     frameNum,
 						EventType:    "started",
 						Significance: id.computeSignificance(interaction),
@@ -153,17 +236,7 @@ func (id *InteractionDetector) DetectInteractions(ctx context.Context, tracks []
 				existingID := id.findExistingInteraction(track1.TrackID, track2.TrackID)
 				if existingID != "" {
 					interaction := id.interactions[existingID]
-					id.endInteraction(interaction)
-
-					events = append(events, InteractionEvent{
-						EventID:      fmt.Sprintf("event_%d_%d", interaction.InteractionID, frameNum),
-						Interaction:  interaction,
-						Timestamp:    time.Now(),
-						FrameNum:     frameNum,
-						EventType:    "ended",
-						Significance: id.computeSignificance(interaction),
-						Attributes:   map[string]interface{}{},
-					})
+					events = append(events, id.endInteraction(interaction, frameNum, frameTime))
 
 					log.Printf("Interaction ended: %s (duration: %.2fs)",
 						interaction.InteractionID, interaction.Duration)
@@ -173,15 +246,44 @@ func (id *InteractionDetector) DetectInteractions(ctx context.Context, tracks []
 	}
 
 	// Check for group interactions (3+ objects)
-	groupEvents := id.detectGroupInteractions(tracks, frameNum)
+	groupEvents := id.detectGroupInteractions(tracks, frameNum, frameTime)
 	events = append(events, groupEvents...)
 
-	// Clean up old interactions
-	id.cleanupInteractions()
+	// End interactions whose tracks are no longer observed
+	events = append(events, id.cleanupInteractions(frameNum, frameTime)...)
 
 	return events, nil
 }
 
+// Finish ends all active interactions (end of video) and returns their ended events
+func (id *InteractionDetector) Finish(frameNum int, frameTime time.Time) []InteractionEvent {
+	events := make([]InteractionEvent, 0, len(id.interactions))
+	for _, interactionID := range id.activeIDs() {
+		events = append(events, id.endInteraction(id.interactions[interactionID], frameNum, frameTime))
+	}
+	return events
+}
+
+// activeIDs returns active interaction IDs in creation order (deterministic event order)
+func (id *InteractionDetector) activeIDs() []string {
+	ids := make([]string, 0, len(id.interactions))
+	for interactionID := range id.interactions {
+		ids = append(ids, interactionID)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return id.interactions[ids[i]].StartFrame < id.interactions[ids[j]].StartFrame ||
+			(id.interactions[ids[i]].StartFrame == id.interactions[ids[j]].StartFrame && ids[i] < ids[j])
+	})
+	return ids
+}
+
+// observeInteraction records that an interaction is still visible in a frame
+func (id *InteractionDetector) observeInteraction(interaction *Interaction, frameNum int, frameTime time.Time) {
+	interaction.LastSeen = frameTime
+	interaction.EndFrame = frameNum
+	interaction.Duration = frameTime.Sub(interaction.StartTime).Seconds()
+}
+
 // computeProximity computes normalized distance between two bounding boxes
 func (id *InteractionDetector) computeProximity(box1, box2 BoundingBox) float64 {
 	// Center points
@@ -209,14 +311,14 @@ func (id *InteractionDetector) computeRelativeVelocity(vel1, vel2 *Velocity) flo
 }
 
 // classifyInteraction classifies interaction type based on spatial and motion features
-func (id *InteractionDetector) classifyInteraction(track1, track2 *TrackedObject, proximity, relativeVel float64) InteractionType {
+func (id *InteractionDetector) classifyInteraction(track1, track2 *TrackedObject, proximity, relativeVel float64, threshold ProximityThreshold) InteractionType {
 	// Too far apart
-	if proximity > id.proximityThreshold.Far {
+	if proximity > threshold.Far {
 		return InteractionNone
 	}
 
 	// Very close - potential collision or meeting
-	if proximity < id.proximityThreshold.Near {
+	if proximity < threshold.Near {
 		if relativeVel > 0.1 {
 			return InteractionCollision
 		}
@@ -252,6 +354,77 @@ func (id *InteractionDetector) classifyInteraction(track1, track2 *TrackedObject
 	return InteractionNone
 }
 
+// handoff is an object passing from one person to another
+type handoff struct {
+	objectID string
+	from     string
+	to       string
+}
+
+// annotate records the handed-over object and direction on an interaction
+func (h handoff) annotate(interaction *Interaction) {
+	interaction.Attributes["object"] = h.objectID
+	interaction.Attributes["from"] = h.from
+	interaction.Attributes["to"] = h.to
+}
+
+// pairKey identifies an unordered pair of tracks
+func pairKey(trackID1, trackID2 string) string {
+	if trackID2 < trackID1 {
+		trackID1, trackID2 = trackID2, trackID1
+	}
+	return trackID1 + "|" + trackID2
+}
+
+// updateHolders assigns each visible object to the person whose box contains its centre
+// (the nearest one when several do) and returns the objects whose holder changed to
+// another person while the previous holder is still visible, keyed by pairKey.
+// Objects no one holds (e.g. mid-air) keep their last holder.
+func (id *InteractionDetector) updateHolders(tracks []TrackedObject) map[string]handoff {
+	visible := make(map[string]bool, len(tracks))
+	for _, track := range tracks {
+		visible[track.TrackID] = true
+	}
+
+	handoffs := make(map[string]handoff)
+	for i := range tracks {
+		object := &tracks[i]
+		if object.Class != ClassObject {
+			continue
+		}
+		cx := object.BoundingBox.X + object.BoundingBox.Width/2
+		cy := object.BoundingBox.Y + object.BoundingBox.Height/2
+
+		holder := ""
+		best := math.MaxFloat64
+		for j := range tracks {
+			person := &tracks[j]
+			if person.Class != ClassPerson {
+				continue
+			}
+			box := person.BoundingBox
+			if cx < box.X || cx > box.X+box.Width || cy < box.Y || cy > box.Y+box.Height {
+				continue
+			}
+			if distance := id.computeProximity(object.BoundingBox, box); distance < best {
+				holder = person.TrackID
+				best = distance
+			}
+		}
+		if holder == "" {
+			continue
+		}
+
+		previous := id.holders[object.TrackID]
+		if previous != "" && previous != holder && visible[previous] {
+			handoffs[pairKey(previous, holder)] = handoff{objectID: object.TrackID, from: previous, to: holder}
+		}
+		id.holders[object.TrackID] = holder
+	}
+
+	return handoffs
+}
+
 // areApproaching checks if two objects are approaching each other
 func (id *InteractionDetector) areApproaching(track1, track2 *TrackedObject) bool {
 	if track1.Velocity == nil || track2.Velocity == nil {
@@ -325,17 +498,19 @@ func (id *InteractionDetector) findExistingInteraction(trackID1, trackID2 string
 }
 
 // createInteraction creates a new interaction
-func (id *InteractionDetector) createInteraction(interactionType InteractionType, track1, track2 *TrackedObject, proximity, relativeVel float64) *Interaction {
+func (id *InteractionDetector) createInteraction(interactionType InteractionType, track1, track2 *TrackedObject, proximity, relativeVel float64, frameNum int, frameTime time.Time) *Interaction {
 	interactionID := fmt.Sprintf("interaction_%d", id.nextInteractionID)
 	id.nextInteractionID++
 
-	now := time.Now()
 	interaction := &Interaction{
 		InteractionID: interactionID,
 		Type:          interactionType,
 		Participants:  []string{track1.TrackID, track2.TrackID},
-		StartTime:     now,
+		StartTime:     frameTime,
 		EndTime:       nil,
+		LastSeen:      frameTime,
+		StartFrame:    frameNum,
+		EndFrame:      frameNum,
 		Duration:      0,
 		Confidence:    0.8,
 		Proximity:     proximity,
@@ -368,28 +543,42 @@ func (id *InteractionDetector) describeInteraction(interactionType InteractionTy
 		return fmt.Sprintf("%s is chasing %s", track1.Class, track2.Class)
 	case InteractionCollision:
 		return fmt.Sprintf("%s and %s are colliding", track1.Class, track2.Class)
+	case InteractionHandoff:
+		return fmt.Sprintf("%s and %s hand over an object", track1.Class, track2.Class)
 	default:
 		return fmt.Sprintf("Interaction between %s and %s", track1.Class, track2.Class)
 	}
 }
 
-// endInteraction marks an interaction as ended
-func (id *InteractionDetector) endInteraction(interaction *Interaction) {
-	now := time.Now()
-	interaction.EndTime = &now
-	interaction.Duration = now.Sub(interaction.StartTime).Seconds()
+// endInteraction marks an interaction as ended at the last frame it was observed in
+// and returns its ended event (detected at frameNum/frameTime)
+func (id *InteractionDetector) endInteraction(interaction *Interaction, frameNum int, frameTime time.Time) InteractionEvent {
+	endTime := interaction.LastSeen
+	interaction.EndTime = &endTime
+	interaction.Duration = endTime.Sub(interaction.StartTime).Seconds()
 	interaction.Active = false
 
 	// Move to history if duration meets minimum
-	if interaction.Duration >= id.minDuration.Seconds() {
+	recorded := interaction.Duration >= id.minDuration.Seconds()
+	if recorded {
 		id.history = append(id.history, *interaction)
 	}
 
 	delete(id.interactions, interaction.InteractionID)
+
+	return InteractionEvent{
+		EventID:      fmt.Sprintf("event_%s_%d", interaction.InteractionID, frameNum),
+		Interaction:  interaction,
+		Timestamp:    frameTime,
+		FrameNum:     frameNum,
+		EventType:    "ended",
+		Significance: id.computeSignificance(interaction),
+		Attributes:   map[string]interface{}{"recorded": recorded},
+	}
 }
 
 // detectGroupInteractions detects interactions involving 3+ objects
-func (id *InteractionDetector) detectGroupInteractions(tracks []TrackedObject, frameNum int) []InteractionEvent {
+func (id *InteractionDetector) detectGroupInteractions(tracks []TrackedObject, frameNum int, frameTime time.Time) []InteractionEvent {
 	events := make([]InteractionEvent, 0)
 
 	// Find clusters of nearby objects
@@ -405,14 +594,16 @@ func (id *InteractionDetector) detectGroupInteractions(tracks []TrackedObject, f
 
 			existingID := id.findGroupInteraction(trackIDs)
 
-			if existingID == "" {
+			if existingID != "" {
+				id.observeInteraction(id.interactions[existingID], frameNum, frameTime)
+			} else {
 				// Create new group interaction
-				interaction := id.createGroupInteraction(tracks, cluster)
+				interaction := id.createGroupInteraction(tracks, cluster, frameNum, frameTime)
 
 				events = append(events, InteractionEvent{
 					EventID:      fmt.Sprintf("event_%s_%d", interaction.InteractionID, frameNum),
 					Interaction:  interaction,
-					Timestamp:    time.Now(),
+					Timestamp:    frameTime,
 					FrameNum:     frameNum,
 					EventType:    "started",
 					Significance: id.computeSignificance(interaction),
@@ -447,7 +638,7 @@ func (id *InteractionDetector) findClusters(tracks []TrackedObject) [][]int {
 			}
 
 			proximity := id.computeProximity(tracks[i].BoundingBox, tracks[j].BoundingBox)
-			if proximity < id.proximityThreshold.Medium {
+			if proximity < id.thresholdFor(tracks[i].Class, tracks[j].Class).Medium {
 				cluster = append(cluster, j)
 				assigned[j] = true
 			}
@@ -489,7 +680,7 @@ func (id *InteractionDetector) findGroupInteraction(trackIDs []string) string {
 }
 
 // createGroupInteraction creates a new group interaction
-func (id *InteractionDetector) createGroupInteraction(tracks []TrackedObject, clusterIndices []int) *Interaction {
+func (id *InteractionDetector) createGroupInteraction(tracks []TrackedObject, clusterIndices []int, frameNum int, frameTime time.Time) *Interaction {
 	interactionID := fmt.Sprintf("interaction_%d", id.nextInteractionID)
 	id.nextInteractionID++
 
@@ -501,13 +692,15 @@ func (id *InteractionDetector) createGroupInteraction(tracks []TrackedObject, cl
 		classes[i] = tracks[idx].Class
 	}
 
-	now := time.Now()
 	interaction := &Interaction{
 		InteractionID: interactionID,
 		Type:          InteractionGrouping,
 		Participants:  participants,
-		StartTime:     now,
+		StartTime:     frameTime,
 		EndTime:       nil,
+		LastSeen:      frameTime,
+		StartFrame:    frameNum,
+		EndFrame:      frameNum,
 		Duration:      0,
 		Confidence:    0.75,
 		Proximity:     0.2, // Average proximity
@@ -534,6 +727,8 @@ func (id *InteractionDetector) computeSignificance(interaction *Interaction) flo
 		significance = 0.95
 	case InteractionChasing:
 		significance = 0.85
+	case InteractionHandoff:
+		significance = 0.8
 	case InteractionMeeting:
 		significance = 0.75
 	case InteractionGrouping:
@@ -562,21 +757,24 @@ func (id *InteractionDetector) computeSignificance(interaction *Interaction) flo
 	return significance
 }
 
-// cleanupInteractions removes stale interactions
-func (id *InteractionDetector) cleanupInteractions() {
-	now := time.Now()
-	for interactionID, interaction := range id.interactions {
-		// Remove if inactive for too long (5 seconds)
-		if now.Sub(interaction.StartTime) > time.Second*5 {
-			id.endInteraction(interaction)
-			delete(id.interactions, interactionID)
+// cleanupInteractions ends interactions not observed for staleAfter
+// (their tracks left the frame, so no pair check ends them) and returns their ended events
+func (id *InteractionDetector) cleanupInteractions(frameNum int, frameTime time.Time) []InteractionEvent {
+	events := make([]InteractionEvent, 0)
+	for _, interactionID := range id.activeIDs() {
+		interaction := id.interactions[interactionID]
+		// Remove if inactive for too long
+		if frameTime.Sub(interaction.LastSeen) > id.staleAfter {
+			events = append(events, id.endInteraction(interaction, frameNum, frameTime))
 		}
 	}
 
 	// Limit history size
-	if len(id.history) > 1000 {
-		id.history = id.history[len(id.history)-1000:]
+	if id.historyLimit > 0 && len(id.history) > id.historyLimit {
+		id.history = id.history[len(id.history)-id.historyLimit:]
 	}
+
+	return events
 }
 
 // GetActiveInteractions returns all active interactions
@@ -625,4 +823,5 @@ func (id *InteractionDetector) Reset() {
 	id.interactions = make(map[string]*Interaction)
 	id.history = make([]Interaction, 0)
 	id.nextInteractionID = 1
+	id.holders = make(map[string]string)
 }
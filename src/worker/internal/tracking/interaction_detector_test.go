@@ -0,0 +1,219 @@
+package tracking
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+var interactionEpoch = time.Unix(0, 0).UTC()
+
+// at returns the video time of a frame sampled every step seconds
+func at(frameNum int, step float64) time.Time {
+	return interactionEpoch.Add(time.Duration(float64(frameNum) * step * float64(time.Second)))
+}
+
+func tracked(trackID string, class ObjectClass, x, width float64) TrackedObject {
+	return TrackedObject{
+		TrackID:     trackID,
+		Class:       class,
+		Confidence:  0.9,
+		BoundingBox: BoundingBox{X: x, Y: 0.3, Width: width, Height: 0.3},
+	}
+}
+
+// detectFrames runs each frame through the detector and returns all events
+func detectFrames(t *testing.T, detector *InteractionDetector, frames [][]TrackedObject, step float64) []InteractionEvent {
+	t.Helper()
+	events := make([]InteractionEvent, 0)
+	for frameNum, tracks := range frames {
+		frameEvents, err := detector.DetectInteractionsAt(context.Background(), tracks, "", frameNum, at(frameNum, step))
+		if err != nil {
+			t.Fatalf("DetectInteractionsAt frame %d: %v", frameNum, err)
+		}
+		events = append(events, frameEvents...)
+	}
+	return events
+}
+
+func endedEvents(events []InteractionEvent) []InteractionEvent {
+	ended := make([]InteractionEvent, 0)
+	for _, event := range events {
+		if event.EventType == "ended" {
+			ended = append(ended, event)
+		}
+	}
+	return ended
+}
+
+func TestInteractionDurationUsesVideoTime(t *testing.T) {
+	detector := NewInteractionDetector(nil)
+
+	// Two people meet for frames 0-4 (2s of video at 2 fps), then walk apart
+	frames := make([][]TrackedObject, 0)
+	for i := 0; i < 5; i++ {
+		frames = append(frames, []TrackedObject{tracked("a", ClassPerson, 0.30, 0.1), tracked("b", ClassPerson, 0.35, 0.1)})
+	}
+	frames = append(frames, []TrackedObject{tracked("a", ClassPerson, 0.0, 0.1), tracked("b", ClassPerson, 0.9, 0.1)})
+
+	ended := endedEvents(detectFrames(t, detector, frames, 0.5))
+	if len(ended) != 1 {
+		t.Fatalf("expected 1 ended interaction, got %d", len(ended))
+	}
+	interaction := ended[0].Interaction
+	if interaction.Type != InteractionMeeting {
+		t.Errorf("Type = %s, want %s", interaction.Type, InteractionMeeting)
+	}
+	if math.Abs(interaction.Duration-2.0) > 1e-9 {
+		t.Errorf("Duration = %v, want 2s of video time", interaction.Duration)
+	}
+	if interaction.EndTime == nil || !interaction.EndTime.Equal(at(4, 0.5)) {
+		t.Errorf("EndTime = %v, want last observed frame %v", interaction.EndTime, at(4, 0.5))
+	}
+	if interaction.StartFrame != 0 || interaction.EndFrame != 4 {
+		t.Errorf("frames = %d-%d, want 0-4", interaction.StartFrame, interaction.EndFrame)
+	}
+	if ended[0].FrameNum != 5 || !ended[0].Timestamp.Equal(at(5, 0.5)) {
+		t.Errorf("ended event at frame %d (%v), want frame 5", ended[0].FrameNum, ended[0].Timestamp)
+	}
+
+	history := detector.GetHistory(0)
+	if len(history) != 1 || history[0].Duration != interaction.Duration {
+		t.Errorf("history = %+v, want the 2s meeting", history)
+	}
+}
+
+func TestStaleInteractionEndsAtLastSeen(t *testing.T) {
+	detector := NewInteractionDetector(nil)
+
+	// Seen for 1s, then both tracks leave the frame
+	frames := [][]TrackedObject{
+		{tracked("a", ClassPerson, 0.30, 0.1), tracked("b", ClassPerson, 0.35, 0.1)},
+		{tracked("a", ClassPerson, 0.30, 0.1), tracked("b", ClassPerson, 0.35, 0.1)},
+		{tracked("a", ClassPerson, 0.30, 0.1), tracked("b", ClassPerson, 0.35, 0.1)},
+	}
+	detectFrames(t, detector, frames, 0.5)
+
+	// Not stale 4s later
+	events, _ := detector.DetectInteractionsAt(context.Background(), nil, "", 10, at(10, 0.5))
+	if len(endedEvents(events)) != 0 {
+		t.Fatalf("interaction ended before staleAfter elapsed")
+	}
+
+	events, _ = detector.DetectInteractionsAt(context.Background(), nil, "", 14, at(14, 0.5))
+	ended := endedEvents(events)
+	if len(ended) != 1 {
+		t.Fatalf("expected the stale interaction to end, got %d ended events", len(ended))
+	}
+	if got := ended[0].Interaction.Duration; math.Abs(got-1.0) > 1e-9 {
+		t.Errorf("Duration = %v, want 1s (until last seen, not until cleanup)", got)
+	}
+	if len(detector.GetActiveInteractions()) != 0 {
+		t.Errorf("stale interaction still active")
+	}
+}
+
+func TestFinishRecordsOnlyLongEnoughInteractions(t *testing.T) {
+	tests := []struct {
+		name     string
+		frames   int
+		step     float64
+		recorded bool
+	}{
+		{"shorter than minimum", 2, 0.25, false},
+		{"exactly minimum", 3, 0.25, true},
+		{"long", 10, 0.5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := NewInteractionDetector(nil)
+			frames := make([][]TrackedObject, tt.frames)
+			for i := range frames {
+				frames[i] = []TrackedObject{tracked("a", ClassPerson, 0.30, 0.1), tracked("b", ClassPerson, 0.35, 0.1)}
+			}
+			detectFrames(t, detector, frames, tt.step)
+
+			ended := detector.Finish(tt.frames-1, at(tt.frames-1, tt.step))
+			if len(ended) != 1 {
+				t.Fatalf("expected 1 ended event, got %d", len(ended))
+			}
+			want := float64(tt.frames-1) * tt.step
+			if math.Abs(ended[0].Interaction.Duration-want) > 1e-9 {
+				t.Errorf("Duration = %v, want %v", ended[0].Interaction.Duration, want)
+			}
+			if ended[0].Attributes["recorded"] != tt.recorded {
+				t.Errorf("recorded = %v, want %v", ended[0].Attributes["recorded"], tt.recorded)
+			}
+			if got := len(detector.GetHistory(0)); (got == 1) != tt.recorded {
+				t.Errorf("history has %d interactions, recorded = %v", got, tt.recorded)
+			}
+		})
+	}
+}
+
+func TestInteractionHandoffBetweenPeople(t *testing.T) {
+	detector := NewInteractionDetector(nil)
+
+	// a holds the bag (centre 0.28 is inside a only), then b does (0.40 inside b only)
+	a := tracked("a", ClassPerson, 0.25, 0.1)
+	b := tracked("b", ClassPerson, 0.32, 0.1)
+	frames := [][]TrackedObject{
+		{a, b, tracked("bag", ClassObject, 0.27, 0.02)},
+		{a, b, tracked("bag", ClassObject, 0.27, 0.02)},
+		{a, b, tracked("bag", ClassObject, 0.39, 0.02)},
+		{a, b, tracked("bag", ClassObject, 0.39, 0.02)},
+	}
+	events := detectFrames(t, detector, frames, 0.5)
+
+	// Events share the live interaction, so check the event types of the a-b pair per frame
+	var handoff *Interaction
+	for _, event := range events {
+		participants := event.Interaction.Participants
+		if len(participants) != 2 || pairKey(participants[0], participants[1]) != pairKey("a", "b") {
+			continue
+		}
+		handoff = event.Interaction
+		want := map[int]string{0: "started", 1: "ongoing", 2: "changed", 3: "ongoing"}[event.FrameNum]
+		if event.EventType != want {
+			t.Errorf("frame %d: event %q, want %q", event.FrameNum, event.EventType, want)
+		}
+	}
+	if handoff == nil || handoff.Type != InteractionHandoff {
+		t.Fatalf("no handoff detected between a and b")
+	}
+	if handoff.Attributes["object"] != "bag" || handoff.Attributes["from"] != "a" || handoff.Attributes["to"] != "b" {
+		t.Errorf("attributes = %v, want bag from a to b", handoff.Attributes)
+	}
+
+	// Still a handoff the frame after the exchange, and timed from the start of the meeting
+	ended := detector.Finish(3, at(3, 0.5))
+	for _, event := range ended {
+		if event.Interaction.InteractionID != handoff.InteractionID {
+			continue
+		}
+		if event.Interaction.Type != InteractionHandoff {
+			t.Errorf("Type = %s at end, want handoff", event.Interaction.Type)
+		}
+		if math.Abs(event.Interaction.Duration-1.5) > 1e-9 {
+			t.Errorf("Duration = %v, want 1.5s", event.Interaction.Duration)
+		}
+	}
+}
+
+func TestNoHandoffWhenHolderLeft(t *testing.T) {
+	detector := NewInteractionDetector(nil)
+
+	// a leaves the frame before b picks up the bag
+	frames := [][]TrackedObject{
+		{tracked("a", ClassPerson, 0.25, 0.1), tracked("bag", ClassObject, 0.27, 0.02)},
+		{tracked("b", ClassPerson, 0.32, 0.1), tracked("bag", ClassObject, 0.39, 0.02)},
+		{tracked("a", ClassPerson, 0.25, 0.1), tracked("b", ClassPerson, 0.32, 0.1), tracked("bag", ClassObject, 0.39, 0.02)},
+	}
+	for _, event := range detectFrames(t, detector, frames, 0.5) {
+		if event.Interaction.Type == InteractionHandoff {
+			t.Fatalf("unexpected handoff at frame %d", event.FrameNum)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package tracking
+
+// boxKalman is a constant-velocity Kalman filter over a bounding box (center x/y, width, height)
+// Each coordinate is an independent position/velocity filter, which is exact for the
+// block-diagonal motion model used by SORT-style trackers. Time step is one tracked frame.
+type boxKalman struct {
+	axes [4]kalmanAxis // cx, cy, w, h
+}
+
+// kalmanAxis is a 2-state (position, velocity) Kalman filter
+type kalmanAxis struct {
+	x float64       // Position
+	v float64       // Velocity (units per frame)
+	p [2][2]float64 // State covariance
+}
+
+// Noise scales relative to box height (as in DeepSORT)
+const (
+	kalmanStdPosition = 1.0 / 20
+	kalmanStdVelocity = 1.0 / 160
+	kalmanMinHeight   = 0.01
+)
+
+// newBoxKalman initialises a filter from the first observed box
+func newBoxKalman(box BoundingBox) *boxKalman {
+	kf := &boxKalman{}
+	stdPos, stdVel := kalmanNoise(box.Height)
+
+	for i, z := range boxToMeasurement(box) {
+		kf.axes[i] = kalmanAxis{
+			x: z,
+			p: [2][2]float64{
+				{4 * stdPos * stdPos, 0},
+				{0, 100 * stdVel * stdVel},
+			},
+		}
+	}
+	return kf
+}
+
+// predict advances the filter one frame and returns the predicted box
+func (kf *boxKalman) predict() BoundingBox {
+	stdPos, stdVel := kalmanNoise(kf.axes[3].x)
+	for i := range kf.axes {
+		kf.axes[i].predict(stdPos*stdPos, stdVel*stdVel)
+	}
+	return kf.box()
+}
+
+// update corrects the filter with an observed box and returns the filtered box
+func (kf *boxKalman) update(box BoundingBox) BoundingBox {
+	stdPos, _ := kalmanNoise(kf.axes[3].x)
+	for i, z := range boxToMeasurement(box) {
+		kf.axes[i].update(z, stdPos*stdPos)
+	}
+	return kf.box()
+}
+
+// box returns the current state as a bounding box
+func (kf *boxKalman) box() BoundingBox {
+	w := kf.axes[2].x
+	h := kf.axes[3].x
+	if w < 0 {
+		w = 0
+	}
+	if h < 0 {
+		h = 0
+	}
+	return BoundingBox{
+		X:      kf.axes[0].x - w/2,
+		Y:      kf.axes[1].x - h/2,
+		Width:  w,
+		Height: h,
+	}
+}
+
+// velocity returns the center velocity (normalized units per frame)
+func (kf *boxKalman) velocity() (float64, float64) {
+	return kf.axes[0].v, kf.axes[1].v
+}
+
+// predict applies x' = x + v with process noise
+func (k *kalmanAxis) predict(qPos, qVel float64) {
+	k.x += k.v
+
+	// P = F P F^T + Q with F = [[1, 1], [0, 1]]
+	p := k.p
+	k.p[0][0] = p[0][0] + p[0][1] + p[1][0] + p[1][1] + qPos
+	k.p[0][1] = p[0][1] + p[1][1]
+	k.p[1][0] = p[1][0] + p[1][1]
+	k.p[1][1] = p[1][1] + qVel
+}
+
+// update corrects the state with a position measurement z of variance r
+func (k *kalmanAxis) update(z, r float64) {
+	s := k.p[0][0] + r
+	if s == 0 {
+		return
+	}
+
+	gainPos := k.p[0][0] / s
+	gainVel := k.p[1][0] / s
+	residual := z - k.x
+
+	k.x += gainPos * residual
+	k.v += gainVel * residual
+
+	// P = (I - K H) P
+	p := k.p
+	k.p[0][0] = (1 - gainPos) * p[0][0]
+	k.p[0][1] = (1 - gainPos) * p[0][1]
+	k.p[1][0] = p[1][0] - gainVel*p[0][0]
+	k.p[1][1] = p[1][1] - gainVel*p[0][1]
+}
+
+// boxToMeasurement converts a box to (cx, cy, w, h)
+func boxToMeasurement(box BoundingBox) [4]float64 {
+	return [4]float64{
+		box.X + box.Width/2,
+		box.Y + box.Height/2,
+		box.Width,
+		box.Height,
+	}
+}
+
+// kalmanNoise returns position and velocity standard deviations for a box height
+func kalmanNoise(height float64) (float64, float64) {
+	if height < kalmanMinHeight {
+		height = kalmanMinHeight
+	}
+	return kalmanStdPosition * height, kalmanStdVelocity * height
+}
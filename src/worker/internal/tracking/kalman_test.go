@@ -0,0 +1,80 @@
+package tracking
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBoxKalmanStationaryBox(t *testing.T) {
+	box := BoundingBox{X: 0.2, Y: 0.3, Width: 0.1, Height: 0.2}
+	kf := newBoxKalman(box)
+
+	for i := 0; i < 10; i++ {
+		kf.predict()
+		kf.update(box)
+	}
+
+	got := kf.predict()
+	if !boxesClose(got, box, 1e-9) {
+		t.Errorf("predicted %+v for a stationary box, want %+v", got, box)
+	}
+	if vx, vy := kf.velocity(); vx != 0 || vy != 0 {
+		t.Errorf("velocity = (%v, %v), want (0, 0)", vx, vy)
+	}
+}
+
+func TestBoxKalmanLearnsConstantVelocity(t *testing.T) {
+	const step = 0.01
+	box := BoundingBox{X: 0.1, Y: 0.4, Width: 0.1, Height: 0.2}
+	kf := newBoxKalman(box)
+
+	for i := 1; i <= 30; i++ {
+		kf.predict()
+		box.X += step
+		kf.update(box)
+	}
+
+	vx, vy := kf.velocity()
+	if math.Abs(vx-step) > 1e-3 || math.Abs(vy) > 1e-6 {
+		t.Errorf("velocity = (%v, %v), want (%v, 0)", vx, vy, step)
+	}
+
+	// A frame without a detection coasts on the learned velocity
+	want := box
+	want.X += step
+	if got := kf.predict(); !boxesClose(got, want, 1e-3) {
+		t.Errorf("predicted %+v, want %+v", got, want)
+	}
+}
+
+func TestBoxKalmanUpdateWeighsMeasurement(t *testing.T) {
+	box := BoundingBox{X: 0.5, Y: 0.5, Width: 0.1, Height: 0.2}
+	kf := newBoxKalman(box)
+	kf.predict()
+
+	observed := BoundingBox{X: 0.52, Y: 0.5, Width: 0.1, Height: 0.2}
+	got := kf.update(observed)
+
+	// The corrected box lies strictly between the prediction and the measurement
+	if got.X <= box.X || got.X >= observed.X {
+		t.Errorf("corrected x = %v, want between %v and %v", got.X, box.X, observed.X)
+	}
+}
+
+func TestBoxKalmanClampsNegativeSize(t *testing.T) {
+	kf := newBoxKalman(BoundingBox{X: 0.5, Y: 0.5, Width: 0.1, Height: 0.1})
+	kf.axes[2].x = -0.05
+	kf.axes[3].x = -0.05
+
+	got := kf.box()
+	if got.Width != 0 || got.Height != 0 {
+		t.Errorf("box size = %vx%v, want 0x0", got.Width, got.Height)
+	}
+}
+
+func boxesClose(a, b BoundingBox, tolerance float64) bool {
+	return math.Abs(a.X-b.X) <= tolerance &&
+		math.Abs(a.Y-b.Y) <= tolerance &&
+		math.Abs(a.Width-b.Width) <= tolerance &&
+		math.Abs(a.Height-b.Height) <= tolerance
+}
@@ -0,0 +1,86 @@
+package tracking
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/clients"
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// MageAgentDetector detects objects by prompting a MageAgent vision model for bounding boxes
+// Slow and imprecise compared to a dedicated detector, but needs no extra infrastructure
+type MageAgentDetector struct {
+	mageAgent *clients.MageAgentClient
+}
+
+// NewMageAgentDetector creates a new MageAgent-backed detector
+func NewMageAgentDetector(mageAgent *clients.MageAgentClient) *MageAgentDetector {
+	return &MageAgentDetector{
+		mageAgent: mageAgent,
+	}
+}
+
+// Name returns the detector name
+func (d *MageAgentDetector) Name() string {
+	return "mageagent"
+}
+
+// Detect asks the vision model for boxes in the frame
+func (d *MageAgentDetector) Detect(ctx context.Context, frame DetectionFrame) ([]Detection, error) {
+	prompt := `Detect all people, vehicles, animals, and significant objects in this video frame.
+For each detected object, provide:
+1. Class: person, vehicle, animal, or object
+2. Confidence: 0.0-1.0
+3. Bounding box: x, y, width, height (normalized 0-1, from top-left)
+4. Attributes: color, size, pose, motion state
+
+Respond with JSON array:
+[
+  {
+    "class": "person|vehicle|animal|object",
+    "confidence": 0.0-1.0,
+    "boundingBox": {"x": 0-1, "y": 0-1, "width": 0-1, "height": 0-1},
+    "attributes": {"color": "...", "size": "small|medium|large", "pose": "...", "motion": "stationary|moving"}
+  }
+]`
+
+	visionReq := models.MageAgentVisionRequest{
+		Image:     frame.Data,
+		Prompt:    prompt,
+		MaxTokens: 1000,
+	}
+
+	visionResp, err := d.mageAgent.AnalyzeFrame(ctx, visionReq)
+	if err != nil {
+		return nil, fmt.Errorf("vision analysis failed: %w", err)
+	}
+
+	detections, err := d.parseDetections(visionResp.Description)
+	if err != nil {
+		log.Printf("Failed to parse detections: %v", err)
+		return []Detection{}, nil // Return empty instead of error
+	}
+
+	return detections, nil
+}
+
+// parseDetections parses AI response into Detection objects
+func (d *MageAgentDetector) parseDetections(response string) ([]Detection, error) {
+	// Response is a JSON array, possibly wrapped in markdown or prose
+	start := strings.Index(response, "[")
+	end := strings.LastIndex(response, "]")
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("no JSON array in response")
+	}
+
+	var detections []Detection
+	if err := json.Unmarshal([]byte(response[start:end+1]), &detections); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal detections: %w", err)
+	}
+
+	return sanitizeDetections(detections), nil
+}
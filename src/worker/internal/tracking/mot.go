@@ -0,0 +1,227 @@
+package tracking
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MOTChallenge text format: one box per line,
+//
+//	frame, id, bb_left, bb_top, bb_width, bb_height, conf, x, y, z
+//
+// Frames are 1-based and boxes are in pixels. Ground-truth files use the conf column as
+// a "consider" flag (0 = ignore) and may carry class and visibility in columns 8-9;
+// detection files use id -1.
+
+// MOTRecord is a single line of a MOTChallenge file, with the box normalized to 0-1
+type MOTRecord struct {
+	Frame       int         `json:"frame"`
+	ID          int         `json:"id"`
+	BoundingBox BoundingBox `json:"boundingBox"`
+	Confidence  float64     `json:"confidence"`
+	Class       int         `json:"class"`      // Ground truth only (-1 when absent)
+	Visibility  float64     `json:"visibility"` // Ground truth only (-1 when absent)
+}
+
+// ReadMOT parses a MOTChallenge file, normalizing boxes by the frame size
+// Pass width/height of 1 when the file already holds normalized boxes
+func ReadMOT(r io.Reader, width, height float64) ([]MOTRecord, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("frame width and height are required to read MOT files")
+	}
+
+	records := make([]MOTRecord, 0)
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.FieldsFunc(line, func(c rune) bool { return c == ',' || c == ' ' || c == '\t' })
+		if len(fields) < 6 {
+			return nil, fmt.Errorf("MOT line %d: expected at least 6 fields, got %d", lineNum, len(fields))
+		}
+
+		values := make([]float64, len(fields))
+		for i, field := range fields {
+			value, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, fmt.Errorf("MOT line %d: invalid value %q: %w", lineNum, field, err)
+			}
+			values[i] = value
+		}
+
+		record := MOTRecord{
+			Frame: int(values[0]),
+			ID:    int(values[1]),
+			BoundingBox: BoundingBox{
+				X:      values[2] / width,
+				Y:      values[3] / height,
+				Width:  values[4] / width,
+				Height: values[5] / height,
+			},
+			Confidence: 1,
+			Class:      -1,
+			Visibility: -1,
+		}
+		if len(values) > 6 {
+			record.Confidence = values[6]
+		}
+		if len(values) > 7 {
+			record.Class = int(values[7])
+		}
+		if len(values) > 8 {
+			record.Visibility = values[8]
+		}
+		if record.Frame <= 0 {
+			return nil, fmt.Errorf("MOT line %d: frame must be positive", lineNum)
+		}
+
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read MOT file: %w", err)
+	}
+
+	return records, nil
+}
+
+// FilterGroundTruth drops ignored ground-truth boxes (consider flag 0) and, when classes
+// are given, boxes of other classes (e.g. 1 = pedestrian in MOT16/17)
+func FilterGroundTruth(records []MOTRecord, classes ...int) []MOTRecord {
+	allowed := make(map[int]bool, len(classes))
+	for _, class := range classes {
+		allowed[class] = true
+	}
+
+	filtered := make([]MOTRecord, 0, len(records))
+	for _, record := range records {
+		if record.Confidence == 0 {
+			continue
+		}
+		if len(allowed) > 0 && record.Class >= 0 && !allowed[record.Class] {
+			continue
+		}
+		filtered = append(filtered, record)
+	}
+	return filtered
+}
+
+// WriteMOT writes records in MOTChallenge format, scaling boxes to the frame size
+func WriteMOT(w io.Writer, records []MOTRecord, width, height float64) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("frame width and height are required to write MOT files")
+	}
+
+	sorted := make([]MOTRecord, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Frame != sorted[j].Frame {
+			return sorted[i].Frame < sorted[j].Frame
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	buf := bufio.NewWriter(w)
+	for _, record := range sorted {
+		_, err := fmt.Fprintf(buf, "%d,%d,%s,%s,%s,%s,%s,-1,-1,-1\n",
+			record.Frame,
+			record.ID,
+			formatMOTValue(record.BoundingBox.X*width),
+			formatMOTValue(record.BoundingBox.Y*height),
+			formatMOTValue(record.BoundingBox.Width*width),
+			formatMOTValue(record.BoundingBox.Height*height),
+			formatMOTValue(record.Confidence),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to write MOT record: %w", err)
+		}
+	}
+
+	return buf.Flush()
+}
+
+// formatMOTValue formats a number to two decimals, dropping trailing zeros
+func formatMOTValue(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+// MOTRecordsFromResults converts a sequence of tracking results to MOT records
+// Only objects matched in their frame are exported (coasting tracks have stale boxes),
+// including the observations a track made before it was confirmed.
+// Track IDs of the form "track_N" keep N; other IDs are numbered in order of appearance.
+func MOTRecordsFromResults(results []*TrackingResult) []MOTRecord {
+	ids := make(map[string]int)
+	nextID := 1
+	for _, result := range results {
+		for _, obj := range result.TrackedObjects {
+			var n int
+			if _, err := fmt.Sscanf(obj.TrackID, "track_%d", &n); err == nil && n > 0 {
+				ids[obj.TrackID] = n
+				if n >= nextID {
+					nextID = n + 1
+				}
+			}
+		}
+	}
+
+	records := make([]MOTRecord, 0)
+	addRecord := func(trackID string, frameNum int, box BoundingBox, confidence float64) {
+		id, exists := ids[trackID]
+		if !exists {
+			id = nextID
+			ids[trackID] = id
+			nextID++
+		}
+		records = append(records, MOTRecord{
+			Frame:       frameNum,
+			ID:          id,
+			BoundingBox: box,
+			Confidence:  confidence,
+			Class:       -1,
+			Visibility:  -1,
+		})
+	}
+
+	for _, result := range results {
+		for _, obs := range result.ConfirmedObservations {
+			addRecord(obs.TrackID, obs.FrameNum, obs.BoundingBox, obs.Confidence)
+		}
+		for _, obj := range result.TrackedObjects {
+			if obj.LostFrames > 0 {
+				continue
+			}
+			addRecord(obj.TrackID, result.FrameNum, obj.BoundingBox, obj.Confidence)
+		}
+	}
+
+	// Released observations belong to earlier frames
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Frame < records[j].Frame })
+	return records
+}
+
+// DetectionsFromMOT groups MOT detection records by frame for a ReplayDetector
+// All records are treated as the given class (MOT detection files carry no class)
+func DetectionsFromMOT(records []MOTRecord, class ObjectClass) map[int][]Detection {
+	frames := make(map[int][]Detection)
+	for _, record := range records {
+		frames[record.Frame] = append(frames[record.Frame], Detection{
+			Class:       class,
+			Confidence:  record.Confidence,
+			BoundingBox: record.BoundingBox,
+		})
+	}
+
+	for frameNum, detections := range frames {
+		frames[frameNum] = sanitizeDetections(detections)
+	}
+	return frames
+}
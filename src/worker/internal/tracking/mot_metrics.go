@@ -0,0 +1,271 @@
+package tracking
+
+import "sort"
+
+// MOTMetrics holds CLEAR-MOT and identity metrics for a tracked sequence
+type MOTMetrics struct {
+	Frames         int     `json:"frames"`
+	GroundTruth    int     `json:"groundTruth"` // Ground-truth boxes
+	Hypotheses     int     `json:"hypotheses"`  // Tracker boxes
+	Matches        int     `json:"matches"`     // True positives
+	FalsePositives int     `json:"falsePositives"`
+	FalseNegatives int     `json:"falseNegatives"`
+	IDSwitches     int     `json:"idSwitches"`
+	Fragmentations int     `json:"fragmentations"`
+	MOTA           float64 `json:"mota"` // 1 - (FN + FP + IDSW) / GT
+	MOTP           float64 `json:"motp"` // Mean IoU of matched pairs
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	IDTP           int     `json:"idtp"`
+	IDF1           float64 `json:"idf1"`
+	IDP            float64 `json:"idp"`
+	IDR            float64 `json:"idr"`
+	GroundTruthIDs int     `json:"groundTruthIds"`
+	HypothesisIDs  int     `json:"hypothesisIds"`
+	IoUThreshold   float64 `json:"iouThreshold"`
+}
+
+// EvaluateMOT scores tracker output against ground truth
+// Boxes match when IoU >= iouThreshold (0.5 is the MOTChallenge convention).
+// Per-frame correspondence follows CLEAR-MOT: matches from the previous frame are kept while
+// still valid, remaining pairs are assigned with the Hungarian algorithm. IDF1 uses a single
+// global ground-truth-to-hypothesis identity assignment maximising matched boxes.
+func EvaluateMOT(groundTruth, hypotheses []MOTRecord, iouThreshold float64) MOTMetrics {
+	metrics := MOTMetrics{
+		GroundTruth:  len(groundTruth),
+		Hypotheses:   len(hypotheses),
+		IoUThreshold: iouThreshold,
+	}
+
+	gtByFrame := groupMOTByFrame(groundTruth)
+	hypByFrame := groupMOTByFrame(hypotheses)
+
+	frameSet := make(map[int]bool)
+	for frame := range gtByFrame {
+		frameSet[frame] = true
+	}
+	for frame := range hypByFrame {
+		frameSet[frame] = true
+	}
+	frames := make([]int, 0, len(frameSet))
+	for frame := range frameSet {
+		frames = append(frames, frame)
+	}
+	sort.Ints(frames)
+	metrics.Frames = len(frames)
+
+	previous := make(map[int]int)      // gt ID -> hyp ID matched in the previous frame it was present
+	lastMatched := make(map[int]int)   // gt ID -> last hyp ID ever matched (for ID switches)
+	tracked := make(map[int][]bool)    // gt ID -> tracked flag per frame of presence
+	pairCounts := make(map[[2]int]int) // (gt ID, hyp ID) -> frames with IoU >= threshold
+	gtCounts := make(map[int]int)
+	hypCounts := make(map[int]int)
+	iouSum := 0.0
+
+	for _, frame := range frames {
+		gts := gtByFrame[frame]
+		hyps := hypByFrame[frame]
+
+		for _, gt := range gts {
+			gtCounts[gt.ID]++
+		}
+		for _, hyp := range hyps {
+			hypCounts[hyp.ID]++
+		}
+
+		// Pairwise overlaps (also feeds the identity assignment)
+		iou := make([][]float64, len(gts))
+		for i, gt := range gts {
+			iou[i] = make([]float64, len(hyps))
+			for j, hyp := range hyps {
+				iou[i][j] = computeIOU(gt.BoundingBox, hyp.BoundingBox)
+				if iou[i][j] >= iouThreshold {
+					pairCounts[[2]int{gt.ID, hyp.ID}]++
+				}
+			}
+		}
+
+		gtMatch := make([]int, len(gts))
+		for i := range gtMatch {
+			gtMatch[i] = -1
+		}
+		hypUsed := make([]bool, len(hyps))
+
+		// Keep last frame's correspondences that are still valid
+		for i, gt := range gts {
+			prevHyp, exists := previous[gt.ID]
+			if !exists {
+				continue
+			}
+			for j, hyp := range hyps {
+				if hyp.ID == prevHyp && !hypUsed[j] && iou[i][j] >= iouThreshold {
+					gtMatch[i] = j
+					hypUsed[j] = true
+					break
+				}
+			}
+		}
+
+		// Assign the rest by minimum distance
+		freeGT := make([]int, 0)
+		for i := range gts {
+			if gtMatch[i] < 0 {
+				freeGT = append(freeGT, i)
+			}
+		}
+		freeHyp := make([]int, 0)
+		for j := range hyps {
+			if !hypUsed[j] {
+				freeHyp = append(freeHyp, j)
+			}
+		}
+		if len(freeGT) > 0 && len(freeHyp) > 0 {
+			cost := make([][]float64, len(freeGT))
+			for a, i := range freeGT {
+				cost[a] = make([]float64, len(freeHyp))
+				for b, j := range freeHyp {
+					if iou[i][j] >= iouThreshold {
+						cost[a][b] = 1 - iou[i][j]
+					} else {
+						cost[a][b] = forbiddenCost
+					}
+				}
+			}
+			for a, b := range hungarianAssign(cost) {
+				if b >= 0 {
+					gtMatch[freeGT[a]] = freeHyp[b]
+					hypUsed[freeHyp[b]] = true
+				}
+			}
+		}
+
+		// Count matches, misses and identity switches
+		for i, gt := range gts {
+			j := gtMatch[i]
+			if j < 0 {
+				metrics.FalseNegatives++
+				delete(previous, gt.ID)
+				tracked[gt.ID] = append(tracked[gt.ID], false)
+				continue
+			}
+
+			hypID := hyps[j].ID
+			metrics.Matches++
+			iouSum += iou[i][j]
+			if last, exists := lastMatched[gt.ID]; exists && last != hypID {
+				metrics.IDSwitches++
+			}
+			lastMatched[gt.ID] = hypID
+			previous[gt.ID] = hypID
+			tracked[gt.ID] = append(tracked[gt.ID], true)
+		}
+
+		for j := range hyps {
+			if !hypUsed[j] {
+				metrics.FalsePositives++
+			}
+		}
+	}
+
+	for _, flags := range tracked {
+		metrics.Fragmentations += countFragmentations(flags)
+	}
+
+	if metrics.GroundTruth > 0 {
+		metrics.MOTA = 1 - float64(metrics.FalseNegatives+metrics.FalsePositives+metrics.IDSwitches)/float64(metrics.GroundTruth)
+		metrics.Recall = float64(metrics.Matches) / float64(metrics.GroundTruth)
+	}
+	if metrics.Hypotheses > 0 {
+		metrics.Precision = float64(metrics.Matches) / float64(metrics.Hypotheses)
+	}
+	if metrics.Matches > 0 {
+		metrics.MOTP = iouSum / float64(metrics.Matches)
+	}
+
+	metrics.GroundTruthIDs = len(gtCounts)
+	metrics.HypothesisIDs = len(hypCounts)
+	metrics.IDTP = identityTruePositives(gtCounts, hypCounts, pairCounts)
+	if metrics.GroundTruth+metrics.Hypotheses > 0 {
+		metrics.IDF1 = 2 * float64(metrics.IDTP) / float64(metrics.GroundTruth+metrics.Hypotheses)
+	}
+	if metrics.Hypotheses > 0 {
+		metrics.IDP = float64(metrics.IDTP) / float64(metrics.Hypotheses)
+	}
+	if metrics.GroundTruth > 0 {
+		metrics.IDR = float64(metrics.IDTP) / float64(metrics.GroundTruth)
+	}
+
+	return metrics
+}
+
+// groupMOTByFrame groups records by frame, ordered by ID within a frame
+func groupMOTByFrame(records []MOTRecord) map[int][]MOTRecord {
+	frames := make(map[int][]MOTRecord)
+	for _, record := range records {
+		frames[record.Frame] = append(frames[record.Frame], record)
+	}
+	for _, frameRecords := range frames {
+		sort.SliceStable(frameRecords, func(i, j int) bool { return frameRecords[i].ID < frameRecords[j].ID })
+	}
+	return frames
+}
+
+// countFragmentations counts interruptions of a ground-truth trajectory between its first
+// and last tracked frames (tracked -> untracked transitions)
+func countFragmentations(flags []bool) int {
+	first, last := -1, -1
+	for i, isTracked := range flags {
+		if isTracked {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return 0
+	}
+
+	fragments := 0
+	for i := first + 1; i <= last; i++ {
+		if flags[i-1] && !flags[i] {
+			fragments++
+		}
+	}
+	return fragments
+}
+
+// identityTruePositives finds the one-to-one identity assignment maximising matched boxes
+func identityTruePositives(gtCounts, hypCounts map[int]int, pairCounts map[[2]int]int) int {
+	gtIDs := sortedKeys(gtCounts)
+	hypIDs := sortedKeys(hypCounts)
+	if len(gtIDs) == 0 || len(hypIDs) == 0 {
+		return 0
+	}
+
+	cost := make([][]float64, len(gtIDs))
+	for i, gtID := range gtIDs {
+		cost[i] = make([]float64, len(hypIDs))
+		for j, hypID := range hypIDs {
+			cost[i][j] = -float64(pairCounts[[2]int{gtID, hypID}])
+		}
+	}
+
+	idtp := 0
+	for i, j := range hungarianAssign(cost) {
+		if j >= 0 {
+			idtp += pairCounts[[2]int{gtIDs[i], hypIDs[j]}]
+		}
+	}
+	return idtp
+}
+
+// sortedKeys returns map keys in ascending order
+func sortedKeys(m map[int]int) []int {
+	keys := make([]int, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,194 @@
+package tracking
+
+import (
+	"math"
+	"testing"
+)
+
+// box returns a 0.1 x 0.1 MOT record at (x, 0)
+func box(frame, id int, x float64) MOTRecord {
+	return MOTRecord{
+		Frame:       frame,
+		ID:          id,
+		BoundingBox: BoundingBox{X: x, Y: 0, Width: 0.1, Height: 0.1},
+		Confidence:  1,
+		Class:       -1,
+		Visibility:  -1,
+	}
+}
+
+func TestEvaluateMOT(t *testing.T) {
+	tests := []struct {
+		name        string
+		groundTruth []MOTRecord
+		hypotheses  []MOTRecord
+		want        MOTMetrics
+	}{
+		{
+			name:        "empty",
+			groundTruth: nil,
+			hypotheses:  nil,
+			want:        MOTMetrics{},
+		},
+		{
+			name:        "perfect tracking under another ID",
+			groundTruth: []MOTRecord{box(1, 1, 0.1), box(2, 1, 0.1), box(3, 1, 0.1)},
+			hypotheses:  []MOTRecord{box(1, 7, 0.1), box(2, 7, 0.1), box(3, 7, 0.1)},
+			want: MOTMetrics{
+				Frames: 3, GroundTruth: 3, Hypotheses: 3, Matches: 3,
+				MOTA: 1, MOTP: 1, Precision: 1, Recall: 1,
+				IDTP: 3, IDF1: 1, IDP: 1, IDR: 1, GroundTruthIDs: 1, HypothesisIDs: 1,
+			},
+		},
+		{
+			// Frame 3: the box is missed and a false positive appears elsewhere
+			name:        "miss and false positive",
+			groundTruth: []MOTRecord{box(1, 1, 0.1), box(2, 1, 0.1), box(3, 1, 0.1), box(4, 1, 0.1)},
+			hypotheses:  []MOTRecord{box(1, 1, 0.1), box(2, 1, 0.1), box(3, 2, 0.8), box(4, 1, 0.1)},
+			want: MOTMetrics{
+				Frames: 4, GroundTruth: 4, Hypotheses: 4, Matches: 3,
+				FalsePositives: 1, FalseNegatives: 1, Fragmentations: 1,
+				MOTA: 0.5, MOTP: 1, Precision: 0.75, Recall: 0.75,
+				IDTP: 3, IDF1: 0.75, IDP: 0.75, IDR: 0.75, GroundTruthIDs: 1, HypothesisIDs: 2,
+			},
+		},
+		{
+			// The track changes ID halfway: one switch, and only one identity counts for IDF1
+			name:        "identity switch",
+			groundTruth: []MOTRecord{box(1, 1, 0.1), box(2, 1, 0.1), box(3, 1, 0.1), box(4, 1, 0.1)},
+			hypotheses:  []MOTRecord{box(1, 1, 0.1), box(2, 1, 0.1), box(3, 2, 0.1), box(4, 2, 0.1)},
+			want: MOTMetrics{
+				Frames: 4, GroundTruth: 4, Hypotheses: 4, Matches: 4, IDSwitches: 1,
+				MOTA: 0.75, MOTP: 1, Precision: 1, Recall: 1,
+				IDTP: 2, IDF1: 0.5, IDP: 0.5, IDR: 0.5, GroundTruthIDs: 1, HypothesisIDs: 2,
+			},
+		},
+		{
+			// Frame 1: shifted by 0.02 (IoU 0.08/0.12 = 2/3, a match)
+			// Frame 2: shifted by 0.05 (IoU 0.05/0.15 = 1/3, a miss plus a false positive)
+			name:        "partial overlap",
+			groundTruth: []MOTRecord{box(1, 1, 0), box(2, 1, 0)},
+			hypotheses:  []MOTRecord{box(1, 1, 0.02), box(2, 1, 0.05)},
+			want: MOTMetrics{
+				Frames: 2, GroundTruth: 2, Hypotheses: 2, Matches: 1,
+				FalsePositives: 1, FalseNegatives: 1,
+				MOTA: 0, MOTP: 2.0 / 3, Precision: 0.5, Recall: 0.5,
+				IDTP: 1, IDF1: 0.5, IDP: 0.5, IDR: 0.5, GroundTruthIDs: 1, HypothesisIDs: 1,
+			},
+		},
+		{
+			// Frame 2: the previous correspondence (IoU 0.09/0.11) is kept over a better new box
+			name:        "correspondence is kept while valid",
+			groundTruth: []MOTRecord{box(1, 1, 0.1), box(2, 1, 0.1)},
+			hypotheses:  []MOTRecord{box(1, 1, 0.1), box(2, 1, 0.11), box(2, 2, 0.1)},
+			want: MOTMetrics{
+				Frames: 2, GroundTruth: 2, Hypotheses: 3, Matches: 2, FalsePositives: 1,
+				MOTA: 0.5, MOTP: (1 + 0.09/0.11) / 2, Precision: 2.0 / 3, Recall: 1,
+				IDTP: 2, IDF1: 0.8, IDP: 2.0 / 3, IDR: 1, GroundTruthIDs: 1, HypothesisIDs: 2,
+			},
+		},
+		{
+			// Two objects whose hypotheses swap IDs in frame 2: each object switches once
+			name:        "swapped identities",
+			groundTruth: []MOTRecord{box(1, 1, 0.1), box(1, 2, 0.5), box(2, 1, 0.1), box(2, 2, 0.5)},
+			hypotheses:  []MOTRecord{box(1, 1, 0.1), box(1, 2, 0.5), box(2, 2, 0.1), box(2, 1, 0.5)},
+			want: MOTMetrics{
+				Frames: 2, GroundTruth: 4, Hypotheses: 4, Matches: 4, IDSwitches: 2,
+				MOTA: 0.5, MOTP: 1, Precision: 1, Recall: 1,
+				IDTP: 2, IDF1: 0.5, IDP: 0.5, IDR: 0.5, GroundTruthIDs: 2, HypothesisIDs: 2,
+			},
+		},
+		{
+			name:        "no hypotheses",
+			groundTruth: []MOTRecord{box(1, 1, 0.1), box(2, 1, 0.1)},
+			hypotheses:  nil,
+			want: MOTMetrics{
+				Frames: 2, GroundTruth: 2, FalseNegatives: 2,
+				MOTA: 0, GroundTruthIDs: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.want.IoUThreshold = 0.5
+			got := EvaluateMOT(tt.groundTruth, tt.hypotheses, 0.5)
+			assertMetrics(t, got, tt.want)
+		})
+	}
+}
+
+func TestEvaluateMOTThreshold(t *testing.T) {
+	groundTruth := []MOTRecord{box(1, 1, 0)}
+	hypotheses := []MOTRecord{box(1, 1, 0.05)} // IoU 1/3
+
+	if got := EvaluateMOT(groundTruth, hypotheses, 0.5); got.Matches != 0 {
+		t.Errorf("IoU threshold 0.5: %d matches, want 0", got.Matches)
+	}
+	if got := EvaluateMOT(groundTruth, hypotheses, 0.3); got.Matches != 1 || got.MOTA != 1 {
+		t.Errorf("IoU threshold 0.3: %d matches, MOTA %v, want 1 match and MOTA 1", got.Matches, got.MOTA)
+	}
+}
+
+func assertMetrics(t *testing.T, got, want MOTMetrics) {
+	t.Helper()
+	ints := []struct {
+		name      string
+		got, want int
+	}{
+		{"Frames", got.Frames, want.Frames},
+		{"GroundTruth", got.GroundTruth, want.GroundTruth},
+		{"Hypotheses", got.Hypotheses, want.Hypotheses},
+		{"Matches", got.Matches, want.Matches},
+		{"FalsePositives", got.FalsePositives, want.FalsePositives},
+		{"FalseNegatives", got.FalseNegatives, want.FalseNegatives},
+		{"IDSwitches", got.IDSwitches, want.IDSwitches},
+		{"Fragmentations", got.Fragmentations, want.Fragmentations},
+		{"IDTP", got.IDTP, want.IDTP},
+		{"GroundTruthIDs", got.GroundTruthIDs, want.GroundTruthIDs},
+		{"HypothesisIDs", got.HypothesisIDs, want.HypothesisIDs},
+	}
+	for _, c := range ints {
+		if c.got != c.want {
+			t.Errorf("%s = %d, want %d", c.name, c.got, c.want)
+		}
+	}
+
+	floats := []struct {
+		name      string
+		got, want float64
+	}{
+		{"MOTA", got.MOTA, want.MOTA},
+		{"MOTP", got.MOTP, want.MOTP},
+		{"Precision", got.Precision, want.Precision},
+		{"Recall", got.Recall, want.Recall},
+		{"IDF1", got.IDF1, want.IDF1},
+		{"IDP", got.IDP, want.IDP},
+		{"IDR", got.IDR, want.IDR},
+		{"IoUThreshold", got.IoUThreshold, want.IoUThreshold},
+	}
+	for _, c := range floats {
+		if math.Abs(c.got-c.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestCountFragmentations(t *testing.T) {
+	tests := []struct {
+		flags []bool
+		want  int
+	}{
+		{flags: nil, want: 0},
+		{flags: []bool{false, false}, want: 0},
+		{flags: []bool{true, true, true}, want: 0},
+		{flags: []bool{false, true, true, false}, want: 0}, // Untracked ends do not count
+		{flags: []bool{true, false, true}, want: 1},
+		{flags: []bool{true, false, false, true, false, true}, want: 2},
+	}
+	for _, tt := range tests {
+		if got := countFragmentations(tt.flags); got != tt.want {
+			t.Errorf("countFragmentations(%v) = %d, want %d", tt.flags, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package tracking
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadMOT(t *testing.T) {
+	input := strings.Join([]string{
+		"# comment lines and blank lines are skipped",
+		"",
+		"1,1,100,200,50,100,1,1,0.75",     // Ground truth: consider flag, class, visibility
+		"1,-1,0,0,100,50,0.42,-1,-1,-1",   // Detection file
+		"2 3 10 20 30 40",                 // Whitespace-separated, no optional columns
+		"3,\t2, 500, 250, 100, 100, 0, 7", // Ignored box of another class
+	}, "\n")
+
+	records, err := ReadMOT(strings.NewReader(input), 1000, 500)
+	if err != nil {
+		t.Fatalf("ReadMOT: %v", err)
+	}
+
+	want := []MOTRecord{
+		{Frame: 1, ID: 1, BoundingBox: BoundingBox{X: 0.1, Y: 0.4, Width: 0.05, Height: 0.2}, Confidence: 1, Class: 1, Visibility: 0.75},
+		{Frame: 1, ID: -1, BoundingBox: BoundingBox{X: 0, Y: 0, Width: 0.1, Height: 0.1}, Confidence: 0.42, Class: -1, Visibility: -1},
+		{Frame: 2, ID: 3, BoundingBox: BoundingBox{X: 0.01, Y: 0.04, Width: 0.03, Height: 0.08}, Confidence: 1, Class: -1, Visibility: -1},
+		{Frame: 3, ID: 2, BoundingBox: BoundingBox{X: 0.5, Y: 0.5, Width: 0.1, Height: 0.2}, Confidence: 0, Class: 7, Visibility: -1},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("ReadMOT() =\n%+v\nwant\n%+v", records, want)
+	}
+}
+
+func TestReadMOTErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		width, height float64
+	}{
+		{name: "missing frame size", input: "1,1,0,0,10,10", width: 0, height: 0},
+		{name: "too few fields", input: "1,1,0,0,10", width: 100, height: 100},
+		{name: "non-numeric field", input: "1,1,0,0,ten,10", width: 100, height: 100},
+		{name: "zero frame", input: "0,1,0,0,10,10", width: 100, height: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadMOT(strings.NewReader(tt.input), tt.width, tt.height); err == nil {
+				t.Error("ReadMOT succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestWriteMOTRoundTrip(t *testing.T) {
+	records := []MOTRecord{
+		{Frame: 2, ID: 1, BoundingBox: BoundingBox{X: 0.1, Y: 0.2, Width: 0.3, Height: 0.4}, Confidence: 0.876},
+		{Frame: 1, ID: 2, BoundingBox: BoundingBox{X: 0.5, Y: 0.5, Width: 0.25, Height: 0.125}, Confidence: 1},
+		{Frame: 1, ID: 1, BoundingBox: BoundingBox{X: 0.001234, Y: 0, Width: 0.1, Height: 0.1}, Confidence: 0.5},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMOT(&buf, records, 1000, 800); err != nil {
+		t.Fatalf("WriteMOT: %v", err)
+	}
+
+	// Sorted by frame then ID, pixels rounded to two decimals
+	want := "1,1,1.23,0,100,80,0.5,-1,-1,-1\n" +
+		"1,2,500,400,250,100,1,-1,-1,-1\n" +
+		"2,1,100,160,300,320,0.88,-1,-1,-1\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteMOT() =\n%s\nwant\n%s", got, want)
+	}
+
+	parsed, err := ReadMOT(strings.NewReader(buf.String()), 1000, 800)
+	if err != nil {
+		t.Fatalf("ReadMOT: %v", err)
+	}
+	if len(parsed) != 3 || parsed[1].BoundingBox != records[1].BoundingBox {
+		t.Errorf("round trip lost data: %+v", parsed)
+	}
+
+	if err := WriteMOT(&buf, records, 0, 0); err == nil {
+		t.Error("WriteMOT succeeded without a frame size, want an error")
+	}
+}
+
+func TestFilterGroundTruth(t *testing.T) {
+	records := []MOTRecord{
+		{Frame: 1, ID: 1, Confidence: 1, Class: 1},
+		{Frame: 1, ID: 2, Confidence: 0, Class: 1}, // Ignored
+		{Frame: 1, ID: 3, Confidence: 1, Class: 7}, // Static person (MOT17)
+		{Frame: 1, ID: 4, Confidence: 1, Class: -1},
+	}
+
+	ids := func(records []MOTRecord) []int {
+		out := make([]int, 0, len(records))
+		for _, record := range records {
+			out = append(out, record.ID)
+		}
+		return out
+	}
+
+	if got := ids(FilterGroundTruth(records)); !reflect.DeepEqual(got, []int{1, 3, 4}) {
+		t.Errorf("without classes kept %v, want [1 3 4]", got)
+	}
+	if got := ids(FilterGroundTruth(records, 1)); !reflect.DeepEqual(got, []int{1, 4}) {
+		t.Errorf("class 1 kept %v, want [1 4]", got)
+	}
+}
+
+func TestDetectionsFromMOT(t *testing.T) {
+	records := []MOTRecord{
+		{Frame: 1, ID: -1, BoundingBox: BoundingBox{X: 0.1, Y: 0.1, Width: 0.1, Height: 0.2}, Confidence: 0.9},
+		{Frame: 1, ID: -1, BoundingBox: BoundingBox{X: 0.5, Y: 0.1, Width: 0.1, Height: 0.2}, Confidence: 0.4},
+		{Frame: 3, ID: -1, BoundingBox: BoundingBox{X: 0.2, Y: 0.2, Width: 0.1, Height: 0.2}, Confidence: 0.7},
+	}
+
+	frames := DetectionsFromMOT(records, ClassPerson)
+	if len(frames[1]) != 2 || len(frames[2]) != 0 || len(frames[3]) != 1 {
+		t.Fatalf("grouped %d/%d/%d detections, want 2/0/1", len(frames[1]), len(frames[2]), len(frames[3]))
+	}
+	if det := frames[3][0]; det.Class != ClassPerson || det.Confidence != 0.7 {
+		t.Errorf("frame 3 detection = %+v, want a person at 0.7", det)
+	}
+}
@@ -5,20 +5,29 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/adverant/nexus/videoagent-worker/internal/clients"
-	"github.com/adverant/nexus/videoagent-worker/internal/models"
 )
 
 // TrackerType represents the tracking algorithm
 type TrackerType string
 
 const (
-	TrackerDeepSORT TrackerType = "deepsort"
-	TrackerByteTrack TrackerType = "bytetrack"
-	TrackerSimpleIOU TrackerType = "simple_iou"
+	TrackerDeepSORT TrackerType = "deepsort"   // Kalman + Hungarian on IoU and appearance cost
+	TrackerByteTrack TrackerType = "bytetrack" // Kalman + Hungarian on IoU, second pass for low-confidence detections
+	TrackerSimpleIOU TrackerType = "simple_iou" // Greedy IoU matching, no motion model
+)
+
+// TrackStatus represents the lifecycle state of a track
+type TrackStatus string
+
+const (
+	TrackTentative TrackStatus = "tentative" // Not yet matched in enough frames to report
+	TrackConfirmed TrackStatus = "confirmed" // Matched in the current frame
+	TrackLost      TrackStatus = "lost"      // Confirmed but unmatched (coasting on prediction)
 )
 
 // ObjectClass represents the class of tracked object
@@ -51,6 +60,8 @@ type TrackedObject struct {
 	LastSeen      time.Time              `json:"lastSeen"`      // Last detection time
 	FrameCount    int                    `json:"frameCount"`    // Frames tracked
 	Lost          bool                   `json:"lost"`          // Track lost flag
+	Status        TrackStatus            `json:"status"`        // Lifecycle state
+	Hits          int                    `json:"hits"`          // Frames matched to a detection
 	LostFrames    int                    `json:"lostFrames"`    // Consecutive lost frames
 	Features      []float64              `json:"features"`      // Appearance features for re-ID
 	Trajectory    []TrajectoryPoint      `json:"trajectory"`    // Movement trajectory
@@ -94,11 +105,24 @@ type Detection struct {
 	Attributes  map[string]interface{} `json:"attributes"`
 }
 
+// TrackObservation is a detection matched to a track in an earlier frame
+type TrackObservation struct {
+	TrackID     string      `json:"trackId"`
+	Class       ObjectClass `json:"class"`
+	FrameNum    int         `json:"frameNum"`
+	Timestamp   time.Time   `json:"timestamp"`
+	BoundingBox BoundingBox `json:"boundingBox"`
+	Confidence  float64     `json:"confidence"`
+}
+
 // TrackingResult represents tracking results for a frame
 type TrackingResult struct {
 	FrameNum      int                    `json:"frameNum"`
 	Timestamp     time.Time              `json:"timestamp"`
 	TrackedObjects []TrackedObject       `json:"trackedObjects"`
+	// Observations from before confirmation of tracks confirmed in this frame
+	// (motion-model trackers only report a track once it has been matched minHits times)
+	ConfirmedObservations []TrackObservation `json:"confirmedObservations,omitempty"`
 	NewTracks     int                    `json:"newTracks"`
 	LostTracks    int                    `json:"lostTracks"`
 	ActiveTracks  int                    `json:"activeTracks"`
@@ -118,34 +142,76 @@ type TrackingStatistics struct {
 // MultiObjectTracker tracks multiple objects across video frames
 type MultiObjectTracker struct {
 	trackerType    TrackerType
-	mageAgent      *clients.MageAgentClient
+	detector       Detector
 	tracks         map[string]*TrackedObject // Active tracks by ID
+	filters        map[string]*boxKalman     // Motion model per track (bytetrack/deepsort)
+	pending        map[string][]TrackObservation // Observations of tentative tracks
+	confirmed      []TrackObservation            // Pending observations released in the current frame
 	nextTrackID    int
 	frameNum       int
 	maxLostFrames  int     // Max frames before track is removed
 	iouThreshold   float64 // IOU threshold for matching
 	confidenceThreshold float64 // Min confidence for detections
+	lowConfidenceThreshold float64 // Min confidence for second-pass detections (bytetrack)
+	secondPassIoU  float64 // IOU threshold for second-pass matching
+	minHits        int     // Matches before a tentative track is confirmed
+	appearanceWeight float64 // Weight of appearance distance in matching cost (deepsort)
 	maxTracks      int     // Max simultaneous tracks
 	mu             sync.RWMutex
 }
 
-// NewMultiObjectTracker creates a new multi-object tracker
+// NewMultiObjectTracker creates a new multi-object tracker that detects objects via MageAgent
 func NewMultiObjectTracker(trackerType TrackerType, mageAgent *clients.MageAgentClient) *MultiObjectTracker {
+	return NewMultiObjectTrackerWithDetector(trackerType, NewMageAgentDetector(mageAgent))
+}
+
+// NewMultiObjectTrackerWithDetector creates a new multi-object tracker using the given detector
+func NewMultiObjectTrackerWithDetector(trackerType TrackerType, detector Detector) *MultiObjectTracker {
 	return &MultiObjectTracker{
 		trackerType:         trackerType,
-		mageAgent:           mageAgent,
+		detector:            detector,
 		tracks:              make(map[string]*TrackedObject),
+		filters:             make(map[string]*boxKalman),
+		pending:             make(map[string][]TrackObservation),
 		nextTrackID:         1,
 		frameNum:            0,
-		maxLostFrames:       30,  // 1 second at 30fps
+		maxLostFrames:       30,  // 1 second at 30fps (see SetFrameRate)
 		iouThreshold:        0.3,  // Standard IOU threshold
 		confidenceThreshold: 0.5,  // Min 50% confidence
+		lowConfidenceThreshold: 0.1, // ByteTrack low-score floor
+		secondPassIoU:       0.2,  // Looser gate: low-score detections are often partly occluded boxes
+		minHits:             3,    // Confirm after 3 matches
+		appearanceWeight:    0.5,  // Equal IoU/appearance weighting
 		maxTracks:           100,  // Max 100 simultaneous tracks
 	}
 }
 
+// maxLostSeconds is how long a lost track is kept before it is removed
+const maxLostSeconds = 1.0
+
+// SetFrameRate sets the rate frames are passed to the tracker at (frames per second)
+// so lost tracks are kept for maxLostSeconds of video rather than a fixed frame count
+func (mot *MultiObjectTracker) SetFrameRate(fps float64) {
+	if fps <= 0 {
+		return
+	}
+
+	mot.mu.Lock()
+	defer mot.mu.Unlock()
+	mot.maxLostFrames = int(math.Ceil(maxLostSeconds * fps))
+	if mot.maxLostFrames < 1 {
+		mot.maxLostFrames = 1
+	}
+}
+
 // Track processes a frame and updates object tracking
 func (mot *MultiObjectTracker) Track(ctx context.Context, frameData string) (*TrackingResult, error) {
+	return mot.TrackAt(ctx, frameData, time.Now())
+}
+
+// TrackAt processes a frame captured at the given time (video time for offline processing)
+// Trajectory timestamps use this time so velocities are measured in video seconds
+func (mot *MultiObjectTracker) TrackAt(ctx context.Context, frameData string, frameTime time.Time) (*TrackingResult, error) {
 	startTime := time.Now()
 	mot.mu.Lock()
 	mot.frameNum++
@@ -153,49 +219,34 @@ func (mot *MultiObjectTracker) Track(ctx context.Context, frameData string) (*Tr
 	mot.mu.Unlock()
 
 	// Detect objects in frame
-	detections, err := mot.detectObjects(ctx, frameData)
+	detections, err := mot.detectObjects(ctx, DetectionFrame{
+		Number: currentFrame,
+		Time:   frameTime,
+		Data:   frameData,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("object detection failed: %w", err)
 	}
 
-	// Match detections to existing tracks
 	mot.mu.Lock()
 	defer mot.mu.Unlock()
 
-	matches, unmatchedDetections, unmatchedTracks := mot.matchDetectionsToTracks(detections)
-
-	// Update matched tracks
-	for trackID, detection := range matches {
-		mot.updateTrack(trackID, detection, currentFrame)
-	}
-
-	// Create new tracks for unmatched detections
-	newTracks := 0
-	for _, detection := range unmatchedDetections {
-		if len(mot.tracks) < mot.maxTracks {
-			mot.createTrack(detection, currentFrame)
-			newTracks++
-		}
-	}
-
-	// Mark unmatched tracks as lost
-	lostTracks := 0
-	for _, trackID := range unmatchedTracks {
-		track := mot.tracks[trackID]
-		track.LostFrames++
-		if track.LostFrames > mot.maxLostFrames {
-			track.Lost = true
-			lostTracks++
-		}
+	mot.confirmed = nil
+	var newTracks, lostTracks int
+	if mot.usesMotionModel() {
+		newTracks, lostTracks = mot.associateWithMotion(detections, currentFrame, frameTime)
+	} else {
+		newTracks, lostTracks = mot.associateGreedy(detections, currentFrame, frameTime)
 	}
 
 	// Remove lost tracks
 	mot.removeLostTracks()
 
-	// Collect active tracks
+	// Collect reportable tracks (confirmed, in creation order)
 	activeTracks := make([]TrackedObject, 0, len(mot.tracks))
-	for _, track := range mot.tracks {
-		if !track.Lost {
+	for _, trackID := range mot.orderedTrackIDs() {
+		track := mot.tracks[trackID]
+		if !track.Lost && track.Status == TrackConfirmed {
 			activeTracks = append(activeTracks, *track)
 		}
 	}
@@ -205,14 +256,16 @@ func (mot *MultiObjectTracker) Track(ctx context.Context, frameData string) (*Tr
 
 	result := &TrackingResult{
 		FrameNum:       currentFrame,
-		Timestamp:      startTime,
+		Timestamp:      frameTime,
 		TrackedObjects: activeTracks,
+		ConfirmedObservations: mot.confirmed,
 		NewTracks:      newTracks,
 		LostTracks:     lostTracks,
 		ActiveTracks:   len(activeTracks),
 		Statistics:     stats,
 		Attributes: map[string]interface{}{
 			"tracker_type": string(mot.trackerType),
+			"detector": mot.detector.Name(),
 			"total_detections": len(detections),
 		},
 	}
@@ -223,47 +276,59 @@ func (mot *MultiObjectTracker) Track(ctx context.Context, frameData string) (*Tr
 	return result, nil
 }
 
-// detectObjects detects objects in a frame using MageAgent
-func (mot *MultiObjectTracker) detectObjects(ctx context.Context, frameData string) ([]Detection, error) {
-	prompt := `Detect all people, vehicles, animals, and significant objects in this video frame.
-For each detected object, provide:
-1. Class: person, vehicle, animal, or object
-2. Confidence: 0.0-1.0
-3. Bounding box: x, y, width, height (normalized 0-1, from top-left)
-4. Attributes: color, size, pose, motion state
-
-Respond with JSON array:
-[
-  {
-    "class": "person|vehicle|animal|object",
-    "confidence": 0.0-1.0,
-    "boundingBox": {"x": 0-1, "y": 0-1, "width": 0-1, "height": 0-1},
-    "attributes": {"color": "...", "size": "small|medium|large", "pose": "...", "motion": "stationary|moving"}
-  }
-]`
-
-	visionReq := models.MageAgentVisionRequest{
-		Image:     frameData,
-		Prompt:    prompt,
-		MaxTokens: 1000,
-	}
-
-	visionResp, err := mot.mageAgent.AnalyzeFrame(ctx, visionReq)
-	if err != nil {
-		return nil, fmt.Errorf("vision analysis failed: %w", err)
+// usesMotionModel reports whether the tracker predicts tracks with a Kalman filter
+func (mot *MultiObjectTracker) usesMotionModel() bool {
+	return mot.trackerType == TrackerByteTrack || mot.trackerType == TrackerDeepSORT
+}
+
+// associateGreedy matches detections to tracks greedily by IOU (simple_iou)
+func (mot *MultiObjectTracker) associateGreedy(detections []Detection, currentFrame int, frameTime time.Time) (int, int) {
+	matches, unmatchedDetections, unmatchedTracks := mot.matchDetectionsToTracks(detections)
+
+	// Update matched tracks
+	for trackID, detection := range matches {
+		mot.updateTrack(trackID, detection, currentFrame, frameTime)
+	}
+
+	// Create new tracks for unmatched detections
+	newTracks := 0
+	for _, detection := range unmatchedDetections {
+		if len(mot.tracks) < mot.maxTracks {
+			mot.createTrack(detection, currentFrame, frameTime)
+			newTracks++
+		}
 	}
 
-	// Parse detections
-	detections, err := mot.parseDetections(visionResp.Description)
+	// Mark unmatched tracks as lost
+	lostTracks := 0
+	for _, trackID := range unmatchedTracks {
+		track := mot.tracks[trackID]
+		track.LostFrames++
+		if track.LostFrames > mot.maxLostFrames {
+			track.Lost = true
+			lostTracks++
+		}
+	}
+
+	return newTracks, lostTracks
+}
+
+// detectObjects runs the detector and applies the tracker's confidence thresholds
+func (mot *MultiObjectTracker) detectObjects(ctx context.Context, frame DetectionFrame) ([]Detection, error) {
+	detections, err := mot.detector.Detect(ctx, frame)
 	if err != nil {
-		log.Printf("Failed to parse detections: %v", err)
-		return []Detection{}, nil // Return empty instead of error
+		return nil, err
+	}
+
+	// Filter by confidence threshold (ByteTrack keeps low-score detections for its second pass)
+	minConfidence := mot.confidenceThreshold
+	if mot.trackerType == TrackerByteTrack {
+		minConfidence = mot.lowConfidenceThreshold
 	}
 
-	// Filter by confidence threshold
 	filtered := make([]Detection, 0)
 	for _, det := range detections {
-		if det.Confidence >= mot.confidenceThreshold {
+		if det.Confidence >= minConfidence {
 			filtered = append(filtered, det)
 		}
 	}
@@ -271,13 +336,6 @@ Respond with JSON array:
 	return filtered, nil
 }
 
-// parseDetections parses AI response into Detection objects
-func (mot *MultiObjectTracker) parseDetections(response string) ([]Detection, error) {
-	// TODO: Implement JSON parsing from response
-	// For now, return empty array
-	return []Detection{}, nil
-}
-
 // matchDetectionsToTracks matches current detections to existing tracks
 func (mot *MultiObjectTracker) matchDetectionsToTracks(detections []Detection) (
 	matches map[string]Detection,
@@ -288,10 +346,11 @@ func (mot *MultiObjectTracker) matchDetectionsToTracks(detections []Detection) (
 	unmatchedDetections = make([]Detection, 0)
 	unmatchedTracks = make([]string, 0)
 
-	// Simple greedy matching based on IOU and class
+	// Simple greedy matching based on IOU and class (tracks in creation order for determinism)
 	usedDetections := make(map[int]bool)
 
-	for trackID, track := range mot.tracks {
+	for _, trackID := range mot.orderedTrackIDs() {
+		track := mot.tracks[trackID]
 		if track.Lost {
 			continue
 		}
@@ -335,8 +394,235 @@ func (mot *MultiObjectTracker) matchDetectionsToTracks(detections []Detection) (
 	return matches, unmatchedDetections, unmatchedTracks
 }
 
+// trackMatch pairs a track with a detection index
+type trackMatch struct {
+	trackID   string
+	detection int
+}
+
+// associateWithMotion runs SORT/ByteTrack-style association (bytetrack, deepsort)
+// 1. Predict every track with its Kalman filter
+// 2. Hungarian-match high-confidence detections to all tracks
+// 3. Hungarian-match low-confidence detections to tracks still unmatched (bytetrack)
+// 4. Drop unmatched tentative tracks, coast unmatched confirmed tracks, start new tentative tracks
+func (mot *MultiObjectTracker) associateWithMotion(detections []Detection, currentFrame int, frameTime time.Time) (int, int) {
+	trackIDs := mot.orderedTrackIDs()
+
+	// Step 1: Predict
+	for _, trackID := range trackIDs {
+		if filter, exists := mot.filters[trackID]; exists {
+			mot.tracks[trackID].BoundingBox = filter.predict()
+		}
+	}
+
+	// Split detections by confidence
+	high := make([]int, 0, len(detections))
+	low := make([]int, 0)
+	for i, det := range detections {
+		if det.Confidence >= mot.confidenceThreshold {
+			high = append(high, i)
+		} else {
+			low = append(low, i)
+		}
+	}
+
+	// Step 2: First pass (appearance only contributes for deepsort)
+	useAppearance := mot.trackerType == TrackerDeepSORT
+	firstMatches, unmatchedTrackIDs, unmatchedHigh := mot.assignDetections(trackIDs, detections, high, mot.iouThreshold, useAppearance)
+
+	// Step 3: Second pass for tracks that were being followed last frame
+	secondCandidates := make([]string, 0, len(unmatchedTrackIDs))
+	remainingTrackIDs := make([]string, 0, len(unmatchedTrackIDs))
+	for _, trackID := range unmatchedTrackIDs {
+		if mot.tracks[trackID].Status == TrackLost {
+			remainingTrackIDs = append(remainingTrackIDs, trackID)
+		} else {
+			secondCandidates = append(secondCandidates, trackID)
+		}
+	}
+
+	secondMatches, stillUnmatched, _ := mot.assignDetections(secondCandidates, detections, low, mot.secondPassIoU, false)
+	remainingTrackIDs = append(remainingTrackIDs, stillUnmatched...)
+
+	// Apply matches
+	for _, match := range append(firstMatches, secondMatches...) {
+		mot.updateTrackWithMotion(match.trackID, detections[match.detection], currentFrame, frameTime)
+	}
+
+	// Step 4: Unmatched tracks
+	lostTracks := 0
+	for _, trackID := range remainingTrackIDs {
+		track := mot.tracks[trackID]
+		if track.Status == TrackTentative {
+			// Never confirmed - most likely a false positive
+			track.Lost = true
+			continue
+		}
+
+		track.Status = TrackLost
+		track.LostFrames++
+		if track.LostFrames > mot.maxLostFrames {
+			track.Lost = true
+			lostTracks++
+		}
+	}
+
+	// New tentative tracks from unmatched high-confidence detections
+	newTracks := 0
+	for _, detIdx := range unmatchedHigh {
+		if len(mot.tracks) < mot.maxTracks {
+			mot.createTrack(detections[detIdx], currentFrame, frameTime)
+			newTracks++
+		}
+	}
+
+	return newTracks, lostTracks
+}
+
+// assignDetections solves the optimal track/detection assignment for a subset of detections
+// Pairs of different class or IOU below the gate are never matched
+func (mot *MultiObjectTracker) assignDetections(trackIDs []string, detections []Detection, detIndices []int, iouGate float64, useAppearance bool) ([]trackMatch, []string, []int) {
+	matches := make([]trackMatch, 0)
+	if len(trackIDs) == 0 || len(detIndices) == 0 {
+		return matches, trackIDs, detIndices
+	}
+
+	// Build cost matrix (rows: tracks, columns: detections)
+	cost := make([][]float64, len(trackIDs))
+	for i, trackID := range trackIDs {
+		track := mot.tracks[trackID]
+		cost[i] = make([]float64, len(detIndices))
+
+		for j, detIdx := range detIndices {
+			detection := detections[detIdx]
+			iou := computeIOU(track.BoundingBox, detection.BoundingBox)
+			if detection.Class != track.Class || iou < iouGate {
+				cost[i][j] = forbiddenCost
+				continue
+			}
+
+			cost[i][j] = 1.0 - iou
+			if useAppearance && len(track.Features) > 0 && len(track.Features) == len(detection.Features) {
+				appearance := cosineDistance(track.Features, detection.Features)
+				cost[i][j] = (1.0-mot.appearanceWeight)*(1.0-iou) + mot.appearanceWeight*appearance
+			}
+		}
+	}
+
+	assignment := hungarianAssign(cost)
+
+	matchedDetections := make(map[int]bool)
+	unmatchedTracks := make([]string, 0)
+	for i, j := range assignment {
+		if j < 0 {
+			unmatchedTracks = append(unmatchedTracks, trackIDs[i])
+			continue
+		}
+		matches = append(matches, trackMatch{trackID: trackIDs[i], detection: detIndices[j]})
+		matchedDetections[j] = true
+	}
+
+	unmatchedDetections := make([]int, 0)
+	for j, detIdx := range detIndices {
+		if !matchedDetections[j] {
+			unmatchedDetections = append(unmatchedDetections, detIdx)
+		}
+	}
+
+	return matches, unmatchedTracks, unmatchedDetections
+}
+
+// updateTrackWithMotion corrects a track's Kalman filter with a matched detection
+func (mot *MultiObjectTracker) updateTrackWithMotion(trackID string, detection Detection, frameNum int, frameTime time.Time) {
+	track := mot.tracks[trackID]
+	filter := mot.filters[trackID]
+
+	track.BoundingBox = filter.update(detection.BoundingBox)
+
+	vx, vy := filter.velocity()
+	speed := math.Sqrt(vx*vx + vy*vy)
+	track.Velocity = &Velocity{
+		VX:        vx,
+		VY:        vy,
+		Speed:     speed,
+		Direction: math.Atan2(vy, vx) * 180 / math.Pi,
+	}
+
+	if speed < 0.01 {
+		track.State = StateStationary
+	} else {
+		track.State = StateMoving
+	}
+
+	// Smooth appearance features for deepsort matching
+	if len(detection.Features) > 0 {
+		if len(track.Features) == len(detection.Features) {
+			for i := range track.Features {
+				track.Features[i] = 0.9*track.Features[i] + 0.1*detection.Features[i]
+			}
+		} else {
+			track.Features = detection.Features
+		}
+	}
+
+	mot.recordObservation(track, detection, frameNum, frameTime)
+
+	switch {
+	case track.Status == TrackLost:
+		track.Status = TrackConfirmed
+	case track.Status == TrackTentative && track.Hits >= mot.minHits:
+		// Release the observations made while tentative (this frame is reported with the track)
+		track.Status = TrackConfirmed
+		mot.confirmed = append(mot.confirmed, mot.pending[track.TrackID]...)
+		delete(mot.pending, track.TrackID)
+	case track.Status == TrackTentative:
+		mot.pending[track.TrackID] = append(mot.pending[track.TrackID], observationOf(track, frameNum, frameTime))
+	}
+}
+
+// observationOf records a track's current box as an observation
+func observationOf(track *TrackedObject, frameNum int, frameTime time.Time) TrackObservation {
+	return TrackObservation{
+		TrackID:     track.TrackID,
+		Class:       track.Class,
+		FrameNum:    frameNum,
+		Timestamp:   frameTime,
+		BoundingBox: track.BoundingBox,
+		Confidence:  track.Confidence,
+	}
+}
+
+// orderedTrackIDs returns track IDs in creation order ("track_2" before "track_10")
+func (mot *MultiObjectTracker) orderedTrackIDs() []string {
+	ids := make([]string, 0, len(mot.tracks))
+	for trackID := range mot.tracks {
+		ids = append(ids, trackID)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if len(ids[i]) != len(ids[j]) {
+			return len(ids[i]) < len(ids[j])
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// cosineDistance computes 1 - cosine similarity between two feature vectors
+func cosineDistance(a, b []float64) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 1.0
+	}
+	return 1.0 - dot/(math.Sqrt(normA)*math.Sqrt(normB))
+}
+
 // updateTrack updates an existing track with new detection
-func (mot *MultiObjectTracker) updateTrack(trackID string, detection Detection, frameNum int) {
+func (mot *MultiObjectTracker) updateTrack(trackID string, detection Detection, frameNum int, frameTime time.Time) {
 	track := mot.tracks[trackID]
 
 	// Update bounding box and velocity
@@ -363,17 +649,24 @@ func (mot *MultiObjectTracker) updateTrack(trackID string, detection Detection,
 		track.State = StateMoving
 	}
 
+	mot.recordObservation(track, detection, frameNum, frameTime)
+}
+
+// recordObservation applies the bookkeeping shared by all trackers for a matched detection
+// (track.BoundingBox must already hold the new box)
+func (mot *MultiObjectTracker) recordObservation(track *TrackedObject, detection Detection, frameNum int, frameTime time.Time) {
 	// Update confidence and timestamps
 	track.Confidence = detection.Confidence
-	track.LastSeen = time.Now()
+	track.LastSeen = frameTime
 	track.FrameCount++
+	track.Hits++
 	track.LostFrames = 0
 
 	// Add trajectory point
 	track.Trajectory = append(track.Trajectory, TrajectoryPoint{
-		X:         detection.BoundingBox.X + detection.BoundingBox.Width/2,
-		Y:         detection.BoundingBox.Y + detection.BoundingBox.Height/2,
-		Timestamp: time.Now(),
+		X:         track.BoundingBox.X + track.BoundingBox.Width/2,
+		Y:         track.BoundingBox.Y + track.BoundingBox.Height/2,
+		Timestamp: frameTime,
 		FrameNum:  frameNum,
 	})
 
@@ -384,11 +677,17 @@ func (mot *MultiObjectTracker) updateTrack(trackID string, detection Detection,
 }
 
 // createTrack creates a new track from detection
-func (mot *MultiObjectTracker) createTrack(detection Detection, frameNum int) {
+func (mot *MultiObjectTracker) createTrack(detection Detection, frameNum int, frameTime time.Time) {
 	trackID := fmt.Sprintf("track_%d", mot.nextTrackID)
 	mot.nextTrackID++
 
-	now := time.Now()
+	// Re-ID annotates track attributes, so never leave them nil
+	attributes := detection.Attributes
+	if attributes == nil {
+		attributes = make(map[string]interface{})
+	}
+
+	now := frameTime
 	track := &TrackedObject{
 		TrackID:     trackID,
 		Class:       detection.Class,
@@ -399,6 +698,8 @@ func (mot *MultiObjectTracker) createTrack(detection Detection, frameNum int) {
 		LastSeen:    now,
 		FrameCount:  1,
 		Lost:        false,
+		Status:      TrackConfirmed,
+		Hits:        1,
 		LostFrames:  0,
 		Features:    detection.Features,
 		Trajectory: []TrajectoryPoint{{
@@ -408,7 +709,16 @@ func (mot *MultiObjectTracker) createTrack(detection Detection, frameNum int) {
 			FrameNum:  frameNum,
 		}},
 		State:      StateEntering,
-		Attributes: detection.Attributes,
+		Attributes: attributes,
+	}
+
+	// Motion-model trackers report a track only once it has been matched minHits times
+	if mot.usesMotionModel() {
+		mot.filters[trackID] = newBoxKalman(detection.BoundingBox)
+		if mot.minHits > 1 {
+			track.Status = TrackTentative
+			mot.pending[trackID] = []TrackObservation{observationOf(track, frameNum, frameTime)}
+		}
 	}
 
 	mot.tracks[trackID] = track
@@ -417,8 +727,10 @@ func (mot *MultiObjectTracker) createTrack(detection Detection, frameNum int) {
 // removeLostTracks removes tracks that have been lost for too long
 func (mot *MultiObjectTracker) removeLostTracks() {
 	for trackID, track := range mot.tracks {
-		if track.Lost && track.LostFrames > mot.maxLostFrames {
+		if track.Lost {
 			delete(mot.tracks, trackID)
+			delete(mot.filters, trackID)
+			delete(mot.pending, trackID)
 		}
 	}
 }
@@ -460,7 +772,8 @@ func (mot *MultiObjectTracker) GetAllTracks() []TrackedObject {
 	defer mot.mu.RUnlock()
 
 	tracks := make([]TrackedObject, 0, len(mot.tracks))
-	for _, track := range mot.tracks {
+	for _, trackID := range mot.orderedTrackIDs() {
+		track := mot.tracks[trackID]
 		if !track.Lost {
 			tracks = append(tracks, *track)
 		}
@@ -474,6 +787,9 @@ func (mot *MultiObjectTracker) Reset() {
 	defer mot.mu.Unlock()
 
 	mot.tracks = make(map[string]*TrackedObject)
+	mot.filters = make(map[string]*boxKalman)
+	mot.pending = make(map[string][]TrackObservation)
+	mot.confirmed = nil
 	mot.nextTrackID = 1
 	mot.frameNum = 0
 }
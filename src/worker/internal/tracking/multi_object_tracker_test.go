@@ -0,0 +1,221 @@
+package tracking
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// runTracker feeds frames 1..frameCount of a replay through a new tracker
+func runTracker(t *testing.T, trackerType TrackerType, frames map[int][]Detection, frameCount int, fps float64) []*TrackingResult {
+	t.Helper()
+	tracker := NewMultiObjectTrackerWithDetector(trackerType, NewReplayDetectorFromFrames(frames))
+	if fps > 0 {
+		tracker.SetFrameRate(fps)
+	}
+
+	epoch := time.Unix(0, 0).UTC()
+	results := make([]*TrackingResult, 0, frameCount)
+	for i := 0; i < frameCount; i++ {
+		result, err := tracker.TrackAt(context.Background(), "", epoch.Add(time.Duration(i)*time.Second/10))
+		if err != nil {
+			t.Fatalf("TrackAt frame %d: %v", i+1, err)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func person(x, confidence float64) Detection {
+	return Detection{
+		Class:       ClassPerson,
+		Confidence:  confidence,
+		BoundingBox: BoundingBox{X: x, Y: 0.3, Width: 0.1, Height: 0.3},
+	}
+}
+
+func reportedIDs(result *TrackingResult) []string {
+	ids := make([]string, 0, len(result.TrackedObjects))
+	for _, obj := range result.TrackedObjects {
+		ids = append(ids, obj.TrackID)
+	}
+	return ids
+}
+
+func TestTrackerConfirmsAfterMinHits(t *testing.T) {
+	frames := map[int][]Detection{}
+	for f := 1; f <= 5; f++ {
+		frames[f] = []Detection{person(0.1+0.01*float64(f), 0.9)}
+	}
+	results := runTracker(t, TrackerByteTrack, frames, 5, 0)
+
+	for f, want := range []int{0, 0, 1, 1, 1} {
+		if got := len(results[f].TrackedObjects); got != want {
+			t.Errorf("frame %d: %d reported tracks, want %d", f+1, got, want)
+		}
+	}
+	if got := results[2].TrackedObjects[0].TrackID; got != "track_1" {
+		t.Errorf("confirmed track ID = %q, want track_1", got)
+	}
+}
+
+func TestTrackerReleasesTentativeObservations(t *testing.T) {
+	frames := map[int][]Detection{
+		1: {person(0.10, 0.9)},
+		2: {person(0.11, 0.8)},
+		3: {person(0.12, 0.9)},
+		4: {person(0.13, 0.9)},
+	}
+	results := runTracker(t, TrackerByteTrack, frames, 4, 0)
+
+	for _, f := range []int{0, 1, 3} {
+		if n := len(results[f].ConfirmedObservations); n != 0 {
+			t.Errorf("frame %d: %d released observations, want 0", f+1, n)
+		}
+	}
+
+	released := results[2].ConfirmedObservations
+	if len(released) != 2 {
+		t.Fatalf("frame 3 released %d observations, want 2", len(released))
+	}
+	for i, obs := range released {
+		if obs.TrackID != "track_1" || obs.FrameNum != i+1 || obs.Class != ClassPerson {
+			t.Errorf("observation %d = %+v, want track_1 at frame %d", i, obs, i+1)
+		}
+	}
+	if released[1].Confidence != 0.8 {
+		t.Errorf("frame 2 confidence = %v, want 0.8", released[1].Confidence)
+	}
+
+	// Every detection ends up in the exported sequence
+	records := MOTRecordsFromResults(results)
+	if len(records) != 4 {
+		t.Fatalf("exported %d MOT records, want 4", len(records))
+	}
+	for i, record := range records {
+		if record.Frame != i+1 || record.ID != 1 {
+			t.Errorf("record %d = frame %d id %d, want frame %d id 1", i, record.Frame, record.ID, i+1)
+		}
+	}
+}
+
+func TestTrackerDropsUnconfirmedTracks(t *testing.T) {
+	frames := map[int][]Detection{
+		1: {person(0.1, 0.9)},
+		2: {person(0.1, 0.9)},
+		// Gone before reaching minHits
+	}
+	results := runTracker(t, TrackerByteTrack, frames, 4, 0)
+
+	for f, result := range results {
+		if len(result.TrackedObjects) != 0 || len(result.ConfirmedObservations) != 0 {
+			t.Errorf("frame %d reported %v, want nothing", f+1, reportedIDs(result))
+		}
+	}
+	if records := MOTRecordsFromResults(results); len(records) != 0 {
+		t.Errorf("exported %d MOT records for a false positive, want 0", len(records))
+	}
+}
+
+func TestTrackerCoastsAndRecoversLostTrack(t *testing.T) {
+	frames := map[int][]Detection{}
+	for _, f := range []int{1, 2, 3, 4, 7} {
+		frames[f] = []Detection{person(0.4, 0.9)}
+	}
+	results := runTracker(t, TrackerByteTrack, frames, 7, 0)
+
+	// Lost tracks coast unreported
+	for _, f := range []int{4, 5} {
+		if ids := reportedIDs(results[f]); len(ids) != 0 {
+			t.Errorf("frame %d reported %v while the track was lost", f+1, ids)
+		}
+	}
+
+	// A recovered track is reported at once under its old ID
+	objs := results[6].TrackedObjects
+	if len(objs) != 1 || objs[0].TrackID != "track_1" || objs[0].Status != TrackConfirmed || results[6].NewTracks != 0 {
+		t.Errorf("frame 7: got %v (%d new), want track_1 recovered", reportedIDs(results[6]), results[6].NewTracks)
+	}
+}
+
+func TestTrackerLostLifetimeScalesWithFrameRate(t *testing.T) {
+	// Seen for 4 frames, then missing for 10 frames and seen again
+	frames := map[int][]Detection{}
+	for _, f := range []int{1, 2, 3, 4, 15} {
+		frames[f] = []Detection{person(0.4, 0.9)}
+	}
+
+	tests := []struct {
+		name   string
+		fps    float64
+		wantID string
+	}{
+		{name: "5fps keeps a track for 5 frames", fps: 5, wantID: "track_2"},
+		{name: "default keeps a track for 30 frames", fps: 0, wantID: "track_1"},
+		{name: "30fps keeps a track for 30 frames", fps: 30, wantID: "track_1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := runTracker(t, TrackerByteTrack, frames, 15, tt.fps)
+			final := results[14]
+
+			// A new track is tentative, so only a recovered track is reported
+			if tt.wantID == "track_1" {
+				if ids := reportedIDs(final); len(ids) != 1 || ids[0] != "track_1" {
+					t.Errorf("frame 15 reported %v, want [track_1]", ids)
+				}
+			} else if ids := reportedIDs(final); len(ids) != 0 || final.NewTracks != 1 {
+				t.Errorf("frame 15 reported %v with %d new tracks, want a new tentative track", ids, final.NewTracks)
+			}
+		})
+	}
+}
+
+func TestTrackerSecondPassMatchesLowConfidence(t *testing.T) {
+	frames := map[int][]Detection{
+		1: {person(0.40, 0.9)},
+		2: {person(0.40, 0.9)},
+		3: {person(0.40, 0.9)},
+		// Partly occluded: low score and shifted (IoU with the prediction ~0.33)
+		4: {person(0.45, 0.3)},
+	}
+	results := runTracker(t, TrackerByteTrack, frames, 4, 0)
+
+	objs := results[3].TrackedObjects
+	if len(objs) != 1 || objs[0].LostFrames != 0 || objs[0].Confidence != 0.3 {
+		t.Errorf("frame 4: got %+v, want track_1 matched to the low-score detection", objs)
+	}
+}
+
+func TestTrackerKeepsIdentitiesOfNeighbours(t *testing.T) {
+	// Two overlapping people moving right; the left detection overlaps both tracks
+	frames := map[int][]Detection{}
+	for f := 1; f <= 3; f++ {
+		frames[f] = []Detection{person(0.30, 0.9), person(0.37, 0.9)}
+	}
+	frames[4] = []Detection{person(0.34, 0.9), person(0.405, 0.9)}
+	results := runTracker(t, TrackerByteTrack, frames, 4, 0)
+
+	objs := results[3].TrackedObjects
+	if len(objs) != 2 {
+		t.Fatalf("frame 4 reported %v, want two tracks", reportedIDs(results[3]))
+	}
+	if objs[0].TrackID != "track_1" || objs[0].BoundingBox.X > objs[1].BoundingBox.X {
+		t.Errorf("frame 4: track_1 at x=%.3f, track_2 at x=%.3f; want track_1 on the left",
+			objs[0].BoundingBox.X, objs[1].BoundingBox.X)
+	}
+}
+
+func TestSimpleIOUTracksImmediately(t *testing.T) {
+	frames := map[int][]Detection{
+		1: {person(0.1, 0.9)},
+		2: {person(0.11, 0.9)},
+	}
+	results := runTracker(t, TrackerSimpleIOU, frames, 2, 0)
+
+	for f, result := range results {
+		if ids := reportedIDs(result); len(ids) != 1 || ids[0] != "track_1" {
+			t.Errorf("frame %d reported %v, want [track_1]", f+1, ids)
+		}
+	}
+}
@@ -0,0 +1,554 @@
+package tracking
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+	"github.com/google/uuid"
+)
+
+// GalleryStore persists gallery identities and their sightings (implemented by storage.StorageManager)
+type GalleryStore interface {
+	ListGalleryIdentities(ctx context.Context, tenantID string) ([]models.GalleryIdentity, error)
+	GetGalleryIdentity(ctx context.Context, tenantID, identityID string) (*models.GalleryIdentity, error)
+	SaveGalleryIdentity(ctx context.Context, identity *models.GalleryIdentity) error
+	DeleteGalleryIdentity(ctx context.Context, tenantID, identityID string) error
+	AddGallerySightings(ctx context.Context, sightings []models.GallerySighting) error
+	ListGallerySightings(ctx context.Context, tenantID, identityID string) ([]models.GallerySighting, error)
+	DeleteGallerySightingsForJob(ctx context.Context, tenantID, jobID string) error
+	MoveGallerySightings(ctx context.Context, tenantID, fromID, toID string, keys []models.SightingKey) (int, error)
+	PruneGallery(ctx context.Context, tenantID string, maxIdentities int, olderThan time.Time) ([]string, error)
+}
+
+// GalleryIndex is a vector index over gallery embeddings (implemented by similarity.QdrantManager)
+type GalleryIndex interface {
+	UpsertPersonIdentity(ctx context.Context, identity *models.GalleryIdentity) error
+	SearchPersonIdentities(ctx context.Context, tenantID string, features []float64, limit int, scoreThreshold float64) ([]models.GalleryMatch, error)
+	DeletePersonIdentity(ctx context.Context, tenantID, identityID string) error
+}
+
+// GalleryCandidate is a video-local identity submitted for gallery resolution
+type GalleryCandidate struct {
+	LocalID    string                   // PersonReID identity ID within the video
+	Features   []float64                // Averaged appearance features
+	Attributes PersonAttributes         // Physical attributes
+	Confidence float64                  // Local identity confidence
+	Sightings  []models.GallerySighting // One per track (tenant and identity are filled in)
+}
+
+// PersonGallery links person identities across a tenant's videos
+// PostgreSQL is the source of truth; the vector index (when set) narrows candidate search.
+// Without an index, or when it finds nothing, every tenant identity is scored exactly,
+// which retention keeps bounded.
+type PersonGallery struct {
+	store              GalleryStore
+	index              GalleryIndex
+	matchThreshold     float64       // Min combined similarity to link to an existing identity
+	candidatesPerQuery int           // Index hits considered per candidate
+	maxIdentities      int           // Retention: identities kept per tenant
+	retention          time.Duration // Retention: drop identities not seen for this long (0 = keep)
+	mu                 sync.Mutex    // Serialises gallery updates within this worker
+}
+
+// NewPersonGallery creates a new persistent re-ID gallery
+func NewPersonGallery(store GalleryStore) *PersonGallery {
+	return &PersonGallery{
+		store:              store,
+		matchThreshold:     0.75, // Stricter than in-video re-ID (different lighting/cameras)
+		candidatesPerQuery: 10,
+		maxIdentities:      10000,
+		retention:          0,
+	}
+}
+
+// SetIndex enables vector-index candidate search
+func (pg *PersonGallery) SetIndex(index GalleryIndex) {
+	pg.index = index
+}
+
+// SetRetention configures per-tenant retention limits (non-positive values disable a limit)
+func (pg *PersonGallery) SetRetention(maxIdentities int, maxAge time.Duration) {
+	pg.maxIdentities = maxIdentities
+	pg.retention = maxAge
+}
+
+// Resolve links a video's identities to gallery identities, creating new ones as needed
+// Returns local identity ID -> gallery identity ID. Identities from the same video are
+// assigned one-to-one (local re-ID already decided they are different people).
+func (pg *PersonGallery) Resolve(ctx context.Context, tenantID, jobID string, candidates []GalleryCandidate) (map[string]string, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	// Step 1: Forget earlier sightings of this job (reprocessing is idempotent)
+	if err := pg.store.DeleteGallerySightingsForJob(ctx, tenantID, jobID); err != nil {
+		return nil, err
+	}
+
+	mapping := make(map[string]string, len(candidates))
+	if len(candidates) == 0 {
+		return mapping, nil
+	}
+
+	// Step 2: Gather gallery identities that could match
+	pool, err := pg.candidatePool(ctx, tenantID, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 3: One-to-one assignment by combined similarity
+	scores := make([][]float64, len(candidates))
+	assignment := make([]int, len(candidates))
+	for i := range assignment {
+		assignment[i] = -1
+	}
+	if len(pool) > 0 {
+		cost := make([][]float64, len(candidates))
+		for i, candidate := range candidates {
+			scores[i] = make([]float64, len(pool))
+			cost[i] = make([]float64, len(pool))
+			for j := range pool {
+				scores[i][j] = pg.matchScore(candidate, &pool[j])
+				if scores[i][j] >= pg.matchThreshold {
+					cost[i][j] = 1 - scores[i][j]
+				} else {
+					cost[i][j] = forbiddenCost
+				}
+			}
+		}
+		assignment = hungarianAssign(cost)
+	}
+
+	// Step 4: Update matched identities and create the rest
+	now := time.Now().UTC()
+	for i, candidate := range candidates {
+		var identity *models.GalleryIdentity
+		matchConfidence := candidate.Confidence
+
+		if j := assignment[i]; j >= 0 {
+			identity = &pool[j]
+			matchConfidence = scores[i][j]
+			if candidate.Confidence > identity.Confidence {
+				identity.Attributes = attributesToMap(candidate.Attributes)
+				identity.Confidence = candidate.Confidence
+			}
+			log.Printf("Gallery: linked %s to %s (similarity %.2f)", candidate.LocalID, identity.IdentityID, matchConfidence)
+		} else {
+			identity = &models.GalleryIdentity{
+				TenantID:   tenantID,
+				IdentityID: uuid.New().String(),
+				Features:   candidate.Features,
+				Attributes: attributesToMap(candidate.Attributes),
+				Confidence: candidate.Confidence,
+				Aliases:    []string{},
+				FirstSeen:  now,
+				LastSeen:   now,
+			}
+			log.Printf("Gallery: new identity %s for %s", identity.IdentityID, candidate.LocalID)
+		}
+
+		if err := pg.store.SaveGalleryIdentity(ctx, identity); err != nil {
+			return nil, err
+		}
+
+		sightings := make([]models.GallerySighting, 0, len(candidate.Sightings))
+		for _, sighting := range candidate.Sightings {
+			sighting.TenantID = tenantID
+			sighting.IdentityID = identity.IdentityID
+			sighting.JobID = jobID
+			sighting.Confidence = matchConfidence
+			sighting.SeenAt = now
+			sightings = append(sightings, sighting)
+		}
+		if err := pg.store.AddGallerySightings(ctx, sightings); err != nil {
+			return nil, err
+		}
+
+		if err := pg.refresh(ctx, identity); err != nil {
+			return nil, err
+		}
+		mapping[candidate.LocalID] = identity.IdentityID
+	}
+
+	// Step 5: Enforce retention
+	if _, err := pg.applyRetention(ctx, tenantID); err != nil {
+		log.Printf("Warning: gallery retention failed for %s: %v", tenantID, err)
+	}
+
+	return mapping, nil
+}
+
+// candidatePool returns gallery identities to score against the candidates
+func (pg *PersonGallery) candidatePool(ctx context.Context, tenantID string, candidates []GalleryCandidate) ([]models.GalleryIdentity, error) {
+	features := make([][]float64, len(candidates))
+	for i, candidate := range candidates {
+		features[i] = candidate.Features
+	}
+	return pg.lookup(ctx, tenantID, features, pg.candidatesPerQuery)
+}
+
+// lookup returns the stored identities the index finds for any of the queries
+// Falls back to every tenant identity when there is no index, the index fails
+// (e.g. features of another dimension) or it finds nothing (not yet backfilled).
+func (pg *PersonGallery) lookup(ctx context.Context, tenantID string, queries [][]float64, limit int) ([]models.GalleryIdentity, error) {
+	if pg.index == nil {
+		return pg.store.ListGalleryIdentities(ctx, tenantID)
+	}
+
+	seen := make(map[string]bool)
+	identities := make([]models.GalleryIdentity, 0)
+	for _, features := range queries {
+		hits, err := pg.index.SearchPersonIdentities(ctx, tenantID, features, limit, 0)
+		if err != nil {
+			// Non-fatal - continue with a full scan
+			log.Printf("Warning: gallery index search failed for %s, scanning all identities: %v", tenantID, err)
+			return pg.store.ListGalleryIdentities(ctx, tenantID)
+		}
+		for _, hit := range hits {
+			if seen[hit.Identity.IdentityID] {
+				continue
+			}
+			seen[hit.Identity.IdentityID] = true
+
+			identity, err := pg.store.GetGalleryIdentity(ctx, tenantID, hit.Identity.IdentityID)
+			if err != nil {
+				return nil, err
+			}
+			if identity != nil {
+				identities = append(identities, *identity)
+			}
+		}
+	}
+
+	if len(identities) == 0 {
+		return pg.store.ListGalleryIdentities(ctx, tenantID)
+	}
+	return identities, nil
+}
+
+// matchScore combines appearance and attribute similarity (as in PersonReID.findBestMatch)
+func (pg *PersonGallery) matchScore(candidate GalleryCandidate, identity *models.GalleryIdentity) float64 {
+	featureSim := featureSimilarity(candidate.Features, identity.Features)
+	attrSim, known := attributeSimilarity(candidate.Attributes, attributesFromMap(identity.Attributes))
+	if !known {
+		return featureSim
+	}
+	return featureSim*0.7 + attrSim*0.3
+}
+
+// refresh recomputes an identity's embedding and time range from its sightings, then saves it
+func (pg *PersonGallery) refresh(ctx context.Context, identity *models.GalleryIdentity) error {
+	sightings, err := pg.store.ListGallerySightings(ctx, identity.TenantID, identity.IdentityID)
+	if err != nil {
+		return err
+	}
+
+	if len(sightings) > 0 {
+		if mean := meanFeatures(sightings); mean != nil {
+			identity.Features = mean
+		}
+		identity.FirstSeen = sightings[0].SeenAt
+		identity.LastSeen = sightings[0].SeenAt
+		for _, sighting := range sightings[1:] {
+			if sighting.SeenAt.Before(identity.FirstSeen) {
+				identity.FirstSeen = sighting.SeenAt
+			}
+			if sighting.SeenAt.After(identity.LastSeen) {
+				identity.LastSeen = sighting.SeenAt
+			}
+		}
+	}
+
+	if err := pg.store.SaveGalleryIdentity(ctx, identity); err != nil {
+		return err
+	}
+
+	if pg.index != nil {
+		if err := pg.index.UpsertPersonIdentity(ctx, identity); err != nil {
+			log.Printf("Warning: failed to index gallery identity %s: %v", identity.IdentityID, err)
+		}
+	}
+	return nil
+}
+
+// Search finds gallery identities whose appearance resembles the given features
+// ("who else appears like this?"); each match includes its sightings
+func (pg *PersonGallery) Search(ctx context.Context, tenantID string, features []float64, limit int) ([]models.GalleryMatch, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	identities, err := pg.lookup(ctx, tenantID, [][]float64{features}, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]models.GalleryMatch, 0, len(identities))
+	for _, identity := range identities {
+		matches = append(matches, models.GalleryMatch{
+			Identity:   identity,
+			Similarity: featureSimilarity(features, identity.Features),
+		})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Similarity != matches[j].Similarity {
+			return matches[i].Similarity > matches[j].Similarity
+		}
+		return matches[i].Identity.IdentityID < matches[j].Identity.IdentityID
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	for i := range matches {
+		sightings, err := pg.store.ListGallerySightings(ctx, tenantID, matches[i].Identity.IdentityID)
+		if err != nil {
+			return nil, err
+		}
+		matches[i].Sightings = sightings
+	}
+
+	return matches, nil
+}
+
+// SearchSimilar finds other gallery identities resembling an existing one
+func (pg *PersonGallery) SearchSimilar(ctx context.Context, tenantID, identityID string, limit int) ([]models.GalleryMatch, error) {
+	identity, err := pg.store.GetGalleryIdentity(ctx, tenantID, identityID)
+	if err != nil {
+		return nil, err
+	}
+	if identity == nil {
+		return nil, fmt.Errorf("gallery identity not found: %s", identityID)
+	}
+
+	matches, err := pg.Search(ctx, tenantID, identity.Features, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	others := make([]models.GalleryMatch, 0, len(matches))
+	for _, match := range matches {
+		if match.Identity.IdentityID != identityID && len(others) < limit {
+			others = append(others, match)
+		}
+	}
+	return others, nil
+}
+
+// Merge folds mergeID into keepID (same person under two gallery identities)
+// Sightings move to keepID, mergeID becomes an alias and the embedding is recomputed
+func (pg *PersonGallery) Merge(ctx context.Context, tenantID, keepID, mergeID string) (*models.GalleryIdentity, error) {
+	if keepID == mergeID {
+		return nil, fmt.Errorf("cannot merge identity %s into itself", keepID)
+	}
+
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	keep, err := pg.store.GetGalleryIdentity(ctx, tenantID, keepID)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := pg.store.GetGalleryIdentity(ctx, tenantID, mergeID)
+	if err != nil {
+		return nil, err
+	}
+	if keep == nil || merged == nil {
+		return nil, fmt.Errorf("one or both identities not found")
+	}
+
+	if _, err := pg.store.MoveGallerySightings(ctx, tenantID, mergeID, keepID, nil); err != nil {
+		return nil, err
+	}
+
+	keep.Aliases = append(keep.Aliases, mergeID)
+	keep.Aliases = append(keep.Aliases, merged.Aliases...)
+	if merged.Confidence > keep.Confidence {
+		keep.Attributes = merged.Attributes
+		keep.Confidence = merged.Confidence
+	}
+
+	if err := pg.deleteIdentity(ctx, tenantID, mergeID); err != nil {
+		return nil, err
+	}
+	if err := pg.refresh(ctx, keep); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Gallery: merged %s into %s", mergeID, keepID)
+	return pg.store.GetGalleryIdentity(ctx, tenantID, keepID)
+}
+
+// Split moves the given sightings of an identity into a new identity (wrongly linked tracks)
+func (pg *PersonGallery) Split(ctx context.Context, tenantID, identityID string, keys []models.SightingKey) (*models.GalleryIdentity, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no sightings to split")
+	}
+
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	source, err := pg.store.GetGalleryIdentity(ctx, tenantID, identityID)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, fmt.Errorf("gallery identity not found: %s", identityID)
+	}
+
+	split := &models.GalleryIdentity{
+		TenantID:   tenantID,
+		IdentityID: uuid.New().String(),
+		Features:   source.Features,
+		Attributes: source.Attributes,
+		Confidence: source.Confidence,
+		Aliases:    []string{},
+		FirstSeen:  source.FirstSeen,
+		LastSeen:   source.LastSeen,
+	}
+	if err := pg.store.SaveGalleryIdentity(ctx, split); err != nil {
+		return nil, err
+	}
+
+	moved, err := pg.store.MoveGallerySightings(ctx, tenantID, identityID, split.IdentityID, keys)
+	if err != nil {
+		pg.store.DeleteGalleryIdentity(ctx, tenantID, split.IdentityID)
+		return nil, err
+	}
+	if moved == 0 {
+		pg.store.DeleteGalleryIdentity(ctx, tenantID, split.IdentityID)
+		return nil, fmt.Errorf("none of the sightings belong to identity %s", identityID)
+	}
+
+	if err := pg.refresh(ctx, split); err != nil {
+		return nil, err
+	}
+
+	// The source keeps its remaining sightings, or disappears if none are left
+	remaining, err := pg.store.ListGallerySightings(ctx, tenantID, identityID)
+	if err != nil {
+		return nil, err
+	}
+	if len(remaining) == 0 {
+		if err := pg.deleteIdentity(ctx, tenantID, identityID); err != nil {
+			return nil, err
+		}
+	} else if err := pg.refresh(ctx, source); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Gallery: split %d sightings of %s into %s", moved, identityID, split.IdentityID)
+	return pg.store.GetGalleryIdentity(ctx, tenantID, split.IdentityID)
+}
+
+// ApplyRetention removes expired and excess identities for a tenant
+func (pg *PersonGallery) ApplyRetention(ctx context.Context, tenantID string) ([]string, error) {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+	return pg.applyRetention(ctx, tenantID)
+}
+
+// applyRetention prunes the store and the index (caller holds the lock)
+func (pg *PersonGallery) applyRetention(ctx context.Context, tenantID string) ([]string, error) {
+	var olderThan time.Time
+	if pg.retention > 0 {
+		olderThan = time.Now().UTC().Add(-pg.retention)
+	}
+
+	removed, err := pg.store.PruneGallery(ctx, tenantID, pg.maxIdentities, olderThan)
+	if err != nil {
+		return nil, err
+	}
+
+	if pg.index != nil {
+		for _, identityID := range removed {
+			if err := pg.index.DeletePersonIdentity(ctx, tenantID, identityID); err != nil {
+				log.Printf("Warning: failed to remove gallery identity %s from index: %v", identityID, err)
+			}
+		}
+	}
+
+	if len(removed) > 0 {
+		log.Printf("Gallery: retention removed %d identities for %s", len(removed), tenantID)
+	}
+	return removed, nil
+}
+
+// deleteIdentity removes an identity from the store and the index
+func (pg *PersonGallery) deleteIdentity(ctx context.Context, tenantID, identityID string) error {
+	if err := pg.store.DeleteGalleryIdentity(ctx, tenantID, identityID); err != nil {
+		return err
+	}
+	if pg.index != nil {
+		if err := pg.index.DeletePersonIdentity(ctx, tenantID, identityID); err != nil {
+			log.Printf("Warning: failed to remove gallery identity %s from index: %v", identityID, err)
+		}
+	}
+	return nil
+}
+
+// featureSimilarity returns cosine similarity, or 0 for empty or mismatched vectors
+func featureSimilarity(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	return 1 - cosineDistance(a, b)
+}
+
+// meanFeatures averages sighting features (sightings with a different dimension are skipped)
+func meanFeatures(sightings []models.GallerySighting) []float64 {
+	var mean []float64
+	count := 0
+	for _, sighting := range sightings {
+		if len(sighting.Features) == 0 {
+			continue
+		}
+		if mean == nil {
+			mean = make([]float64, len(sighting.Features))
+		}
+		if len(sighting.Features) != len(mean) {
+			continue
+		}
+		for i, v := range sighting.Features {
+			mean[i] += v
+		}
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+	for i := range mean {
+		mean[i] /= float64(count)
+	}
+	return mean
+}
+
+// attributesToMap converts person attributes to a JSON-style map for storage
+func attributesToMap(attributes PersonAttributes) map[string]interface{} {
+	result := make(map[string]interface{})
+	if data, err := json.Marshal(attributes); err == nil {
+		json.Unmarshal(data, &result)
+	}
+	return result
+}
+
+// attributesFromMap converts stored attributes back to PersonAttributes
+func attributesFromMap(attributes map[string]interface{}) PersonAttributes {
+	var result PersonAttributes
+	if data, err := json.Marshal(attributes); err == nil {
+		json.Unmarshal(data, &result)
+	}
+	return result
+}
@@ -0,0 +1,476 @@
+package tracking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// fakeGalleryStore is an in-memory GalleryStore with the StorageManager's semantics
+type fakeGalleryStore struct {
+	identities map[string]models.GalleryIdentity // tenant/identity -> identity
+	sightings  []models.GallerySighting
+}
+
+func newFakeGalleryStore() *fakeGalleryStore {
+	return &fakeGalleryStore{identities: make(map[string]models.GalleryIdentity)}
+}
+
+func galleryKey(tenantID, identityID string) string {
+	return tenantID + "/" + identityID
+}
+
+func (s *fakeGalleryStore) ListGalleryIdentities(ctx context.Context, tenantID string) ([]models.GalleryIdentity, error) {
+	list := make([]models.GalleryIdentity, 0)
+	for _, identity := range s.identities {
+		if identity.TenantID == tenantID {
+			list = append(list, identity)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].LastSeen.Equal(list[j].LastSeen) {
+			return list[i].LastSeen.After(list[j].LastSeen)
+		}
+		return list[i].IdentityID < list[j].IdentityID
+	})
+	return list, nil
+}
+
+func (s *fakeGalleryStore) GetGalleryIdentity(ctx context.Context, tenantID, identityID string) (*models.GalleryIdentity, error) {
+	identity, exists := s.identities[galleryKey(tenantID, identityID)]
+	if !exists {
+		return nil, nil
+	}
+	identity.Appearances = 0
+	videos := make(map[string]bool)
+	for _, sighting := range s.sightings {
+		if sighting.TenantID == tenantID && sighting.IdentityID == identityID {
+			identity.Appearances++
+			videos[sighting.JobID] = true
+		}
+	}
+	identity.VideoCount = len(videos)
+	return &identity, nil
+}
+
+func (s *fakeGalleryStore) SaveGalleryIdentity(ctx context.Context, identity *models.GalleryIdentity) error {
+	s.identities[galleryKey(identity.TenantID, identity.IdentityID)] = *identity
+	return nil
+}
+
+func (s *fakeGalleryStore) DeleteGalleryIdentity(ctx context.Context, tenantID, identityID string) error {
+	delete(s.identities, galleryKey(tenantID, identityID))
+	kept := s.sightings[:0]
+	for _, sighting := range s.sightings {
+		if sighting.TenantID != tenantID || sighting.IdentityID != identityID {
+			kept = append(kept, sighting)
+		}
+	}
+	s.sightings = kept
+	return nil
+}
+
+func (s *fakeGalleryStore) AddGallerySightings(ctx context.Context, sightings []models.GallerySighting) error {
+	s.sightings = append(s.sightings, sightings...)
+	return nil
+}
+
+func (s *fakeGalleryStore) ListGallerySightings(ctx context.Context, tenantID, identityID string) ([]models.GallerySighting, error) {
+	list := make([]models.GallerySighting, 0)
+	for _, sighting := range s.sightings {
+		if sighting.TenantID == tenantID && sighting.IdentityID == identityID {
+			list = append(list, sighting)
+		}
+	}
+	return list, nil
+}
+
+func (s *fakeGalleryStore) DeleteGallerySightingsForJob(ctx context.Context, tenantID, jobID string) error {
+	kept := s.sightings[:0]
+	for _, sighting := range s.sightings {
+		if sighting.TenantID != tenantID || sighting.JobID != jobID {
+			kept = append(kept, sighting)
+		}
+	}
+	s.sightings = kept
+	return nil
+}
+
+func (s *fakeGalleryStore) MoveGallerySightings(ctx context.Context, tenantID, fromID, toID string, keys []models.SightingKey) (int, error) {
+	selected := make(map[models.SightingKey]bool, len(keys))
+	for _, key := range keys {
+		selected[key] = true
+	}
+
+	moved := 0
+	for i, sighting := range s.sightings {
+		if sighting.TenantID != tenantID || sighting.IdentityID != fromID {
+			continue
+		}
+		if len(keys) > 0 && !selected[models.SightingKey{JobID: sighting.JobID, TrackID: sighting.TrackID}] {
+			continue
+		}
+		s.sightings[i].IdentityID = toID
+		moved++
+	}
+	return moved, nil
+}
+
+func (s *fakeGalleryStore) PruneGallery(ctx context.Context, tenantID string, maxIdentities int, olderThan time.Time) ([]string, error) {
+	removed := make([]string, 0)
+	list, _ := s.ListGalleryIdentities(ctx, tenantID)
+	for i, identity := range list {
+		expired := !olderThan.IsZero() && identity.LastSeen.Before(olderThan)
+		excess := maxIdentities > 0 && i >= maxIdentities
+		if expired || excess {
+			s.DeleteGalleryIdentity(ctx, tenantID, identity.IdentityID)
+			removed = append(removed, identity.IdentityID)
+		}
+	}
+	return removed, nil
+}
+
+// fakeGalleryIndex is an exact in-memory GalleryIndex
+type fakeGalleryIndex struct {
+	identities map[string]models.GalleryIdentity
+	searches   int
+	err        error // Returned by searches when set
+	empty      bool  // Searches find nothing (e.g. an index not yet backfilled)
+}
+
+func newFakeGalleryIndex() *fakeGalleryIndex {
+	return &fakeGalleryIndex{identities: make(map[string]models.GalleryIdentity)}
+}
+
+func (x *fakeGalleryIndex) UpsertPersonIdentity(ctx context.Context, identity *models.GalleryIdentity) error {
+	x.identities[galleryKey(identity.TenantID, identity.IdentityID)] = *identity
+	return nil
+}
+
+func (x *fakeGalleryIndex) SearchPersonIdentities(ctx context.Context, tenantID string, features []float64, limit int, scoreThreshold float64) ([]models.GalleryMatch, error) {
+	x.searches++
+	if x.err != nil {
+		return nil, x.err
+	}
+	matches := make([]models.GalleryMatch, 0)
+	if x.empty {
+		return matches, nil
+	}
+	for _, identity := range x.identities {
+		if identity.TenantID != tenantID {
+			continue
+		}
+		similarity := featureSimilarity(features, identity.Features)
+		if similarity >= scoreThreshold {
+			matches = append(matches, models.GalleryMatch{
+				Identity:   models.GalleryIdentity{TenantID: tenantID, IdentityID: identity.IdentityID},
+				Similarity: similarity,
+			})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Similarity > matches[j].Similarity })
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+func (x *fakeGalleryIndex) DeletePersonIdentity(ctx context.Context, tenantID, identityID string) error {
+	delete(x.identities, galleryKey(tenantID, identityID))
+	return nil
+}
+
+const galleryTenant = "org:acme"
+
+// candidate builds a gallery candidate with one sighting per track
+func candidate(localID string, features []float64, trackIDs ...string) GalleryCandidate {
+	sightings := make([]models.GallerySighting, 0, len(trackIDs))
+	for i, trackID := range trackIDs {
+		sightings = append(sightings, models.GallerySighting{
+			TrackID:   trackID,
+			StartTime: float64(i * 10),
+			EndTime:   float64(i*10 + 5),
+			Features:  features,
+		})
+	}
+	return GalleryCandidate{LocalID: localID, Features: features, Confidence: 0.9, Sightings: sightings}
+}
+
+var (
+	red   = []float64{1, 0, 0}
+	green = []float64{0, 1, 0}
+	blue  = []float64{0, 0, 1}
+)
+
+func resolve(t *testing.T, gallery *PersonGallery, tenantID, jobID string, candidates ...GalleryCandidate) map[string]string {
+	t.Helper()
+	mapping, err := gallery.Resolve(context.Background(), tenantID, jobID, candidates)
+	if err != nil {
+		t.Fatalf("Resolve(%s): %v", jobID, err)
+	}
+	return mapping
+}
+
+func TestGalleryResolveLinksAcrossVideos(t *testing.T) {
+	store := newFakeGalleryStore()
+	gallery := NewPersonGallery(store)
+
+	first := resolve(t, gallery, galleryTenant, "job-1", candidate("p1", red, "track_1"), candidate("p2", green, "track_2"))
+	if len(first) != 2 || first["p1"] == first["p2"] {
+		t.Fatalf("first video mapping = %v, want two distinct identities", first)
+	}
+
+	// The same red person reappears (slightly different appearance); the blue person is new
+	second := resolve(t, gallery, galleryTenant, "job-2",
+		candidate("q1", []float64{0.95, 0.05, 0}, "track_4"), candidate("q2", blue, "track_5"))
+	if second["q1"] != first["p1"] {
+		t.Errorf("q1 linked to %s, want %s", second["q1"], first["p1"])
+	}
+	if second["q2"] == first["p1"] || second["q2"] == first["p2"] {
+		t.Errorf("q2 linked to an existing identity %s, want a new one", second["q2"])
+	}
+
+	identity, _ := store.GetGalleryIdentity(context.Background(), galleryTenant, first["p1"])
+	if identity.Appearances != 2 || identity.VideoCount != 2 {
+		t.Errorf("linked identity has %d appearances in %d videos, want 2 in 2", identity.Appearances, identity.VideoCount)
+	}
+}
+
+func TestGalleryResolveIsOneToOne(t *testing.T) {
+	store := newFakeGalleryStore()
+	gallery := NewPersonGallery(store)
+	first := resolve(t, gallery, galleryTenant, "job-1", candidate("p1", red, "track_1"))
+
+	// Two different people of a later video both resemble p1; only the closer one links
+	second := resolve(t, gallery, galleryTenant, "job-2",
+		candidate("q1", []float64{0.9, 0.1, 0}, "track_1"), candidate("q2", []float64{1, 0, 0.01}, "track_2"))
+	if second["q2"] != first["p1"] {
+		t.Errorf("closer candidate linked to %s, want %s", second["q2"], first["p1"])
+	}
+	if second["q1"] == first["p1"] {
+		t.Error("both candidates linked to the same identity")
+	}
+}
+
+func TestGalleryResolveIsIdempotentPerJob(t *testing.T) {
+	store := newFakeGalleryStore()
+	gallery := NewPersonGallery(store)
+
+	first := resolve(t, gallery, galleryTenant, "job-1", candidate("p1", red, "track_1", "track_3"))
+	again := resolve(t, gallery, galleryTenant, "job-1", candidate("p1", red, "track_1", "track_3"))
+	if again["p1"] != first["p1"] {
+		t.Errorf("reprocessing linked to %s, want %s", again["p1"], first["p1"])
+	}
+
+	sightings, _ := store.ListGallerySightings(context.Background(), galleryTenant, first["p1"])
+	if len(sightings) != 2 {
+		t.Errorf("%d sightings after reprocessing, want 2", len(sightings))
+	}
+}
+
+func TestGalleryResolveIsTenantScoped(t *testing.T) {
+	store := newFakeGalleryStore()
+	gallery := NewPersonGallery(store)
+
+	if _, err := gallery.Resolve(context.Background(), "", "job-1", nil); err == nil {
+		t.Error("Resolve without a tenant succeeded, want an error")
+	}
+
+	acme := resolve(t, gallery, galleryTenant, "job-1", candidate("p1", red, "track_1"))
+	other := resolve(t, gallery, "user:bob", "job-2", candidate("p1", red, "track_1"))
+	if other["p1"] == acme["p1"] {
+		t.Error("another tenant's video linked to this tenant's identity")
+	}
+}
+
+func TestGalleryResolveUsesIndex(t *testing.T) {
+	tests := []struct {
+		name  string
+		index *fakeGalleryIndex
+	}{
+		{name: "index finds the identity", index: newFakeGalleryIndex()},
+		{name: "empty index falls back to the store", index: &fakeGalleryIndex{identities: map[string]models.GalleryIdentity{}, empty: true}},
+		{name: "failing index falls back to the store", index: &fakeGalleryIndex{identities: map[string]models.GalleryIdentity{}, err: errors.New("dimension mismatch")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeGalleryStore()
+			gallery := NewPersonGallery(store)
+			gallery.SetIndex(tt.index)
+
+			first := resolve(t, gallery, galleryTenant, "job-1", candidate("p1", red, "track_1"))
+			second := resolve(t, gallery, galleryTenant, "job-2", candidate("q1", red, "track_1"))
+			if second["q1"] != first["p1"] {
+				t.Errorf("q1 linked to %s, want %s", second["q1"], first["p1"])
+			}
+			if tt.index.searches == 0 {
+				t.Error("the index was never searched")
+			}
+			if tt.index.err == nil && len(tt.index.identities) != 1 {
+				t.Errorf("index holds %d identities, want 1", len(tt.index.identities))
+			}
+		})
+	}
+}
+
+func TestGallerySearch(t *testing.T) {
+	store := newFakeGalleryStore()
+	gallery := NewPersonGallery(store)
+	index := &fakeGalleryIndex{identities: map[string]models.GalleryIdentity{}}
+	gallery.SetIndex(index)
+
+	ids := resolve(t, gallery, galleryTenant, "job-1",
+		candidate("p1", red, "track_1"), candidate("p2", []float64{0.8, 0.6, 0}, "track_2"), candidate("p3", blue, "track_3"))
+
+	for _, empty := range []bool{false, true} {
+		index.empty = empty
+		matches, err := gallery.Search(context.Background(), galleryTenant, red, 2)
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if len(matches) != 2 || matches[0].Identity.IdentityID != ids["p1"] || matches[1].Identity.IdentityID != ids["p2"] {
+			t.Fatalf("empty index %v: got %d matches, want p1 then p2", empty, len(matches))
+		}
+		if len(matches[0].Sightings) != 1 || matches[0].Sightings[0].TrackID != "track_1" {
+			t.Errorf("empty index %v: first match sightings = %+v", empty, matches[0].Sightings)
+		}
+	}
+
+	similar, err := gallery.SearchSimilar(context.Background(), galleryTenant, ids["p1"], 1)
+	if err != nil {
+		t.Fatalf("SearchSimilar: %v", err)
+	}
+	if len(similar) != 1 || similar[0].Identity.IdentityID != ids["p2"] {
+		t.Errorf("SearchSimilar returned %+v, want only p2", similar)
+	}
+}
+
+func TestGalleryMerge(t *testing.T) {
+	store := newFakeGalleryStore()
+	index := newFakeGalleryIndex()
+	gallery := NewPersonGallery(store)
+	gallery.SetIndex(index)
+
+	ids := resolve(t, gallery, galleryTenant, "job-1", candidate("p1", red, "track_1"), candidate("p2", green, "track_2"))
+
+	if _, err := gallery.Merge(context.Background(), galleryTenant, ids["p1"], ids["p1"]); err == nil {
+		t.Error("merging an identity into itself succeeded, want an error")
+	}
+	if _, err := gallery.Merge(context.Background(), galleryTenant, ids["p1"], "missing"); err == nil {
+		t.Error("merging a missing identity succeeded, want an error")
+	}
+
+	merged, err := gallery.Merge(context.Background(), galleryTenant, ids["p1"], ids["p2"])
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if merged.Appearances != 2 || len(merged.Aliases) != 1 || merged.Aliases[0] != ids["p2"] {
+		t.Errorf("merged identity has %d appearances and aliases %v, want 2 and [%s]", merged.Appearances, merged.Aliases, ids["p2"])
+	}
+	if want := []float64{0.5, 0.5, 0}; fmt.Sprint(merged.Features) != fmt.Sprint(want) {
+		t.Errorf("merged features = %v, want the sighting mean %v", merged.Features, want)
+	}
+
+	if gone, _ := store.GetGalleryIdentity(context.Background(), galleryTenant, ids["p2"]); gone != nil {
+		t.Error("merged identity is still stored")
+	}
+	if _, indexed := index.identities[galleryKey(galleryTenant, ids["p2"])]; indexed {
+		t.Error("merged identity is still indexed")
+	}
+}
+
+func TestGallerySplit(t *testing.T) {
+	store := newFakeGalleryStore()
+	index := newFakeGalleryIndex()
+	gallery := NewPersonGallery(store)
+	gallery.SetIndex(index)
+
+	ids := resolve(t, gallery, galleryTenant, "job-1", candidate("p1", red, "track_1", "track_2", "track_3"))
+	source := ids["p1"]
+
+	if _, err := gallery.Split(context.Background(), galleryTenant, source, nil); err == nil {
+		t.Error("splitting no sightings succeeded, want an error")
+	}
+	if _, err := gallery.Split(context.Background(), galleryTenant, source, []models.SightingKey{{JobID: "job-9", TrackID: "track_1"}}); err == nil {
+		t.Error("splitting sightings of another job succeeded, want an error")
+	}
+	if list, _ := store.ListGalleryIdentities(context.Background(), galleryTenant); len(list) != 1 {
+		t.Errorf("%d identities after a failed split, want 1", len(list))
+	}
+
+	split, err := gallery.Split(context.Background(), galleryTenant, source, []models.SightingKey{{JobID: "job-1", TrackID: "track_3"}})
+	if err != nil {
+		t.Fatalf("Split: %v", err)
+	}
+	if split.IdentityID == source || split.Appearances != 1 {
+		t.Errorf("split identity %s has %d appearances, want a new identity with 1", split.IdentityID, split.Appearances)
+	}
+	if remaining, _ := store.GetGalleryIdentity(context.Background(), galleryTenant, source); remaining == nil || remaining.Appearances != 2 {
+		t.Errorf("source identity = %+v, want 2 remaining appearances", remaining)
+	}
+	if _, indexed := index.identities[galleryKey(galleryTenant, split.IdentityID)]; !indexed {
+		t.Error("split identity was not indexed")
+	}
+
+	// Splitting every remaining sighting removes the source
+	if _, err := gallery.Split(context.Background(), galleryTenant, source, []models.SightingKey{
+		{JobID: "job-1", TrackID: "track_1"}, {JobID: "job-1", TrackID: "track_2"},
+	}); err != nil {
+		t.Fatalf("Split: %v", err)
+	}
+	if gone, _ := store.GetGalleryIdentity(context.Background(), galleryTenant, source); gone != nil {
+		t.Error("emptied source identity is still stored")
+	}
+	if _, indexed := index.identities[galleryKey(galleryTenant, source)]; indexed {
+		t.Error("emptied source identity is still indexed")
+	}
+}
+
+func TestGalleryRetention(t *testing.T) {
+	store := newFakeGalleryStore()
+	index := newFakeGalleryIndex()
+	gallery := NewPersonGallery(store)
+	gallery.SetIndex(index)
+
+	now := time.Now().UTC()
+	for i, lastSeen := range []time.Time{now.Add(-400 * 24 * time.Hour), now.Add(-2 * time.Hour), now.Add(-time.Hour), now} {
+		identity := &models.GalleryIdentity{
+			TenantID:   galleryTenant,
+			IdentityID: fmt.Sprintf("id-%d", i),
+			Features:   red,
+			LastSeen:   lastSeen,
+		}
+		store.SaveGalleryIdentity(context.Background(), identity)
+		index.UpsertPersonIdentity(context.Background(), identity)
+	}
+	other := &models.GalleryIdentity{TenantID: "user:bob", IdentityID: "bob-1", LastSeen: now.Add(-400 * 24 * time.Hour)}
+	store.SaveGalleryIdentity(context.Background(), other)
+
+	// Expired identities go first, then the least recently seen beyond the limit
+	gallery.SetRetention(2, 365*24*time.Hour)
+	removed, err := gallery.ApplyRetention(context.Background(), galleryTenant)
+	if err != nil {
+		t.Fatalf("ApplyRetention: %v", err)
+	}
+	sort.Strings(removed)
+	if fmt.Sprint(removed) != "[id-0 id-1]" {
+		t.Errorf("removed %v, want [id-0 id-1]", removed)
+	}
+	if len(index.identities) != 2 {
+		t.Errorf("index holds %d identities, want 2", len(index.identities))
+	}
+	if kept, _ := store.GetGalleryIdentity(context.Background(), "user:bob", "bob-1"); kept == nil {
+		t.Error("retention removed another tenant's identity")
+	}
+
+	// Resolve enforces retention too
+	resolve(t, gallery, galleryTenant, "job-1", candidate("p1", blue, "track_1"))
+	if list, _ := store.ListGalleryIdentities(context.Background(), galleryTenant); len(list) != 2 {
+		t.Errorf("%d identities after Resolve, want 2", len(list))
+	}
+}
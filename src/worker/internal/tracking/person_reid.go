@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"image"
 	"log"
 	"math"
+	"strings"
 	"sync"
 	"time"
 
@@ -55,8 +57,14 @@ type ReIDMatch struct {
 
 // PersonReID handles person re-identification across frames and tracks
 type PersonReID struct {
-	mageAgent        *clients.MageAgentClient
+	mageAgent        *clients.MageAgentClient   // Attribute extraction (optional)
+	embedder         AppearanceEmbedder         // Appearance embeddings from person crops
+	fallback         AppearanceEmbedder         // Used for the whole run when the embedder fails first
+	embedMu          sync.Mutex                 // Guards the embedder choice
+	embedderWorked   bool                       // The embedder has produced features this run
+	usingFallback    bool                       // The run switched to the fallback embedder
 	identities       map[string]*PersonIdentity // Known identities
+	trackFeatures    map[string][]float64       // Features observed per track (for splits)
 	nextIdentityID   int
 	featureThreshold float64 // Feature distance threshold for matching
 	minConfidence    float64 // Minimum confidence for re-ID
@@ -68,14 +76,26 @@ type PersonReID struct {
 func NewPersonReID(mageAgent *clients.MageAgentClient) *PersonReID {
 	return &PersonReID{
 		mageAgent:        mageAgent,
+		embedder:         NewHistogramEmbedder(),
+		fallback:         NewHistogramEmbedder(),
 		identities:       make(map[string]*PersonIdentity),
+		trackFeatures:    make(map[string][]float64),
 		nextIdentityID:   1,
-		featureThreshold: 0.3,  // Cosine distance threshold
+		featureThreshold: 0.3,  // Max cosine distance for a match
 		minConfidence:    0.6,  // 60% minimum confidence
 		maxIdentities:    1000, // Track up to 1000 unique people
 	}
 }
 
+// SetEmbedder replaces the colour-histogram embedder (histograms remain the fallback)
+func (pr *PersonReID) SetEmbedder(embedder AppearanceEmbedder) {
+	pr.embedMu.Lock()
+	defer pr.embedMu.Unlock()
+	pr.embedder = embedder
+	pr.embedderWorked = false
+	pr.usingFallback = false
+}
+
 // IdentifyPerson identifies or creates an identity for a person track
 func (pr *PersonReID) IdentifyPerson(ctx context.Context, track *TrackedObject, frameData string) (*ReIDMatch, error) {
 	if track.Class != ClassPerson {
@@ -96,10 +116,12 @@ func (pr *PersonReID) IdentifyPerson(ctx context.Context, track *TrackedObject,
 	pr.mu.Lock()
 	defer pr.mu.Unlock()
 
+	pr.trackFeatures[track.TrackID] = features
 	bestMatch := pr.findBestMatch(features, attributes)
 
 	if bestMatch != nil {
 		// Update existing identity
+		bestMatch.TrackID = track.TrackID
 		identity := pr.identities[bestMatch.IdentityID]
 		identity.LastSeen = time.Now()
 		identity.Appearances++
@@ -142,8 +164,73 @@ func (pr *PersonReID) IdentifyPerson(ctx context.Context, track *TrackedObject,
 	return nil, fmt.Errorf("maximum identities reached")
 }
 
-// extractPersonFeatures extracts visual features and attributes from person crop
+// extractPersonFeatures embeds the person crop and asks the vision model for attributes
+// Features always come from the crop; attributes are best-effort (empty when unavailable)
 func (pr *PersonReID) extractPersonFeatures(ctx context.Context, frameData string, bbox BoundingBox) ([]float64, PersonAttributes, error) {
+	crop, err := cropFrame(frameData, bbox)
+	if err != nil {
+		return nil, PersonAttributes{}, fmt.Errorf("failed to crop person: %w", err)
+	}
+
+	features, err := pr.embed(ctx, crop)
+	if err != nil {
+		return nil, PersonAttributes{}, err
+	}
+
+	attributes, err := pr.extractPersonAttributes(ctx, crop)
+	if err != nil {
+		log.Printf("Failed to extract person attributes: %v", err)
+		attributes = PersonAttributes{}
+	}
+
+	return features, attributes, nil
+}
+
+// embed returns appearance features from the embedder chosen for this run
+// Embedders produce vectors in different spaces, so they are never mixed: if the embedder
+// fails before producing any features the run switches to the fallback for good, and later
+// failures drop the crop instead.
+func (pr *PersonReID) embed(ctx context.Context, crop image.Image) ([]float64, error) {
+	pr.embedMu.Lock()
+	defer pr.embedMu.Unlock()
+
+	if pr.usingFallback {
+		features, err := pr.fallback.Embed(ctx, crop)
+		if err != nil {
+			return nil, fmt.Errorf("appearance embedding (%s) failed: %w", pr.fallback.Name(), err)
+		}
+		return features, nil
+	}
+
+	features, err := pr.embedder.Embed(ctx, crop)
+	if err == nil {
+		pr.embedderWorked = true
+		return features, nil
+	}
+	if pr.embedderWorked || pr.fallback == nil {
+		return nil, fmt.Errorf("appearance embedding (%s) failed: %w", pr.embedder.Name(), err)
+	}
+
+	log.Printf("Appearance embedding (%s) failed, using %s for this run: %v", pr.embedder.Name(), pr.fallback.Name(), err)
+	pr.usingFallback = true
+	features, err = pr.fallback.Embed(ctx, crop)
+	if err != nil {
+		return nil, fmt.Errorf("appearance embedding (%s) failed: %w", pr.fallback.Name(), err)
+	}
+	return features, nil
+}
+
+// extractPersonAttributes asks the vision model to describe the cropped person
+func (pr *PersonReID) extractPersonAttributes(ctx context.Context, crop image.Image) (PersonAttributes, error) {
+	if pr.mageAgent == nil {
+		return PersonAttributes{}, nil
+	}
+
+	cropData, err := encodeCrop(crop)
+	if err != nil {
+		return PersonAttributes{}, err
+	}
+
 	prompt := `Analyze this person and extract detailed attributes for re-identification:
 
 1. HEIGHT: tall, medium, short
@@ -158,7 +245,7 @@ func (pr *PersonReID) extractPersonFeatures(ctx context.Context, frameData strin
 10. GENDER: male, female, unknown
 11. POSE: standing, sitting, walking, running, crouching
 
-Also generate a feature vector (128 dimensions) representing appearance for matching.
+Use an empty string for anything you cannot see.
 
 Respond with JSON:
 {
@@ -174,69 +261,62 @@ Respond with JSON:
     "age": "child|teen|adult|elderly",
     "gender": "male|female|unknown",
     "pose": "standing|sitting|walking|running|crouching"
-  },
-  "features": [128 float values between -1 and 1]
+  }
 }`
 
 	visionReq := models.MageAgentVisionRequest{
-		Image:     frameData,
+		Image:     cropData,
 		Prompt:    prompt,
-		MaxTokens: 1200,
+		MaxTokens: 600,
 	}
 
 	visionResp, err := pr.mageAgent.AnalyzeFrame(ctx, visionReq)
 	if err != nil {
-		return nil, PersonAttributes{}, fmt.Errorf("vision analysis failed: %w", err)
+		return PersonAttributes{}, fmt.Errorf("vision analysis failed: %w", err)
 	}
 
-	// Parse response
-	features, attributes, err := pr.parsePersonFeatures(visionResp.Description)
-	if err != nil {
-		log.Printf("Failed to parse person features: %v", err)
-		// Return default values
-		return pr.generateDefaultFeatures(), PersonAttributes{}, nil
-	}
-
-	return features, attributes, nil
+	return pr.parsePersonAttributes(visionResp.Description)
 }
 
-// parsePersonFeatures parses AI response into features and attributes
-func (pr *PersonReID) parsePersonFeatures(response string) ([]float64, PersonAttributes, error) {
+// parsePersonAttributes parses AI response into person attributes
+func (pr *PersonReID) parsePersonAttributes(response string) (PersonAttributes, error) {
 	jsonStr := extractJSON(response)
 
 	var parsed struct {
 		Attributes PersonAttributes `json:"attributes"`
-		Features   []float64        `json:"features"`
 	}
 
 	if err := json.Unmarshal([]byte(jsonStr), &parsed); err != nil {
-		return nil, PersonAttributes{}, fmt.Errorf("failed to unmarshal: %w", err)
+		return PersonAttributes{}, fmt.Errorf("failed to unmarshal: %w", err)
 	}
 
-	// Validate features length
-	if len(parsed.Features) != 128 {
-		return nil, PersonAttributes{}, fmt.Errorf("invalid feature length: %d", len(parsed.Features))
-	}
-
-	return parsed.Features, parsed.Attributes, nil
+	return parsed.Attributes, nil
 }
 
 // findBestMatch finds the best matching identity for given features
+// A match requires feature distance within featureThreshold; attributes refine the ranking
 func (pr *PersonReID) findBestMatch(features []float64, attributes PersonAttributes) *ReIDMatch {
 	var bestMatch *ReIDMatch
-	bestScore := pr.featureThreshold // Must be better than threshold
+	bestScore := 0.0
 
 	for identityID, identity := range pr.identities {
 		// Compute feature distance (cosine distance)
 		featureDist := pr.computeFeatureDistance(features, identity.Features)
+		if featureDist > pr.featureThreshold {
+			continue
+		}
 
-		// Compute attribute similarity
-		attrSim := pr.computeAttributeSimilarity(attributes, identity.Attributes)
-
-		// Combined score (70% features, 30% attributes)
-		combinedScore := (1.0 - featureDist) * 0.7 + attrSim * 0.3
+		// Combined score (70% features, 30% attributes when any are known)
+		combinedScore := 1.0 - featureDist
+		attrSim, known := attributeSimilarity(attributes, identity.Attributes)
+		if known {
+			combinedScore = (1.0-featureDist)*0.7 + attrSim*0.3
+		}
+		if combinedScore < pr.minConfidence {
+			continue
+		}
 
-		if combinedScore > bestScore {
+		if combinedScore > bestScore || (combinedScore == bestScore && bestMatch != nil && identityID < bestMatch.IdentityID) {
 			bestScore = combinedScore
 			bestMatch = &ReIDMatch{
 				IdentityID:   identityID,
@@ -277,60 +357,56 @@ func (pr *PersonReID) computeFeatureDistance(features1, features2 []float64) flo
 
 // computeAttributeSimilarity computes similarity score for attributes
 func (pr *PersonReID) computeAttributeSimilarity(attr1, attr2 PersonAttributes) float64 {
+	score, _ := attributeSimilarity(attr1, attr2)
+	return score
+}
+
+// attributeSimilarity compares attributes known on both sides (shared with PersonGallery)
+// Returns false when no attribute can be compared
+func attributeSimilarity(attr1, attr2 PersonAttributes) (float64, bool) {
 	score := 0.0
 	total := 0.0
 
-	// Compare each attribute (equal weight)
-	if attr1.Height == attr2.Height {
-		score += 1.0
-	}
-	total += 1.0
-
-	if attr1.Build == attr2.Build {
-		score += 1.0
-	}
-	total += 1.0
-
-	if attr1.HairColor == attr2.HairColor {
-		score += 1.0
-	}
-	total += 1.0
-
-	if attr1.HairLength == attr2.HairLength {
-		score += 1.0
-	}
-	total += 1.0
-
-	if attr1.Age == attr2.Age {
-		score += 1.0
-	}
-	total += 1.0
-
-	if attr1.Gender == attr2.Gender {
-		score += 1.0
+	// Compare each attribute (equal weight), skipping unknown values
+	for _, pair := range [][2]string{
+		{attr1.Height, attr2.Height},
+		{attr1.Build, attr2.Build},
+		{attr1.HairColor, attr2.HairColor},
+		{attr1.HairLength, attr2.HairLength},
+		{attr1.Age, attr2.Age},
+		{attr1.Gender, attr2.Gender},
+	} {
+		a := strings.ToLower(strings.TrimSpace(pair[0]))
+		b := strings.ToLower(strings.TrimSpace(pair[1]))
+		if a == "" || b == "" || a == "unknown" || b == "unknown" {
+			continue
+		}
+		if a == b {
+			score += 1.0
+		}
+		total += 1.0
 	}
-	total += 1.0
 
 	// Clothing colors overlap
-	colorOverlap := 0.0
-	for _, color1 := range attr1.ClothingColors {
-		for _, color2 := range attr2.ClothingColors {
-			if color1 == color2 {
-				colorOverlap += 1.0
-				break
+	if len(attr1.ClothingColors) > 0 && len(attr2.ClothingColors) > 0 {
+		colorOverlap := 0.0
+		for _, color1 := range attr1.ClothingColors {
+			for _, color2 := range attr2.ClothingColors {
+				if strings.EqualFold(color1, color2) {
+					colorOverlap += 1.0
+					break
+				}
 			}
 		}
-	}
-	if len(attr1.ClothingColors) > 0 {
 		score += colorOverlap / float64(len(attr1.ClothingColors))
 		total += 1.0
 	}
 
 	if total == 0 {
-		return 0.0
+		return 0.0, false
 	}
 
-	return score / total
+	return score / total, true
 }
 
 // createIdentity creates a new person identity
@@ -357,10 +433,14 @@ func (pr *PersonReID) createIdentity(features []float64, attributes PersonAttrib
 }
 
 // updateAveragedFeatures updates averaged feature vector
+// Vectors of another dimension come from another embedder and are ignored
 func (pr *PersonReID) updateAveragedFeatures(oldFeatures, newFeatures []float64, appearances int) []float64 {
-	if len(oldFeatures) != len(newFeatures) {
+	if len(oldFeatures) == 0 {
 		return newFeatures
 	}
+	if len(oldFeatures) != len(newFeatures) {
+		return oldFeatures
+	}
 
 	// Running average
 	averaged := make([]float64, len(oldFeatures))
@@ -373,16 +453,6 @@ func (pr *PersonReID) updateAveragedFeatures(oldFeatures, newFeatures []float64,
 	return averaged
 }
 
-// generateDefaultFeatures generates default feature vector
-func (pr *PersonReID) generateDefaultFeatures() []float64 {
-	features := make([]float64, 128)
-	// Initialize with small random values
-	for i := range features {
-		features[i] = (float64(i%10) - 5.0) / 10.0
-	}
-	return features
-}
-
 // GetIdentity retrieves an identity by ID
 func (pr *PersonReID) GetIdentity(identityID string) (*PersonIdentity, bool) {
 	pr.mu.RLock()
@@ -404,7 +474,12 @@ func (pr *PersonReID) GetAllIdentities() []PersonIdentity {
 }
 
 // MergeIdentities merges two identities (when same person has multiple IDs)
+// Features are averaged weighted by appearances; id2 is kept as an alias of id1
 func (pr *PersonReID) MergeIdentities(id1, id2 string) error {
+	if id1 == id2 {
+		return fmt.Errorf("cannot merge identity %s into itself", id1)
+	}
+
 	pr.mu.Lock()
 	defer pr.mu.Unlock()
 
@@ -415,17 +490,27 @@ func (pr *PersonReID) MergeIdentities(id1, id2 string) error {
 		return fmt.Errorf("one or both identities not found")
 	}
 
+	// Average features (weights taken before appearances are combined)
+	appearances1 := identity1.Appearances
+	appearances2 := identity2.Appearances
+	totalAppearances := appearances1 + appearances2
+	if len(identity1.Features) == len(identity2.Features) && totalAppearances > 0 {
+		for i := range identity1.Features {
+			identity1.Features[i] = (identity1.Features[i]*float64(appearances1) +
+				identity2.Features[i]*float64(appearances2)) / float64(totalAppearances)
+		}
+	}
+
 	// Merge into identity1
+	identity1.FirstSeen = minTime(identity1.FirstSeen, identity2.FirstSeen)
 	identity1.LastSeen = maxTime(identity1.LastSeen, identity2.LastSeen)
-	identity1.Appearances += identity2.Appearances
+	identity1.Appearances = totalAppearances
 	identity1.TrackIDs = append(identity1.TrackIDs, identity2.TrackIDs...)
 	identity1.Aliases = append(identity1.Aliases, id2)
-
-	// Average features
-	totalAppearances := identity1.Appearances + identity2.Appearances
-	for i := range identity1.Features {
-		identity1.Features[i] = (identity1.Features[i]*float64(identity1.Appearances) +
-			identity2.Features[i]*float64(identity2.Appearances)) / float64(totalAppearances)
+	identity1.Aliases = append(identity1.Aliases, identity2.Aliases...)
+	if identity2.Confidence > identity1.Confidence {
+		identity1.Attributes = identity2.Attributes
+		identity1.Confidence = identity2.Confidence
 	}
 
 	// Remove identity2
@@ -436,13 +521,107 @@ func (pr *PersonReID) MergeIdentities(id1, id2 string) error {
 	return nil
 }
 
+// SplitIdentity moves the given tracks of an identity into a new identity
+// (when re-ID wrongly linked different people). Features of both identities are
+// recomputed from the features observed on their tracks.
+func (pr *PersonReID) SplitIdentity(identityID string, trackIDs []string) (*PersonIdentity, error) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	identity, exists := pr.identities[identityID]
+	if !exists {
+		return nil, fmt.Errorf("identity not found: %s", identityID)
+	}
+
+	splitSet := make(map[string]bool, len(trackIDs))
+	for _, trackID := range trackIDs {
+		splitSet[trackID] = true
+	}
+
+	kept := make([]string, 0, len(identity.TrackIDs))
+	moved := make([]string, 0, len(trackIDs))
+	for _, trackID := range identity.TrackIDs {
+		if splitSet[trackID] {
+			moved = append(moved, trackID)
+		} else {
+			kept = append(kept, trackID)
+		}
+	}
+	if len(moved) == 0 {
+		return nil, fmt.Errorf("none of the tracks belong to identity %s", identityID)
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("cannot split every track out of identity %s", identityID)
+	}
+
+	split := pr.createIdentity(pr.meanTrackFeatures(moved, identity.Features), identity.Attributes, moved[0])
+	split.TrackIDs = moved
+	split.Appearances = len(moved)
+	split.FirstSeen = identity.FirstSeen
+	split.LastSeen = identity.LastSeen
+
+	identity.TrackIDs = kept
+	identity.Appearances -= len(moved)
+	if identity.Appearances < len(kept) {
+		identity.Appearances = len(kept)
+	}
+	identity.Features = pr.meanTrackFeatures(kept, identity.Features)
+
+	log.Printf("Split %d tracks of %s into %s", len(moved), identityID, split.IdentityID)
+
+	return split, nil
+}
+
+// meanTrackFeatures averages the features observed on tracks (fallback when none are known)
+func (pr *PersonReID) meanTrackFeatures(trackIDs []string, fallback []float64) []float64 {
+	var mean []float64
+	count := 0
+	for _, trackID := range trackIDs {
+		features, exists := pr.trackFeatures[trackID]
+		if !exists || len(features) == 0 {
+			continue
+		}
+		if mean == nil {
+			mean = make([]float64, len(features))
+		}
+		if len(features) != len(mean) {
+			continue
+		}
+		for i, v := range features {
+			mean[i] += v
+		}
+		count++
+	}
+	if count == 0 {
+		return fallback
+	}
+	for i := range mean {
+		mean[i] /= float64(count)
+	}
+	return mean
+}
+
+// GetTrackFeatures returns the appearance features observed on a track
+func (pr *PersonReID) GetTrackFeatures(trackID string) ([]float64, bool) {
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+	features, exists := pr.trackFeatures[trackID]
+	return features, exists
+}
+
 // Reset clears all identities
 func (pr *PersonReID) Reset() {
 	pr.mu.Lock()
 	defer pr.mu.Unlock()
 
 	pr.identities = make(map[string]*PersonIdentity)
+	pr.trackFeatures = make(map[string][]float64)
 	pr.nextIdentityID = 1
+
+	pr.embedMu.Lock()
+	pr.embedderWorked = false
+	pr.usingFallback = false
+	pr.embedMu.Unlock()
 }
 
 // GetStatistics returns re-ID statistics
@@ -470,6 +649,14 @@ func (pr *PersonReID) GetStatistics() map[string]interface{} {
 	}
 }
 
+// minTime returns the earlier of two times
+func minTime(t1, t2 time.Time) time.Time {
+	if t1.Before(t2) {
+		return t1
+	}
+	return t2
+}
+
 // maxTime returns the later of two times
 func maxTime(t1, t2 time.Time) time.Time {
 	if t1.After(t2) {
@@ -0,0 +1,137 @@
+package tracking
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// scriptedEmbedder returns fixed-size vectors and fails on the calls listed in failOn (1-based)
+type scriptedEmbedder struct {
+	name   string
+	dim    int
+	failOn map[int]bool
+	calls  int
+}
+
+func (e *scriptedEmbedder) Embed(ctx context.Context, crop image.Image) ([]float64, error) {
+	e.calls++
+	if e.failOn[e.calls] {
+		return nil, errors.New("embedding server unavailable")
+	}
+	features := make([]float64, e.dim)
+	features[0] = 1
+	return features, nil
+}
+
+func (e *scriptedEmbedder) Name() string {
+	return e.name
+}
+
+// testFrame returns a base64 PNG frame
+func testFrame(t *testing.T) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 4), G: uint8(y * 4), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func personTrack(trackID string) *TrackedObject {
+	return &TrackedObject{
+		TrackID:     trackID,
+		Class:       ClassPerson,
+		BoundingBox: BoundingBox{X: 0.25, Y: 0.1, Width: 0.5, Height: 0.8},
+		Attributes:  make(map[string]interface{}),
+	}
+}
+
+func TestPersonReIDNeverMixesEmbeddingSpaces(t *testing.T) {
+	frame := testFrame(t)
+
+	tests := []struct {
+		name      string
+		failOn    map[int]bool
+		wantDims  []int // Feature size per track (0 = re-ID failed for the track)
+		wantCalls int   // Calls to the primary embedder
+	}{
+		{
+			name:      "embedder works",
+			failOn:    nil,
+			wantDims:  []int{512, 512, 512},
+			wantCalls: 3,
+		},
+		{
+			name:      "embedder fails first: histograms for the whole run",
+			failOn:    map[int]bool{1: true},
+			wantDims:  []int{2 * histogramRegionBins, 2 * histogramRegionBins, 2 * histogramRegionBins},
+			wantCalls: 1,
+		},
+		{
+			name:      "embedder fails later: the crop is dropped",
+			failOn:    map[int]bool{2: true},
+			wantDims:  []int{512, 0, 512},
+			wantCalls: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			embedder := &scriptedEmbedder{name: "model", dim: 512, failOn: tt.failOn}
+			reid := NewPersonReID(nil)
+			reid.SetEmbedder(embedder)
+
+			for i, wantDim := range tt.wantDims {
+				track := personTrack(string(rune('a' + i)))
+				_, err := reid.IdentifyPerson(context.Background(), track, frame)
+				if wantDim == 0 {
+					if err == nil {
+						t.Errorf("track %d: re-ID succeeded, want the crop dropped", i)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("track %d: %v", i, err)
+				}
+				if len(track.Features) != wantDim {
+					t.Errorf("track %d: %d-D features, want %d-D", i, len(track.Features), wantDim)
+				}
+			}
+
+			if embedder.calls != tt.wantCalls {
+				t.Errorf("primary embedder called %d times, want %d", embedder.calls, tt.wantCalls)
+			}
+			for _, identity := range reid.GetAllIdentities() {
+				if len(identity.Features) != tt.wantDims[0] {
+					t.Errorf("identity %s has %d-D features, want %d-D", identity.IdentityID, len(identity.Features), tt.wantDims[0])
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateAveragedFeaturesIgnoresOtherSpaces(t *testing.T) {
+	reid := NewPersonReID(nil)
+
+	if got := reid.updateAveragedFeatures([]float64{1, 0}, []float64{0, 1}, 2); got[0] != 0.5 || got[1] != 0.5 {
+		t.Errorf("running average = %v, want [0.5 0.5]", got)
+	}
+	if got := reid.updateAveragedFeatures([]float64{1, 0}, []float64{0, 1, 0}, 2); len(got) != 2 || got[0] != 1 {
+		t.Errorf("mismatched update = %v, want the old features kept", got)
+	}
+	if got := reid.updateAveragedFeatures(nil, []float64{0, 1}, 1); len(got) != 2 {
+		t.Errorf("first update = %v, want the new features", got)
+	}
+}
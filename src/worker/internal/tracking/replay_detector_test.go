@@ -0,0 +1,117 @@
+package tracking
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeReplayFile(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "detections.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReplayDetectorReturnsRecordedFrames(t *testing.T) {
+	path := writeReplayFile(t,
+		`{"frame": 1, "detections": [{"class": "person", "confidence": 0.9, "boundingBox": {"x": 0.1, "y": 0.2, "width": 0.1, "height": 0.3}}]}`,
+		``,
+		`{"frame": 3, "detections": [{"class": "vehicle", "confidence": 0.7, "boundingBox": {"x": 0.5, "y": 0.5, "width": 0.2, "height": 0.1}}]}`,
+		`{"frame": 3, "detections": [{"class": "person", "confidence": 0.6, "boundingBox": {"x": 0.0, "y": 0.0, "width": 0.1, "height": 0.1}}]}`,
+	)
+	detector, err := NewReplayDetector(path)
+	if err != nil {
+		t.Fatalf("NewReplayDetector: %v", err)
+	}
+
+	if got := detector.FrameCount(); got != 3 {
+		t.Errorf("FrameCount() = %d, want 3", got)
+	}
+
+	counts := map[int]int{1: 1, 2: 0, 3: 2, 4: 0}
+	for frameNum, want := range counts {
+		detections, err := detector.Detect(context.Background(), DetectionFrame{Number: frameNum})
+		if err != nil {
+			t.Fatalf("Detect frame %d: %v", frameNum, err)
+		}
+		if len(detections) != want {
+			t.Errorf("frame %d: %d detections, want %d", frameNum, len(detections), want)
+		}
+	}
+
+	first, _ := detector.Detect(context.Background(), DetectionFrame{Number: 1})
+	if first[0].Class != ClassPerson || first[0].Confidence != 0.9 || first[0].BoundingBox.Height != 0.3 {
+		t.Errorf("frame 1 detection = %+v", first[0])
+	}
+}
+
+func TestReplayDetectorIsRepeatable(t *testing.T) {
+	detector := NewReplayDetectorFromFrames(map[int][]Detection{
+		1: {person(0.1, 0.9)},
+	})
+
+	// Callers may modify the returned slice without changing the recording
+	detections, _ := detector.Detect(context.Background(), DetectionFrame{Number: 1})
+	detections[0].Confidence = 0
+
+	again, _ := detector.Detect(context.Background(), DetectionFrame{Number: 1})
+	if again[0].Confidence != 0.9 {
+		t.Errorf("second Detect returned confidence %v, want 0.9", again[0].Confidence)
+	}
+}
+
+func TestReplayDetectorRejectsInvalidFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{name: "malformed JSON", line: `{"frame": 1, "detections": [`},
+		{name: "zero frame", line: `{"frame": 0, "detections": []}`},
+		{name: "negative frame", line: `{"frame": -2, "detections": []}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewReplayDetector(writeReplayFile(t, tt.line)); err == nil {
+				t.Error("NewReplayDetector succeeded, want an error")
+			}
+		})
+	}
+
+	if _, err := NewReplayDetector(filepath.Join(t.TempDir(), "missing.jsonl")); err == nil {
+		t.Error("NewReplayDetector succeeded for a missing file, want an error")
+	}
+}
+
+func TestReplayDetectorHonoursCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	detector := NewReplayDetectorFromFrames(nil)
+	if _, err := detector.Detect(ctx, DetectionFrame{Number: 1}); err == nil {
+		t.Error("Detect succeeded with a cancelled context, want an error")
+	}
+}
+
+func TestReplayTrackingIsDeterministic(t *testing.T) {
+	frames := map[int][]Detection{}
+	for f := 1; f <= 20; f++ {
+		x := 0.1 + 0.01*float64(f)
+		frames[f] = []Detection{person(x, 0.9), person(0.8-x, 0.8)}
+	}
+
+	first := MOTRecordsFromResults(runTracker(t, TrackerByteTrack, frames, 20, 5))
+	second := MOTRecordsFromResults(runTracker(t, TrackerByteTrack, frames, 20, 5))
+	if len(first) != 40 {
+		t.Fatalf("exported %d records, want 40", len(first))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("record %d differs between runs: %+v vs %+v", i, first[i], second[i])
+		}
+	}
+}
@@ -0,0 +1,454 @@
+package tracking
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// Spatial event types
+const (
+	SpatialEventZoneEnter = "zone_enter"
+	SpatialEventZoneExit  = "zone_exit"
+	SpatialEventDwell     = "dwell"
+	SpatialEventLineCross = "line_cross"
+)
+
+// Line crossing directions (see models.TripLine)
+const (
+	DirectionLeftToRight = "left_to_right"
+	DirectionRightToLeft = "right_to_left"
+)
+
+// SpatialAnalyzer evaluates zones and lines against track trajectories frame by frame
+// It only consumes new TrajectoryPoints, so it can run alongside any tracker (offline jobs
+// or live streams) as long as Update is called with the tracker's current objects.
+type SpatialAnalyzer struct {
+	rules     models.SpatialRules
+	epoch     time.Time     // Event timestamps are seconds since epoch
+	exitGrace time.Duration // Tracks unseen for longer leave their zones
+	tracks    map[string]*spatialTrackState
+	occupancy map[string]int // Last reported count per zone
+	samples   []models.OccupancySample
+	stats     map[string]*models.ZoneStats
+	dwellSum  map[string]float64 // Completed visit durations per zone
+}
+
+// spatialTrackState is the per-track state of the analyzer
+type spatialTrackState struct {
+	class     string
+	lastFrame int
+	lastPoint TrajectoryPoint
+	inside    map[string]time.Time // Zone ID -> entered at
+	dwelled   map[string]bool      // Zone ID -> dwell event emitted for this visit
+	lineSide  map[string]float64   // Line ID -> last non-zero side
+	linePoint map[string]TrajectoryPoint
+}
+
+// NewSpatialAnalyzer validates the rules and creates an analyzer
+// Timestamps of events are reported in seconds since epoch.
+func NewSpatialAnalyzer(rules models.SpatialRules, epoch time.Time) (*SpatialAnalyzer, error) {
+	if err := ValidateSpatialRules(rules); err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]*models.ZoneStats, len(rules.Zones))
+	for _, zone := range rules.Zones {
+		stats[zone.ZoneID] = &models.ZoneStats{ZoneID: zone.ZoneID}
+	}
+
+	return &SpatialAnalyzer{
+		rules:     rules,
+		epoch:     epoch,
+		exitGrace: time.Second,
+		tracks:    make(map[string]*spatialTrackState),
+		occupancy: make(map[string]int),
+		samples:   make([]models.OccupancySample, 0),
+		stats:     stats,
+		dwellSum:  make(map[string]float64),
+	}, nil
+}
+
+// SetExitGrace sets how long a track may go undetected before it leaves its zones
+func (sa *SpatialAnalyzer) SetExitGrace(grace time.Duration) {
+	sa.exitGrace = grace
+}
+
+// ValidateSpatialRules checks IDs and geometry of user-defined rules
+func ValidateSpatialRules(rules models.SpatialRules) error {
+	ids := make(map[string]bool)
+
+	for i, zone := range rules.Zones {
+		if zone.ZoneID == "" {
+			return fmt.Errorf("zone %d: zoneId is required", i)
+		}
+		if ids[zone.ZoneID] {
+			return fmt.Errorf("duplicate rule ID: %s", zone.ZoneID)
+		}
+		ids[zone.ZoneID] = true
+
+		if len(zone.Polygon) < 3 {
+			return fmt.Errorf("zone %s: polygon needs at least 3 points", zone.ZoneID)
+		}
+		for _, point := range zone.Polygon {
+			if !validSpatialPoint(point) {
+				return fmt.Errorf("zone %s: point (%.3f, %.3f) is outside the normalized frame", zone.ZoneID, point.X, point.Y)
+			}
+		}
+		if zone.DwellThreshold < 0 {
+			return fmt.Errorf("zone %s: dwellThreshold must not be negative", zone.ZoneID)
+		}
+	}
+
+	for i, line := range rules.Lines {
+		if line.LineID == "" {
+			return fmt.Errorf("line %d: lineId is required", i)
+		}
+		if ids[line.LineID] {
+			return fmt.Errorf("duplicate rule ID: %s", line.LineID)
+		}
+		ids[line.LineID] = true
+
+		if !validSpatialPoint(line.Start) || !validSpatialPoint(line.End) {
+			return fmt.Errorf("line %s: endpoints must be inside the normalized frame", line.LineID)
+		}
+		if line.Start == line.End {
+			return fmt.Errorf("line %s: start and end must differ", line.LineID)
+		}
+	}
+
+	return nil
+}
+
+// validSpatialPoint reports whether a point lies in the normalized frame
+func validSpatialPoint(point models.SpatialPoint) bool {
+	return point.X >= 0 && point.X <= 1 && point.Y >= 0 && point.Y <= 1
+}
+
+// Update processes the tracker's objects for a frame and returns its events and occupancy changes
+// Objects not matched in this frame (LostFrames > 0) are ignored; tracks absent for longer
+// than the exit grace period leave their zones at their last observed time.
+func (sa *SpatialAnalyzer) Update(objects []TrackedObject, frameNum int, frameTime time.Time) ([]models.SpatialEvent, []models.OccupancySample) {
+	events := make([]models.SpatialEvent, 0)
+
+	for _, obj := range objects {
+		if obj.LostFrames > 0 || len(obj.Trajectory) == 0 {
+			continue
+		}
+
+		state, exists := sa.tracks[obj.TrackID]
+		if !exists {
+			// Start from the current point (a track returning after the grace period keeps
+			// its older trajectory, which must not replay events)
+			state = &spatialTrackState{
+				class:     string(obj.Class),
+				lastFrame: obj.Trajectory[len(obj.Trajectory)-1].FrameNum - 1,
+				inside:    make(map[string]time.Time),
+				dwelled:   make(map[string]bool),
+				lineSide:  make(map[string]float64),
+				linePoint: make(map[string]TrajectoryPoint),
+			}
+			sa.tracks[obj.TrackID] = state
+		}
+
+		// Only trajectory points not seen yet (several when frames were skipped)
+		for _, point := range obj.Trajectory {
+			if point.FrameNum <= state.lastFrame {
+				continue
+			}
+			events = append(events, sa.observe(obj.TrackID, state, point)...)
+			state.lastFrame = point.FrameNum
+			state.lastPoint = point
+		}
+	}
+
+	// Tracks that disappeared leave their zones
+	for _, trackID := range sa.sortedTrackIDs() {
+		state := sa.tracks[trackID]
+		if frameTime.Sub(state.lastPoint.Timestamp) > sa.exitGrace {
+			events = append(events, sa.exitAll(trackID, state)...)
+			delete(sa.tracks, trackID)
+		}
+	}
+
+	samples := sa.sampleOccupancy(frameNum, frameTime)
+	sortSpatialEvents(events)
+	return events, samples
+}
+
+// Finish closes all open zone visits (end of video or stream)
+func (sa *SpatialAnalyzer) Finish(frameNum int, frameTime time.Time) ([]models.SpatialEvent, []models.OccupancySample) {
+	events := make([]models.SpatialEvent, 0)
+	for _, trackID := range sa.sortedTrackIDs() {
+		events = append(events, sa.exitAll(trackID, sa.tracks[trackID])...)
+		delete(sa.tracks, trackID)
+	}
+
+	samples := sa.sampleOccupancy(frameNum, frameTime)
+	sortSpatialEvents(events)
+	return events, samples
+}
+
+// Occupancy returns occupancy changes recorded so far
+func (sa *SpatialAnalyzer) Occupancy() []models.OccupancySample {
+	samples := make([]models.OccupancySample, len(sa.samples))
+	copy(samples, sa.samples)
+	return samples
+}
+
+// Stats returns per-zone activity in rule order
+func (sa *SpatialAnalyzer) Stats() []models.ZoneStats {
+	stats := make([]models.ZoneStats, 0, len(sa.rules.Zones))
+	for _, zone := range sa.rules.Zones {
+		zoneStats := *sa.stats[zone.ZoneID]
+		if zoneStats.Exits > 0 {
+			zoneStats.MeanDwell = sa.dwellSum[zone.ZoneID] / float64(zoneStats.Exits)
+		}
+		stats = append(stats, zoneStats)
+	}
+	return stats
+}
+
+// observe evaluates one new trajectory point of a track
+func (sa *SpatialAnalyzer) observe(trackID string, state *spatialTrackState, point TrajectoryPoint) []models.SpatialEvent {
+	events := make([]models.SpatialEvent, 0)
+	position := models.SpatialPoint{X: point.X, Y: point.Y}
+
+	// Step 1: Line crossings (segment from the last point on either side of the line)
+	for _, line := range sa.rules.Lines {
+		if !classAllowed(line.Classes, state.class) {
+			continue
+		}
+
+		side := lineSide(line, point)
+		if side == 0 {
+			continue
+		}
+
+		prevSide, hasPrev := state.lineSide[line.LineID]
+		prevPoint := state.linePoint[line.LineID]
+		state.lineSide[line.LineID] = side
+		state.linePoint[line.LineID] = point
+
+		if !hasPrev || (prevSide > 0) == (side > 0) || !segmentCrossesLine(line, prevPoint, point) {
+			continue
+		}
+
+		// Interpolate the crossing point and time (side values vary linearly along the segment)
+		t := prevSide / (prevSide - side)
+		direction := DirectionLeftToRight
+		if prevSide > 0 {
+			direction = DirectionRightToLeft
+		}
+		crossTime := prevPoint.Timestamp.Add(time.Duration(t * float64(point.Timestamp.Sub(prevPoint.Timestamp))))
+
+		events = append(events, models.SpatialEvent{
+			Type:        SpatialEventLineCross,
+			RuleID:      line.LineID,
+			TrackID:     trackID,
+			Class:       state.class,
+			Timestamp:   sa.seconds(crossTime),
+			FrameNumber: point.FrameNum,
+			Position: models.SpatialPoint{
+				X: prevPoint.X + t*(point.X-prevPoint.X),
+				Y: prevPoint.Y + t*(point.Y-prevPoint.Y),
+			},
+			Direction: direction,
+		})
+	}
+
+	// Step 2: Zone enter/exit and dwell
+	for _, zone := range sa.rules.Zones {
+		if !classAllowed(zone.Classes, state.class) {
+			continue
+		}
+
+		enteredAt, wasInside := state.inside[zone.ZoneID]
+		isInside := pointInPolygon(zone.Polygon, point.X, point.Y)
+
+		switch {
+		case isInside && !wasInside:
+			state.inside[zone.ZoneID] = point.Timestamp
+			state.dwelled[zone.ZoneID] = false
+			sa.stats[zone.ZoneID].Entries++
+			events = append(events, models.SpatialEvent{
+				Type:        SpatialEventZoneEnter,
+				RuleID:      zone.ZoneID,
+				TrackID:     trackID,
+				Class:       state.class,
+				Timestamp:   sa.seconds(point.Timestamp),
+				FrameNumber: point.FrameNum,
+				Position:    position,
+			})
+
+		case isInside && wasInside:
+			dwell := point.Timestamp.Sub(enteredAt).Seconds()
+			if zone.DwellThreshold > 0 && !state.dwelled[zone.ZoneID] && dwell >= zone.DwellThreshold {
+				state.dwelled[zone.ZoneID] = true
+				sa.stats[zone.ZoneID].DwellEvents++
+				events = append(events, models.SpatialEvent{
+					Type:        SpatialEventDwell,
+					RuleID:      zone.ZoneID,
+					TrackID:     trackID,
+					Class:       state.class,
+					Timestamp:   sa.seconds(point.Timestamp),
+					FrameNumber: point.FrameNum,
+					Position:    position,
+					DwellTime:   dwell,
+				})
+			}
+
+		case !isInside && wasInside:
+			events = append(events, sa.exitZone(trackID, state, zone.ZoneID, point))
+		}
+	}
+
+	return events
+}
+
+// exitAll closes every zone visit of a track at its last observed point
+func (sa *SpatialAnalyzer) exitAll(trackID string, state *spatialTrackState) []models.SpatialEvent {
+	events := make([]models.SpatialEvent, 0, len(state.inside))
+	for _, zone := range sa.rules.Zones {
+		if _, inside := state.inside[zone.ZoneID]; inside {
+			events = append(events, sa.exitZone(trackID, state, zone.ZoneID, state.lastPoint))
+		}
+	}
+	return events
+}
+
+// exitZone ends a zone visit and records its duration
+func (sa *SpatialAnalyzer) exitZone(trackID string, state *spatialTrackState, zoneID string, point TrajectoryPoint) models.SpatialEvent {
+	dwell := point.Timestamp.Sub(state.inside[zoneID]).Seconds()
+	delete(state.inside, zoneID)
+	delete(state.dwelled, zoneID)
+
+	stats := sa.stats[zoneID]
+	stats.Exits++
+	sa.dwellSum[zoneID] += dwell
+	if dwell > stats.MaxDwell {
+		stats.MaxDwell = dwell
+	}
+
+	return models.SpatialEvent{
+		Type:        SpatialEventZoneExit,
+		RuleID:      zoneID,
+		TrackID:     trackID,
+		Class:       state.class,
+		Timestamp:   sa.seconds(point.Timestamp),
+		FrameNumber: point.FrameNum,
+		Position:    models.SpatialPoint{X: point.X, Y: point.Y},
+		DwellTime:   dwell,
+	}
+}
+
+// sampleOccupancy records and returns zone counts that changed since the last sample
+func (sa *SpatialAnalyzer) sampleOccupancy(frameNum int, frameTime time.Time) []models.OccupancySample {
+	samples := make([]models.OccupancySample, 0)
+	for _, zone := range sa.rules.Zones {
+		count := 0
+		byClass := make(map[string]int)
+		for _, state := range sa.tracks {
+			if _, inside := state.inside[zone.ZoneID]; inside {
+				count++
+				byClass[state.class]++
+			}
+		}
+
+		last, sampled := sa.occupancy[zone.ZoneID]
+		if sampled && last == count {
+			continue
+		}
+		sa.occupancy[zone.ZoneID] = count
+
+		samples = append(samples, models.OccupancySample{
+			ZoneID:      zone.ZoneID,
+			Timestamp:   sa.seconds(frameTime),
+			FrameNumber: frameNum,
+			Count:       count,
+			ByClass:     byClass,
+		})
+		if count > sa.stats[zone.ZoneID].MaxOccupancy {
+			sa.stats[zone.ZoneID].MaxOccupancy = count
+		}
+	}
+
+	sa.samples = append(sa.samples, samples...)
+	return samples
+}
+
+// seconds converts a time to seconds since the analyzer epoch
+func (sa *SpatialAnalyzer) seconds(t time.Time) float64 {
+	return t.Sub(sa.epoch).Seconds()
+}
+
+// sortedTrackIDs returns tracked IDs in a stable order
+func (sa *SpatialAnalyzer) sortedTrackIDs() []string {
+	ids := make([]string, 0, len(sa.tracks))
+	for id := range sa.tracks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// sortSpatialEvents orders events by time, then track and rule
+func sortSpatialEvents(events []models.SpatialEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Timestamp != events[j].Timestamp {
+			return events[i].Timestamp < events[j].Timestamp
+		}
+		if events[i].TrackID != events[j].TrackID {
+			return events[i].TrackID < events[j].TrackID
+		}
+		return events[i].RuleID < events[j].RuleID
+	})
+}
+
+// classAllowed reports whether a class passes a rule's class filter (empty = all)
+func classAllowed(classes []string, class string) bool {
+	if len(classes) == 0 {
+		return true
+	}
+	for _, allowed := range classes {
+		if strings.EqualFold(allowed, class) {
+			return true
+		}
+	}
+	return false
+}
+
+// lineSide returns the signed side of a point relative to the line (positive = right of Start->End)
+func lineSide(line models.TripLine, point TrajectoryPoint) float64 {
+	side := (line.End.X-line.Start.X)*(point.Y-line.Start.Y) - (line.End.Y-line.Start.Y)*(point.X-line.Start.X)
+	if math.Abs(side) < 1e-12 {
+		return 0
+	}
+	return side
+}
+
+// segmentCrossesLine reports whether the movement a->b passes between the line's endpoints
+func segmentCrossesLine(line models.TripLine, a, b TrajectoryPoint) bool {
+	side := func(px, py float64) float64 {
+		return (b.X-a.X)*(py-a.Y) - (b.Y-a.Y)*(px-a.X)
+	}
+	s1 := side(line.Start.X, line.Start.Y)
+	s2 := side(line.End.X, line.End.Y)
+	return (s1 <= 0 && s2 >= 0) || (s1 >= 0 && s2 <= 0)
+}
+
+// pointInPolygon tests containment with the even-odd ray casting rule
+func pointInPolygon(polygon []models.SpatialPoint, x, y float64) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		pi, pj := polygon[i], polygon[j]
+		if (pi.Y > y) != (pj.Y > y) && x < (pj.X-pi.X)*(y-pi.Y)/(pj.Y-pi.Y)+pi.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,296 @@
+package tracking
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+var spatialEpoch = time.Unix(0, 0).UTC()
+
+// spatialTime returns the video time of a frame sampled every step seconds
+func spatialTime(frameNum int, step float64) time.Time {
+	return spatialEpoch.Add(time.Duration(float64(frameNum) * step * float64(time.Second)))
+}
+
+// walkTrack feeds one track through the analyzer, one trajectory point per frame
+func walkTrack(t *testing.T, analyzer *SpatialAnalyzer, class ObjectClass, positions [][2]float64, step float64) []models.SpatialEvent {
+	t.Helper()
+	obj := TrackedObject{TrackID: "track_1", Class: class}
+	events := make([]models.SpatialEvent, 0)
+	for frameNum, position := range positions {
+		frameTime := spatialTime(frameNum, step)
+		obj.Trajectory = append(obj.Trajectory, TrajectoryPoint{X: position[0], Y: position[1], Timestamp: frameTime, FrameNum: frameNum})
+		frameEvents, _ := analyzer.Update([]TrackedObject{obj}, frameNum, frameTime)
+		events = append(events, frameEvents...)
+	}
+	return events
+}
+
+func eventsOfType(events []models.SpatialEvent, eventType string) []models.SpatialEvent {
+	matching := make([]models.SpatialEvent, 0)
+	for _, event := range events {
+		if event.Type == eventType {
+			matching = append(matching, event)
+		}
+	}
+	return matching
+}
+
+func newAnalyzer(t *testing.T, rules models.SpatialRules) *SpatialAnalyzer {
+	t.Helper()
+	analyzer, err := NewSpatialAnalyzer(rules, spatialEpoch)
+	if err != nil {
+		t.Fatalf("NewSpatialAnalyzer: %v", err)
+	}
+	return analyzer
+}
+
+func TestSpatialLineCrossing(t *testing.T) {
+	// A vertical line drawn bottom to top: its left is the left of the frame
+	fullLine := models.TripLine{LineID: "gate", Start: models.SpatialPoint{X: 0.5, Y: 1}, End: models.SpatialPoint{X: 0.5, Y: 0}}
+	shortLine := models.TripLine{LineID: "gate", Start: models.SpatialPoint{X: 0.5, Y: 0.6}, End: models.SpatialPoint{X: 0.5, Y: 0.4}}
+	carLine := fullLine
+	carLine.Classes = []string{"car"}
+
+	tests := []struct {
+		name      string
+		line      models.TripLine
+		positions [][2]float64
+		step      float64
+		crossed   bool
+		direction string
+		frame     int
+		time      float64
+		position  models.SpatialPoint
+	}{
+		{
+			name:      "left to right",
+			line:      fullLine,
+			positions: [][2]float64{{0.2, 0.5}, {0.4, 0.5}, {0.6, 0.5}, {0.8, 0.5}},
+			step:      0.5,
+			crossed:   true,
+			direction: DirectionLeftToRight,
+			frame:     2,
+			time:      0.75,
+			position:  models.SpatialPoint{X: 0.5, Y: 0.5},
+		},
+		{
+			name:      "right to left",
+			line:      fullLine,
+			positions: [][2]float64{{0.8, 0.5}, {0.6, 0.5}, {0.4, 0.5}, {0.2, 0.5}},
+			step:      0.5,
+			crossed:   true,
+			direction: DirectionRightToLeft,
+			frame:     2,
+			time:      0.75,
+			position:  models.SpatialPoint{X: 0.5, Y: 0.5},
+		},
+		{
+			name:      "crossing time interpolated along the segment",
+			line:      fullLine,
+			positions: [][2]float64{{0.3, 0.2}, {0.9, 0.8}},
+			step:      0.6,
+			crossed:   true,
+			direction: DirectionLeftToRight,
+			frame:     1,
+			time:      0.2,
+			position:  models.SpatialPoint{X: 0.5, Y: 0.4},
+		},
+		{
+			name:      "pausing on the line",
+			line:      fullLine,
+			positions: [][2]float64{{0.4, 0.5}, {0.5, 0.5}, {0.5, 0.5}, {0.6, 0.5}},
+			step:      0.5,
+			crossed:   true,
+			direction: DirectionLeftToRight,
+			frame:     3,
+			time:      0.75,
+			position:  models.SpatialPoint{X: 0.5, Y: 0.5},
+		},
+		{
+			name:      "passing outside the endpoints",
+			line:      shortLine,
+			positions: [][2]float64{{0.2, 0.8}, {0.4, 0.8}, {0.6, 0.8}, {0.8, 0.8}},
+			step:      0.5,
+		},
+		{
+			name:      "turning back before the line",
+			line:      fullLine,
+			positions: [][2]float64{{0.2, 0.5}, {0.45, 0.5}, {0.2, 0.5}},
+			step:      0.5,
+		},
+		{
+			name:      "class not counted by the line",
+			line:      carLine,
+			positions: [][2]float64{{0.2, 0.5}, {0.8, 0.5}},
+			step:      0.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := newAnalyzer(t, models.SpatialRules{Lines: []models.TripLine{tt.line}})
+			crossings := eventsOfType(walkTrack(t, analyzer, ClassPerson, tt.positions, tt.step), SpatialEventLineCross)
+
+			if !tt.crossed {
+				if len(crossings) != 0 {
+					t.Fatalf("unexpected crossings: %+v", crossings)
+				}
+				return
+			}
+			if len(crossings) != 1 {
+				t.Fatalf("got %d crossings, want 1", len(crossings))
+			}
+			crossing := crossings[0]
+			if crossing.Direction != tt.direction {
+				t.Errorf("Direction = %s, want %s", crossing.Direction, tt.direction)
+			}
+			if crossing.FrameNumber != tt.frame {
+				t.Errorf("FrameNumber = %d, want %d", crossing.FrameNumber, tt.frame)
+			}
+			if math.Abs(crossing.Timestamp-tt.time) > 1e-6 {
+				t.Errorf("Timestamp = %v, want %v", crossing.Timestamp, tt.time)
+			}
+			if math.Abs(crossing.Position.X-tt.position.X) > 1e-9 || math.Abs(crossing.Position.Y-tt.position.Y) > 1e-9 {
+				t.Errorf("Position = %+v, want %+v", crossing.Position, tt.position)
+			}
+			if crossing.RuleID != "gate" || crossing.TrackID != "track_1" || crossing.Class != string(ClassPerson) {
+				t.Errorf("crossing = %+v, want gate/track_1/person", crossing)
+			}
+		})
+	}
+}
+
+// centreZone covers the middle fifth of the frame
+func centreZone(dwellThreshold float64) models.SpatialRules {
+	return models.SpatialRules{Zones: []models.Zone{{
+		ZoneID:         "centre",
+		Polygon:        []models.SpatialPoint{{X: 0.4, Y: 0}, {X: 0.6, Y: 0}, {X: 0.6, Y: 1}, {X: 0.4, Y: 1}},
+		DwellThreshold: dwellThreshold,
+	}}}
+}
+
+func TestSpatialZoneEnterDwellExit(t *testing.T) {
+	analyzer := newAnalyzer(t, centreZone(1.0))
+
+	// Enters at frame 1, stays for frames 1-5 and leaves at frame 6 (0.5s per frame)
+	positions := [][2]float64{{0.2, 0.5}, {0.5, 0.5}, {0.5, 0.5}, {0.5, 0.5}, {0.5, 0.5}, {0.5, 0.5}, {0.8, 0.5}}
+	events := walkTrack(t, analyzer, ClassPerson, positions, 0.5)
+
+	want := []struct {
+		eventType string
+		frame     int
+		time      float64
+		dwell     float64
+	}{
+		{SpatialEventZoneEnter, 1, 0.5, 0},
+		{SpatialEventDwell, 3, 1.5, 1.0},
+		{SpatialEventZoneExit, 6, 3.0, 2.5},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events (%+v), want %d", len(events), events, len(want))
+	}
+	for i, w := range want {
+		event := events[i]
+		if event.Type != w.eventType || event.FrameNumber != w.frame || math.Abs(event.Timestamp-w.time) > 1e-6 || math.Abs(event.DwellTime-w.dwell) > 1e-6 {
+			t.Errorf("event %d = %s frame %d at %vs dwell %v, want %s frame %d at %vs dwell %v",
+				i, event.Type, event.FrameNumber, event.Timestamp, event.DwellTime, w.eventType, w.frame, w.time, w.dwell)
+		}
+	}
+
+	stats := analyzer.Stats()[0]
+	if stats.Entries != 1 || stats.Exits != 1 || stats.DwellEvents != 1 || stats.MaxOccupancy != 1 {
+		t.Errorf("stats = %+v, want one visit with one dwell event", stats)
+	}
+	if math.Abs(stats.MaxDwell-2.5) > 1e-6 || math.Abs(stats.MeanDwell-2.5) > 1e-6 {
+		t.Errorf("dwell stats = max %v mean %v, want 2.5s", stats.MaxDwell, stats.MeanDwell)
+	}
+
+	counts := make([]int, 0)
+	for _, sample := range analyzer.Occupancy() {
+		counts = append(counts, sample.Count)
+	}
+	if len(counts) != 3 || counts[0] != 0 || counts[1] != 1 || counts[2] != 0 {
+		t.Errorf("occupancy counts = %v, want only the changes [0 1 0]", counts)
+	}
+}
+
+func TestSpatialDwellFiresOncePerVisit(t *testing.T) {
+	analyzer := newAnalyzer(t, centreZone(0.5))
+
+	// Two visits of 1.5s each; dwell fires once in each, not on every frame past the threshold
+	positions := [][2]float64{
+		{0.5, 0.5}, {0.5, 0.5}, {0.5, 0.5}, {0.5, 0.5},
+		{0.8, 0.5},
+		{0.5, 0.5}, {0.5, 0.5}, {0.5, 0.5}, {0.5, 0.5},
+	}
+	events := walkTrack(t, analyzer, ClassPerson, positions, 0.5)
+
+	dwells := eventsOfType(events, SpatialEventDwell)
+	if len(dwells) != 2 || dwells[0].FrameNumber != 1 || dwells[1].FrameNumber != 6 {
+		t.Fatalf("dwell events = %+v, want frames 1 and 6", dwells)
+	}
+	if len(eventsOfType(events, SpatialEventZoneEnter)) != 2 || len(eventsOfType(events, SpatialEventZoneExit)) != 1 {
+		t.Errorf("events = %+v, want two entries and one exit", events)
+	}
+}
+
+func TestSpatialExitAfterGracePeriod(t *testing.T) {
+	analyzer := newAnalyzer(t, centreZone(0))
+
+	// Seen inside the zone for frames 0-2, then only predicted (lost) until frame 5
+	obj := TrackedObject{TrackID: "track_1", Class: ClassPerson}
+	for frameNum := 0; frameNum <= 2; frameNum++ {
+		obj.Trajectory = append(obj.Trajectory, TrajectoryPoint{X: 0.5, Y: 0.5, Timestamp: spatialTime(frameNum, 0.5), FrameNum: frameNum})
+		analyzer.Update([]TrackedObject{obj}, frameNum, spatialTime(frameNum, 0.5))
+	}
+
+	lost := obj
+	lost.LostFrames = 1
+	lost.Trajectory = append(append([]TrajectoryPoint(nil), obj.Trajectory...), TrajectoryPoint{X: 0.9, Y: 0.5, Timestamp: spatialTime(3, 0.5), FrameNum: 3})
+	for frameNum := 3; frameNum <= 4; frameNum++ {
+		// Still within the 1s grace period of the last observation at 1.0s
+		events, _ := analyzer.Update([]TrackedObject{lost}, frameNum, spatialTime(frameNum, 0.5))
+		if len(events) != 0 {
+			t.Fatalf("frame %d: events %+v inside the grace period", frameNum, events)
+		}
+	}
+
+	events, occupancy := analyzer.Update(nil, 5, spatialTime(5, 0.5))
+	if len(events) != 1 || events[0].Type != SpatialEventZoneExit {
+		t.Fatalf("events = %+v, want a zone exit after the grace period", events)
+	}
+	exit := events[0]
+	if exit.FrameNumber != 2 || math.Abs(exit.Timestamp-1.0) > 1e-6 || math.Abs(exit.DwellTime-1.0) > 1e-6 {
+		t.Errorf("exit at frame %d, %vs after %vs; want the last observation (frame 2, 1.0s, 1.0s)", exit.FrameNumber, exit.Timestamp, exit.DwellTime)
+	}
+	if len(occupancy) != 1 || occupancy[0].Count != 0 || occupancy[0].FrameNumber != 5 {
+		t.Errorf("occupancy = %+v, want the zone emptied at frame 5", occupancy)
+	}
+
+	// The track returns with its old trajectory: only the new point is evaluated
+	obj.Trajectory = append(obj.Trajectory, TrajectoryPoint{X: 0.5, Y: 0.5, Timestamp: spatialTime(6, 0.5), FrameNum: 6})
+	events, _ = analyzer.Update([]TrackedObject{obj}, 6, spatialTime(6, 0.5))
+	if len(events) != 1 || events[0].Type != SpatialEventZoneEnter || events[0].FrameNumber != 6 {
+		t.Errorf("events = %+v, want one zone enter at frame 6", events)
+	}
+}
+
+func TestSpatialFinishClosesOpenVisits(t *testing.T) {
+	analyzer := newAnalyzer(t, centreZone(0))
+	walkTrack(t, analyzer, ClassPerson, [][2]float64{{0.2, 0.5}, {0.5, 0.5}, {0.5, 0.5}}, 0.5)
+
+	events, occupancy := analyzer.Finish(3, spatialTime(3, 0.5))
+	if len(events) != 1 || events[0].Type != SpatialEventZoneExit || math.Abs(events[0].DwellTime-0.5) > 1e-6 {
+		t.Fatalf("events = %+v, want the open visit closed after 0.5s", events)
+	}
+	if len(occupancy) != 1 || occupancy[0].Count != 0 {
+		t.Errorf("occupancy = %+v, want the zone emptied", occupancy)
+	}
+	if events, _ := analyzer.Finish(4, spatialTime(4, 0.5)); len(events) != 0 {
+		t.Errorf("second Finish returned %+v", events)
+	}
+}
@@ -0,0 +1,426 @@
+package tracking
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/adverant/nexus/videoagent-worker/internal/models"
+)
+
+// TrackVisualizer renders dashboard summaries of tracks in pure Go:
+// per-class occupancy heatmaps, a trajectory overlay and a Gantt-style timeline
+type TrackVisualizer struct {
+	epoch          time.Time     // Trajectory timestamps are offsets from this time
+	gridColumns    int           // Heatmap grid columns
+	gridRows       int           // Heatmap grid rows
+	imageWidth     int           // Rendered image width (height follows the frame aspect ratio)
+	sampleInterval time.Duration // Time represented by one observation
+	gapThreshold   time.Duration // Longer gaps split timeline segments
+}
+
+// NewTrackVisualizer creates a visualizer for trajectories timed relative to epoch
+func NewTrackVisualizer(epoch time.Time) *TrackVisualizer {
+	return &TrackVisualizer{
+		epoch:          epoch,
+		gridColumns:    32,
+		gridRows:       18,
+		imageWidth:     640,
+		sampleInterval: 200 * time.Millisecond, // 5 fps tracking
+		gapThreshold:   time.Second,
+	}
+}
+
+// SetSampleInterval sets the time between tracking samples
+// Gaps longer than three intervals (at least one second) split timeline segments.
+func (tv *TrackVisualizer) SetSampleInterval(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	tv.sampleInterval = interval
+	tv.gapThreshold = 3 * interval
+	if tv.gapThreshold < time.Second {
+		tv.gapThreshold = time.Second
+	}
+}
+
+// SetGridSize sets the heatmap grid resolution
+func (tv *TrackVisualizer) SetGridSize(columns, rows int) {
+	if columns > 0 && rows > 0 {
+		tv.gridColumns = columns
+		tv.gridRows = rows
+	}
+}
+
+// Build produces heatmaps, timeline and rendered images
+// background is a representative frame for the overlays (nil renders on a plain canvas).
+func (tv *TrackVisualizer) Build(tracks []TrackedObject, analyses map[string]*TrajectoryAnalysis, background image.Image) (*models.TrackingVisuals, error) {
+	visuals := &models.TrackingVisuals{
+		Heatmaps: tv.Heatmaps(tracks),
+		Timeline: tv.Timeline(tracks, analyses),
+		Images:   make([]models.TrackingImage, 0),
+	}
+
+	for _, heatmap := range visuals.Heatmaps {
+		img, err := tv.RenderHeatmap(heatmap, background)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s heatmap: %w", heatmap.Class, err)
+		}
+		visuals.Images = append(visuals.Images, img)
+	}
+
+	img, err := tv.RenderTrajectories(tracks, analyses, background)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render trajectories: %w", err)
+	}
+	visuals.Images = append(visuals.Images, img)
+
+	return visuals, nil
+}
+
+// Heatmaps accumulates the time each class spent in each grid cell ("all" first, then by class)
+func (tv *TrackVisualizer) Heatmaps(tracks []TrackedObject) []models.OccupancyHeatmap {
+	grids := map[string]*models.OccupancyHeatmap{"all": tv.newHeatmap("all")}
+	classes := make([]string, 0)
+
+	for _, track := range tracks {
+		class := string(track.Class)
+		if _, exists := grids[class]; !exists {
+			grids[class] = tv.newHeatmap(class)
+			classes = append(classes, class)
+		}
+
+		for i, point := range track.Trajectory {
+			weight := tv.pointWeight(track.Trajectory, i)
+			col := minInt(int(clampUnit(point.X)*float64(tv.gridColumns)), tv.gridColumns-1)
+			row := minInt(int(clampUnit(point.Y)*float64(tv.gridRows)), tv.gridRows-1)
+			cell := row*tv.gridColumns + col
+
+			for _, grid := range []*models.OccupancyHeatmap{grids["all"], grids[class]} {
+				grid.Cells[cell] += weight
+				grid.Total += weight
+				if grid.Cells[cell] > grid.MaxValue {
+					grid.MaxValue = grid.Cells[cell]
+				}
+			}
+		}
+	}
+
+	sort.Strings(classes)
+	heatmaps := []models.OccupancyHeatmap{*grids["all"]}
+	for _, class := range classes {
+		heatmaps = append(heatmaps, *grids[class])
+	}
+	return heatmaps
+}
+
+// newHeatmap creates an empty grid for a class
+func (tv *TrackVisualizer) newHeatmap(class string) *models.OccupancyHeatmap {
+	return &models.OccupancyHeatmap{
+		Class:   class,
+		Columns: tv.gridColumns,
+		Rows:    tv.gridRows,
+		Cells:   make([]float64, tv.gridColumns*tv.gridRows),
+	}
+}
+
+// pointWeight is the time (seconds) an observation stands for: the interval to the next
+// observation, capped at the sample interval across detection gaps
+func (tv *TrackVisualizer) pointWeight(trajectory []TrajectoryPoint, i int) float64 {
+	interval := tv.sampleInterval
+	if i+1 < len(trajectory) {
+		next := trajectory[i+1].Timestamp.Sub(trajectory[i].Timestamp)
+		if next > 0 && next <= tv.gapThreshold {
+			interval = next
+		}
+	}
+	return interval.Seconds()
+}
+
+// Timeline builds a Gantt-style row per track, ordered by start time
+func (tv *TrackVisualizer) Timeline(tracks []TrackedObject, analyses map[string]*TrajectoryAnalysis) models.TrackTimeline {
+	timeline := models.TrackTimeline{
+		Tracks: make([]models.TimelineTrack, 0, len(tracks)),
+	}
+
+	for _, track := range tracks {
+		if len(track.Trajectory) == 0 {
+			continue
+		}
+
+		row := models.TimelineTrack{
+			TrackID:  track.TrackID,
+			Class:    string(track.Class),
+			Segments: make([]models.TimelineSegment, 0, 1),
+		}
+		if analysis := analyses[track.TrackID]; analysis != nil {
+			row.Pattern = string(analysis.Pattern)
+			row.AverageSpeed = analysis.AverageSpeed
+			row.TotalDistance = analysis.TotalDistance
+		}
+
+		first := track.Trajectory[0]
+		segment := models.TimelineSegment{
+			Start:      tv.seconds(first.Timestamp),
+			StartFrame: first.FrameNum,
+		}
+		prev := first
+		for _, point := range track.Trajectory[1:] {
+			if point.Timestamp.Sub(prev.Timestamp) > tv.gapThreshold {
+				segment.End = tv.seconds(prev.Timestamp) + tv.sampleInterval.Seconds()
+				segment.EndFrame = prev.FrameNum
+				row.Segments = append(row.Segments, segment)
+				segment = models.TimelineSegment{
+					Start:      tv.seconds(point.Timestamp),
+					StartFrame: point.FrameNum,
+				}
+			}
+			prev = point
+		}
+		segment.End = tv.seconds(prev.Timestamp) + tv.sampleInterval.Seconds()
+		segment.EndFrame = prev.FrameNum
+		row.Segments = append(row.Segments, segment)
+
+		if segment.End > timeline.Duration {
+			timeline.Duration = segment.End
+		}
+		timeline.Tracks = append(timeline.Tracks, row)
+	}
+
+	sort.SliceStable(timeline.Tracks, func(i, j int) bool {
+		a, b := timeline.Tracks[i], timeline.Tracks[j]
+		if a.Segments[0].Start != b.Segments[0].Start {
+			return a.Segments[0].Start < b.Segments[0].Start
+		}
+		return a.TrackID < b.TrackID
+	})
+
+	return timeline
+}
+
+// seconds converts a trajectory timestamp to seconds since the epoch
+func (tv *TrackVisualizer) seconds(t time.Time) float64 {
+	return t.Sub(tv.epoch).Seconds()
+}
+
+// RenderHeatmap draws a heatmap over the (dimmed) background frame as PNG
+func (tv *TrackVisualizer) RenderHeatmap(heatmap models.OccupancyHeatmap, background image.Image) (models.TrackingImage, error) {
+	canvas := tv.newCanvas(background)
+	bounds := canvas.Bounds()
+
+	if heatmap.MaxValue > 0 {
+		for y := 0; y < bounds.Dy(); y++ {
+			for x := 0; x < bounds.Dx(); x++ {
+				// Sample the grid bilinearly at cell centres for a smooth map
+				gx := (float64(x)+0.5)/float64(bounds.Dx())*float64(heatmap.Columns) - 0.5
+				gy := (float64(y)+0.5)/float64(bounds.Dy())*float64(heatmap.Rows) - 0.5
+				value := sampleGrid(heatmap, gx, gy) / heatmap.MaxValue
+				if value <= 0.01 {
+					continue
+				}
+
+				// Square-root scale keeps briefly visited cells visible
+				level := math.Sqrt(value)
+				blendPixel(canvas, x, y, heatColor(level), 0.35+0.45*level)
+			}
+		}
+	}
+
+	return encodeTrackingImage("heatmap", heatmap.Class, canvas)
+}
+
+// RenderTrajectories draws every track path over the background frame as PNG
+// Paths start with a filled dot and end with a ring; dotted segments show predicted motion.
+func (tv *TrackVisualizer) RenderTrajectories(tracks []TrackedObject, analyses map[string]*TrajectoryAnalysis, background image.Image) (models.TrackingImage, error) {
+	canvas := tv.newCanvas(background)
+	width := float64(canvas.Bounds().Dx())
+	height := float64(canvas.Bounds().Dy())
+
+	ordered := make([]TrackedObject, len(tracks))
+	copy(ordered, tracks)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].TrackID < ordered[j].TrackID })
+
+	for _, track := range ordered {
+		if len(track.Trajectory) == 0 {
+			continue
+		}
+		c := TrackColor(track.TrackID)
+
+		for i := 1; i < len(track.Trajectory); i++ {
+			a, b := track.Trajectory[i-1], track.Trajectory[i]
+			if b.Timestamp.Sub(a.Timestamp) > tv.gapThreshold {
+				continue // Don't bridge detection gaps
+			}
+			drawLine(canvas, a.X*width, a.Y*height, b.X*width, b.Y*height, c, 2, false)
+		}
+
+		if analysis := analyses[track.TrackID]; analysis != nil && analysis.Pattern != PatternStationary {
+			last := track.Trajectory[len(track.Trajectory)-1]
+			px, py := last.X, last.Y
+			for _, prediction := range analysis.Predictions {
+				nx, ny := clampUnit(prediction.Position.X), clampUnit(prediction.Position.Y)
+				drawLine(canvas, px*width, py*height, nx*width, ny*height, c, 1, true)
+				px, py = nx, ny
+			}
+		}
+
+		first := track.Trajectory[0]
+		last := track.Trajectory[len(track.Trajectory)-1]
+		fillCircle(canvas, first.X*width, first.Y*height, 4, c)
+		drawRing(canvas, last.X*width, last.Y*height, 6, c)
+	}
+
+	return encodeTrackingImage("trajectories", "all", canvas)
+}
+
+// newCanvas creates an RGBA canvas with the background frame dimmed to greyscale
+func (tv *TrackVisualizer) newCanvas(background image.Image) *image.RGBA {
+	width := tv.imageWidth
+	height := width * 9 / 16
+	if background != nil && background.Bounds().Dx() > 0 {
+		src := background.Bounds()
+		height = maxInt(1, int(math.Round(float64(width)*float64(src.Dy())/float64(src.Dx()))))
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			grey := uint8(24)
+			if background != nil {
+				src := background.Bounds()
+				sx := src.Min.X + x*src.Dx()/width
+				sy := src.Min.Y + y*src.Dy()/height
+				r, g, b, _ := background.At(sx, sy).RGBA()
+				luma := (0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)) / 65535
+				grey = uint8(20 + luma*110)
+			}
+			canvas.SetRGBA(x, y, color.RGBA{grey, grey, grey, 255})
+		}
+	}
+	return canvas
+}
+
+// encodeTrackingImage encodes a canvas as a PNG tracking image
+func encodeTrackingImage(kind, class string, canvas *image.RGBA) (models.TrackingImage, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, canvas); err != nil {
+		return models.TrackingImage{}, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return models.TrackingImage{
+		Kind:        kind,
+		Class:       class,
+		ContentType: "image/png",
+		Width:       canvas.Bounds().Dx(),
+		Height:      canvas.Bounds().Dy(),
+		Data:        buf.Bytes(),
+	}, nil
+}
+
+// sampleGrid interpolates a heatmap bilinearly (coordinates in cells, centres at integers)
+func sampleGrid(heatmap models.OccupancyHeatmap, gx, gy float64) float64 {
+	cell := func(col, row int) float64 {
+		col = minInt(maxInt(col, 0), heatmap.Columns-1)
+		row = minInt(maxInt(row, 0), heatmap.Rows-1)
+		return heatmap.Cells[row*heatmap.Columns+col]
+	}
+
+	x0, y0 := int(math.Floor(gx)), int(math.Floor(gy))
+	fx, fy := gx-float64(x0), gy-float64(y0)
+	top := cell(x0, y0)*(1-fx) + cell(x0+1, y0)*fx
+	bottom := cell(x0, y0+1)*(1-fx) + cell(x0+1, y0+1)*fx
+	return top*(1-fy) + bottom*fy
+}
+
+// heatColor maps 0-1 to a blue-cyan-green-yellow-red ramp
+func heatColor(level float64) color.RGBA {
+	stops := []color.RGBA{
+		{0, 0, 255, 255},
+		{0, 255, 255, 255},
+		{0, 255, 0, 255},
+		{255, 255, 0, 255},
+		{255, 0, 0, 255},
+	}
+	position := clampUnit(level) * float64(len(stops)-1)
+	i := minInt(int(position), len(stops)-2)
+	f := position - float64(i)
+	lerp := func(a, b uint8) uint8 { return uint8(float64(a) + (float64(b)-float64(a))*f) }
+	return color.RGBA{lerp(stops[i].R, stops[i+1].R), lerp(stops[i].G, stops[i+1].G), lerp(stops[i].B, stops[i+1].B), 255}
+}
+
+// TrackColor picks a stable, saturated colour for a track ID (shared by all renderers)
+func TrackColor(trackID string) color.RGBA {
+	h := fnv.New32a()
+	h.Write([]byte(trackID))
+	hue := float64((h.Sum32()*2654435761)>>16%360) / 60 // Multiplicative mix spreads similar IDs
+
+	x := 1 - math.Abs(math.Mod(hue, 2)-1)
+	var r, g, b float64
+	switch int(hue) {
+	case 0:
+		r, g, b = 1, x, 0
+	case 1:
+		r, g, b = x, 1, 0
+	case 2:
+		r, g, b = 0, 1, x
+	case 3:
+		r, g, b = 0, x, 1
+	case 4:
+		r, g, b = x, 0, 1
+	default:
+		r, g, b = 1, 0, x
+	}
+	return color.RGBA{uint8(r * 255), uint8(g * 255), uint8(b * 255), 255}
+}
+
+// blendPixel alpha-blends a colour onto the canvas
+func blendPixel(canvas *image.RGBA, x, y int, c color.RGBA, alpha float64) {
+	if !(image.Point{x, y}.In(canvas.Bounds())) {
+		return
+	}
+	dst := canvas.RGBAAt(x, y)
+	mix := func(d, s uint8) uint8 { return uint8(float64(d)*(1-alpha) + float64(s)*alpha) }
+	canvas.SetRGBA(x, y, color.RGBA{mix(dst.R, c.R), mix(dst.G, c.G), mix(dst.B, c.B), 255})
+}
+
+// drawLine draws a line of the given thickness (dashed draws every other 6px)
+func drawLine(canvas *image.RGBA, x0, y0, x1, y1 float64, c color.RGBA, thickness float64, dashed bool) {
+	length := math.Hypot(x1-x0, y1-y0)
+	steps := maxInt(1, int(math.Ceil(length)))
+	for i := 0; i <= steps; i++ {
+		if dashed && (i/6)%2 == 1 {
+			continue
+		}
+		t := float64(i) / float64(steps)
+		fillCircle(canvas, x0+(x1-x0)*t, y0+(y1-y0)*t, thickness/2, c)
+	}
+}
+
+// fillCircle draws a filled disc
+func fillCircle(canvas *image.RGBA, cx, cy, radius float64, c color.RGBA) {
+	r := math.Max(radius, 0.5)
+	for y := int(math.Floor(cy - r)); y <= int(math.Ceil(cy+r)); y++ {
+		for x := int(math.Floor(cx - r)); x <= int(math.Ceil(cx+r)); x++ {
+			if math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy) <= r {
+				blendPixel(canvas, x, y, c, 1)
+			}
+		}
+	}
+}
+
+// drawRing draws a 2px circle outline
+func drawRing(canvas *image.RGBA, cx, cy, radius float64, c color.RGBA) {
+	for y := int(math.Floor(cy - radius - 1)); y <= int(math.Ceil(cy+radius+1)); y++ {
+		for x := int(math.Floor(cx - radius - 1)); x <= int(math.Ceil(cx+radius+1)); x++ {
+			d := math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy)
+			if d >= radius-1 && d <= radius+1 {
+				blendPixel(canvas, x, y, c, 1)
+			}
+		}
+	}
+}
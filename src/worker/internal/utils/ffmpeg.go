@@ -1,9 +1,12 @@
 package utils
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"image"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -577,3 +580,256 @@ func (h *FFmpegHelper) ValidateVideo(videoPath string) error {
 
 	return nil
 }
+
+// TempDir returns the helper's working directory
+func (h *FFmpegHelper) TempDir() string {
+	return h.tempDir
+}
+
+// HasFilter reports whether the installed ffmpeg provides a filter (e.g. "ass" needs libass)
+func (h *FFmpegHelper) HasFilter(name string) bool {
+	cmd := exec.Command(h.ffmpegPath, "-hide_banner", "-filters")
+
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[1] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderAnnotated re-encodes a video through a filter script, optionally muxing
+// an SRT file as a soft subtitle track (empty softSubtitlePath skips it)
+func (h *FFmpegHelper) RenderAnnotated(ctx context.Context, videoPath, filterScriptPath, softSubtitlePath, outputPath string) error {
+	args := []string{"-i", videoPath}
+	if softSubtitlePath != "" {
+		args = append(args, "-i", softSubtitlePath)
+	}
+
+	args = append(args,
+		"-filter_script:v", filterScriptPath,
+		"-map", "0:v:0",
+		"-map", "0:a?", // Keep audio when present
+	)
+	if softSubtitlePath != "" {
+		args = append(args, "-map", "1:s:0", "-c:s", "mov_text")
+	}
+
+	args = append(args,
+		"-c:v", "libx264",
+		"-preset", "veryfast",
+		"-crf", "23",
+		"-pix_fmt", "yuv420p",
+		"-c:a", "aac", // Source audio (PCM, Vorbis, Opus...) may not be muxable into MP4
+		"-b:a", "160k",
+		"-movflags", "+faststart",
+		"-y",
+		outputPath,
+	)
+
+	cmd := exec.CommandContext(ctx, h.ffmpegPath, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("annotated render failed: %w: %s", err, lastLines(string(output), 5))
+	}
+
+	return nil
+}
+
+// ExtractGrayFrames decodes a burst of frames as 8-bit grayscale scaled to width x height
+// Frames are sampled at fps from start for duration seconds; fewer frames are returned near the end.
+func (h *FFmpegHelper) ExtractGrayFrames(ctx context.Context, videoPath string, start, duration, fps float64, width, height int) ([]*image.Gray, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid frame size %dx%d", width, height)
+	}
+
+	cmd := exec.CommandContext(ctx, h.ffmpegPath,
+		"-ss", fmt.Sprintf("%.3f", start),
+		"-t", fmt.Sprintf("%.3f", duration),
+		"-i", videoPath,
+		"-vf", fmt.Sprintf("fps=%g,scale=%d:%d,format=gray", fps, width, height),
+		"-f", "rawvideo",
+		"-an",
+		"-",
+	)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("gray frame extraction failed: %w: %s", err, lastLines(stderr.String(), 5))
+	}
+
+	frameSize := width * height
+	frames := make([]*image.Gray, 0, len(output)/frameSize)
+	for offset := 0; offset+frameSize <= len(output); offset += frameSize {
+		frame := image.NewGray(image.Rect(0, 0, width, height))
+		copy(frame.Pix, output[offset:offset+frameSize])
+		frames = append(frames, frame)
+	}
+
+	return frames, nil
+}
+
+// ExtractThumbnail extracts the frame at timestamp as a JPEG scaled to width (height keeps the aspect ratio)
+func (h *FFmpegHelper) ExtractThumbnail(ctx context.Context, videoPath string, timestamp float64, width int, outputPath string) error {
+	cmd := exec.CommandContext(ctx, h.ffmpegPath,
+		"-ss", fmt.Sprintf("%.3f", timestamp),
+		"-i", videoPath,
+		"-vframes", "1",
+		"-vf", fmt.Sprintf("scale=%d:-2", width),
+		"-q:v", "2",
+		"-y",
+		outputPath,
+	)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("thumbnail extraction failed: %w: %s", err, lastLines(string(output), 5))
+	}
+
+	return nil
+}
+
+// GenerateSpriteSheets samples one frame every interval seconds, scales it to
+// tileWidth x tileHeight and tiles columns x rows frames per JPEG sheet
+// outputPattern is a printf-style path (e.g. "sprite_%03d.jpg"); the written sheets are returned in order.
+func (h *FFmpegHelper) GenerateSpriteSheets(ctx context.Context, videoPath string, interval float64, tileWidth, tileHeight, columns, rows int, outputPattern string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, h.ffmpegPath,
+		"-i", videoPath,
+		"-vf", fmt.Sprintf("fps=1/%g,scale=%d:%d,tile=%dx%d", interval, tileWidth, tileHeight, columns, rows),
+		"-q:v", "4",
+		"-an",
+		"-start_number", "0",
+		"-y",
+		outputPattern,
+	)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("sprite sheet generation failed: %w: %s", err, lastLines(string(output), 5))
+	}
+
+	sheets := []string{}
+	for i := 0; ; i++ {
+		path := fmt.Sprintf(outputPattern, i)
+		if _, err := os.Stat(path); err != nil {
+			break
+		}
+		sheets = append(sheets, path)
+	}
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("sprite sheet generation produced no sheets")
+	}
+
+	return sheets, nil
+}
+
+// RunFilterAnalysis decodes a video through an analysis filter graph and returns ffmpeg's log
+// The graph's output labels are mapped to a null muxer; analysis filters report through the log.
+func (h *FFmpegHelper) RunFilterAnalysis(ctx context.Context, videoPath, filterGraph string, outputs []string) (string, error) {
+	args := []string{"-hide_banner", "-nostats", "-i", videoPath, "-filter_complex", filterGraph}
+	for _, output := range outputs {
+		args = append(args, "-map", output)
+	}
+	args = append(args, "-f", "null", "-")
+
+	cmd := exec.CommandContext(ctx, h.ffmpegPath, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("filter analysis failed: %w: %s", err, lastLines(stderr.String(), 5))
+	}
+
+	return stderr.String(), nil
+}
+
+// CutClip re-encodes [start, start+duration) of the video to an H.264/AAC MP4
+// videoFilter (e.g. a crop) is applied when set. Every clip is encoded with the same
+// settings so clips of one source can be joined with ConcatClips without re-encoding.
+func (h *FFmpegHelper) CutClip(ctx context.Context, videoPath string, start, duration float64, videoFilter, outputPath string) error {
+	args := []string{
+		"-hide_banner",
+		"-ss", fmt.Sprintf("%.3f", start),
+		"-i", videoPath,
+		"-t", fmt.Sprintf("%.3f", duration),
+		"-map", "0:v:0",
+		"-map", "0:a:0?",
+	}
+	if videoFilter != "" {
+		args = append(args, "-vf", videoFilter)
+	}
+	args = append(args,
+		"-c:v", "libx264",
+		"-preset", "veryfast",
+		"-crf", "20",
+		"-pix_fmt", "yuv420p",
+		"-c:a", "aac",
+		"-b:a", "160k",
+		"-ar", "48000",
+		"-movflags", "+faststart",
+		"-y",
+		outputPath,
+	)
+
+	cmd := exec.CommandContext(ctx, h.ffmpegPath, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("clip extraction failed: %w: %s", err, lastLines(string(output), 5))
+	}
+
+	return nil
+}
+
+// ConcatClips joins clips end to end with the concat demuxer (stream copy)
+// The clips must share codecs and parameters, as CutClip output does.
+func (h *FFmpegHelper) ConcatClips(ctx context.Context, clipPaths []string, outputPath string) error {
+	if len(clipPaths) == 0 {
+		return fmt.Errorf("no clips to concatenate")
+	}
+
+	var list strings.Builder
+	for _, path := range clipPaths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve clip path: %w", err)
+		}
+		fmt.Fprintf(&list, "file '%s'\n", strings.ReplaceAll(abs, "'", `'\''`))
+	}
+
+	listPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + "_concat.txt"
+	if err := os.WriteFile(listPath, []byte(list.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write concat list: %w", err)
+	}
+	defer os.Remove(listPath)
+
+	cmd := exec.CommandContext(ctx, h.ffmpegPath,
+		"-hide_banner",
+		"-f", "concat",
+		"-safe", "0",
+		"-i", listPath,
+		"-c", "copy",
+		"-movflags", "+faststart",
+		"-y",
+		outputPath,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("clip concatenation failed: %w: %s", err, lastLines(string(output), 5))
+	}
+
+	return nil
+}
+
+// lastLines returns the last n non-empty lines of ffmpeg output for error messages
+func lastLines(output string, n int) string {
+	lines := make([]string, 0, n)
+	all := strings.Split(strings.TrimSpace(output), "\n")
+	for i := len(all) - 1; i >= 0 && len(lines) < n; i-- {
+		if line := strings.TrimSpace(all[i]); line != "" {
+			lines = append([]string{line}, lines...)
+		}
+	}
+	return strings.Join(lines, "; ")
+}